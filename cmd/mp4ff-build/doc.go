@@ -0,0 +1,21 @@
+/*
+mp4ff-build builds an MP4 file from a JSON or YAML description of its boxes.
+The description is a JSON array of boxes in the same format as the output of mp4ff-info -json.
+A description file ending in .yaml or .yml is read as YAML with the same structure.
+Box sizes are calculated. Counts and offsets that are zero or not given are calculated:
+sample entry and sample counts from the entries, trun data offsets into the following mdat,
+saio offsets to the senc data, and stco/co64 chunk offsets into the first mdat, track by track.
+The mdat payload can be given as a hex string in Data, or as a file name in DataFile.
+
+	Usage of mp4ff-build:
+
+		mp4ff-build [options] <descriptionFile> <outFile>
+
+	options:
+
+		-datadir string
+			Directory for relative DataFile names (default directory of descriptionFile)
+		-version
+			Get mp4ff version
+*/
+package main
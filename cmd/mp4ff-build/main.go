@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Eyevinn/mp4ff/internal"
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+const (
+	appName = "mp4ff-build"
+)
+
+var usg = `%s builds an MP4 file from a JSON or YAML description of its boxes.
+The description is a JSON array of boxes in the same format as the output of mp4ff-info -json.
+A description file ending in .yaml or .yml is read as YAML with the same structure.
+Box sizes are calculated. Counts and offsets that are zero or not given are calculated:
+sample entry and sample counts from the entries, trun data offsets into the following mdat,
+saio offsets to the senc data, and stco/co64 chunk offsets into the first mdat, track by track.
+The mdat payload can be given as a hex string in Data, or as a file name in DataFile.
+
+Usage of %s:
+`
+
+type options struct {
+	dataDir string
+	version bool
+}
+
+func parseOptions(fs *flag.FlagSet, args []string) (*options, error) {
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, usg, appName, appName)
+		fmt.Fprintf(os.Stderr, "\n%s [options] <descriptionFile> <outFile>\n\noptions:\n", appName)
+		fs.PrintDefaults()
+	}
+
+	opts := options{}
+
+	fs.StringVar(&opts.dataDir, "datadir", "", "Directory for relative DataFile names (default directory of descriptionFile)")
+	fs.BoolVar(&opts.version, "version", false, "Get mp4ff version")
+
+	err := fs.Parse(args[1:])
+	return &opts, err
+}
+
+func main() {
+	if err := run(os.Args); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet(appName, flag.ContinueOnError)
+	o, err := parseOptions(fs, args)
+
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if o.version {
+		fmt.Printf("%s %s\n", appName, internal.GetVersion())
+		return nil
+	}
+
+	if len(fs.Args()) != 2 {
+		fs.Usage()
+		return fmt.Errorf("must specify description file and output file")
+	}
+	descPath, outPath := fs.Arg(0), fs.Arg(1)
+
+	dataDir := o.dataDir
+	if dataDir == "" {
+		dataDir = filepath.Dir(descPath)
+	}
+
+	desc, err := os.ReadFile(descPath)
+	if err != nil {
+		return fmt.Errorf("error reading description: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(descPath)) {
+	case ".yaml", ".yml":
+		desc, err = yamlToJSON(desc)
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %w", descPath, err)
+		}
+	}
+	f, err := mp4.BuildFileFromJSON(bytes.NewReader(desc), dataDir)
+	if err != nil {
+		return fmt.Errorf("error building %s: %w", descPath, err)
+	}
+
+	ofh, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("error creating output file: %w", err)
+	}
+	defer ofh.Close()
+	err = f.Encode(ofh)
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %w", outPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommandLines(t *testing.T) {
+	tmpDir := t.TempDir()
+	outFile := filepath.Join(tmpDir, "init.mp4")
+	cases := []struct {
+		desc        string
+		args        []string
+		expectedErr bool
+	}{
+		{desc: "help", args: []string{appName, "-h"}, expectedErr: false},
+		{desc: "version", args: []string{appName, "-version"}, expectedErr: false},
+		{desc: "no args", args: []string{appName}, expectedErr: true},
+		{desc: "unknown args", args: []string{appName, "-x"}, expectedErr: true},
+		{desc: "non-existing description", args: []string{appName, "notExists.json", outFile}, expectedErr: true},
+		{desc: "bad description", args: []string{appName, "main.go", outFile}, expectedErr: true},
+		{desc: "bad output dir", args: []string{appName, "../../mp4/testdata/golden_init_mp4.json",
+			filepath.Join(tmpDir, "missing", "init.mp4")}, expectedErr: true},
+		{desc: "init segment", args: []string{appName, "../../mp4/testdata/golden_init_mp4.json", outFile}, expectedErr: false},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			err := run(c.args)
+			if c.expectedErr != (err != nil) {
+				t.Errorf("got error %v, expected error %t", err, c.expectedErr)
+			}
+		})
+	}
+	built, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, err := os.ReadFile("../../mp4/testdata/init.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(built, orig) {
+		t.Errorf("built init segment differs from original")
+	}
+}
+
+func TestYAMLDescription(t *testing.T) {
+	desc, err := os.ReadFile("../../mp4/testdata/golden_init_mp4.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(desc))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	writeYAML(&sb, value, "")
+	tmpDir := t.TempDir()
+	yamlFile := filepath.Join(tmpDir, "init.yaml")
+	if err := os.WriteFile(yamlFile, []byte(sb.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outFile := filepath.Join(tmpDir, "init.mp4")
+	if err := run([]string{appName, yamlFile, outFile}); err != nil {
+		t.Fatal(err)
+	}
+	built, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, err := os.ReadFile("../../mp4/testdata/init.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(built, orig) {
+		t.Errorf("init segment built from YAML differs from original")
+	}
+}
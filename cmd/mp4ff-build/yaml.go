@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The YAML support covers the subset needed for box descriptions:
+// block mappings and sequences, flow collections like [1, 2] and {a: 1},
+// plain, single-quoted, and double-quoted scalars, and comments.
+// Anchors, aliases, tags, multi-line scalars, and multiple documents are not supported.
+
+// yamlLine - a non-empty line without comment
+type yamlLine struct {
+	nr     int // one-based line number
+	indent int
+	text   string
+}
+
+var yamlNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// yamlToJSON converts a YAML document to JSON.
+func yamlToJSON(data []byte) ([]byte, error) {
+	value, err := parseYAML(string(data))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// parseYAML parses a YAML document into maps, slices, strings, bools, json.Number, and nil.
+func parseYAML(doc string) (interface{}, error) {
+	var lines []yamlLine
+	for i, line := range strings.Split(doc, "\n") {
+		line = strings.TrimRight(stripYAMLComment(line), " \r")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || (len(lines) == 0 && trimmed == "---") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("yaml line %d: tab in indentation", i+1)
+		}
+		lines = append(lines, yamlLine{nr: i + 1, indent: len(line) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	value, next, err := parseYAMLBlock(lines, 0, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if next < len(lines) {
+		return nil, fmt.Errorf("yaml line %d: unexpected indentation", lines[next].nr)
+	}
+	return value, nil
+}
+
+// stripYAMLComment removes a comment starting with # at the start or after a space, outside quotes.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" [{,", line[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' '):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// parseYAMLBlock parses the block starting at lines[i] with the given indentation.
+// It returns the value and the index of the first line after the block.
+func parseYAMLBlock(lines []yamlLine, i, indent int) (interface{}, int, error) {
+	switch {
+	case isYAMLSeqItem(lines[i].text):
+		return parseYAMLSeq(lines, i, indent)
+	case yamlKeyEnd(lines[i].text) >= 0:
+		return parseYAMLMap(lines, i, indent)
+	default:
+		value, err := parseYAMLInline(lines[i].text)
+		if err != nil {
+			return nil, i, fmt.Errorf("yaml line %d: %w", lines[i].nr, err)
+		}
+		return value, i + 1, nil
+	}
+}
+
+func parseYAMLSeq(lines []yamlLine, i, indent int) (interface{}, int, error) {
+	seq := make([]interface{}, 0)
+	for i < len(lines) && lines[i].indent == indent && isYAMLSeqItem(lines[i].text) {
+		rest := strings.TrimLeft(lines[i].text[1:], " ")
+		if rest == "" {
+			var item interface{}
+			i++
+			if i < len(lines) && lines[i].indent > indent {
+				var err error
+				item, i, err = parseYAMLBlock(lines, i, lines[i].indent)
+				if err != nil {
+					return nil, i, err
+				}
+			}
+			seq = append(seq, item)
+			continue
+		}
+		// The rest of the line starts a block indented to its column, e.g. "- key: value"
+		lines[i] = yamlLine{nr: lines[i].nr, indent: indent + len(lines[i].text) - len(rest), text: rest}
+		item, next, err := parseYAMLBlock(lines, i, lines[i].indent)
+		if err != nil {
+			return nil, next, err
+		}
+		seq = append(seq, item)
+		i = next
+	}
+	return seq, i, nil
+}
+
+func parseYAMLMap(lines []yamlLine, i, indent int) (interface{}, int, error) {
+	m := make(map[string]interface{})
+	for i < len(lines) && lines[i].indent == indent && !isYAMLSeqItem(lines[i].text) {
+		line := lines[i]
+		keyEnd := yamlKeyEnd(line.text)
+		if keyEnd < 0 {
+			return nil, i, fmt.Errorf("yaml line %d: no key", line.nr)
+		}
+		key, err := parseYAMLScalar(strings.TrimSpace(line.text[:keyEnd]))
+		if err != nil {
+			return nil, i, fmt.Errorf("yaml line %d: %w", line.nr, err)
+		}
+		keyStr := fmt.Sprint(key)
+		if _, ok := m[keyStr]; ok {
+			return nil, i, fmt.Errorf("yaml line %d: duplicate key %q", line.nr, keyStr)
+		}
+		valueText := strings.TrimSpace(line.text[keyEnd+1:])
+		i++
+		var value interface{}
+		switch {
+		case valueText != "":
+			value, err = parseYAMLInline(valueText)
+			if err != nil {
+				return nil, i, fmt.Errorf("yaml line %d: %w", line.nr, err)
+			}
+		case i < len(lines) && (lines[i].indent > indent || (lines[i].indent == indent && isYAMLSeqItem(lines[i].text))):
+			value, i, err = parseYAMLBlock(lines, i, lines[i].indent)
+			if err != nil {
+				return nil, i, err
+			}
+		}
+		m[keyStr] = value
+	}
+	if i < len(lines) && lines[i].indent > indent {
+		return nil, i, fmt.Errorf("yaml line %d: unexpected indentation", lines[i].nr)
+	}
+	return m, i, nil
+}
+
+// yamlKeyEnd returns the index of the colon ending a mapping key, or -1 if the text is not a mapping entry.
+func yamlKeyEnd(text string) int {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return -1
+	}
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && i == 0:
+			quote = c
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			return i
+		}
+	}
+	return -1
+}
+
+// parseYAMLInline parses a scalar or a flow collection.
+func parseYAMLInline(text string) (interface{}, error) {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		p := yamlFlowParser{text: text}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos != len(p.text) {
+			return nil, fmt.Errorf("unexpected %q after flow collection", p.text[p.pos:])
+		}
+		return value, nil
+	}
+	return parseYAMLScalar(text)
+}
+
+// parseYAMLScalar parses a quoted or plain scalar.
+func parseYAMLScalar(text string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, "\""):
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("bad double-quoted scalar %s", text)
+		}
+		return s, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("bad single-quoted scalar %s", text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	}
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if yamlNumber.MatchString(text) {
+		return json.Number(text), nil
+	}
+	return text, nil
+}
+
+// yamlFlowParser parses flow collections like [1, "a", {b: 2}].
+type yamlFlowParser struct {
+	text string
+	pos  int
+}
+
+func (p *yamlFlowParser) skipSpace() {
+	for p.pos < len(p.text) && p.text[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *yamlFlowParser) parseValue() (interface{}, error) {
+	p.skipSpace()
+	if p.pos == len(p.text) {
+		return nil, fmt.Errorf("unexpected end of flow collection")
+	}
+	switch p.text[p.pos] {
+	case '[':
+		return p.parseSeq()
+	case '{':
+		return p.parseMap()
+	}
+	return p.parseScalar()
+}
+
+func (p *yamlFlowParser) parseSeq() (interface{}, error) {
+	p.pos++ // [
+	seq := make([]interface{}, 0)
+	p.skipSpace()
+	if p.pos < len(p.text) && p.text[p.pos] == ']' {
+		p.pos++
+		return seq, nil
+	}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, value)
+		end, err := p.endOfItem(']')
+		if err != nil {
+			return nil, err
+		}
+		if end {
+			return seq, nil
+		}
+	}
+}
+
+func (p *yamlFlowParser) parseMap() (interface{}, error) {
+	p.pos++ // {
+	m := make(map[string]interface{})
+	p.skipSpace()
+	if p.pos < len(p.text) && p.text[p.pos] == '}' {
+		p.pos++
+		return m, nil
+	}
+	for {
+		key, err := p.parseScalar()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos == len(p.text) || p.text[p.pos] != ':' {
+			return nil, fmt.Errorf("missing colon after key %v", key)
+		}
+		p.pos++
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		m[fmt.Sprint(key)] = value
+		end, err := p.endOfItem('}')
+		if err != nil {
+			return nil, err
+		}
+		if end {
+			return m, nil
+		}
+	}
+}
+
+// endOfItem consumes a comma or the closing character, and returns true for the latter.
+func (p *yamlFlowParser) endOfItem(closing byte) (bool, error) {
+	p.skipSpace()
+	if p.pos == len(p.text) {
+		return false, fmt.Errorf("missing %c", closing)
+	}
+	switch p.text[p.pos] {
+	case ',':
+		p.pos++
+		return false, nil
+	case closing:
+		p.pos++
+		return true, nil
+	}
+	return false, fmt.Errorf("unexpected %q in flow collection", p.text[p.pos])
+}
+
+func (p *yamlFlowParser) parseScalar() (interface{}, error) {
+	p.skipSpace()
+	start := p.pos
+	if p.pos < len(p.text) && (p.text[p.pos] == '"' || p.text[p.pos] == '\'') {
+		quote := p.text[p.pos]
+		for p.pos++; p.pos < len(p.text); p.pos++ {
+			c := p.text[p.pos]
+			if quote == '"' && c == '\\' {
+				p.pos++
+				continue
+			}
+			if c == quote {
+				if quote == '\'' && p.pos+1 < len(p.text) && p.text[p.pos+1] == '\'' {
+					p.pos++
+					continue
+				}
+				p.pos++
+				return parseYAMLScalar(p.text[start:p.pos])
+			}
+		}
+		return nil, fmt.Errorf("unterminated quoted scalar")
+	}
+	for p.pos < len(p.text) && !strings.ContainsRune(",[]{}:", rune(p.text[p.pos])) {
+		p.pos++
+	}
+	return parseYAMLScalar(strings.TrimSpace(p.text[start:p.pos]))
+}
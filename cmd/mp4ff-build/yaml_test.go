@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	cases := []struct {
+		desc     string
+		yaml     string
+		wantJSON string
+		wantErr  bool
+	}{
+		{desc: "empty", yaml: "# only comment\n", wantJSON: `null`},
+		{desc: "scalars", yaml: "a: 1\nb: -2.5e3\nc: text with 'quote\nd: \"x # y\"\ne: 'it''s'\nf: ~\ng: true\nh: 0x10\n",
+			wantJSON: `{"a":1,"b":-2.5e3,"c":"text with 'quote","d":"x # y","e":"it's","f":null,"g":true,"h":"0x10"}`},
+		{desc: "nested", yaml: "---\ntop:\n  inner:\n    - 1\n    - two # comment\n  empty:\nlist:\n- a: 1\n  b: [1, \"2\", {c: 3}]\n- - x\n  - y\n-\n  z: {}\n",
+			wantJSON: `{"top":{"inner":[1,"two"],"empty":null},"list":[{"a":1,"b":[1,"2",{"c":3}]},["x","y"],{"z":{}}]}`},
+		{desc: "top-level sequence", yaml: "- type: ftyp\n  fields:\n    MajorBrand: iso5\n    CompatibleBrands: []\n",
+			wantJSON: `[{"type":"ftyp","fields":{"MajorBrand":"iso5","CompatibleBrands":[]}}]`},
+		{desc: "bad indentation", yaml: "a:\n  b: 1\n   c: 2\n", wantErr: true},
+		{desc: "duplicate key", yaml: "a: 1\na: 2\n", wantErr: true},
+		{desc: "tab indentation", yaml: "a:\n\tb: 1\n", wantErr: true},
+		{desc: "unterminated flow", yaml: "a: [1, 2\n", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			got, err := yamlToJSON([]byte(c.yaml))
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gotValue, wantValue interface{}
+			if err := json.Unmarshal(got, &gotValue); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(c.wantJSON), &wantValue); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotValue, wantValue) {
+				t.Errorf("got %s, want %s", got, c.wantJSON)
+			}
+		})
+	}
+}
+
+// writeYAML writes a decoded JSON value as block-style YAML.
+func writeYAML(sb *strings.Builder, value interface{}, indent string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for i, k := range keys {
+			if i > 0 {
+				sb.WriteString(indent)
+			}
+			writeYAMLEntry(sb, k+":", v[k], indent+"  ")
+		}
+	case []interface{}:
+		for i, item := range v {
+			if i > 0 {
+				sb.WriteString(indent)
+			}
+			writeYAMLEntry(sb, "-", item, indent+"  ")
+		}
+	}
+}
+
+func writeYAMLEntry(sb *strings.Builder, prefix string, value interface{}, indent string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			sb.WriteString(prefix + " {}\n")
+			return
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			sb.WriteString(prefix + " []\n")
+			return
+		}
+	case string:
+		sb.WriteString(fmt.Sprintf("%s %q\n", prefix, v))
+		return
+	case nil:
+		sb.WriteString(prefix + " null\n")
+		return
+	default:
+		sb.WriteString(fmt.Sprintf("%s %v\n", prefix, v))
+		return
+	}
+	if prefix == "-" {
+		sb.WriteString("- ")
+		writeYAML(sb, value, indent)
+		return
+	}
+	sb.WriteString(prefix + "\n" + indent)
+	writeYAML(sb, value, indent)
+}
@@ -2,6 +2,7 @@
 mp4ff-crop crops a (progressive) mp4 file to just before a sync frame after specified number of milliseconds.
 The goal is to leave the file structure intact except for cropping of samples and
 moving mdat to the end of the file, if not already there.
+The duration is in presentation time, so edit list offsets for B-frames or AAC priming are taken into account.
 
 	Usage of mp4ff-crop:
 
@@ -19,6 +19,7 @@ const (
 var usg = `%s crops a (progressive) mp4 file to just before a sync frame after specified number of milliseconds.
 The goal is to leave the file structure intact except for cropping of samples and
 moving mdat to the end of the file, if not already there.
+The duration is in presentation time, so edit list offsets for B-frames or AAC priming are taken into account.
 
 Usage of %s:
 `
@@ -151,10 +152,16 @@ func findEndTime(moov *mp4.MoovBox, durationMS int) (endTime, endTimescale uint6
 	//fmt.Printf("video trak %d duration = %.3fs\n", trak.Tkhd.TrackID, trakDur)
 	endTimescale = uint64(syncTrak.Mdia.Mdhd.Timescale)
 	endTime = uint64(durationMS) * endTimescale / 1000
+	// The duration is in presentation time, so the media start offset (e.g. from B-frames) is added
+	startOffset := syncTrak.PresentationTimeline(moov.Mvhd.Timescale).StartOffset()
 
 	stbl := syncTrak.Mdia.Minf.Stbl
 	stts := stbl.Stts // TimeToSampleBox
-	lastSampleNr, err := stts.GetSampleNrAtTime(endTime)
+	mediaEndTime, err := addTimeOffset(endTime, startOffset)
+	if err != nil {
+		return 0, 0, err
+	}
+	lastSampleNr, err := stts.GetSampleNrAtTime(mediaEndTime)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -173,14 +180,26 @@ func findEndTime(moov *mp4.MoovBox, durationMS int) (endTime, endTimescale uint6
 		}
 	}
 	lastTime, lastDur := stts.GetDecodeTime(lastSampleNr)
-	endTime = lastTime + uint64(lastDur)
-
+	endTime, err = addTimeOffset(lastTime+uint64(lastDur), -startOffset)
+	if err != nil {
+		return 0, 0, err
+	}
 	return endTime, endTimescale, nil
 }
 
+// addTimeOffset returns t + offset, or an error if the result is negative,
+// e.g. when an initial empty edit is longer than the crop duration.
+func addTimeOffset(t uint64, offset int64) (uint64, error) {
+	res := int64(t) + offset
+	if res < 0 {
+		return 0, fmt.Errorf("time %d with offset %d is before the start of the media", t, offset)
+	}
+	return uint64(res), nil
+}
+
 func cropToTime(inMP4 *mp4.File, endTime, endTimescale uint64, w io.Writer, ifh io.ReadSeeker) error {
 	traks := inMP4.Moov.Traks
-	tos, err := findTrakEnds(traks, endTime, endTimescale)
+	tos, err := findTrakEnds(traks, inMP4.Moov.Mvhd.Timescale, endTime, endTimescale)
 	if err != nil {
 		return err
 	}
@@ -218,7 +237,8 @@ type trakOut struct {
 }
 
 // findTrakEnds - find where traks end in form of last chunk, lastSampleNr and endTime
-func findTrakEnds(traks []*mp4.TrakBox, endTime, endTimescale uint64) (map[uint32]*trakOut, error) {
+// endTime is a presentation time, which is mapped to media time using the edit list of each trak.
+func findTrakEnds(traks []*mp4.TrakBox, movieTimescale uint32, endTime, endTimescale uint64) (map[uint32]*trakOut, error) {
 	tos := make(map[uint32]*trakOut, len(traks))
 	for _, trak := range traks {
 		trackID := trak.Tkhd.TrackID
@@ -232,6 +252,11 @@ func findTrakEnds(traks []*mp4.TrakBox, endTime, endTimescale uint64) (map[uint3
 		if trackTimeScale != uint32(endTimescale) {
 			trackEndTime = endTime * uint64(trackTimeScale) / endTimescale
 		}
+		startOffset := trak.PresentationTimeline(movieTimescale).StartOffset()
+		trackEndTime, err := addTimeOffset(trackEndTime, startOffset)
+		if err != nil {
+			return nil, fmt.Errorf("track %d: %w", trackID, err)
+		}
 		stts := stbl.Stts
 		endSampleNr, err := stts.GetSampleNrAtTime(trackEndTime)
 		if err != nil {
@@ -416,6 +441,8 @@ func cropStblChildren(traks []*mp4.TrakBox, trakOuts map[uint32]*trakOut) (err e
 				err = cropStsc(ch.(*mp4.StscBox), to.lastSampleNr)
 			case "stsz":
 				cropStsz(ch.(*mp4.StszBox), to.lastSampleNr)
+			case "stz2":
+				cropStsz(stbl.Stsz, to.lastSampleNr) // Written back to stz2 on encode
 			case "sdtp":
 				cropSdtp(ch.(*mp4.SdtpBox), to.lastSampleNr)
 			case "stco":
@@ -70,3 +70,52 @@ func TestCroppedFileDuration(t *testing.T) {
 		t.Errorf("got %d/%dms instead of %dms", moovDur, moovTimescale, cropDur)
 	}
 }
+
+// TestCroppedFileWithEditList - check that the presentation duration matches the
+// cropped media minus the edit list offset
+func TestCroppedFileWithEditList(t *testing.T) {
+	testFile := "../../mp4/testdata/bbb_prog_10s.mp4"
+	outFile := t.TempDir() + "/cropped.mp4"
+
+	err := run([]string{"appName", "-d", "3000", testFile, outFile}, os.Stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decCropped, err := mp4.ReadMP4File(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moov := decCropped.Moov
+	for _, trak := range moov.Traks {
+		if trak.Mdia.Hdlr.HandlerType != "vide" {
+			continue
+		}
+		stts := trak.Mdia.Minf.Stbl.Stts
+		lastTime, lastDur := stts.GetDecodeTime(trak.Mdia.Minf.Stbl.Stsz.SampleNumber)
+		mediaEnd := int64(lastTime + uint64(lastDur))
+		pt := trak.PresentationTimeline(moov.Mvhd.Timescale)
+		presEnd := (mediaEnd - pt.StartOffset()) * int64(moov.Mvhd.Timescale) / int64(trak.Mdia.Mdhd.Timescale)
+		if uint64(presEnd) != moov.Mvhd.Duration {
+			t.Errorf("got movie duration %d instead of %d", moov.Mvhd.Duration, presEnd)
+		}
+	}
+}
+
+func TestAddTimeOffset(t *testing.T) {
+	cases := []struct {
+		t       uint64
+		offset  int64
+		want    uint64
+		wantErr bool
+	}{
+		{t: 1000, offset: 200, want: 1200},
+		{t: 1000, offset: -200, want: 800},
+		{t: 100, offset: -200, wantErr: true},
+	}
+	for _, c := range cases {
+		got, err := addTimeOffset(c.t, c.offset)
+		if (err != nil) != c.wantErr || got != c.want {
+			t.Errorf("addTimeOffset(%d, %d) = %d, %v", c.t, c.offset, got, err)
+		}
+	}
+}
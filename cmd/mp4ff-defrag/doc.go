@@ -0,0 +1,18 @@
+/*
+mp4ff-defrag converts a fragmented mp4 file (like a CMAF track or a recording of moof/mdat pairs)
+to a progressive mp4 file with one mdat box.
+Sample groups are carried over to the sample tables, and senc encryption information is stored
+in the mdat box and referenced by saiz and saio boxes. Tracks starting later than others get an empty edit.
+
+	Usage of mp4ff-defrag:
+
+		mp4ff-defrag [options] <inFile> <outFile>
+
+	options:
+
+		-moovlast
+			Put moov box after mdat box
+		-version
+			Get mp4ff version
+*/
+package main
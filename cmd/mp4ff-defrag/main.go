@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Eyevinn/mp4ff/internal"
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+const (
+	appName = "mp4ff-defrag"
+)
+
+var usg = `%s converts a fragmented mp4 file (like a CMAF track or a recording of moof/mdat pairs)
+to a progressive mp4 file with one mdat box.
+Sample groups are carried over to the sample tables, and senc encryption information is stored
+in the mdat box and referenced by saiz and saio boxes. Tracks starting later than others get an empty edit.
+
+Usage of %s:
+`
+
+type options struct {
+	moovLast bool
+	version  bool
+}
+
+func parseOptions(fs *flag.FlagSet, args []string) (*options, error) {
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, usg, appName, appName)
+		fmt.Fprintf(os.Stderr, "\n%s [options] <inFile> <outFile>\n\noptions:\n", appName)
+		fs.PrintDefaults()
+	}
+
+	opts := options{}
+
+	fs.BoolVar(&opts.moovLast, "moovlast", false, "Put moov box after mdat box")
+	fs.BoolVar(&opts.version, "version", false, "Get mp4ff version")
+
+	err := fs.Parse(args[1:])
+	return &opts, err
+}
+
+func main() {
+	if err := run(os.Args, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet(appName, flag.ContinueOnError)
+	o, err := parseOptions(fs, args)
+
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if o.version {
+		fmt.Fprintf(stdout, "%s %s\n", appName, internal.GetVersion())
+		return nil
+	}
+
+	if len(fs.Args()) != 2 {
+		fs.Usage()
+		return fmt.Errorf("must specify inFile and outFile")
+	}
+
+	inFilePath := fs.Arg(0)
+	outFilePath := fs.Arg(1)
+
+	ifh, err := os.Open(inFilePath)
+	if err != nil {
+		return fmt.Errorf("error opening input file: %w", err)
+	}
+	defer ifh.Close()
+	inMP4, err := mp4.DecodeFile(ifh)
+	if err != nil {
+		return fmt.Errorf("error decoding mp4 file: %w", err)
+	}
+
+	outMP4, err := inMP4.Defragment(mp4.DefragmentOptions{MoovLast: o.moovLast})
+	if err != nil {
+		return fmt.Errorf("error defragmenting mp4 file: %w", err)
+	}
+
+	ofh, err := os.Create(outFilePath)
+	if err != nil {
+		return fmt.Errorf("error creating output file: %w", err)
+	}
+	defer ofh.Close()
+
+	err = outMP4.Encode(ofh)
+	if err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+	return nil
+}
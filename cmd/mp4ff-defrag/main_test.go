@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestCommandLines(t *testing.T) {
+	cases := []struct {
+		desc        string
+		args        []string
+		expectedErr bool
+	}{
+		{desc: "help", args: []string{appName, "-h"}, expectedErr: false},
+		{desc: "version", args: []string{appName, "-version"}, expectedErr: false},
+		{desc: "no args", args: []string{appName}, expectedErr: true},
+		{desc: "unknown args", args: []string{appName, "-x"}, expectedErr: true},
+		{desc: "non-existing infile", args: []string{appName, "notExists.mp4", "dummy.mp4"}, expectedErr: true},
+		{desc: "bad infile", args: []string{appName, "main.go", "dummy.mp4"}, expectedErr: true},
+		{desc: "progressive infile", args: []string{appName, "../../mp4/testdata/prog_8s.mp4", "dummy.mp4"}, expectedErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			gotOut := bytes.Buffer{}
+			err := run(c.args, &gotOut)
+			if c.expectedErr {
+				if err == nil {
+					t.Error("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+				return
+			}
+		})
+	}
+}
+
+func TestDefragmentedFile(t *testing.T) {
+	testFile := "../../mp4/testdata/cbcs.mp4"
+	for _, moovLast := range []bool{false, true} {
+		outFile := t.TempDir() + "/defrag.mp4"
+		args := []string{appName, testFile, outFile}
+		if moovLast {
+			args = []string{appName, "-moovlast", testFile, outFile}
+		}
+		err := run(args, os.Stdout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ofh, err := os.Open(outFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ofh.Close()
+		decFile, err := mp4.DecodeFile(ofh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decFile.IsFragmented() {
+			t.Error("output file is fragmented")
+		}
+		lastBox := decFile.Children[len(decFile.Children)-1].Type()
+		wantedLastBox := "mdat"
+		if moovLast {
+			wantedLastBox = "moov"
+		}
+		if lastBox != wantedLastBox {
+			t.Errorf("last box is %s instead of %s", lastBox, wantedLastBox)
+		}
+	}
+}
@@ -7,8 +7,12 @@ mp4ff-info prints the box tree of input mp4 (ISOBMFF) file.
 
 	options:
 
+		-json
+			print box tree as JSON
 		-l string
 			level of details, e.g. all:1 or trun:1,subs:1
+		-path string
+			only print boxes matching box path, e.g. moov/trak[2]/mdia/minf/stbl/stsd/avc1/avcC
 		-version
 			Get mp4ff version
 */
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -22,6 +23,8 @@ Usage of %s:
 
 type options struct {
 	levels  string
+	path    string
+	json    bool
 	version bool
 }
 
@@ -35,6 +38,8 @@ func parseOptions(fs *flag.FlagSet, args []string) (*options, error) {
 	opts := options{}
 
 	fs.StringVar(&opts.levels, "l", "", "level of details, e.g. all:1 or trun:1,subs:1")
+	fs.StringVar(&opts.path, "path", "", "only print boxes matching box path, e.g. moov/trak[2]/mdia/minf/stbl/stsd/avc1/avcC")
+	fs.BoolVar(&opts.json, "json", false, "print box tree as JSON")
 	fs.BoolVar(&opts.version, "version", false, "Get mp4ff version")
 
 	err := fs.Parse(args[1:])
@@ -82,9 +87,56 @@ func run(args []string, w io.Writer) error {
 		}
 		_, _ = fmt.Fprintf(os.Stderr, "Warning: could not parse input file completely: %v\n", parseErr)
 	}
-	err = parsedMp4.Info(w, opts.levels, "", "  ")
+	switch {
+	case opts.json:
+		err = printJSON(w, parsedMp4, opts.path)
+	case opts.path != "":
+		err = printMatchingBoxes(w, parsedMp4, opts.path, opts.levels)
+	default:
+		err = parsedMp4.Info(w, opts.levels, "", "  ")
+	}
 	if err != nil {
 		return fmt.Errorf("could not print info: %w", err)
 	}
 	return parseErr
 }
+
+// printMatchingBoxes prints the path and offset followed by info for each box matching path.
+func printMatchingBoxes(w io.Writer, parsedMp4 *mp4.File, path, levels string) error {
+	matches, err := parsedMp4.Find(path)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no box matching %q", path)
+	}
+	for _, m := range matches {
+		_, err = fmt.Fprintf(w, "%s at offset %d\n", m.Path, m.Offset)
+		if err != nil {
+			return err
+		}
+		err = m.Box.Info(w, levels, "  ", "  ")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printJSON prints the box tree as JSON. If path is set, only the matching boxes are printed.
+func printJSON(w io.Writer, parsedMp4 *mp4.File, path string) error {
+	nodes := parsedMp4.BoxTree()
+	if path != "" {
+		matches, err := parsedMp4.Find(path)
+		if err != nil {
+			return err
+		}
+		nodes = make([]*mp4.BoxNode, 0, len(matches))
+		for _, m := range matches {
+			nodes = append(nodes, mp4.NewBoxNode(m.Box, m.Offset))
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(nodes)
+}
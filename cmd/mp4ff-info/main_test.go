@@ -21,6 +21,11 @@ func TestOptions(t *testing.T) {
 		{desc: "bad writer", args: []string{appName, "../../mp4/testdata/init.mp4"}, w: &badWriter{}, err: true},
 		{desc: "good file", args: []string{appName, "../../mp4/testdata/init.mp4"}, w: os.Stdout, err: false},
 		{desc: "good with details", args: []string{appName, "-l", "all:1", "../../mp4/testdata/init.mp4"}, w: os.Stdout, err: false},
+		{desc: "box path", args: []string{appName, "-path", "moov/trak/tkhd", "../../mp4/testdata/init.mp4"}, w: os.Stdout, err: false},
+		{desc: "no matching box", args: []string{appName, "-path", "moof", "../../mp4/testdata/init.mp4"}, w: os.Stdout, err: true},
+		{desc: "bad box path", args: []string{appName, "-path", "moov[0]", "../../mp4/testdata/init.mp4"}, w: os.Stdout, err: true},
+		{desc: "json", args: []string{appName, "-json", "../../mp4/testdata/init.mp4"}, w: os.Stdout, err: false},
+		{desc: "json with box path", args: []string{appName, "-json", "-path", "moov/trak", "../../mp4/testdata/init.mp4"}, w: os.Stdout, err: false},
 		{desc: "version", args: []string{appName, "-version"}, w: os.Stdout, err: false},
 		{desc: "help", args: []string{appName, "-h"}, w: os.Stdout, err: false},
 	}
@@ -1,6 +1,6 @@
 /*
-mp4ff-subslister lists and displays content of wvtt or stpp samples.
-These corresponds to WebVTT or TTML subtitles in ISOBMFF files.
+mp4ff-subslister lists and displays content of wvtt, stpp, or tx3g samples.
+These corresponds to WebVTT, TTML, or 3GPP timed text subtitles in ISOBMFF files.
 Uses track with given non-zero track ID or first subtitle track found in an asset.
 
 	Usage of mp4ff-subslister:
@@ -16,8 +16,8 @@ const (
 	appName = "mp4ff-subslister"
 )
 
-var usg = `%s lists and displays content of wvtt or stpp samples.
-These corresponds to WebVTT or TTML subtitles in ISOBMFF files.
+var usg = `%s lists and displays content of wvtt, stpp, or tx3g samples.
+These corresponds to WebVTT, TTML, or 3GPP timed text subtitles in ISOBMFF files.
 Uses track with given non-zero track ID or first subtitle track found in an asset.
 
 Usage of %s:
@@ -125,12 +125,9 @@ type subtitleTrack struct {
 }
 
 func parseProgressiveMp4(f *mp4.File, w io.Writer, trackID uint32, maxNrSamples int) error {
-	subsTrak, err := findWvttTrack(f.Moov, w, trackID)
+	subsTrak, err := findSubtitleTrack(f.Moov, w, trackID)
 	if err != nil {
-		subsTrak, err = findStppTrack(f.Moov, w, trackID)
-		if err != nil {
-			return fmt.Errorf("no subtitle track found: %w", err)
-		}
+		return err
 	}
 	stbl := subsTrak.trak.Mdia.Minf.Stbl
 	nrSamples := stbl.Stsz.SampleNumber
@@ -161,6 +158,8 @@ func parseProgressiveMp4(f *mp4.File, w io.Writer, trackID uint32, maxNrSamples
 			err = printWvttSample(w, sample, sampleNr, int64(decTime), dur)
 		case "stpp":
 			err = printStppSample(w, sample, sampleNr, int64(decTime), dur)
+		case "tx3g":
+			err = printTx3gSample(w, sample, sampleNr, int64(decTime), dur)
 		}
 		if err != nil {
 			return err
@@ -172,6 +171,23 @@ func parseProgressiveMp4(f *mp4.File, w io.Writer, trackID uint32, maxNrSamples
 	return nil
 }
 
+// findSubtitleTrack finds a wvtt, stpp, or tx3g track and prints its sample description.
+func findSubtitleTrack(moov *mp4.MoovBox, w io.Writer, trackID uint32) (*subtitleTrack, error) {
+	subsTrak, err := findWvttTrack(moov, w, trackID)
+	if err == nil {
+		return subsTrak, nil
+	}
+	subsTrak, err = findStppTrack(moov, w, trackID)
+	if err == nil {
+		return subsTrak, nil
+	}
+	subsTrak, err = findTx3gTrack(moov, w, trackID)
+	if err != nil {
+		return nil, fmt.Errorf("no subtitle track found: %w", err)
+	}
+	return subsTrak, nil
+}
+
 func findWvttTrack(moov *mp4.MoovBox, w io.Writer, trackID uint32) (*subtitleTrack, error) {
 	subsTrak, err := findTrack(moov, "text", trackID)
 	if err != nil {
@@ -216,17 +232,36 @@ func findStppTrack(moov *mp4.MoovBox, w io.Writer, trackID uint32) (*subtitleTra
 	}, nil
 }
 
+func findTx3gTrack(moov *mp4.MoovBox, w io.Writer, trackID uint32) (*subtitleTrack, error) {
+	for _, trak := range moov.Traks {
+		if trackID != 0 && trak.Tkhd.TrackID != trackID {
+			continue
+		}
+		tx3g := trak.Mdia.Minf.Stbl.Stsd.Tx3g
+		if tx3g == nil {
+			continue
+		}
+		fmt.Fprintf(w, "Track %d, timescale = %d\n", trak.Tkhd.TrackID, trak.Mdia.Mdhd.Timescale)
+		err := tx3g.Info(w, "", "  ", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &subtitleTrack{
+			variant: "tx3g",
+			trak:    trak,
+		}, nil
+	}
+	return nil, fmt.Errorf("no tx3g track found")
+}
+
 func parseFragmentedMp4(f *mp4.File, w io.Writer, trackID uint32, maxNrSamples int) error {
 	var subsTrex *mp4.TrexBox
 	var subsTrak *subtitleTrack
 	var err error
 	if f.Init != nil { // Print vttC header and timescale if moov-box is present
-		subsTrak, err = findWvttTrack(f.Moov, w, trackID)
+		subsTrak, err = findSubtitleTrack(f.Moov, w, trackID)
 		if err != nil {
-			subsTrak, err = findStppTrack(f.Moov, w, trackID)
-			if err != nil {
-				return fmt.Errorf("no subtitle track found: %w", err)
-			}
+			return err
 		}
 		for _, trex := range f.Init.Moov.Mvex.Trexs {
 			if trex.TrackID == subsTrak.trak.Tkhd.TrackID {
@@ -276,6 +311,8 @@ func parseFragmentedMp4(f *mp4.File, w io.Writer, trackID uint32, maxNrSamples i
 			err = printWvttSample(w, sample.Data, i+1, sample.PresentationTime(), sample.Dur)
 		case "stpp":
 			err = printStppSample(w, sample.Data, i+1, sample.PresentationTime(), sample.Dur)
+		case "tx3g":
+			err = printTx3gSample(w, sample.Data, i+1, sample.PresentationTime(), sample.Dur)
 		default:
 			return fmt.Errorf("unknown subtitle track type")
 		}
@@ -319,3 +356,12 @@ func printStppSample(w io.Writer, sample []byte, nr int, pts int64, dur uint32)
 	_, err := w.Write(sample)
 	return err
 }
+
+func printTx3gSample(w io.Writer, sample []byte, nr int, pts int64, dur uint32) error {
+	fmt.Fprintf(w, "Sample %d, pts=%d, dur=%d\n", nr, pts, dur)
+	tx3gSample, err := mp4.DecodeTx3gSample(sample)
+	if err != nil {
+		return err
+	}
+	return tx3gSample.Info(w, "", "", "  ")
+}
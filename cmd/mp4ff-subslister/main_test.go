@@ -120,6 +120,48 @@ var wantedStppSamples = `Sample 1, pts=0, dur=540000
 </tt>
 `
 
+var wantedTx3gProgressive = `Track 1, timescale = 1000
+  [tx3g] size=69
+   - dataReferenceIndex: 1
+   - displayFlags: 00000000
+   - justification: horizontal=1 vertical=-1
+   - backgroundColor: 00000000
+   - defaultTextBox: top=0 left=0 bottom=0 right=0
+   - defaultStyle: chars=[0,0) fontID=1 faceStyle=0 fontSize=18 color=ffffffff
+    [ftab] size=23
+     - font 1: "Sans-Serif"
+Sample 1, pts=0, dur=2000
+text: "Hello world"
+Sample 2, pts=2000, dur=500
+text: ""
+Sample 3, pts=2500, dur=1500
+text: "Bold and red"
+[styl] size=22
+ - style: chars=[0,4) fontID=1 faceStyle=1 fontSize=18 color=ff0000ff
+[hlit] size=12
+ - startChar: 9
+ - endChar: 12
+[hclr] size=12
+ - highlightColor: ffff00ff
+Sample 4, pts=4000, dur=2000
+text: "Visit mp4ff"
+[href] size=51
+ - chars: [6,11)
+ - URL: "https://github.com/Eyevinn/mp4ff"
+ - altString: "mp4ff"
+[tbox] size=16
+ - textBox: top=10 left=10 bottom=50 right=300
+[blnk] size=12
+ - startChar: 0
+ - endChar: 5
+[dlay] size=12
+ - scrollDelay: 100
+[krok] size=30
+ - highlightStartTime: 0
+ - highlightEndTime=500 chars=[0,5)
+ - highlightEndTime=1000 chars=[6,11)
+`
+
 var wantedStppCombined = wantedStppCombinedStart + wantedStppSamples
 var wantedStppProgressive = wantedStppProgStart + wantedStppSamples
 
@@ -162,6 +204,12 @@ func TestSubsLister(t *testing.T) {
 			expectedErr: false,
 			wanted:      wantedStppProgressive,
 		},
+		{
+			desc:        "tx3g progressive",
+			args:        []string{appName, "testdata/tx3g_prog.mp4"},
+			expectedErr: false,
+			wanted:      wantedTx3gProgressive,
+		},
 		{
 			desc:        "max nr samples",
 			args:        []string{appName, "-m", "1", "testdata/stpp_prog.mp4"},
@@ -0,0 +1,19 @@
+/*
+mp4ff-validate checks a CMAF track against rules of ISO/IEC 23000-19 (CMAF) and lists the findings.
+The first file must contain the CMAF header (init segment) and may also contain segments.
+Further files contain CMAF segments in order.
+A further file with an init segment starts a new track, which is validated with that init segment.
+An error is returned if any finding has severity error.
+
+	Usage of mp4ff-validate:
+
+		mp4ff-validate [options] <initFile> [<segmentFile> ...]
+
+	options:
+
+		-level string
+			Lowest severity to list: info, warning, or error (default "info")
+		-version
+			Get mp4ff version
+*/
+package main
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Eyevinn/mp4ff/internal"
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+const (
+	appName = "mp4ff-validate"
+)
+
+var usg = `%s checks a CMAF track against rules of ISO/IEC 23000-19 (CMAF) and lists the findings.
+The first file must contain the CMAF header (init segment) and may also contain segments.
+Further files contain CMAF segments in order.
+A further file with an init segment starts a new track, which is validated with that init segment.
+An error is returned if any finding has severity error.
+
+Usage of %s:
+`
+
+type options struct {
+	level   string
+	version bool
+}
+
+func parseOptions(fs *flag.FlagSet, args []string) (*options, error) {
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, usg, appName, appName)
+		fmt.Fprintf(os.Stderr, "\n%s [options] <initFile> [<segmentFile> ...]\n\noptions:\n", appName)
+		fs.PrintDefaults()
+	}
+
+	opts := options{}
+
+	fs.StringVar(&opts.level, "level", "info", "Lowest severity to list: info, warning, or error")
+	fs.BoolVar(&opts.version, "version", false, "Get mp4ff version")
+
+	err := fs.Parse(args[1:])
+	return &opts, err
+}
+
+func main() {
+	if err := run(os.Args, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet(appName, flag.ContinueOnError)
+	o, err := parseOptions(fs, args)
+
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if o.version {
+		fmt.Fprintf(stdout, "%s %s\n", appName, internal.GetVersion())
+		return nil
+	}
+
+	var minSeverity mp4.Severity
+	switch o.level {
+	case "info":
+		minSeverity = mp4.SeverityInfo
+	case "warning":
+		minSeverity = mp4.SeverityWarning
+	case "error":
+		minSeverity = mp4.SeverityError
+	default:
+		return fmt.Errorf("unknown level %q", o.level)
+	}
+
+	if len(fs.Args()) == 0 {
+		fs.Usage()
+		return fmt.Errorf("must specify at least one file")
+	}
+
+	var tracks []*cmafTrackFiles
+	for i, filePath := range fs.Args() {
+		f, err := decodeFile(filePath)
+		if err != nil {
+			return err
+		}
+		if f.Init != nil {
+			tracks = append(tracks, &cmafTrackFiles{initPath: filePath, init: f.Init})
+		} else if i == 0 {
+			return fmt.Errorf("no init segment in %s", filePath)
+		}
+		track := tracks[len(tracks)-1]
+		track.segments = append(track.segments, f.Segments...)
+	}
+
+	nrErrors, nrWarnings := 0, 0
+	for _, track := range tracks {
+		if len(tracks) > 1 {
+			fmt.Fprintf(stdout, "%s:\n", track.initPath)
+		}
+		for _, finding := range mp4.ValidateCMAF(track.init, track.segments) {
+			switch finding.Severity {
+			case mp4.SeverityError:
+				nrErrors++
+			case mp4.SeverityWarning:
+				nrWarnings++
+			}
+			if finding.Severity >= minSeverity {
+				fmt.Fprintln(stdout, finding)
+			}
+		}
+	}
+	fmt.Fprintf(stdout, "%d errors, %d warnings\n", nrErrors, nrWarnings)
+	if nrErrors > 0 {
+		return fmt.Errorf("validation failed with %d errors", nrErrors)
+	}
+	return nil
+}
+
+// cmafTrackFiles - an init segment and the segments that follow it
+type cmafTrackFiles struct {
+	initPath string
+	init     *mp4.InitSegment
+	segments []*mp4.MediaSegment
+}
+
+func decodeFile(filePath string) (*mp4.File, error) {
+	fh, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer fh.Close()
+	f, err := mp4.DecodeFile(fh)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", filePath, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCommandLines(t *testing.T) {
+	cases := []struct {
+		desc        string
+		args        []string
+		expectedErr bool
+		wantedOut   string
+	}{
+		{desc: "help", args: []string{appName, "-h"}, expectedErr: false},
+		{desc: "version", args: []string{appName, "-version"}, expectedErr: false},
+		{desc: "no args", args: []string{appName}, expectedErr: true},
+		{desc: "unknown args", args: []string{appName, "-x"}, expectedErr: true},
+		{desc: "bad level", args: []string{appName, "-level", "fatal", "../../mp4/testdata/hvc1_init.mp4"}, expectedErr: true},
+		{desc: "non-existing file", args: []string{appName, "notExists.mp4"}, expectedErr: true},
+		{desc: "bad file", args: []string{appName, "main.go"}, expectedErr: true},
+		{desc: "no init", args: []string{appName, "../../mp4/testdata/hvc1_seg_1.m4s"}, expectedErr: true},
+		{desc: "conforming track", args: []string{appName, "../../mp4/testdata/hvc1_init.mp4",
+			"../../mp4/testdata/hvc1_seg_1.m4s"}, expectedErr: false, wantedOut: "0 errors, 0 warnings\n"},
+		{desc: "tfdt discontinuity", args: []string{appName, "-level", "error", "../../mp4/testdata/hvc1_init.mp4",
+			"../../mp4/testdata/hvc1_seg_1.m4s", "../../mp4/testdata/hvc1_seg_1.m4s"}, expectedErr: true,
+			wantedOut: "error: segment[2]/moof[1]/traf[1]/tfdt: baseMediaDecodeTime"},
+		{desc: "two tracks", args: []string{appName, "../../mp4/testdata/hvc1_init.mp4", "../../mp4/testdata/hvc1_seg_1.m4s",
+			"../../mp4/testdata/hvc1_init.mp4", "../../mp4/testdata/hvc1_seg_1.m4s"}, expectedErr: false,
+			wantedOut: "../../mp4/testdata/hvc1_init.mp4:\n../../mp4/testdata/hvc1_init.mp4:\n0 errors, 0 warnings\n"},
+		{desc: "muxed file", args: []string{appName, "../../mp4/testdata/cbcs.mp4"}, expectedErr: true,
+			wantedOut: "error: ftyp: none of the CMAF brands cmfc or cmf2 present\nerror: moov: CMAF header"},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			gotOut := bytes.Buffer{}
+			err := run(c.args, &gotOut)
+			if c.expectedErr != (err != nil) {
+				t.Errorf("got error %v, expected error %t", err, c.expectedErr)
+			}
+			if !strings.HasPrefix(gotOut.String(), c.wantedOut) {
+				t.Errorf("got output %q, wanted prefix %q", gotOut.String(), c.wantedOut)
+			}
+		})
+	}
+}
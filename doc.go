@@ -12,10 +12,13 @@ Some useful command line tools are available in [cmd](cmd) directory.
  2. [mp4ff-pslister] extracts and displays SPS and PPS for AVC or HEVC in a mp4 or a bytestream (Annex B) file.
     Partial information is printed for HEVC.
  3. [mp4ff-nallister] lists NALUs and picture types for video in progressive or fragmented file
- 4. [mp4ff-subslister] lists details of wvtt or stpp (WebVTT or TTML in ISOBMFF) subtitle samples
+ 4. [mp4ff-subslister] lists details of wvtt, stpp, or tx3g (WebVTT, TTML, or 3GPP timed text in ISOBMFF) subtitle samples
  5. [mp4ff-crop] crops a **progressive** mp4 file to a specified duration
  6. [mp4ff-encrypt] encrypts a fragmented file using cenc or cbcs Common Encryption scheme
  7. [mp4ff-decrypt] decrypts a fragmented file encrypted using cenc or cbcs Common Encryption scheme
+ 8. [mp4ff-defrag] converts a **fragmented** mp4 file to a progressive mp4 file
+ 9. [mp4ff-validate] checks a CMAF track against CMAF rules and lists the findings
+ 10. [mp4ff-build] builds a mp4 file from a JSON description of its boxes
 
 You can install these tools by going to their respective directory and run `go install .` or directly from the repo with
 
@@ -52,9 +55,12 @@ The top-level packages in the mp4ff module are
  4. [sei] provides support for handling  Supplementary Enhancement Information (SEI) such as timestamps
     for AVC and HEVC video.
  5. [av1] provides basic support for AV1 video packaging
- 6. [aac] provides support for AAC audio. This includes handling ADTS headers which is common
+ 6. [dovi] provides the Dolby Vision configuration record and codec strings
+ 7. [id3] provides ID3v2 tags as carried in emsg boxes and timed metadata for HLS and DASH
+ 8. [scte35] provides SCTE-35 splice_info_section messages as carried in emsg boxes
+ 9. [aac] provides support for AAC audio. This includes handling ADTS headers which is common
     for AAC inside MPEG-2 TS streams.
- 7. [bits] provides bit-wise and byte-wise readers and writers used by the other packages.
+ 10. [bits] provides bit-wise and byte-wise readers and writers used by the other packages.
 
 # Specifications
 
@@ -67,6 +73,9 @@ in the code.
 [hevc]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/hevc
 [sei]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/sei
 [av1]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/av1
+[dovi]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/dovi
+[id3]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/id3
+[scte35]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/scte35
 [aac]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/aac
 [bits]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/bits
 [initcreator]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/examples/initcreator
@@ -82,5 +91,8 @@ in the code.
 [mp4ff-crop]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/cmd/mp4ff-crop
 [mp4ff-encrypt]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/cmd/mp4ff-encrypt
 [mp4ff-decrypt]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/cmd/mp4ff-decrypt
+[mp4ff-defrag]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/cmd/mp4ff-defrag
+[mp4ff-validate]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/cmd/mp4ff-validate
+[mp4ff-build]: https://pkg.go.dev/github.com/Eyevinn/mp4ff/cmd/mp4ff-build
 */
 package mp4ff
@@ -0,0 +1,5 @@
+/*
+Package dovi decodes (parses) and encodes (writes) the Dolby Vision decoder configuration record
+carried in dvcC, dvvC, and dvwC boxes, and provides Dolby Vision codec strings.
+*/
+package dovi
@@ -0,0 +1,152 @@
+package dovi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// DecConfRecSize is the fixed size in bytes of a DOVIDecoderConfigurationRecord
+const DecConfRecSize = 24
+
+// ErrTooShort is returned when the data is shorter than DecConfRecSize
+var ErrTooShort = errors.New("DOVIDecoderConfigurationRecord: data too short")
+
+// DecConfRec - DOVIDecoderConfigurationRecord
+// Specified in Dolby Vision Streams within the ISO Base Media File Format v2.x
+type DecConfRec struct {
+	VersionMajor            byte
+	VersionMinor            byte
+	Profile                 byte
+	Level                   byte
+	RPUPresent              bool
+	ELPresent               bool
+	BLPresent               bool
+	BLSignalCompatibilityID byte
+	MDCompression           byte
+}
+
+// DecodeDecConfRec - decode a DOVIDecoderConfigurationRecord. Reserved bits are ignored.
+func DecodeDecConfRec(data []byte) (DecConfRec, error) {
+	if len(data) < DecConfRecSize {
+		return DecConfRec{}, fmt.Errorf("%w: %d bytes", ErrTooShort, len(data))
+	}
+	sr := bits.NewFixedSliceReader(data)
+	r := DecConfRec{}
+	r.VersionMajor = sr.ReadUint8()
+	r.VersionMinor = sr.ReadUint8()
+	v := sr.ReadUint16()
+	r.Profile = byte(v >> 9)
+	r.Level = byte((v >> 3) & 0x3f)
+	r.RPUPresent = (v>>2)&1 == 1
+	r.ELPresent = (v>>1)&1 == 1
+	r.BLPresent = v&1 == 1
+	b := sr.ReadUint8()
+	r.BLSignalCompatibilityID = b >> 4
+	r.MDCompression = (b >> 2) & 0x03
+	return r, sr.AccError()
+}
+
+// Size - total size in bytes
+func (r *DecConfRec) Size() uint64 {
+	return DecConfRecSize
+}
+
+// Encode - write a DOVIDecoderConfigurationRecord to w
+func (r *DecConfRec) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(r.Size()))
+	err := r.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - write a DOVIDecoderConfigurationRecord to sw
+func (r *DecConfRec) EncodeSW(sw bits.SliceWriter) error {
+	sw.WriteUint8(r.VersionMajor)
+	sw.WriteUint8(r.VersionMinor)
+	sw.WriteBits(uint(r.Profile), 7)
+	sw.WriteBits(uint(r.Level), 6)
+	sw.WriteFlag(r.RPUPresent)
+	sw.WriteFlag(r.ELPresent)
+	sw.WriteFlag(r.BLPresent)
+	sw.WriteBits(uint(r.BLSignalCompatibilityID), 4)
+	sw.WriteBits(uint(r.MDCompression), 2)
+	sw.WriteBits(0, 26)
+	sw.WriteZeroBytes(16)
+	return sw.AccError()
+}
+
+// BoxType returns the box type for the profile: dvcC for profile 7 and lower,
+// dvvC for profiles 8 to 10, and dvwC for higher profiles.
+func (r *DecConfRec) BoxType() string {
+	switch {
+	case r.Profile <= 7:
+		return "dvcC"
+	case r.Profile <= 10:
+		return "dvvC"
+	default:
+		return "dvwC"
+	}
+}
+
+// CodecString returns the Dolby Vision codec string such as dvh1.08.06 for the sample entry.
+func (r *DecConfRec) CodecString(sampleEntry string) string {
+	return CodecString(sampleEntry, r.Profile, r.Level)
+}
+
+// CheckSampleEntry checks that profile and level are valid and that sampleEntry may be used with the profile.
+func (r *DecConfRec) CheckSampleEntry(sampleEntry string) error {
+	if r.Level < 1 || r.Level > 13 {
+		return fmt.Errorf("dolby vision level %d not in range 1-13", r.Level)
+	}
+	allowed, ok := sampleEntriesForProfile[r.Profile]
+	if !ok {
+		return fmt.Errorf("dolby vision profile %d not supported", r.Profile)
+	}
+	for _, se := range allowed {
+		if se == sampleEntry {
+			return nil
+		}
+	}
+	return fmt.Errorf("sample entry %s not allowed for dolby vision profile %d", sampleEntry, r.Profile)
+}
+
+// sampleEntriesForProfile lists the sample entries allowed for the supported profiles.
+var sampleEntriesForProfile = map[byte][]string{
+	4:  {"dvh1", "dvhe"},
+	5:  {"dvh1", "dvhe"},
+	7:  {"dvh1", "dvhe"},
+	8:  {"hvc1", "hev1", "dvh1", "dvhe"},
+	9:  {"avc1", "avc3", "dva1", "dvav"},
+	10: {"av01", "dav1"},
+}
+
+// CodecString returns the Dolby Vision codec string sampleEntry.PP.LL, e.g. dvh1.08.06.
+// See Dolby Vision Profiles and Levels Section 2.
+func CodecString(sampleEntry string, profile, level byte) string {
+	return fmt.Sprintf("%s.%02d.%02d", sampleEntry, profile, level)
+}
+
+// SampleEntryCodecString returns the Dolby Vision sample entry to use in codec strings for
+// a base sample entry, e.g. dvh1 for hvc1. Dolby Vision sample entries are returned unchanged.
+func SampleEntryCodecString(sampleEntry string) string {
+	switch sampleEntry {
+	case "hvc1":
+		return "dvh1"
+	case "hev1":
+		return "dvhe"
+	case "avc1":
+		return "dva1"
+	case "avc3":
+		return "dvav"
+	case "av01":
+		return "dav1"
+	default:
+		return sampleEntry
+	}
+}
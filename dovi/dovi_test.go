@@ -0,0 +1,81 @@
+package dovi
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+// Profile 8.4, level 6 with RPU and BL present
+const doviProfile8Hex = "010010354000000000000000000000000000000000000000"
+
+func TestDecodeEncodeDecConfRec(t *testing.T) {
+	data, _ := hex.DecodeString(doviProfile8Hex)
+	wanted := DecConfRec{
+		VersionMajor:            1,
+		VersionMinor:            0,
+		Profile:                 8,
+		Level:                   6,
+		RPUPresent:              true,
+		ELPresent:               false,
+		BLPresent:               true,
+		BLSignalCompatibilityID: 4,
+	}
+	got, err := DecodeDecConfRec(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(got, wanted); diff != nil {
+		t.Error(diff)
+	}
+	if got.BoxType() != "dvvC" {
+		t.Errorf("got box type %s instead of dvvC", got.BoxType())
+	}
+	buf := bytes.Buffer{}
+	err = got.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("encoded %x differs from %x", buf.Bytes(), data)
+	}
+	_, err = DecodeDecConfRec(data[:10])
+	if !errors.Is(err, ErrTooShort) {
+		t.Errorf("expected ErrTooShort, got %v", err)
+	}
+}
+
+func TestCodecStrings(t *testing.T) {
+	r := DecConfRec{Profile: 5, Level: 9}
+	if got := r.CodecString("dvh1"); got != "dvh1.05.09" {
+		t.Errorf("got %s", got)
+	}
+	if got := CodecString(SampleEntryCodecString("av01"), 10, 13); got != "dav1.10.13" {
+		t.Errorf("got %s", got)
+	}
+	testCases := []struct {
+		profile     byte
+		level       byte
+		sampleEntry string
+		ok          bool
+	}{
+		{5, 6, "dvh1", true},
+		{5, 6, "hvc1", false},
+		{8, 6, "hvc1", true},
+		{9, 5, "avc3", true},
+		{10, 6, "dav1", true},
+		{10, 6, "dvh1", false},
+		{6, 6, "dvh1", false},
+		{8, 14, "hvc1", false},
+	}
+	for _, tc := range testCases {
+		r := DecConfRec{Profile: tc.profile, Level: tc.level}
+		err := r.CheckSampleEntry(tc.sampleEntry)
+		if (err == nil) != tc.ok {
+			t.Errorf("profile %d level %d %s: got err %v", tc.profile, tc.level, tc.sampleEntry, err)
+		}
+	}
+}
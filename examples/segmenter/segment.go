@@ -212,15 +212,20 @@ func getSegmentStartsFromVideo(parsedMp4 *mp4.File, segDurMS uint32) (timeScale
 	stts := refTrak.Mdia.Minf.Stbl.Stts
 	stss := refTrak.Mdia.Minf.Stbl.Stss
 	ctts := refTrak.Mdia.Minf.Stbl.Ctts
+	// Presentation times are relative to the start given by the edit list
+	startOffset := refTrak.PresentationTimeline(parsedMp4.Moov.Mvhd.Timescale).StartOffset()
 	syncPoints = make([]syncPoint, 0, stss.EntryCount())
 	var segmentStep = uint32(uint64(segDurMS) * uint64(timeScale) / 1000)
 	var nextSegmentStart uint32 = 0
 	for _, sampleNr := range stss.SampleNumber {
 		decodeTime, _ := stts.GetDecodeTime(sampleNr)
-		presTime := int64(decodeTime)
+		presTime := int64(decodeTime) - startOffset
 		if ctts != nil {
 			presTime += int64(ctts.GetCompositionTimeOffset(sampleNr))
 		}
+		if presTime < 0 {
+			presTime = 0
+		}
 		if presTime >= int64(nextSegmentStart) {
 			syncPoints = append(syncPoints, syncPoint{sampleNr, decodeTime, uint64(presTime)})
 			nextSegmentStart += segmentStep
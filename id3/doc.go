@@ -0,0 +1,9 @@
+/*
+Package id3 decodes (parses) and encodes (writes) ID3v2.3 and ID3v2.4 tags as carried in
+emsg boxes and timed metadata samples for HLS and DASH.
+
+The frames PRIV, TXXX, GEOB, and text frames like TIT2 are decoded into typed frames.
+Other frames, and frames that are compressed, encrypted, or grouped, are kept as UnknownFrame.
+Unsynchronisation is handled both on tag and frame level.
+*/
+package id3
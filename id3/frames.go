@@ -0,0 +1,399 @@
+package id3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Text encodings
+const (
+	EncodingISO88591 = 0
+	EncodingUTF16    = 1 // UTF-16 with byte order mark
+	EncodingUTF16BE  = 2 // ID3v2.4 only
+	EncodingUTF8     = 3 // ID3v2.4 only
+)
+
+// Frame - ID3v2 frame
+type Frame interface {
+	ID() string
+	String() string
+	encodeBody(version byte) ([]byte, error)
+}
+
+// decodeFrameBody decodes the body of frame id
+func decodeFrameBody(id string, body []byte) (Frame, error) {
+	switch {
+	case id == "PRIV":
+		return decodePrivFrame(body)
+	case id == "TXXX":
+		return decodeTxxxFrame(body)
+	case id == "GEOB":
+		return decodeGeobFrame(body)
+	case id[0] == 'T':
+		return decodeTextFrame(id, body)
+	default:
+		return &UnknownFrame{FrameID: id, Data: body}, nil
+	}
+}
+
+// PrivFrame - private frame (PRIV) with owner identifier and binary data
+type PrivFrame struct {
+	Owner string
+	Data  []byte
+}
+
+// ID - frame ID PRIV
+func (f *PrivFrame) ID() string {
+	return "PRIV"
+}
+
+func decodePrivFrame(body []byte) (*PrivFrame, error) {
+	owner, rest, err := splitTerminated(EncodingISO88591, body)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivFrame{Owner: decodeLatin1(owner), Data: rest}, nil
+}
+
+func (f *PrivFrame) encodeBody(version byte) ([]byte, error) {
+	owner, err := encodeText(EncodingISO88591, f.Owner, true)
+	if err != nil {
+		return nil, err
+	}
+	return append(owner, f.Data...), nil
+}
+
+// String - owner and data size
+func (f *PrivFrame) String() string {
+	return fmt.Sprintf("PRIV owner=%q data=%d bytes", f.Owner, len(f.Data))
+}
+
+// TextFrame - text information frame like TIT2 (title)
+type TextFrame struct {
+	FrameID  string
+	Encoding byte
+	Text     string // Multiple values in ID3v2.4 are separated by zero characters
+}
+
+// NewTIT2Frame - title frame with UTF-8 encoding in ID3v2.4 and UTF-16 in ID3v2.3
+func NewTIT2Frame(version byte, title string) *TextFrame {
+	return &TextFrame{FrameID: "TIT2", Encoding: defaultEncoding(version), Text: title}
+}
+
+// ID - frame ID
+func (f *TextFrame) ID() string {
+	return f.FrameID
+}
+
+func decodeTextFrame(id string, body []byte) (*TextFrame, error) {
+	if len(body) < 1 {
+		return nil, fmt.Errorf("empty text frame")
+	}
+	text, err := decodeText(body[0], body[1:])
+	if err != nil {
+		return nil, err
+	}
+	return &TextFrame{FrameID: id, Encoding: body[0], Text: trimTerminators(text)}, nil
+}
+
+func (f *TextFrame) encodeBody(version byte) ([]byte, error) {
+	if err := checkEncoding(f.Encoding, version); err != nil {
+		return nil, err
+	}
+	text, err := encodeText(f.Encoding, f.Text, false)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{f.Encoding}, text...), nil
+}
+
+// String - frame ID and text
+func (f *TextFrame) String() string {
+	return fmt.Sprintf("%s %q", f.FrameID, f.Text)
+}
+
+// TxxxFrame - user defined text information frame (TXXX)
+type TxxxFrame struct {
+	Encoding    byte
+	Description string
+	Value       string
+}
+
+// NewTxxxFrame - TXXX frame with UTF-8 encoding in ID3v2.4 and UTF-16 in ID3v2.3
+func NewTxxxFrame(version byte, description, value string) *TxxxFrame {
+	return &TxxxFrame{Encoding: defaultEncoding(version), Description: description, Value: value}
+}
+
+// ID - frame ID TXXX
+func (f *TxxxFrame) ID() string {
+	return "TXXX"
+}
+
+func decodeTxxxFrame(body []byte) (*TxxxFrame, error) {
+	if len(body) < 1 {
+		return nil, fmt.Errorf("empty TXXX frame")
+	}
+	enc := body[0]
+	desc, rest, err := splitTerminated(enc, body[1:])
+	if err != nil {
+		return nil, err
+	}
+	description, err := decodeText(enc, desc)
+	if err != nil {
+		return nil, err
+	}
+	value, err := decodeText(enc, rest)
+	if err != nil {
+		return nil, err
+	}
+	return &TxxxFrame{Encoding: enc, Description: description, Value: trimTerminators(value)}, nil
+}
+
+func (f *TxxxFrame) encodeBody(version byte) ([]byte, error) {
+	if err := checkEncoding(f.Encoding, version); err != nil {
+		return nil, err
+	}
+	desc, err := encodeText(f.Encoding, f.Description, true)
+	if err != nil {
+		return nil, err
+	}
+	value, err := encodeText(f.Encoding, f.Value, false)
+	if err != nil {
+		return nil, err
+	}
+	body := append([]byte{f.Encoding}, desc...)
+	return append(body, value...), nil
+}
+
+// String - description and value
+func (f *TxxxFrame) String() string {
+	return fmt.Sprintf("TXXX description=%q value=%q", f.Description, f.Value)
+}
+
+// GeobFrame - general encapsulated object frame (GEOB)
+type GeobFrame struct {
+	Encoding    byte
+	MIMEType    string
+	Filename    string
+	Description string
+	Data        []byte
+}
+
+// ID - frame ID GEOB
+func (f *GeobFrame) ID() string {
+	return "GEOB"
+}
+
+func decodeGeobFrame(body []byte) (*GeobFrame, error) {
+	if len(body) < 1 {
+		return nil, fmt.Errorf("empty GEOB frame")
+	}
+	f := &GeobFrame{Encoding: body[0]}
+	mime, rest, err := splitTerminated(EncodingISO88591, body[1:])
+	if err != nil {
+		return nil, err
+	}
+	f.MIMEType = decodeLatin1(mime)
+	filename, rest, err := splitTerminated(f.Encoding, rest)
+	if err != nil {
+		return nil, err
+	}
+	if f.Filename, err = decodeText(f.Encoding, filename); err != nil {
+		return nil, err
+	}
+	desc, rest, err := splitTerminated(f.Encoding, rest)
+	if err != nil {
+		return nil, err
+	}
+	if f.Description, err = decodeText(f.Encoding, desc); err != nil {
+		return nil, err
+	}
+	f.Data = rest
+	return f, nil
+}
+
+func (f *GeobFrame) encodeBody(version byte) ([]byte, error) {
+	if err := checkEncoding(f.Encoding, version); err != nil {
+		return nil, err
+	}
+	mime, err := encodeText(EncodingISO88591, f.MIMEType, true)
+	if err != nil {
+		return nil, err
+	}
+	filename, err := encodeText(f.Encoding, f.Filename, true)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := encodeText(f.Encoding, f.Description, true)
+	if err != nil {
+		return nil, err
+	}
+	body := append([]byte{f.Encoding}, mime...)
+	body = append(body, filename...)
+	body = append(body, desc...)
+	return append(body, f.Data...), nil
+}
+
+// String - MIME type, filename, description and data size
+func (f *GeobFrame) String() string {
+	return fmt.Sprintf("GEOB mime=%q filename=%q description=%q data=%d bytes",
+		f.MIMEType, f.Filename, f.Description, len(f.Data))
+}
+
+// UnknownFrame - frame that is not decoded. Data is the frame body without unsynchronisation.
+// Flags are the frame header flags except for unsynchronisation.
+type UnknownFrame struct {
+	FrameID string
+	Flags   uint16
+	Data    []byte
+}
+
+// ID - frame ID
+func (f *UnknownFrame) ID() string {
+	return f.FrameID
+}
+
+func (f *UnknownFrame) encodeBody(version byte) ([]byte, error) {
+	return f.Data, nil
+}
+
+// String - frame ID and data size
+func (f *UnknownFrame) String() string {
+	return fmt.Sprintf("%s flags=%04x data=%d bytes", f.FrameID, f.Flags, len(f.Data))
+}
+
+// defaultEncoding - UTF-8 for ID3v2.4 and UTF-16 for ID3v2.3
+func defaultEncoding(version byte) byte {
+	if version >= 4 {
+		return EncodingUTF8
+	}
+	return EncodingUTF16
+}
+
+// checkEncoding checks that the text encoding is allowed in the ID3 version
+func checkEncoding(enc, version byte) error {
+	switch enc {
+	case EncodingISO88591, EncodingUTF16:
+		return nil
+	case EncodingUTF16BE, EncodingUTF8:
+		if version >= 4 {
+			return nil
+		}
+		return fmt.Errorf("text encoding %d not allowed in ID3v2.%d", enc, version)
+	default:
+		return fmt.Errorf("unknown text encoding %d", enc)
+	}
+}
+
+// splitTerminated splits data at the first string terminator of the encoding
+func splitTerminated(enc byte, data []byte) (str, rest []byte, err error) {
+	switch enc {
+	case EncodingISO88591, EncodingUTF8:
+		i := bytes.IndexByte(data, 0)
+		if i < 0 {
+			return nil, nil, fmt.Errorf("missing string terminator")
+		}
+		return data[:i], data[i+1:], nil
+	case EncodingUTF16, EncodingUTF16BE:
+		for i := 0; i+1 < len(data); i += 2 {
+			if data[i] == 0 && data[i+1] == 0 {
+				return data[:i], data[i+2:], nil
+			}
+		}
+		return nil, nil, fmt.Errorf("missing string terminator")
+	default:
+		return nil, nil, fmt.Errorf("unknown text encoding %d", enc)
+	}
+}
+
+// trimTerminators removes trailing zero characters
+func trimTerminators(s string) string {
+	return strings.TrimRight(s, "\x00")
+}
+
+func decodeLatin1(data []byte) string {
+	r := make([]rune, len(data))
+	for i, c := range data {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
+
+// decodeText decodes data (without terminator) in text encoding enc
+func decodeText(enc byte, data []byte) (string, error) {
+	switch enc {
+	case EncodingISO88591:
+		return decodeLatin1(data), nil
+	case EncodingUTF8:
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("invalid UTF-8 text")
+		}
+		return string(data), nil
+	case EncodingUTF16, EncodingUTF16BE:
+		var order binary.ByteOrder = binary.BigEndian
+		if enc == EncodingUTF16 && len(data) >= 2 {
+			switch {
+			case data[0] == 0xff && data[1] == 0xfe:
+				order = binary.LittleEndian
+				data = data[2:]
+			case data[0] == 0xfe && data[1] == 0xff:
+				data = data[2:]
+			}
+		}
+		if len(data)%2 != 0 {
+			return "", fmt.Errorf("odd length %d of UTF-16 text", len(data))
+		}
+		u := make([]uint16, len(data)/2)
+		for i := range u {
+			u[i] = order.Uint16(data[2*i:])
+		}
+		return string(utf16.Decode(u)), nil
+	default:
+		return "", fmt.Errorf("unknown text encoding %d", enc)
+	}
+}
+
+// encodeText encodes s in text encoding enc, optionally with terminator.
+// UTF-16 is written little-endian with byte order mark.
+func encodeText(enc byte, s string, terminate bool) ([]byte, error) {
+	var out []byte
+	switch enc {
+	case EncodingISO88591:
+		for _, r := range s {
+			if r > 0xff {
+				return nil, fmt.Errorf("character %q not in ISO-8859-1", r)
+			}
+			out = append(out, byte(r))
+		}
+		if terminate {
+			out = append(out, 0)
+		}
+	case EncodingUTF8:
+		out = append(out, s...)
+		if terminate {
+			out = append(out, 0)
+		}
+	case EncodingUTF16, EncodingUTF16BE:
+		u := utf16.Encode([]rune(s))
+		var order binary.ByteOrder = binary.BigEndian
+		if enc == EncodingUTF16 {
+			out = append(out, 0xff, 0xfe)
+			order = binary.LittleEndian
+		}
+		b := make([]byte, 2)
+		for _, v := range u {
+			order.PutUint16(b, v)
+			out = append(out, b...)
+		}
+		if terminate {
+			out = append(out, 0, 0)
+		}
+	default:
+		return nil, fmt.Errorf("unknown text encoding %d", enc)
+	}
+	return out, nil
+}
@@ -0,0 +1,297 @@
+package id3
+
+import (
+	"errors"
+	"fmt"
+)
+
+// HeaderSize is the size of the ID3v2 tag header
+const HeaderSize = 10
+
+// Tag header flags
+const (
+	FlagUnsynchronisation = 0x80
+	FlagExtendedHeader    = 0x40
+	FlagExperimental      = 0x20
+	FlagFooter            = 0x10
+)
+
+// Frame format flags in ID3v2.3
+const (
+	frameFlagCompressionV3 = 0x0080
+	frameFlagEncryptionV3  = 0x0040
+	frameFlagGroupingV3    = 0x0020
+)
+
+// Frame format flags in ID3v2.4
+const (
+	frameFlagGroupingV4          = 0x0040
+	frameFlagCompressionV4       = 0x0008
+	frameFlagEncryptionV4        = 0x0004
+	frameFlagUnsynchronisationV4 = 0x0002
+	frameFlagDataLengthV4        = 0x0001
+)
+
+// ErrNotID3 is returned if the data does not start with an ID3v2 tag header
+var ErrNotID3 = errors.New("not an ID3v2 tag")
+
+// Tag - ID3v2.3 or ID3v2.4 tag
+type Tag struct {
+	MajorVersion      byte // 3 or 4
+	Revision          byte
+	Unsynchronisation bool // Apply unsynchronisation when encoding
+	Frames            []Frame
+}
+
+// NewTag - create an empty tag with majorVersion 3 or 4
+func NewTag(majorVersion byte) *Tag {
+	return &Tag{MajorVersion: majorVersion}
+}
+
+// AddFrame - add a frame to the tag
+func (t *Tag) AddFrame(f Frame) {
+	t.Frames = append(t.Frames, f)
+}
+
+// Frame - first frame with frame ID id, or nil if not present
+func (t *Tag) Frame(id string) Frame {
+	for _, f := range t.Frames {
+		if f.ID() == id {
+			return f
+		}
+	}
+	return nil
+}
+
+// TagSize - total size of the tag starting at data[0] including header and optional footer
+func TagSize(data []byte) (int, error) {
+	if len(data) < HeaderSize || string(data[0:3]) != "ID3" {
+		return 0, ErrNotID3
+	}
+	size, ok := decodeSynchsafe(data[6:10])
+	if !ok {
+		return 0, fmt.Errorf("tag size is not synchsafe")
+	}
+	total := HeaderSize + int(size)
+	if data[3] == 4 && data[5]&FlagFooter != 0 {
+		total += HeaderSize
+	}
+	return total, nil
+}
+
+// DecodeTag - decode the ID3v2 tag at the start of data
+func DecodeTag(data []byte) (*Tag, error) {
+	size, err := TagSize(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < size {
+		return nil, fmt.Errorf("tag size %d larger than data size %d", size, len(data))
+	}
+	t := &Tag{MajorVersion: data[3], Revision: data[4]}
+	if t.MajorVersion != 3 && t.MajorVersion != 4 {
+		return nil, fmt.Errorf("ID3v2.%d not supported", t.MajorVersion)
+	}
+	flags := data[5]
+	t.Unsynchronisation = flags&FlagUnsynchronisation != 0
+	body := data[HeaderSize:size]
+	if flags&FlagFooter != 0 && t.MajorVersion == 4 {
+		body = body[:len(body)-HeaderSize]
+	}
+	if t.Unsynchronisation && t.MajorVersion == 3 {
+		body = removeUnsynchronisation(body)
+	}
+	if flags&FlagExtendedHeader != 0 {
+		if len(body) < 4 {
+			return nil, fmt.Errorf("extended header: too short")
+		}
+		var extSize int
+		if t.MajorVersion == 3 {
+			extSize = 4 + int(uint32(body[0])<<24|uint32(body[1])<<16|uint32(body[2])<<8|uint32(body[3]))
+		} else {
+			s, ok := decodeSynchsafe(body[0:4])
+			if !ok {
+				return nil, fmt.Errorf("extended header size is not synchsafe")
+			}
+			extSize = int(s)
+		}
+		if extSize > len(body) {
+			return nil, fmt.Errorf("extended header size %d too large", extSize)
+		}
+		body = body[extSize:]
+	}
+	for len(body) >= HeaderSize && body[0] != 0 {
+		f, n, err := decodeFrame(body, t.MajorVersion, t.Unsynchronisation)
+		if err != nil {
+			return nil, err
+		}
+		t.Frames = append(t.Frames, f)
+		body = body[n:]
+	}
+	return t, nil
+}
+
+// decodeFrame decodes a frame and returns it and the number of bytes consumed
+func decodeFrame(data []byte, version byte, tagUnsync bool) (Frame, int, error) {
+	id := string(data[0:4])
+	for _, c := range []byte(id) {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return nil, 0, fmt.Errorf("bad frame ID %q", id)
+		}
+	}
+	var size uint32
+	if version == 4 {
+		s, ok := decodeSynchsafe(data[4:8])
+		if !ok {
+			return nil, 0, fmt.Errorf("frame %s: size is not synchsafe", id)
+		}
+		size = s
+	} else {
+		size = uint32(data[4])<<24 | uint32(data[5])<<16 | uint32(data[6])<<8 | uint32(data[7])
+	}
+	flags := uint16(data[8])<<8 | uint16(data[9])
+	end := HeaderSize + int(size)
+	if end > len(data) {
+		return nil, 0, fmt.Errorf("frame %s: size %d too large", id, size)
+	}
+	body := data[HeaderSize:end]
+	var transformed bool
+	if version == 4 {
+		if flags&frameFlagUnsynchronisationV4 != 0 || tagUnsync {
+			body = removeUnsynchronisation(body)
+		}
+		flags &^= frameFlagUnsynchronisationV4
+		transformed = flags&(frameFlagGroupingV4|frameFlagCompressionV4|frameFlagEncryptionV4) != 0
+		if !transformed && flags&frameFlagDataLengthV4 != 0 {
+			if len(body) < 4 {
+				return nil, 0, fmt.Errorf("frame %s: too short for data length indicator", id)
+			}
+			body = body[4:]
+			flags &^= frameFlagDataLengthV4
+		}
+	} else {
+		transformed = flags&(frameFlagCompressionV3|frameFlagEncryptionV3|frameFlagGroupingV3) != 0
+	}
+	if transformed {
+		return &UnknownFrame{FrameID: id, Flags: flags, Data: body}, end, nil
+	}
+	f, err := decodeFrameBody(id, body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("frame %s: %w", id, err)
+	}
+	if u, ok := f.(*UnknownFrame); ok {
+		u.Flags = flags
+	}
+	return f, end, nil
+}
+
+// Encode - encode the tag including header
+func (t *Tag) Encode() ([]byte, error) {
+	if t.MajorVersion != 3 && t.MajorVersion != 4 {
+		return nil, fmt.Errorf("ID3v2.%d not supported", t.MajorVersion)
+	}
+	var body []byte
+	for _, f := range t.Frames {
+		frameBody, err := f.encodeBody(t.MajorVersion)
+		if err != nil {
+			return nil, fmt.Errorf("frame %s: %w", f.ID(), err)
+		}
+		var flags uint16
+		if u, ok := f.(*UnknownFrame); ok {
+			flags = u.Flags
+		}
+		if t.Unsynchronisation && t.MajorVersion == 4 {
+			frameBody = applyUnsynchronisation(frameBody)
+			flags |= frameFlagUnsynchronisationV4
+		}
+		id := f.ID()
+		if len(id) != 4 {
+			return nil, fmt.Errorf("frame ID %q is not 4 characters", id)
+		}
+		size := uint32(len(frameBody))
+		hdr := make([]byte, HeaderSize)
+		copy(hdr, id)
+		if t.MajorVersion == 4 {
+			if size >= 1<<28 {
+				return nil, fmt.Errorf("frame %s: size %d too large", id, size)
+			}
+			encodeSynchsafe(hdr[4:8], size)
+		} else {
+			hdr[4], hdr[5], hdr[6], hdr[7] = byte(size>>24), byte(size>>16), byte(size>>8), byte(size)
+		}
+		hdr[8], hdr[9] = byte(flags>>8), byte(flags)
+		body = append(body, hdr...)
+		body = append(body, frameBody...)
+	}
+	var flags byte
+	if t.Unsynchronisation {
+		flags |= FlagUnsynchronisation
+		if t.MajorVersion == 3 {
+			body = applyUnsynchronisation(body)
+		}
+	}
+	if len(body) >= 1<<28 {
+		return nil, fmt.Errorf("tag size %d too large", len(body))
+	}
+	out := make([]byte, HeaderSize, HeaderSize+len(body))
+	copy(out, "ID3")
+	out[3] = t.MajorVersion
+	out[4] = t.Revision
+	out[5] = flags
+	encodeSynchsafe(out[6:10], uint32(len(body)))
+	return append(out, body...), nil
+}
+
+// String - tag version and frames on separate lines
+func (t *Tag) String() string {
+	msg := fmt.Sprintf("ID3v2.%d.%d", t.MajorVersion, t.Revision)
+	for _, f := range t.Frames {
+		msg += fmt.Sprintf("\n  %s", f)
+	}
+	return msg
+}
+
+// decodeSynchsafe decodes a 28-bit synchsafe integer
+func decodeSynchsafe(b []byte) (uint32, bool) {
+	var v uint32
+	for _, c := range b {
+		if c&0x80 != 0 {
+			return 0, false
+		}
+		v = v<<7 | uint32(c)
+	}
+	return v, true
+}
+
+// encodeSynchsafe encodes v as a 28-bit synchsafe integer in 4 bytes
+func encodeSynchsafe(b []byte, v uint32) {
+	b[0] = byte(v>>21) & 0x7f
+	b[1] = byte(v>>14) & 0x7f
+	b[2] = byte(v>>7) & 0x7f
+	b[3] = byte(v) & 0x7f
+}
+
+// applyUnsynchronisation inserts a zero byte after each 0xff that is followed by
+// a byte with the three top bits set or a zero byte, or is the last byte.
+func applyUnsynchronisation(data []byte) []byte {
+	out := make([]byte, 0, len(data)+len(data)/16)
+	for i, c := range data {
+		out = append(out, c)
+		if c == 0xff && (i+1 == len(data) || data[i+1]&0xe0 == 0xe0 || data[i+1] == 0) {
+			out = append(out, 0)
+		}
+	}
+	return out
+}
+
+// removeUnsynchronisation removes the zero bytes after each 0xff
+func removeUnsynchronisation(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		out = append(out, data[i])
+		if data[i] == 0xff && i+1 < len(data) && data[i+1] == 0 {
+			i++
+		}
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+package id3
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestEncodeDecodeTag(t *testing.T) {
+	for _, version := range []byte{3, 4} {
+		for _, unsync := range []bool{false, true} {
+			tag := NewTag(version)
+			tag.Unsynchronisation = unsync
+			tag.AddFrame(&PrivFrame{Owner: "com.apple.streaming.transportStreamTimestamp",
+				Data: []byte{0x00, 0x00, 0x00, 0x00, 0xff, 0xe0, 0x00, 0xff}})
+			tag.AddFrame(NewTIT2Frame(version, "Ad break 🎬"))
+			tag.AddFrame(NewTxxxFrame(version, "CUE", "duration=30"))
+			tag.AddFrame(&GeobFrame{Encoding: EncodingISO88591, MIMEType: "application/octet-stream",
+				Filename: "data.bin", Description: "payload", Data: []byte{0xff, 0xff, 0x00, 0x01}})
+			tag.AddFrame(&UnknownFrame{FrameID: "WXXX", Data: []byte{0, 'a', 0, 'b'}})
+			data, err := tag.Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			size, err := TagSize(data)
+			if err != nil || size != len(data) {
+				t.Errorf("v2.%d unsync=%t: tag size %d, err=%v, wanted %d", version, unsync, size, err, len(data))
+			}
+			if unsync && bytes.Contains(data, []byte{0xff, 0xe0}) {
+				t.Errorf("v2.%d: false sync in unsynchronised tag", version)
+			}
+			got, err := DecodeTag(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(got, tag); diff != nil {
+				t.Errorf("v2.%d unsync=%t: %v", version, unsync, diff)
+			}
+		}
+	}
+}
+
+func TestEncodingVersionCheck(t *testing.T) {
+	tag := NewTag(3)
+	tag.AddFrame(&TextFrame{FrameID: "TIT2", Encoding: EncodingUTF8, Text: "title"})
+	if _, err := tag.Encode(); err == nil {
+		t.Error("expected error for UTF-8 in ID3v2.3")
+	}
+	tag = NewTag(3)
+	tag.AddFrame(&TextFrame{FrameID: "TIT2", Encoding: EncodingISO88591, Text: "€"})
+	if _, err := tag.Encode(); err == nil {
+		t.Error("expected error for non-Latin-1 character in ISO-8859-1 text")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	if _, err := DecodeTag([]byte("not an ID3 tag")); err != ErrNotID3 {
+		t.Errorf("got error %v instead of ErrNotID3", err)
+	}
+	// ID3v2.2 is not supported
+	if _, err := DecodeTag([]byte{'I', 'D', '3', 2, 0, 0, 0, 0, 0, 0}); err == nil {
+		t.Error("expected error for ID3v2.2")
+	}
+	// Frame size larger than tag
+	data := []byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 12, 'T', 'I', 'T', '2', 0, 0, 0, 10, 0, 0, 3, 'a'}
+	if _, err := DecodeTag(data); err == nil {
+		t.Error("expected error for too large frame size")
+	}
+}
+
+func TestDecodeV24FrameFlags(t *testing.T) {
+	// TIT2 with per-frame unsynchronisation and data length indicator, followed by padding
+	body := []byte{0, 0, 0, 3, EncodingISO88591, 0xff, 0x00, 'x'}
+	frame := append([]byte{'T', 'I', 'T', '2', 0, 0, 0, byte(len(body)), 0, 0x03}, body...)
+	data := []byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, byte(len(frame) + 4)}
+	data = append(data, frame...)
+	data = append(data, 0, 0, 0, 0)
+	tag, err := DecodeTag(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := &TextFrame{FrameID: "TIT2", Encoding: EncodingISO88591, Text: "ÿx"}
+	if diff := deep.Equal(tag.Frame("TIT2"), wanted); diff != nil {
+		t.Error(diff)
+	}
+	if len(tag.Frames) != 1 {
+		t.Errorf("got %d frames instead of 1", len(tag.Frames))
+	}
+}
+
+func TestUnsynchronisation(t *testing.T) {
+	testCases := []struct {
+		in, out []byte
+	}{
+		{[]byte{0xff, 0xe0}, []byte{0xff, 0x00, 0xe0}},
+		{[]byte{0xff, 0x00}, []byte{0xff, 0x00, 0x00}},
+		{[]byte{0xff, 0x10}, []byte{0xff, 0x10}},
+		{[]byte{0x01, 0xff}, []byte{0x01, 0xff, 0x00}},
+	}
+	for _, tc := range testCases {
+		got := applyUnsynchronisation(tc.in)
+		if !bytes.Equal(got, tc.out) {
+			t.Errorf("unsynchronisation of %x: got %x instead of %x", tc.in, got, tc.out)
+		}
+		back := removeUnsynchronisation(got)
+		if !bytes.Equal(back, tc.in) {
+			t.Errorf("resynchronisation of %x: got %x instead of %x", got, back, tc.in)
+		}
+	}
+}
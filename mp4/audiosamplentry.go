@@ -107,7 +107,7 @@ func DecodeAudioSampleEntry(hdr BoxHeader, startPos uint64, r io.Reader) (Box, e
 
 	pos := startPos + nrAudioSampleBytesBeforeChildren // Size of all previous data
 	for {
-		box, err := DecodeBox(pos, restReader)
+		box, err := decodeChildBox(pos, restReader)
 		if err == io.EOF {
 			break
 		} else if err != nil {
@@ -145,7 +145,7 @@ func DecodeAudioSampleEntrySR(hdr BoxHeader, startPos uint64, sr bits.SliceReade
 	pos := startPos + nrAudioSampleBytesBeforeChildren // Size of all previous data
 	lastPos := startPos + hdr.Size
 	for pos < lastPos {
-		box, err := DecodeBoxSR(pos, sr)
+		box, err := decodeChildBoxSR(pos, sr)
 		if err != nil {
 			return nil, err
 		}
@@ -157,6 +157,11 @@ func DecodeAudioSampleEntrySR(hdr BoxHeader, startPos uint64, sr bits.SliceReade
 	return a, sr.AccError()
 }
 
+// GetChildren - list of child boxes
+func (a *AudioSampleEntryBox) GetChildren() []Box {
+	return a.Children
+}
+
 // Type - return box type
 func (a *AudioSampleEntryBox) Type() string {
 	return a.name
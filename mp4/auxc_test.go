@@ -0,0 +1,12 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestAuxC(t *testing.T) {
+	boxDiffAfterEncodeAndDecode(t, &mp4.AuxCBox{AuxType: "urn:mpeg:mpegB:cicp:systems:auxiliary:alpha"})
+	boxDiffAfterEncodeAndDecode(t, &mp4.AuxCBox{AuxType: "urn:mpeg:hevc:2015:auxid:2", AuxSubtype: []byte{1, 2}})
+}
@@ -0,0 +1,81 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// BlinBox - Stereo Camera System Baseline Box (blin)
+//
+// BaselineValue is the distance between the camera centers in micrometers.
+//
+// Contained in : Stereo Camera System Box (cams)
+//
+// Defined in Apple HEVC Stereo Video - ISOBMFF Extensions
+type BlinBox struct {
+	Version       byte
+	Flags         uint32
+	BaselineValue uint32
+}
+
+// DecodeBlin - box-specific decode
+func DecodeBlin(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeBlinSR(hdr, startPos, sr)
+}
+
+// DecodeBlinSR - box-specific decode
+func DecodeBlinSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &BlinBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.BaselineValue = sr.ReadUint32()
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *BlinBox) Type() string {
+	return "blin"
+}
+
+// Size - return calculated size
+func (b *BlinBox) Size() uint64 {
+	return uint64(boxHeaderSize + 8)
+}
+
+// Encode - write box to w
+func (b *BlinBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *BlinBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint32(b.BaselineValue)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *BlinBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - baselineValue: %d", b.BaselineValue)
+	return bd.err
+}
@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -15,158 +16,252 @@ const (
 	flagsMask     = 0x00ffffff // Flags for masks from full header
 )
 
+// boxTypeEntry - decode functions and constructor information of a box type
+type boxTypeEntry struct {
+	// dec and decSR are nil for box types that are only decoded by their parent box.
+	dec   BoxDecoder
+	decSR BoxDecoderSR
+	// box is a nil pointer to the box struct, used to create empty boxes in BuildBox.
+	box Box
+	// newBox creates an empty box instead of box, if the struct is shared by several box types
+	// and needs the box type to be set.
+	newBox func(boxType string) Box
+}
+
+// boxTypes is the single table of implemented box types.
+// The decoders and decodersSR maps are set from it.
+var boxTypes map[string]boxTypeEntry
+
 var decoders map[string]BoxDecoder
 
 func init() {
-	decoders = map[string]BoxDecoder{
-		"\xa9ART": DecodeGenericContainerBox,
-		"\xa9nam": DecodeGenericContainerBox,
-		"\xa9too": DecodeGenericContainerBox,
-		"\xa9cpy": DecodeGenericContainerBox,
-		"ac-3":    DecodeAudioSampleEntry,
-		"ac-4":    DecodeAudioSampleEntry,
-		"alou":    DecodeLoudnessBaseBox,
-		"av01":    DecodeVisualSampleEntry,
-		"av1C":    DecodeAv1C,
-		"avc1":    DecodeVisualSampleEntry,
-		"avc3":    DecodeVisualSampleEntry,
-		"av3c":    DecodeAv3c,
-		"avcC":    DecodeAvcC,
-		"avs3":    DecodeVisualSampleEntry,
-		"btrt":    DecodeBtrt,
-		"cdat":    DecodeCdat,
-		"cdsc":    DecodeTrefType,
-		"clap":    DecodeClap,
-		"co64":    DecodeCo64,
-		"CoLL":    DecodeCoLL,
-		"colr":    DecodeColr,
-		"cslg":    DecodeCslg,
-		"ctim":    DecodeCtim,
-		"ctts":    DecodeCtts,
-		"dac3":    DecodeDac3,
-		"dac4":    DecodeDac4,
-		"mhaC":    DecodeMhaC,
-		"data":    DecodeData,
-		"dec3":    DecodeDec3,
-		"dOps":    DecodeDops,
-		"desc":    DecodeGenericContainerBox,
-		"dinf":    DecodeDinf,
-		"dpnd":    DecodeTrefType,
-		"dref":    DecodeDref,
-		"ec-3":    DecodeAudioSampleEntry,
-		"edts":    DecodeEdts,
-		"elng":    DecodeElng,
-		"elst":    DecodeElst,
-		"emeb":    DecodeEmeb,
-		"emib":    DecodeEmib,
-		"emsg":    DecodeEmsg,
-		"enca":    DecodeAudioSampleEntry,
-		"encv":    DecodeVisualSampleEntry,
-		"esds":    DecodeEsds,
-		"evte":    DecodeEvte,
-		"font":    DecodeTrefType,
-		"free":    DecodeFree,
-		"frma":    DecodeFrma,
-		"ftyp":    DecodeFtyp,
-		"hdlr":    DecodeHdlr,
-		"hev1":    DecodeVisualSampleEntry,
-		"hind":    DecodeTrefType,
-		"hint":    DecodeTrefType,
-		"hvc1":    DecodeVisualSampleEntry,
-		"hvcC":    DecodeHvcC,
-		"iden":    DecodeIden,
-		"ilst":    DecodeIlst,
-		"iods":    DecodeUnknown,
-		"ipir":    DecodeTrefType,
-		"kind":    DecodeKind,
-		"leva":    DecodeLeva,
-		"ludt":    DecodeLudt,
-		"mdat":    DecodeMdat,
-		"mehd":    DecodeMehd,
-		"mdhd":    DecodeMdhd,
-		"mdia":    DecodeMdia,
-		"meta":    DecodeMeta,
-		"mfhd":    DecodeMfhd,
-		"mfra":    DecodeMfra,
-		"mfro":    DecodeMfro,
-		"mha1":    DecodeAudioSampleEntry,
-		"mha2":    DecodeAudioSampleEntry,
-		"mhm1":    DecodeAudioSampleEntry,
-		"mhm2":    DecodeAudioSampleEntry,
-		"mime":    DecodeMime,
-		"minf":    DecodeMinf,
-		"moof":    DecodeMoof,
-		"moov":    DecodeMoov,
-		"mp4a":    DecodeAudioSampleEntry,
-		"mpod":    DecodeTrefType,
-		"mvex":    DecodeMvex,
-		"mvhd":    DecodeMvhd,
-		"nmhd":    DecodeNmhd,
-		"Opus":    DecodeAudioSampleEntry,
-		"pasp":    DecodePasp,
-		"payl":    DecodePayl,
-		"prft":    DecodePrft,
-		"pssh":    DecodePssh,
-		"saio":    DecodeSaio,
-		"saiz":    DecodeSaiz,
-		"sbgp":    DecodeSbgp,
-		"schi":    DecodeSchi,
-		"schm":    DecodeSchm,
-		"sdtp":    DecodeSdtp,
-		"senc":    DecodeSenc,
-		"sgpd":    DecodeSgpd,
-		"sidx":    DecodeSidx,
-		"silb":    DecodeSilb,
-		"sinf":    DecodeSinf,
-		"skip":    DecodeFree,
-		"SmDm":    DecodeSmDm,
-		"smhd":    DecodeSmhd,
-		"ssix":    DecodeSsix,
-		"stbl":    DecodeStbl,
-		"stco":    DecodeStco,
-		"sthd":    DecodeSthd,
-		"stpp":    DecodeStpp,
-		"stsc":    DecodeStsc,
-		"stsd":    DecodeStsd,
-		"stss":    DecodeStss,
-		"stsz":    DecodeStsz,
-		"sttg":    DecodeSttg,
-		"stts":    DecodeStts,
-		"styp":    DecodeStyp,
-		"subs":    DecodeSubs,
-		"subt":    DecodeTrefType,
-		"sync":    DecodeTrefType,
-		"tenc":    DecodeTenc,
-		"tfdt":    DecodeTfdt,
-		"tfhd":    DecodeTfhd,
-		"tfra":    DecodeTfra,
-		"tkhd":    DecodeTkhd,
-		"tlou":    DecodeLoudnessBaseBox,
-		"traf":    DecodeTraf,
-		"trak":    DecodeTrak,
-		"tref":    DecodeTref,
-		"trep":    DecodeTrep,
-		"trex":    DecodeTrex,
-		"trun":    DecodeTrun,
-		"udta":    DecodeUdta,
-		"url ":    DecodeURLBox,
-		"uuid":    DecodeUUIDBox,
-		"vdep":    DecodeTrefType,
-		"vlab":    DecodeVlab,
-		"vmhd":    DecodeVmhd,
-		"vp08":    DecodeVisualSampleEntry,
-		"vp09":    DecodeVisualSampleEntry,
-		"vpcC":    DecodeVppC,
-		"vplx":    DecodeTrefType,
-		"vsid":    DecodeVsid,
-		"vtta":    DecodeVtta,
-		"vvc1":    DecodeVisualSampleEntry,
-		"vvcC":    DecodeVvcC,
-		"vvi1":    DecodeVisualSampleEntry,
-		"vttc":    DecodeVttc,
-		"vttC":    DecodeVttC,
-		"vtte":    DecodeVtte,
-		"wvtt":    DecodeWvtt,
+	boxTypes = map[string]boxTypeEntry{
+		"----":    {DecodeFreeFormItem, DecodeFreeFormItemSR, nil, newGenericContainer},
+		"\xa9ART": {DecodeGenericContainerBox, DecodeGenericContainerBoxSR, nil, newGenericContainer},
+		"\xa9alb": {DecodeGenericContainerBox, DecodeGenericContainerBoxSR, nil, newGenericContainer},
+		"\xa9cmt": {DecodeGenericContainerBox, DecodeGenericContainerBoxSR, nil, newGenericContainer},
+		"\xa9cpy": {DecodeGenericContainerBox, DecodeGenericContainerBoxSR, nil, newGenericContainer},
+		"\xa9day": {DecodeGenericContainerBox, DecodeGenericContainerBoxSR, nil, newGenericContainer},
+		"\xa9gen": {DecodeGenericContainerBox, DecodeGenericContainerBoxSR, nil, newGenericContainer},
+		"\xa9nam": {DecodeGenericContainerBox, DecodeGenericContainerBoxSR, nil, newGenericContainer},
+		"\xa9too": {DecodeGenericContainerBox, DecodeGenericContainerBoxSR, nil, newGenericContainer},
+		"ac-3":    {DecodeAudioSampleEntry, DecodeAudioSampleEntrySR, nil, newAudioSampleEntry},
+		"ac-4":    {DecodeAudioSampleEntry, DecodeAudioSampleEntrySR, nil, newAudioSampleEntry},
+		"alou":    {DecodeLoudnessBaseBox, DecodeLoudnessBaseBoxSR, nil, newLoudnessBase},
+		"auxC":    {DecodeAuxC, DecodeAuxCSR, (*AuxCBox)(nil), nil},
+		"av01":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"av1C":    {DecodeAv1C, DecodeAv1CSR, (*Av1CBox)(nil), nil},
+		"avc1":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"avc3":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"av3c":    {DecodeAv3c, DecodeAv3cSR, (*Av3cBox)(nil), nil},
+		"avcC":    {DecodeAvcC, DecodeAvcCSR, (*AvcCBox)(nil), nil},
+		"avs3":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"blin":    {DecodeBlin, DecodeBlinSR, (*BlinBox)(nil), nil},
+		"blnk":    {DecodeBlnk, DecodeBlnkSR, (*BlnkBox)(nil), nil},
+		"btrt":    {DecodeBtrt, DecodeBtrtSR, (*BtrtBox)(nil), nil},
+		"cams":    {DecodeCams, DecodeCamsSR, (*CamsBox)(nil), nil},
+		"cbmp":    {DecodeCbmp, DecodeCbmpSR, (*CbmpBox)(nil), nil},
+		"cdat":    {DecodeCdat, DecodeCdatSR, (*CdatBox)(nil), nil},
+		"cdsc":    {DecodeTrefType, DecodeTrefTypeSR, nil, newTrefType},
+		"clap":    {DecodeClap, DecodeClapSR, (*ClapBox)(nil), nil},
+		"cmfy":    {DecodeCmfy, DecodeCmfySR, (*CmfyBox)(nil), nil},
+		"co64":    {DecodeCo64, DecodeCo64SR, (*Co64Box)(nil), nil},
+		"CoLL":    {DecodeCoLL, DecodeCoLLSR, (*CoLLBox)(nil), nil},
+		"colr":    {DecodeColr, DecodeColrSR, (*ColrBox)(nil), nil},
+		"covr":    {DecodeGenericContainerBox, DecodeGenericContainerBoxSR, nil, newGenericContainer},
+		"cslg":    {DecodeCslg, DecodeCslgSR, (*CslgBox)(nil), nil},
+		"ctim":    {DecodeCtim, DecodeCtimSR, (*CtimBox)(nil), nil},
+		"ctts":    {DecodeCtts, DecodeCttsSR, (*CttsBox)(nil), nil},
+		"dac3":    {DecodeDac3, DecodeDac3SR, (*Dac3Box)(nil), nil},
+		"dac4":    {DecodeDac4, DecodeDac4SR, (*Dac4Box)(nil), nil},
+		"mhaC":    {DecodeMhaC, DecodeMhaCSR, (*MhaCBox)(nil), nil},
+		"dadj":    {DecodeDadj, DecodeDadjSR, (*DadjBox)(nil), nil},
+		"data":    {DecodeData, DecodeDataSR, (*DataBox)(nil), nil},
+		"dav1":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"dec3":    {DecodeDec3, DecodeDec3SR, (*Dec3Box)(nil), nil},
+		"dlay":    {DecodeDlay, DecodeDlaySR, (*DlayBox)(nil), nil},
+		"dOps":    {DecodeDops, DecodeDopsSR, (*DopsBox)(nil), nil},
+		"desc":    {DecodeGenericContainerBox, DecodeGenericContainerBoxSR, nil, newGenericContainer},
+		"dinf":    {DecodeDinf, DecodeDinfSR, (*DinfBox)(nil), nil},
+		"dpnd":    {DecodeTrefType, DecodeTrefTypeSR, nil, newTrefType},
+		"dref":    {DecodeDref, DecodeDrefSR, (*DrefBox)(nil), nil},
+		"dvcC":    {DecodeDvcC, DecodeDvcCSR, nil, newDvcC},
+		"dva1":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"dvav":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"dvh1":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"dvhe":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"dvvC":    {DecodeDvcC, DecodeDvcCSR, nil, newDvcC},
+		"dvwC":    {DecodeDvcC, DecodeDvcCSR, nil, newDvcC},
+		"ec-3":    {DecodeAudioSampleEntry, DecodeAudioSampleEntrySR, nil, newAudioSampleEntry},
+		"edts":    {DecodeEdts, DecodeEdtsSR, (*EdtsBox)(nil), nil},
+		"elng":    {DecodeElng, DecodeElngSR, (*ElngBox)(nil), nil},
+		"elst":    {DecodeElst, DecodeElstSR, (*ElstBox)(nil), nil},
+		"emeb":    {DecodeEmeb, DecodeEmebSR, (*EmebBox)(nil), nil},
+		"emib":    {DecodeEmib, DecodeEmibSR, (*EmibBox)(nil), nil},
+		"emsg":    {DecodeEmsg, DecodeEmsgSR, (*EmsgBox)(nil), nil},
+		"enca":    {DecodeAudioSampleEntry, DecodeAudioSampleEntrySR, nil, newAudioSampleEntry},
+		"encv":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"equi":    {DecodeEqui, DecodeEquiSR, (*EquiBox)(nil), nil},
+		"esds":    {DecodeEsds, DecodeEsdsSR, (*EsdsBox)(nil), nil},
+		"evte":    {DecodeEvte, DecodeEvteSR, (*EvteBox)(nil), nil},
+		"eyes":    {DecodeEyes, DecodeEyesSR, (*EyesBox)(nil), nil},
+		"font":    {DecodeTrefType, DecodeTrefTypeSR, nil, newTrefType},
+		"free":    {DecodeFree, DecodeFreeSR, nil, newFree},
+		"frma":    {DecodeFrma, DecodeFrmaSR, (*FrmaBox)(nil), nil},
+		"ftab":    {DecodeFtab, DecodeFtabSR, (*FtabBox)(nil), nil},
+		"ftyp":    {DecodeFtyp, DecodeFtypSR, (*FtypBox)(nil), nil},
+		"gmhd":    {DecodeGmhd, DecodeGmhdSR, (*GmhdBox)(nil), nil},
+		"gmin":    {DecodeGmin, DecodeGminSR, (*GminBox)(nil), nil},
+		"hclr":    {DecodeHclr, DecodeHclrSR, (*HclrBox)(nil), nil},
+		"hdlr":    {DecodeHdlr, DecodeHdlrSR, (*HdlrBox)(nil), nil},
+		"hero":    {DecodeHero, DecodeHeroSR, (*HeroBox)(nil), nil},
+		"hev1":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"hind":    {DecodeTrefType, DecodeTrefTypeSR, nil, newTrefType},
+		"hint":    {DecodeTrefType, DecodeTrefTypeSR, nil, newTrefType},
+		"hlit":    {DecodeHlit, DecodeHlitSR, (*HlitBox)(nil), nil},
+		"href":    {DecodeHref, DecodeHrefSR, (*HrefBox)(nil), nil},
+		"hvc1":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"hvcC":    {DecodeHvcC, DecodeHvcCSR, (*HvcCBox)(nil), nil},
+		"idat":    {DecodeIdat, DecodeIdatSR, (*IdatBox)(nil), nil},
+		"iden":    {DecodeIden, DecodeIdenSR, (*IdenBox)(nil), nil},
+		"iinf":    {DecodeIinf, DecodeIinfSR, (*IinfBox)(nil), nil},
+		"iloc":    {DecodeIloc, DecodeIlocSR, (*IlocBox)(nil), nil},
+		"ilst":    {DecodeIlst, DecodeIlstSR, (*IlstBox)(nil), nil},
+		"imir":    {DecodeImir, DecodeImirSR, (*ImirBox)(nil), nil},
+		"infe":    {DecodeInfe, DecodeInfeSR, (*InfeBox)(nil), nil},
+		"iods":    {DecodeUnknown, DecodeUnknownSR, nil, nil},
+		"ipco":    {DecodeIpco, DecodeIpcoSR, (*IpcoBox)(nil), nil},
+		"ipir":    {DecodeTrefType, DecodeTrefTypeSR, nil, newTrefType},
+		"ipma":    {DecodeIpma, DecodeIpmaSR, (*IpmaBox)(nil), nil},
+		"iprp":    {DecodeIprp, DecodeIprpSR, (*IprpBox)(nil), nil},
+		"iref":    {DecodeIref, DecodeIrefSR, (*IrefBox)(nil), nil},
+		"irot":    {DecodeIrot, DecodeIrotSR, (*IrotBox)(nil), nil},
+		"ispe":    {DecodeIspe, DecodeIspeSR, (*IspeBox)(nil), nil},
+		"kind":    {DecodeKind, DecodeKindSR, (*KindBox)(nil), nil},
+		"krok":    {DecodeKrok, DecodeKrokSR, (*KrokBox)(nil), nil},
+		"leva":    {DecodeLeva, DecodeLevaSR, (*LevaBox)(nil), nil},
+		"ludt":    {DecodeLudt, DecodeLudtSR, (*LudtBox)(nil), nil},
+		"mdat":    {DecodeMdat, DecodeMdatSR, (*MdatBox)(nil), nil},
+		"mean":    {nil, nil, (*MeanBox)(nil), nil},
+		"mehd":    {DecodeMehd, DecodeMehdSR, (*MehdBox)(nil), nil},
+		"mdhd":    {DecodeMdhd, DecodeMdhdSR, (*MdhdBox)(nil), nil},
+		"mdia":    {DecodeMdia, DecodeMdiaSR, (*MdiaBox)(nil), nil},
+		"meta":    {DecodeMeta, DecodeMetaSR, (*MetaBox)(nil), nil},
+		"mett":    {DecodeMett, DecodeMettSR, (*MettBox)(nil), nil},
+		"metx":    {DecodeMetx, DecodeMetxSR, (*MetxBox)(nil), nil},
+		"mfhd":    {DecodeMfhd, DecodeMfhdSR, (*MfhdBox)(nil), nil},
+		"mfra":    {DecodeMfra, DecodeMfraSR, (*MfraBox)(nil), nil},
+		"mfro":    {DecodeMfro, DecodeMfroSR, (*MfroBox)(nil), nil},
+		"mha1":    {DecodeAudioSampleEntry, DecodeAudioSampleEntrySR, nil, newAudioSampleEntry},
+		"mha2":    {DecodeAudioSampleEntry, DecodeAudioSampleEntrySR, nil, newAudioSampleEntry},
+		"mhm1":    {DecodeAudioSampleEntry, DecodeAudioSampleEntrySR, nil, newAudioSampleEntry},
+		"mhm2":    {DecodeAudioSampleEntry, DecodeAudioSampleEntrySR, nil, newAudioSampleEntry},
+		"mime":    {DecodeMime, DecodeMimeSR, (*MimeBox)(nil), nil},
+		"minf":    {DecodeMinf, DecodeMinfSR, (*MinfBox)(nil), nil},
+		"moof":    {DecodeMoof, DecodeMoofSR, (*MoofBox)(nil), nil},
+		"moov":    {DecodeMoov, DecodeMoovSR, (*MoovBox)(nil), nil},
+		"mp4a":    {DecodeAudioSampleEntry, DecodeAudioSampleEntrySR, nil, newAudioSampleEntry},
+		"mpod":    {DecodeTrefType, DecodeTrefTypeSR, nil, newTrefType},
+		"must":    {DecodeMust, DecodeMustSR, (*MustBox)(nil), nil},
+		"mvex":    {DecodeMvex, DecodeMvexSR, (*MvexBox)(nil), nil},
+		"mvhd":    {DecodeMvhd, DecodeMvhdSR, (*MvhdBox)(nil), nil},
+		"name":    {nil, nil, (*NameBox)(nil), nil},
+		"nmhd":    {DecodeNmhd, DecodeNmhdSR, (*NmhdBox)(nil), nil},
+		"Opus":    {DecodeAudioSampleEntry, DecodeAudioSampleEntrySR, nil, newAudioSampleEntry},
+		"pasp":    {DecodePasp, DecodePaspSR, (*PaspBox)(nil), nil},
+		"payl":    {DecodePayl, DecodePaylSR, (*PaylBox)(nil), nil},
+		"pitm":    {DecodePitm, DecodePitmSR, (*PitmBox)(nil), nil},
+		"pixi":    {DecodePixi, DecodePixiSR, (*PixiBox)(nil), nil},
+		"prft":    {DecodePrft, DecodePrftSR, (*PrftBox)(nil), nil},
+		"prhd":    {DecodePrhd, DecodePrhdSR, (*PrhdBox)(nil), nil},
+		"prji":    {DecodePrji, DecodePrjiSR, (*PrjiBox)(nil), nil},
+		"proj":    {DecodeProj, DecodeProjSR, (*ProjBox)(nil), nil},
+		"pssh":    {DecodePssh, DecodePsshSR, (*PsshBox)(nil), nil},
+		"saio":    {DecodeSaio, DecodeSaioSR, (*SaioBox)(nil), nil},
+		"saiz":    {DecodeSaiz, DecodeSaizSR, (*SaizBox)(nil), nil},
+		"sbgp":    {DecodeSbgp, DecodeSbgpSR, (*SbgpBox)(nil), nil},
+		"schi":    {DecodeSchi, DecodeSchiSR, (*SchiBox)(nil), nil},
+		"schm":    {DecodeSchm, DecodeSchmSR, (*SchmBox)(nil), nil},
+		"sdtp":    {DecodeSdtp, DecodeSdtpSR, (*SdtpBox)(nil), nil},
+		"senc":    {DecodeSenc, DecodeSencSR, (*SencBox)(nil), nil},
+		"sgpd":    {DecodeSgpd, DecodeSgpdSR, (*SgpdBox)(nil), nil},
+		"sidx":    {DecodeSidx, DecodeSidxSR, (*SidxBox)(nil), nil},
+		"silb":    {DecodeSilb, DecodeSilbSR, (*SilbBox)(nil), nil},
+		"sinf":    {DecodeSinf, DecodeSinfSR, (*SinfBox)(nil), nil},
+		"skip":    {DecodeFree, DecodeFreeSR, nil, newFree},
+		"SmDm":    {DecodeSmDm, DecodeSmDmSR, (*SmDmBox)(nil), nil},
+		"smhd":    {DecodeSmhd, DecodeSmhdSR, (*SmhdBox)(nil), nil},
+		"ssix":    {DecodeSsix, DecodeSsixSR, (*SsixBox)(nil), nil},
+		"st3d":    {DecodeSt3d, DecodeSt3dSR, (*St3dBox)(nil), nil},
+		"stbl":    {DecodeStbl, DecodeStblSR, (*StblBox)(nil), nil},
+		"stco":    {DecodeStco, DecodeStcoSR, (*StcoBox)(nil), nil},
+		"sthd":    {DecodeSthd, DecodeSthdSR, (*SthdBox)(nil), nil},
+		"stpp":    {DecodeStpp, DecodeStppSR, (*StppBox)(nil), nil},
+		"stri":    {DecodeStri, DecodeStriSR, (*StriBox)(nil), nil},
+		"stsc":    {DecodeStsc, DecodeStscSR, (*StscBox)(nil), nil},
+		"stsd":    {DecodeStsd, DecodeStsdSR, (*StsdBox)(nil), nil},
+		"stss":    {DecodeStss, DecodeStssSR, (*StssBox)(nil), nil},
+		"stsz":    {DecodeStsz, DecodeStszSR, (*StszBox)(nil), nil},
+		"sttg":    {DecodeSttg, DecodeSttgSR, (*SttgBox)(nil), nil},
+		"stts":    {DecodeStts, DecodeSttsSR, (*SttsBox)(nil), nil},
+		"styl":    {DecodeStyl, DecodeStylSR, (*StylBox)(nil), nil},
+		"styp":    {DecodeStyp, DecodeStypSR, (*StypBox)(nil), nil},
+		"stz2":    {DecodeStz2, DecodeStz2SR, (*Stz2Box)(nil), nil},
+		"subs":    {DecodeSubs, DecodeSubsSR, (*SubsBox)(nil), nil},
+		"subt":    {DecodeTrefType, DecodeTrefTypeSR, nil, newTrefType},
+		"sv3d":    {DecodeSv3d, DecodeSv3dSR, (*Sv3dBox)(nil), nil},
+		"svhd":    {DecodeSvhd, DecodeSvhdSR, (*SvhdBox)(nil), nil},
+		"sync":    {DecodeTrefType, DecodeTrefTypeSR, nil, newTrefType},
+		"tbox":    {DecodeTbox, DecodeTboxSR, (*TboxBox)(nil), nil},
+		"tcmi":    {DecodeTcmi, DecodeTcmiSR, (*TcmiBox)(nil), nil},
+		"tenc":    {DecodeTenc, DecodeTencSR, (*TencBox)(nil), nil},
+		"tfdt":    {DecodeTfdt, DecodeTfdtSR, (*TfdtBox)(nil), nil},
+		"tfhd":    {DecodeTfhd, DecodeTfhdSR, (*TfhdBox)(nil), nil},
+		"tfra":    {DecodeTfra, DecodeTfraSR, (*TfraBox)(nil), nil},
+		"tkhd":    {DecodeTkhd, DecodeTkhdSR, (*TkhdBox)(nil), nil},
+		"tlou":    {DecodeLoudnessBaseBox, DecodeLoudnessBaseBoxSR, nil, newLoudnessBase},
+		"tmcd":    {DecodeTmcd, DecodeTmcdSR, nil, nil},
+		"traf":    {DecodeTraf, DecodeTrafSR, (*TrafBox)(nil), nil},
+		"trak":    {DecodeTrak, DecodeTrakSR, (*TrakBox)(nil), nil},
+		"tref":    {DecodeTref, DecodeTrefSR, (*TrefBox)(nil), nil},
+		"trep":    {DecodeTrep, DecodeTrepSR, (*TrepBox)(nil), nil},
+		"trex":    {DecodeTrex, DecodeTrexSR, (*TrexBox)(nil), nil},
+		"trkn":    {DecodeGenericContainerBox, DecodeGenericContainerBoxSR, nil, newGenericContainer},
+		"trun":    {DecodeTrun, DecodeTrunSR, (*TrunBox)(nil), nil},
+		"tx3g":    {DecodeTx3g, DecodeTx3gSR, (*Tx3gBox)(nil), nil},
+		"txtC":    {DecodeTxtC, DecodeTxtCSR, (*TxtCBox)(nil), nil},
+		"udta":    {DecodeUdta, DecodeUdtaSR, (*UdtaBox)(nil), nil},
+		"uri ":    {DecodeURI, DecodeURISR, (*URIBox)(nil), nil},
+		"uriI":    {DecodeURIInit, DecodeURIInitSR, (*URIInitBox)(nil), nil},
+		"urim":    {DecodeUrim, DecodeUrimSR, (*UrimBox)(nil), nil},
+		"url ":    {DecodeURLBox, DecodeURLBoxSR, (*URLBox)(nil), nil},
+		"uuid":    {DecodeUUIDBox, DecodeUUIDBoxSR, (*UUIDBox)(nil), nil},
+		"vdep":    {DecodeTrefType, DecodeTrefTypeSR, nil, newTrefType},
+		"vexu":    {DecodeVexu, DecodeVexuSR, (*VexuBox)(nil), nil},
+		"vlab":    {DecodeVlab, DecodeVlabSR, (*VlabBox)(nil), nil},
+		"vmhd":    {DecodeVmhd, DecodeVmhdSR, (*VmhdBox)(nil), nil},
+		"vp08":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"vp09":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"vpcC":    {DecodeVppC, DecodeVppCSR, (*VppCBox)(nil), nil},
+		"vplx":    {DecodeTrefType, DecodeTrefTypeSR, nil, newTrefType},
+		"vsid":    {DecodeVsid, DecodeVsidSR, (*VsidBox)(nil), nil},
+		"vtta":    {DecodeVtta, DecodeVttaSR, (*VttaBox)(nil), nil},
+		"vvc1":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"vvcC":    {DecodeVvcC, DecodeVvcCSR, (*VvcCBox)(nil), nil},
+		"vvi1":    {DecodeVisualSampleEntry, DecodeVisualSampleEntrySR, nil, newVisualSampleEntry},
+		"vttc":    {DecodeVttc, DecodeVttcSR, (*VttcBox)(nil), nil},
+		"vttC":    {DecodeVttC, DecodeVttCSR, (*VttCBox)(nil), nil},
+		"vtte":    {DecodeVtte, DecodeVtteSR, (*VtteBox)(nil), nil},
+		"wvtt":    {DecodeWvtt, DecodeWvttSR, (*WvttBox)(nil), nil},
+	}
+	decoders = make(map[string]BoxDecoder, len(boxTypes))
+	decodersSR = make(map[string]BoxDecoderSR, len(boxTypes))
+	for boxType, e := range boxTypes {
+		if e.dec == nil {
+			continue
+		}
+		decoders[boxType] = e.dec
+		decodersSR[boxType] = e.decSR
 	}
 }
 
@@ -191,13 +286,19 @@ type BoxHeader struct {
 	Name   string
 	Size   uint64
 	Hdrlen int
+	// SizeToEnd is true if the size field is 0, meaning that the box extends to the end of the file.
+	// Size is then resolved to the header length plus the remaining number of bytes.
+	SizeToEnd bool
 }
 
 func (b BoxHeader) payloadLen() int {
 	return int(b.Size) - b.Hdrlen
 }
 
-// DecodeHeader decodes a box header (size + box type + possible largeSize)
+// DecodeHeader decodes a box header (size + box type + possible largeSize).
+// A size field of 0 means that the box extends to the end of the file.
+// The size is then resolved using the Len method (e.g. bytes.Buffer and bytes.Reader)
+// or by seeking to the end if r is an io.Seeker. If neither is available, Size is set to 0.
 func DecodeHeader(r io.Reader) (BoxHeader, error) {
 	buf := make([]byte, boxHeaderSize)
 	n, err := io.ReadFull(r, buf)
@@ -219,12 +320,44 @@ func DecodeHeader(r io.Reader) (BoxHeader, error) {
 		size = binary.BigEndian.Uint64(buf)
 		headerLen += largeSizeLen
 	case 0: // size 0 means to end of file
-		return BoxHeader{}, fmt.Errorf("Size 0, meaning to end of file, not supported")
+		remaining, ok, err := remainingBytes(r)
+		if err != nil {
+			return BoxHeader{}, err
+		}
+		hdr := BoxHeader{Name: string(buf[4:8]), Hdrlen: headerLen, SizeToEnd: true}
+		if ok {
+			hdr.Size = uint64(headerLen) + remaining
+		}
+		return hdr, nil
 	}
 	if uint64(headerLen) > size {
 		return BoxHeader{}, fmt.Errorf("box header size %d exceeds box size %d", headerLen, size)
 	}
-	return BoxHeader{string(buf[4:8]), size, headerLen}, nil
+	return BoxHeader{Name: string(buf[4:8]), Size: size, Hdrlen: headerLen}, nil
+}
+
+// remainingBytes returns the number of bytes left in r if it can be found without reading.
+func remainingBytes(r io.Reader) (uint64, bool, error) {
+	if l, ok := r.(interface{ Len() int }); ok {
+		return uint64(l.Len()), true, nil
+	}
+	s, ok := r.(io.Seeker)
+	if !ok {
+		return 0, false, nil
+	}
+	pos, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false, err
+	}
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, false, err
+	}
+	_, err = s.Seek(pos, io.SeekStart)
+	if err != nil {
+		return 0, false, err
+	}
+	return uint64(end - pos), true, nil
 }
 
 // EncodeHeader - encode a box header to a writer
@@ -322,13 +455,38 @@ type BoxDecoder func(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error)
 
 // DecodeBox decodes a box
 func DecodeBox(startPos uint64, r io.Reader) (Box, error) {
-	var err error
-	var b Box
+	h, err := DecodeHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBoxBody(h, startPos, r)
+}
 
+// decodeChildBox decodes a box inside another box. Size 0 is only allowed for top-level boxes.
+func decodeChildBox(startPos uint64, r io.Reader) (Box, error) {
 	h, err := DecodeHeader(r)
 	if err != nil {
 		return nil, err
 	}
+	if h.SizeToEnd {
+		return nil, fmt.Errorf("decode %s pos %d: size 0 only allowed for top-level boxes", h.Name, startPos)
+	}
+	return decodeBoxBody(h, startPos, r)
+}
+
+// decodeBoxBody decodes a box after its header
+func decodeBoxBody(h BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	var err error
+	var b Box
+	if h.SizeToEnd && h.Size == 0 {
+		// The remaining length is not known, so read the rest of the data
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		h.Size = uint64(h.Hdrlen + len(data))
+		r = bytes.NewReader(data)
+	}
 
 	d, ok := decoders[h.Name]
 
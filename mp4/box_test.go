@@ -80,9 +80,9 @@ func TestDecodeHeader(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name:    "zero size not supported",
+			name:    "zero size extends to end of file",
 			data:    []byte{0x00, 0x00, 0x00, 0x00, 't', 'e', 's', 't'},
-			wantErr: true,
+			wantErr: false,
 		},
 	}
 
@@ -0,0 +1,746 @@
+package mp4
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+)
+
+// jsonFieldSetter is implemented by boxes with state in unexported fields.
+// It is the inverse of jsonFielder.
+type jsonFieldSetter interface {
+	// setJSONFields sets the box state from the entries added by addJSONFields and removes them from fields.
+	setJSONFields(fields map[string]interface{}) error
+}
+
+// newBoxOfType returns an empty box of type boxType.
+// Box types without an implementation result in an UnknownBox.
+func newBoxOfType(boxType string) Box {
+	e, ok := boxTypes[boxType]
+	switch {
+	case ok && e.newBox != nil:
+		return e.newBox(boxType)
+	case ok && e.box != nil:
+		if b, ok := reflect.New(reflect.TypeOf(e.box).Elem()).Interface().(Box); ok {
+			return b
+		}
+	}
+	return &UnknownBox{name: boxType, size: boxHeaderSize}
+}
+
+// Constructors for box types sharing a struct, used in boxTypes.
+
+func newVisualSampleEntry(boxType string) Box { return NewVisualSampleEntryBox(boxType) }
+
+func newAudioSampleEntry(boxType string) Box { return NewAudioSampleEntryBox(boxType) }
+
+func newTrefType(boxType string) Box { return &TrefTypeBox{Name: boxType} }
+
+func newDvcC(boxType string) Box { return &DvcCBox{Name: boxType} }
+
+func newLoudnessBase(boxType string) Box { return &LoudnessBaseBox{Name: boxType} }
+
+func newGenericContainer(boxType string) Box { return NewGenericContainerBox(boxType) }
+
+func newFree(boxType string) Box { return &FreeBox{Name: boxType} }
+
+// newTmcdBoxOfFields returns the tmcd box variant matching the fields,
+// since tmcd is used both as sample entry, as gmhd child, and as track reference.
+func newTmcdBoxOfFields(fields map[string]interface{}) Box {
+	if _, ok := fields["TrackIDs"]; ok {
+		return &TrefTypeBox{Name: "tmcd"}
+	}
+	for _, key := range []string{"DataReferenceIndex", "Flags", "Timescale", "FrameDuration", "NumberOfFrames"} {
+		if _, ok := fields[key]; ok {
+			return &TmcdBox{}
+		}
+	}
+	return &TmcdInfoBox{}
+}
+
+// DecodeBoxNodesJSON decodes a JSON array of box descriptions like the output of File.BoxTree.
+// Numbers are kept as json.Number to preserve 64-bit values.
+func DecodeBoxNodesJSON(r io.Reader) ([]*BoxNode, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var nodes []*BoxNode
+	if err := dec.Decode(&nodes); err != nil {
+		return nil, fmt.Errorf("decode box description: %w", err)
+	}
+	return nodes, nil
+}
+
+// BuildBox creates a box with descendants from a box description.
+//
+// The description has the same format as a BoxNode created by NewBoxNode.
+// Size and Offset are ignored, since they are calculated from the box content.
+// Fields are set on the box struct by json field name (see BoxNode), and all fields not present keep their zero values.
+// Counts that are not given are set from the number of children or entries
+// (stsd SampleCount, dref EntryCount, stsz SampleNumber, saiz SampleCount, and senc SampleCount).
+// Byte slices are given as hex strings.
+// The mdat payload is given as a hex string in Data, or as a file name in DataFile,
+// where relative file names are relative to dataDir. If there is no payload,
+// DataLength zero bytes are written.
+func BuildBox(node *BoxNode, dataDir string) (Box, error) {
+	return buildBox(node, dataDir, node.Type)
+}
+
+// BuildFile creates a File from a list of top-level box descriptions like the output of File.BoxTree.
+//
+// See BuildBox for the description format. The file is encoded box by box in the given order.
+//
+// Offsets that are not given (zero or no entries) are calculated from the layout of the file:
+//   - trun DataOffset, for truns with the data-offset-present flag, pointing into the next mdat box
+//     with the sample data of the truns of a moof in order,
+//   - saio offsets in traf, pointing to the sample auxiliary information in the senc box of the traf,
+//   - stco and co64 chunk offsets, pointing into the first mdat box with the chunks
+//     placed after each other, track by track.
+func BuildFile(nodes []*BoxNode, dataDir string) (*File, error) {
+	boxes := make([]Box, 0, len(nodes))
+	for _, node := range nodes {
+		b, err := BuildBox(node, dataDir)
+		if err != nil {
+			return nil, err
+		}
+		boxes = append(boxes, b)
+	}
+	if err := setBuiltOffsets(boxes); err != nil {
+		return nil, err
+	}
+	f := NewFile()
+	f.FragEncMode = EncModeBoxTree
+	var pos uint64
+	for _, b := range boxes {
+		if mdat, ok := b.(*MdatBox); ok {
+			mdat.StartPos = pos // Needed to read sample data, like for a decoded file
+		}
+		f.AddChild(b, pos)
+		pos += b.Size()
+	}
+	return f, nil
+}
+
+// builtChunkOffsets - a stco or co64 box with offsets to calculate
+type builtChunkOffsets struct {
+	stbl       *StblBox
+	chunkSizes []uint64
+}
+
+// setBuiltOffsets sets the trun, saio, stco, and co64 offsets that are not given.
+// Boxes with no offsets get entries first, since that changes their size and the positions of other boxes.
+func setBuiltOffsets(boxes []Box) error {
+	var moov *MoovBox
+	var chunkOffsets []builtChunkOffsets
+	for _, b := range boxes {
+		switch box := b.(type) {
+		case *MoovBox:
+			moov = box
+			for _, trak := range box.Traks {
+				co, err := newBuiltChunkOffsets(trak)
+				if err != nil {
+					return err
+				}
+				if co != nil {
+					chunkOffsets = append(chunkOffsets, *co)
+				}
+			}
+		case *MoofBox:
+			for _, traf := range box.Trafs {
+				if traf.Saio != nil && traf.Senc != nil && len(traf.Saio.Offset) == 0 {
+					traf.Saio.Offset = []int64{0}
+				}
+			}
+		}
+	}
+	positions := make([]uint64, len(boxes))
+	var pos uint64
+	firstMdat := -1
+	for i, b := range boxes {
+		positions[i] = pos
+		pos += b.Size()
+		if b.Type() == "mdat" && firstMdat < 0 {
+			firstMdat = i
+		}
+	}
+	for i, b := range boxes {
+		moof, ok := b.(*MoofBox)
+		if !ok {
+			continue
+		}
+		setBuiltSaioOffsets(moof)
+		if err := setBuiltTrunDataOffsets(moof, positions[i], boxes[i+1:], positions[i+1:], moov); err != nil {
+			return fmt.Errorf("moof at %d: %w", positions[i], err)
+		}
+	}
+	if len(chunkOffsets) == 0 {
+		return nil
+	}
+	if firstMdat < 0 {
+		return fmt.Errorf("no mdat box for chunk offsets")
+	}
+	mdat := boxes[firstMdat].(*MdatBox)
+	offset := positions[firstMdat] + mdat.HeaderSize()
+	for _, co := range chunkOffsets {
+		for i, size := range co.chunkSizes {
+			if co.stbl.Stco != nil {
+				if offset > math.MaxUint32 {
+					return fmt.Errorf("chunk offset %d does not fit in stco", offset)
+				}
+				co.stbl.Stco.ChunkOffset[i] = uint32(offset)
+			} else {
+				co.stbl.Co64.ChunkOffset[i] = offset
+			}
+			offset += size
+		}
+	}
+	return nil
+}
+
+// newBuiltChunkOffsets returns the chunk sizes of trak if its stco or co64 box has no entries but there are samples.
+// The chunk offset box gets zero entries for all chunks.
+func newBuiltChunkOffsets(trak *TrakBox) (*builtChunkOffsets, error) {
+	if trak.Mdia == nil || trak.Mdia.Minf == nil || trak.Mdia.Minf.Stbl == nil {
+		return nil, nil
+	}
+	stbl := trak.Mdia.Minf.Stbl
+	switch {
+	case stbl.Stsz == nil || stbl.Stsc == nil || stbl.Stsz.GetNrSamples() == 0:
+		return nil, nil
+	case stbl.Stco != nil && len(stbl.Stco.ChunkOffset) > 0, stbl.Co64 != nil && len(stbl.Co64.ChunkOffset) > 0:
+		return nil, nil
+	case stbl.Stco == nil && stbl.Co64 == nil:
+		return nil, nil
+	}
+	var chunkSizes []uint64
+	for nr := 1; nr <= int(stbl.Stsz.GetNrSamples()); nr++ {
+		chunkNr, _, err := stbl.Stsc.ChunkNrFromSampleNr(nr)
+		if err != nil {
+			return nil, fmt.Errorf("track %d: %w", trak.Tkhd.TrackID, err)
+		}
+		for len(chunkSizes) < chunkNr {
+			chunkSizes = append(chunkSizes, 0)
+		}
+		chunkSizes[chunkNr-1] += uint64(stbl.Stsz.GetSampleSize(nr))
+	}
+	if stbl.Stco != nil {
+		stbl.Stco.ChunkOffset = make([]uint32, len(chunkSizes))
+	} else {
+		stbl.Co64.ChunkOffset = make([]uint64, len(chunkSizes))
+	}
+	return &builtChunkOffsets{stbl: stbl, chunkSizes: chunkSizes}, nil
+}
+
+// setBuiltSaioOffsets sets saio offsets of zero to the start of the senc sample data relative to the moof start.
+func setBuiltSaioOffsets(moof *MoofBox) {
+	trafPos := childrenOffset(moof, moof.Children)
+	for _, c := range moof.Children {
+		traf, ok := c.(*TrafBox)
+		if !ok {
+			trafPos += c.Size()
+			continue
+		}
+		if traf.Saio != nil && traf.Senc != nil && len(traf.Saio.Offset) == 1 && traf.Saio.Offset[0] == 0 {
+			sencPos := trafPos + childrenOffset(traf, traf.Children)
+			for _, tc := range traf.Children {
+				if tc == Box(traf.Senc) {
+					break
+				}
+				sencPos += tc.Size()
+			}
+			// Skip header, version and flags, and sample count
+			traf.Saio.Offset[0] = int64(sencPos + 16)
+		}
+		trafPos += traf.Size()
+	}
+}
+
+// setBuiltTrunDataOffsets sets trun data offsets of zero to point into the first mdat box in boxes.
+func setBuiltTrunDataOffsets(moof *MoofBox, moofPos uint64, boxes []Box, positions []uint64, moov *MoovBox) error {
+	needed := false
+	for _, traf := range moof.Trafs {
+		for _, trun := range traf.Truns {
+			if trun.HasDataOffset() && trun.DataOffset == 0 {
+				needed = true
+			}
+		}
+	}
+	if !needed {
+		return nil
+	}
+	var mdat *MdatBox
+	var mdatPos uint64
+	for i, b := range boxes {
+		if m, ok := b.(*MdatBox); ok {
+			mdat, mdatPos = m, positions[i]
+			break
+		}
+	}
+	if mdat == nil {
+		return fmt.Errorf("no mdat box after moof for trun data offsets")
+	}
+	offset := mdatPos + mdat.HeaderSize() - moofPos
+	for _, traf := range moof.Trafs {
+		var trex *TrexBox
+		if moov != nil && moov.Mvex != nil && traf.Tfhd != nil {
+			trex, _ = moov.Mvex.GetTrex(traf.Tfhd.TrackID)
+		}
+		for _, trun := range traf.Truns {
+			if trun.HasDataOffset() && trun.DataOffset == 0 {
+				if offset > math.MaxInt32 {
+					return fmt.Errorf("trun data offset %d does not fit in 32 bits", offset)
+				}
+				trun.DataOffset = int32(offset)
+			}
+			offset += builtTrunDataSize(trun, traf.Tfhd, trex)
+		}
+	}
+	return nil
+}
+
+// builtTrunDataSize returns the size of the sample data of trun, using default sample sizes if needed.
+func builtTrunDataSize(trun *TrunBox, tfhd *TfhdBox, trex *TrexBox) uint64 {
+	if trun.HasSampleSize() {
+		return trun.SizeOfData()
+	}
+	var defaultSize uint32
+	switch {
+	case tfhd != nil && tfhd.HasDefaultSampleSize():
+		defaultSize = tfhd.DefaultSampleSize
+	case trex != nil:
+		defaultSize = trex.DefaultSampleSize
+	}
+	return uint64(defaultSize) * uint64(len(trun.Samples))
+}
+
+// BuildFileFromJSON creates a File from a JSON array of box descriptions. See BuildBox for the format.
+func BuildFileFromJSON(r io.Reader, dataDir string) (*File, error) {
+	nodes, err := DecodeBoxNodesJSON(r)
+	if err != nil {
+		return nil, err
+	}
+	return BuildFile(nodes, dataDir)
+}
+
+func buildBox(node *BoxNode, dataDir, path string) (Box, error) {
+	boxType, err := boxTypeFromString(node.Type)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	b := newBoxOfType(boxType)
+	if boxType == "tmcd" {
+		b = newTmcdBoxOfFields(node.Fields)
+	}
+	if len(node.Children) > 0 {
+		if err := addBuiltChildren(b, node.Children, dataDir, path); err != nil {
+			return nil, err
+		}
+	}
+	fields := make(map[string]interface{}, len(node.Fields))
+	for key, value := range node.Fields {
+		fields[key] = value
+	}
+	if m, ok := b.(*MdatBox); ok {
+		if err := setMdatData(m, fields, dataDir); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if fs, ok := b.(jsonFieldSetter); ok {
+		if err := fs.setJSONFields(fields); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	_, hasChildren := b.(childrenGetter)
+	if err := setStructFields(reflect.ValueOf(b).Elem(), fields, hasChildren); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	setBuiltCounts(b)
+	return b, nil
+}
+
+// setBuiltCounts sets counts that are not given from the number of children or entries.
+func setBuiltCounts(b Box) {
+	switch box := b.(type) {
+	case *StsdBox:
+		if box.SampleCount == 0 {
+			box.SampleCount = uint32(len(box.Children))
+		}
+	case *StszBox:
+		if box.SampleNumber == 0 && box.SampleUniformSize == 0 {
+			box.SampleNumber = uint32(len(box.SampleSize))
+		}
+	case *SaizBox:
+		if box.SampleCount == 0 && box.DefaultSampleInfoSize == 0 {
+			box.SampleCount = uint32(len(box.SampleInfo))
+		}
+	case *SencBox:
+		if box.SampleCount == 0 {
+			box.SampleCount = uint32(len(box.IVs))
+			if len(box.SubSamples) > len(box.IVs) {
+				box.SampleCount = uint32(len(box.SubSamples))
+			}
+		}
+	}
+}
+
+// boxTypeFromString is the inverse of boxTypeString.
+func boxTypeFromString(s string) (string, error) {
+	boxType := make([]byte, 0, 4)
+	for _, r := range s {
+		if r > 0xff {
+			return "", fmt.Errorf("box type %q has non Latin-1 character", s)
+		}
+		boxType = append(boxType, byte(r))
+	}
+	if len(boxType) != 4 {
+		return "", fmt.Errorf("box type %q is not 4 characters", s)
+	}
+	return string(boxType), nil
+}
+
+func addBuiltChildren(b Box, children []*BoxNode, dataDir, path string) error {
+	for i, childNode := range children {
+		child, err := buildBox(childNode, dataDir, fmt.Sprintf("%s/%s[%d]", path, childNode.Type, i+1))
+		if err != nil {
+			return err
+		}
+		switch p := b.(type) {
+		case interface{ AddChild(Box) }:
+			p.AddChild(child)
+		case interface{ AddChild(Box) error }:
+			if err := p.AddChild(child); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		default:
+			return fmt.Errorf("%s: box cannot have children", path)
+		}
+	}
+	return nil
+}
+
+// setMdatData sets the payload from Data, DataFile, or DataLength.
+func setMdatData(m *MdatBox, fields map[string]interface{}, dataDir string) error {
+	var data []byte
+	hasData := false
+	if value, ok := fields["DataFile"]; ok {
+		fileName, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("DataFile: not a string")
+		}
+		if !filepath.IsAbs(fileName) {
+			fileName = filepath.Join(dataDir, fileName)
+		}
+		var err error
+		data, err = os.ReadFile(fileName)
+		if err != nil {
+			return fmt.Errorf("DataFile: %w", err)
+		}
+		hasData = true
+		delete(fields, "DataFile")
+	}
+	if value, ok := fields["Data"]; ok {
+		if hasData {
+			return fmt.Errorf("both Data and DataFile given")
+		}
+		hexData, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("Data: not a hex string")
+		}
+		var err error
+		data, err = hex.DecodeString(hexData)
+		if err != nil {
+			return fmt.Errorf("Data: %w", err)
+		}
+		hasData = true
+		delete(fields, "Data")
+	}
+	if value, ok := fields["DataLength"]; ok {
+		dataLength, err := toUint64(value)
+		if err != nil {
+			return fmt.Errorf("DataLength: %w", err)
+		}
+		switch {
+		case !hasData:
+			data = make([]byte, dataLength)
+		case uint64(len(data)) != dataLength:
+			return fmt.Errorf("DataLength %d does not match data length %d", dataLength, len(data))
+		}
+		delete(fields, "DataLength")
+	}
+	m.Data = data
+	return nil
+}
+
+// setStructFields sets the fields of struct v from fields with json field names as keys.
+// If skipBoxes is set, box fields cannot be set since they are set by adding children.
+func setStructFields(v reflect.Value, fields map[string]interface{}, skipBoxes bool) error {
+	settable := make(map[string]reflect.Value)
+	collectSettableFields(settable, v)
+	for key, value := range fields {
+		fv, ok := settable[key]
+		if !ok {
+			return fmt.Errorf("unknown field %q for %s", key, v.Type().Name())
+		}
+		if skipBoxes && isBoxType(fv.Type()) {
+			return fmt.Errorf("field %q is a box, give it as a child instead", key)
+		}
+		if err := setValue(fv, value); err != nil {
+			return fmt.Errorf("field %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// collectSettableFields collects exported fields including those of embedded structs.
+func collectSettableFields(settable map[string]reflect.Value, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			collectSettableFields(settable, v.Field(i))
+			continue
+		}
+		if sf.PkgPath != "" || !v.Field(i).CanSet() {
+			continue
+		}
+		name, ok := jsonFieldName(sf)
+		if !ok {
+			continue
+		}
+		settable[name] = v.Field(i)
+	}
+}
+
+// setValue sets v from a value decoded from JSON. It is the inverse of jsonValue.
+func setValue(v reflect.Value, value interface{}) error {
+	if value == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("%v is not a bool", value)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := toInt64(value)
+		if err != nil {
+			return err
+		}
+		if v.OverflowInt(i) {
+			return fmt.Errorf("%d overflows %s", i, v.Type())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := toUint64(value)
+		if err != nil {
+			return err
+		}
+		if v.OverflowUint(u) {
+			return fmt.Errorf("%d overflows %s", u, v.Type())
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat64(value)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%v is not a string", value)
+		}
+		v.SetString(s)
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Struct:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%v is not an object", value)
+		}
+		return setStructFields(v, fields, false)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return setBytes(v, value)
+		}
+		values, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%v is not an array", value)
+		}
+		if v.Kind() == reflect.Array {
+			if len(values) != v.Len() {
+				return fmt.Errorf("got %d values for array of length %d", len(values), v.Len())
+			}
+		} else {
+			v.Set(reflect.MakeSlice(v.Type(), len(values), len(values)))
+		}
+		for i, val := range values {
+			if err := setValue(v.Index(i), val); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+	default:
+		return fmt.Errorf("cannot set value of kind %s", v.Kind())
+	}
+	return nil
+}
+
+// setBytes sets a byte slice or array from a hex string.
+func setBytes(v reflect.Value, value interface{}) error {
+	hexData, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("%v is not a hex string", value)
+	}
+	data, err := hex.DecodeString(hexData)
+	if err != nil {
+		return err
+	}
+	if v.Kind() == reflect.Array {
+		if len(data) != v.Len() {
+			return fmt.Errorf("got %d bytes for array of length %d", len(data), v.Len())
+		}
+	} else {
+		v.Set(reflect.MakeSlice(v.Type(), len(data), len(data)))
+	}
+	for i, b := range data {
+		v.Index(i).SetUint(uint64(b))
+	}
+	return nil
+}
+
+func toInt64(value interface{}) (int64, error) {
+	switch n := value.(type) {
+	case json.Number:
+		return strconv.ParseInt(string(n), 10, 64)
+	case int64:
+		return n, nil
+	case int:
+		return int64(n), nil
+	case uint64:
+		if n > 1<<63-1 {
+			return 0, fmt.Errorf("%d overflows int64", n)
+		}
+		return int64(n), nil
+	case float64:
+		if n != float64(int64(n)) {
+			return 0, fmt.Errorf("%v is not an integer", n)
+		}
+		return int64(n), nil
+	default:
+		return 0, fmt.Errorf("%v is not a number", value)
+	}
+}
+
+func toUint64(value interface{}) (uint64, error) {
+	switch n := value.(type) {
+	case json.Number:
+		return strconv.ParseUint(string(n), 10, 64)
+	case uint64:
+		return n, nil
+	case int64, int:
+		i, _ := toInt64(n)
+		if i < 0 {
+			return 0, fmt.Errorf("%d is negative", i)
+		}
+		return uint64(i), nil
+	case float64:
+		if n < 0 || n != float64(uint64(n)) {
+			return 0, fmt.Errorf("%v is not an unsigned integer", n)
+		}
+		return uint64(n), nil
+	default:
+		return 0, fmt.Errorf("%v is not a number", value)
+	}
+}
+
+func toFloat64(value interface{}) (float64, error) {
+	switch n := value.(type) {
+	case json.Number:
+		return n.Float64()
+	case float64:
+		return n, nil
+	case int64:
+		return float64(n), nil
+	case uint64:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	default:
+		return 0, fmt.Errorf("%v is not a number", value)
+	}
+}
+
+// popUint64 removes key from fields and returns its unsigned value, if present.
+func popUint64(fields map[string]interface{}, key string) (uint64, bool, error) {
+	value, ok := fields[key]
+	if !ok {
+		return 0, false, nil
+	}
+	delete(fields, key)
+	u, err := toUint64(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", key, err)
+	}
+	return u, true, nil
+}
+
+// popBytes removes key from fields and returns its hex-decoded value, if present.
+func popBytes(fields map[string]interface{}, key string) ([]byte, bool, error) {
+	value, ok := fields[key]
+	if !ok {
+		return nil, false, nil
+	}
+	delete(fields, key)
+	hexData, ok := value.(string)
+	if !ok {
+		return nil, false, fmt.Errorf("%s: not a hex string", key)
+	}
+	data, err := hex.DecodeString(hexData)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", key, err)
+	}
+	return data, true, nil
+}
+
+// popBrands removes MajorBrand, MinorVersion, and CompatibleBrands from fields and returns raw ftyp/styp data.
+func popBrands(fields map[string]interface{}) ([]byte, error) {
+	majorBrand, _ := fields["MajorBrand"].(string)
+	if len(majorBrand) != 4 {
+		return nil, fmt.Errorf("MajorBrand %q is not 4 characters", majorBrand)
+	}
+	delete(fields, "MajorBrand")
+	minorVersion, _, err := popUint64(fields, "MinorVersion")
+	if err != nil {
+		return nil, err
+	}
+	var brands []string
+	switch values := fields["CompatibleBrands"].(type) {
+	case nil:
+	case []string:
+		brands = values
+	case []interface{}:
+		for _, v := range values {
+			brand, ok := v.(string)
+			if !ok || len(brand) != 4 {
+				return nil, fmt.Errorf("CompatibleBrands: %v is not 4 characters", v)
+			}
+			brands = append(brands, brand)
+		}
+	default:
+		return nil, fmt.Errorf("CompatibleBrands: not an array")
+	}
+	delete(fields, "CompatibleBrands")
+	return NewFtyp(majorBrand, uint32(minorVersion), brands).data, nil
+}
@@ -0,0 +1,249 @@
+package mp4_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestBuildFileRoundTrip(t *testing.T) {
+	testFiles := []string{"init.mp4", "1.m4s", "prog_8s.mp4", "cbcs.mp4", "hvc1_init.mp4",
+		"moof_enc.m4s", "opus.mp4", "multi_sidx_segment.m4s"}
+	for _, name := range testFiles {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := mp4.DecodeFile(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes := f.BoxTree()
+		for i, node := range nodes {
+			if mdat, ok := f.Children[i].(*mp4.MdatBox); ok {
+				node.Fields["Data"] = hex.EncodeToString(mdat.Data)
+			}
+		}
+		desc, err := json.Marshal(nodes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		built, err := mp4.BuildFileFromJSON(bytes.NewReader(desc), "")
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		buf := bytes.Buffer{}
+		err = built.Encode(&buf)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("%s: built file differs from original", name)
+		}
+	}
+}
+
+func TestBuildFileWithDataFile(t *testing.T) {
+	dir := t.TempDir()
+	payload := []byte("some media data")
+	err := os.WriteFile(filepath.Join(dir, "payload.bin"), payload, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := `[
+	  {"type": "ftyp", "fields": {"MajorBrand": "isom", "MinorVersion": 512, "CompatibleBrands": ["isom", "iso2"]}},
+	  {"type": "©too", "children": [{"type": "data", "fields": {"Data": "6d70346666"}}]},
+	  {"type": "mdat", "fields": {"DataFile": "payload.bin"}},
+	  {"type": "abcd", "fields": {"Payload": "0102"}}
+	]`
+	f, err := mp4.BuildFileFromJSON(strings.NewReader(desc), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	err = f.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := mp4.DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Children) != 4 {
+		t.Fatalf("got %d top-level boxes instead of 4", len(decoded.Children))
+	}
+	ftyp := decoded.Children[0].(*mp4.FtypBox)
+	if ftyp.MajorBrand() != "isom" || ftyp.MinorVersion() != 512 || len(ftyp.CompatibleBrands()) != 2 {
+		t.Errorf("unexpected ftyp %s %d %v", ftyp.MajorBrand(), ftyp.MinorVersion(), ftyp.CompatibleBrands())
+	}
+	if decoded.Children[1].Type() != "\xa9too" {
+		t.Errorf("got box type %q instead of \xa9too", decoded.Children[1].Type())
+	}
+	mdat := decoded.Children[2].(*mp4.MdatBox)
+	if !bytes.Equal(mdat.Data, payload) {
+		t.Errorf("got mdat data %q instead of %q", mdat.Data, payload)
+	}
+	if decoded.Children[3].Type() != "abcd" || decoded.Children[3].Size() != 10 {
+		t.Errorf("got box %s of size %d instead of abcd of size 10", decoded.Children[3].Type(), decoded.Children[3].Size())
+	}
+	b, err := mp4.BuildBox(&mp4.BoxNode{Type: "mdat", Fields: map[string]interface{}{"DataLength": uint64(4)}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := b.(*mp4.MdatBox).Data; !bytes.Equal(data, make([]byte, 4)) {
+		t.Errorf("got mdat data %v instead of 4 zero bytes", data)
+	}
+}
+
+// TestBuildFileIncompleteMoov checks that a moov box without complete track is accepted.
+func TestBuildFileIncompleteMoov(t *testing.T) {
+	descs := []string{
+		`[{"type": "moov", "children": [{"type": "mvhd"}]}]`,
+		`[{"type": "moov", "children": [{"type": "trak", "children": [{"type": "tkhd"}]}]}]`,
+		`[{"type": "moov", "children": [{"type": "mvhd"}, {"type": "mvex", "children": [{"type": "trex"}]}]}]`,
+	}
+	for _, desc := range descs {
+		f, err := mp4.BuildFileFromJSON(strings.NewReader(desc), "")
+		if err != nil {
+			t.Fatalf("%s: %v", desc, err)
+		}
+		buf := bytes.Buffer{}
+		if err = f.Encode(&buf); err != nil {
+			t.Fatalf("%s: %v", desc, err)
+		}
+		decoded, err := mp4.DecodeFile(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", desc, err)
+		}
+		if decoded.Moov == nil {
+			t.Errorf("%s: no moov box after decode", desc)
+		}
+	}
+}
+
+func TestBuildBoxErrors(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		wanted string
+	}{
+		{`{"type": "tkhd", "fields": {"Unknown": 1}}`, `unknown field "Unknown"`},
+		{`{"type": "tkhd", "fields": {"Version": 256}}`, `field "Version": 256 overflows uint8`},
+		{`{"type": "tkhd", "fields": {"TrackID": -1}}`, `field "TrackID"`},
+		{`{"type": "hdlr", "fields": {"Name": 3}}`, `field "Name": 3 is not a string`},
+		{`{"type": "mdat", "fields": {"Data": "00", "DataLength": 2}}`, "DataLength 2 does not match"},
+		{`{"type": "mdat", "fields": {"DataFile": "missing.bin"}}`, "DataFile"},
+		{`{"type": "tkhd", "children": [{"type": "free"}]}`, "box cannot have children"},
+		{`{"type": "trak", "fields": {"Tkhd": {}}}`, `field "Tkhd" is a box`},
+		{`{"type": "moov", "children": [{"type": "abcde"}]}`, "moov/abcde[1]: box type"},
+	}
+	for _, tc := range testCases {
+		var node mp4.BoxNode
+		dec := json.NewDecoder(strings.NewReader(tc.desc))
+		dec.UseNumber()
+		err := dec.Decode(&node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = mp4.BuildBox(&node, t.TempDir())
+		if err == nil || !strings.Contains(err.Error(), tc.wanted) {
+			t.Errorf("%s: got error %v, wanted %q", tc.desc, err, tc.wanted)
+		}
+	}
+}
+
+// TestBuildFileCalculatedOffsets removes offsets and counts from descriptions and checks that they are calculated.
+func TestBuildFileCalculatedOffsets(t *testing.T) {
+	counts := map[string][]string{"stsd": {"SampleCount"}, "stsz": {"SampleNumber"}}
+	testCases := []struct {
+		name    string
+		removed map[string][]string
+	}{
+		{"1.m4s", map[string][]string{"trun": {"DataOffset"}}},
+		{"init.mp4", counts},
+		// The truns of the two tracks are interleaved in mdat, so only the saio offsets are calculated
+		{"cbcs.mp4", map[string][]string{"saio": {"Offset"}, "stsd": {"SampleCount"}}},
+	}
+	for _, tc := range testCases {
+		name := tc.name
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := mp4.DecodeFile(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		built := buildFromStrippedTree(t, f, tc.removed)
+		buf := bytes.Buffer{}
+		if err := built.Encode(&buf); err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("%s: built file differs from original", name)
+		}
+	}
+
+	f, err := mp4.ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	built := buildFromStrippedTree(t, f, map[string][]string{"stco": {"ChunkOffset"}, "stsz": {"SampleNumber"}})
+	mdat := built.Mdat
+	offset := mdat.PayloadAbsoluteOffset()
+	for _, trak := range built.Moov.Traks {
+		stbl := trak.Mdia.Minf.Stbl
+		if len(stbl.Stco.ChunkOffset) != len(f.Moov.Traks[trak.Tkhd.TrackID-1].Mdia.Minf.Stbl.Stco.ChunkOffset) {
+			t.Fatalf("track %d: %d chunk offsets", trak.Tkhd.TrackID, len(stbl.Stco.ChunkOffset))
+		}
+		if uint64(stbl.Stco.ChunkOffset[0]) != offset {
+			t.Errorf("track %d: first chunk at %d instead of %d", trak.Tkhd.TrackID, stbl.Stco.ChunkOffset[0], offset)
+		}
+		size, err := stbl.Stsz.GetTotalSampleSize(1, stbl.Stsz.GetNrSamples())
+		if err != nil {
+			t.Fatal(err)
+		}
+		offset += size
+	}
+	if offset != mdat.PayloadAbsoluteOffset()+uint64(len(mdat.Data)) {
+		t.Errorf("chunks end at %d, not at end of mdat", offset)
+	}
+}
+
+// buildFromStrippedTree builds a file from the box tree of f with the given fields removed.
+func buildFromStrippedTree(t *testing.T, f *mp4.File, removed map[string][]string) *mp4.File {
+	t.Helper()
+	nodes := f.BoxTree()
+	for i, node := range nodes {
+		if mdat, ok := f.Children[i].(*mp4.MdatBox); ok {
+			node.Fields["Data"] = hex.EncodeToString(mdat.Data)
+		}
+	}
+	var strip func(nodes []*mp4.BoxNode)
+	strip = func(nodes []*mp4.BoxNode) {
+		for _, node := range nodes {
+			for _, field := range removed[node.Type] {
+				delete(node.Fields, field)
+			}
+			strip(node.Children)
+		}
+	}
+	strip(nodes)
+	desc, err := json.Marshal(nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	built, err := mp4.BuildFileFromJSON(bytes.NewReader(desc), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return built
+}
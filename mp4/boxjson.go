@@ -0,0 +1,189 @@
+package mp4
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strings"
+)
+
+// BoxNode - structured representation of a box and its children, e.g. for JSON output.
+//
+// Fields contains the exported fields of the box struct with the Go field names as keys,
+// or the names in json struct tags where present. A tag "-" leaves the field out,
+// as done for decoding state like StartPos.
+// Byte slices are hex-encoded strings, and structs (like sample entries in tables or descriptors)
+// are objects with field names as keys. Child boxes are in Children instead of Fields.
+// Some boxes replace or add fields, e.g. mdat has DataLength instead of the data and
+// ftyp has MajorBrand, MinorVersion, and CompatibleBrands.
+type BoxNode struct {
+	Type     string                 `json:"type"`
+	Size     uint64                 `json:"size"`
+	Offset   uint64                 `json:"offset"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+	Children []*BoxNode             `json:"children,omitempty"`
+}
+
+// jsonFielder is implemented by boxes which have some state in unexported fields,
+// or that have data that should not be output as is.
+type jsonFielder interface {
+	// addJSONFields adds, replaces, or removes entries of fields generated from the exported struct fields.
+	addJSONFields(fields map[string]interface{})
+}
+
+var boxInterfaceType = reflect.TypeOf((*Box)(nil)).Elem()
+
+// NewBoxNode returns a tree of BoxNodes for box b starting at offset.
+func NewBoxNode(b Box, offset uint64) *BoxNode {
+	node := &BoxNode{Type: boxTypeString(b.Type()), Size: b.Size(), Offset: offset}
+	cb, hasChildren := b.(childrenGetter)
+	fields := structFields(reflect.ValueOf(b), hasChildren)
+	if jf, ok := b.(jsonFielder); ok {
+		jf.addJSONFields(fields)
+	}
+	if len(fields) > 0 {
+		node.Fields = fields
+	}
+	if hasChildren {
+		children := cb.GetChildren()
+		node.Children = newBoxNodes(children, offset+childrenOffset(b, children))
+	}
+	return node
+}
+
+// BoxTree returns BoxNode trees for all top-level boxes of the file.
+func (f *File) BoxTree() []*BoxNode {
+	return newBoxNodes(f.Children, 0)
+}
+
+// BoxTree returns BoxNode trees for all boxes of the init segment.
+func (s *InitSegment) BoxTree() []*BoxNode {
+	return newBoxNodes(s.Children, 0)
+}
+
+// BoxTree returns BoxNode trees for all boxes of the fragment starting at f.StartPos.
+func (f *Fragment) BoxTree() []*BoxNode {
+	return newBoxNodes(f.Children, f.StartPos)
+}
+
+// boxTypeString returns the box type as valid UTF-8 by interpreting the bytes as Latin-1,
+// so that for example "\xa9too" becomes "©too".
+func boxTypeString(boxType string) string {
+	runes := make([]rune, len(boxType))
+	for i := 0; i < len(boxType); i++ {
+		runes[i] = rune(boxType[i])
+	}
+	return string(runes)
+}
+
+func newBoxNodes(boxes []Box, offset uint64) []*BoxNode {
+	nodes := make([]*BoxNode, 0, len(boxes))
+	for _, b := range boxes {
+		nodes = append(nodes, NewBoxNode(b, offset))
+		offset += b.Size()
+	}
+	return nodes
+}
+
+// structFields returns the exported fields of the struct v points to.
+// If skipBoxes is set, fields with boxes are left out since they are output as children.
+func structFields(v reflect.Value, skipBoxes bool) map[string]interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	fields := make(map[string]interface{})
+	if v.Kind() != reflect.Struct {
+		return fields
+	}
+	addStructFields(fields, v, skipBoxes)
+	return fields
+}
+
+func addStructFields(fields map[string]interface{}, v reflect.Value, skipBoxes bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			addStructFields(fields, v.Field(i), skipBoxes)
+			continue
+		}
+		if sf.PkgPath != "" { // unexported
+			continue
+		}
+		if skipBoxes && isBoxType(sf.Type) {
+			continue
+		}
+		name, ok := jsonFieldName(sf)
+		if !ok {
+			continue
+		}
+		fields[name] = jsonValue(v.Field(i))
+	}
+}
+
+// jsonFieldName returns the name from the json struct tag of sf, or the Go field name if there is no tag.
+// false is returned if the field should be left out.
+func jsonFieldName(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if i := strings.Index(tag, ","); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "" {
+		return sf.Name, true
+	}
+	return tag, true
+}
+
+// isBoxType returns true for boxes and slices of boxes.
+func isBoxType(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Implements(boxInterfaceType)
+}
+
+// jsonValue converts v to a value with stable JSON output.
+func jsonValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return jsonValue(v.Elem())
+	case reflect.Struct:
+		return structFields(v, false)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			for i := range b {
+				b[i] = byte(v.Index(i).Uint())
+			}
+			return hex.EncodeToString(b)
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = jsonValue(v.Index(i))
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,167 @@
+package mp4_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestBoxTreeJSON(t *testing.T) {
+	testCases := []struct {
+		file   string
+		golden string
+	}{
+		{"testdata/init.mp4", "testdata/golden_init_mp4.json"},
+		{"testdata/1.m4s", "testdata/golden_1_m4s.json"},
+	}
+	for _, tc := range testCases {
+		data, err := os.ReadFile(tc.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := mp4.DecodeFile(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := json.MarshalIndent(f.BoxTree(), "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *update {
+			err = writeGolden(t, tc.golden, out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		golden, err := os.ReadFile(tc.golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(golden, out) {
+			t.Errorf("JSON output for %s differs from %s", tc.file, tc.golden)
+		}
+	}
+}
+
+func TestBoxNodeOffsets(t *testing.T) {
+	data, err := os.ReadFile("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := mp4.DecodeFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var check func(nodes []*mp4.BoxNode)
+	check = func(nodes []*mp4.BoxNode) {
+		for _, n := range nodes {
+			typ := string(data[n.Offset+4 : n.Offset+8])
+			if typ != n.Type {
+				t.Errorf("found %q instead of %q at offset %d", typ, n.Type, n.Offset)
+			}
+			check(n.Children)
+		}
+	}
+	nodes := f.BoxTree()
+	check(nodes)
+	mdat := nodes[2]
+	if mdat.Type != "mdat" || mdat.Fields["DataLength"] != mdat.Size-8 || mdat.Fields["Data"] != nil {
+		t.Errorf("unexpected mdat node %+v", mdat)
+	}
+}
+
+// TestBoxJSONSchema checks that the BoxNode field names of the boxes in the test files do not change.
+// The field names of each box type, with paths like "Entries[].SampleCount" for nested values,
+// are compared to a golden file.
+func TestBoxJSONSchema(t *testing.T) {
+	goldenPath := "testdata/golden_boxnode_schema.json"
+	files, err := filepath.Glob("testdata/*.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m4sFiles, err := filepath.Glob("testdata/*.m4s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make(map[string]map[string]bool)
+	for _, fileName := range append(files, m4sFiles...) {
+		f, err := mp4.ReadMP4File(fileName)
+		if err != nil {
+			continue // Not all test files can be decoded as a whole
+		}
+		data, err := json.Marshal(f.BoxTree())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var nodes []interface{}
+		if err = json.Unmarshal(data, &nodes); err != nil {
+			t.Fatal(err)
+		}
+		addSchemaNodes(paths, nodes)
+	}
+	schema := make(map[string][]string, len(paths))
+	for boxType, boxPaths := range paths {
+		schema[boxType] = make([]string, 0, len(boxPaths))
+		for p := range boxPaths {
+			schema[boxType] = append(schema[boxType], p)
+		}
+		sort.Strings(schema[boxType])
+	}
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		err = writeGolden(t, goldenPath, out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	golden, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(golden, out) {
+		t.Errorf("BoxNode field names differ from %s", goldenPath)
+	}
+}
+
+// addSchemaNodes adds the field paths of JSON-decoded BoxNodes and their children to paths per box type.
+func addSchemaNodes(paths map[string]map[string]bool, nodes []interface{}) {
+	for _, n := range nodes {
+		node := n.(map[string]interface{})
+		boxType := node["type"].(string)
+		if paths[boxType] == nil {
+			paths[boxType] = make(map[string]bool)
+		}
+		addSchemaPaths(paths[boxType], "", node["fields"])
+		if children, ok := node["children"].([]interface{}); ok {
+			addSchemaNodes(paths, children)
+		}
+	}
+}
+
+func addSchemaPaths(paths map[string]bool, prefix string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, x := range v {
+			p := key
+			if prefix != "" {
+				p = prefix + "." + key
+			}
+			paths[p] = true
+			addSchemaPaths(paths, p, x)
+		}
+	case []interface{}:
+		for _, x := range v {
+			addSchemaPaths(paths, prefix+"[]", x)
+		}
+	}
+}
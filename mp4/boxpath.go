@@ -0,0 +1,141 @@
+package mp4
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BoxMatch - a box found by a box path query together with its position.
+type BoxMatch struct {
+	Box    Box
+	Path   string // Path with all indices resolved, like "moov[1]/trak[2]/mdia[1]"
+	Offset uint64 // Byte offset of the start of the box
+}
+
+// pathElem - one level of a box path. index 0 means all matching boxes.
+type pathElem struct {
+	boxType string
+	index   int
+}
+
+// childrenGetter is implemented by all boxes with child boxes
+type childrenGetter interface {
+	GetChildren() []Box
+}
+
+// FindBoxes returns all boxes matching path among the descendants of b.
+//
+// A path is a slash-separated list of box types like "moov/trak/mdia/minf/stbl/stsd/*/avcC",
+// where the first element is matched against the children of b.
+// A type can be followed by a one-based index to select one box among
+// the siblings of that type, like "trak[2]". The wildcard "*" matches any box type,
+// and "*[2]" is the second child of any type.
+// The offsets are relative to the start of b.
+func FindBoxes(b Box, path string) ([]BoxMatch, error) {
+	cb, ok := b.(childrenGetter)
+	if !ok {
+		return nil, fmt.Errorf("box %s has no children", b.Type())
+	}
+	children := cb.GetChildren()
+	return findBoxesInChildren(children, childrenOffset(b, children), path)
+}
+
+// Find returns all top-level boxes and descendants of the file matching path.
+// The path syntax is described at FindBoxes. Offsets are relative to the start of the file.
+func (f *File) Find(path string) ([]BoxMatch, error) {
+	return findBoxesInChildren(f.Children, 0, path)
+}
+
+// Find returns all boxes of the init segment matching path.
+// The path syntax is described at FindBoxes. Offsets are relative to the start of the init segment.
+func (s *InitSegment) Find(path string) ([]BoxMatch, error) {
+	return findBoxesInChildren(s.Children, 0, path)
+}
+
+// Find returns all boxes of the fragment matching path, like "moof/traf/trun".
+// The path syntax is described at FindBoxes. Offsets are file positions starting at f.StartPos.
+func (f *Fragment) Find(path string) ([]BoxMatch, error) {
+	return findBoxesInChildren(f.Children, f.StartPos, path)
+}
+
+func findBoxesInChildren(children []Box, offset uint64, path string) ([]BoxMatch, error) {
+	elems, err := parseBoxPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return findBoxes(children, offset, "", elems, nil), nil
+}
+
+// parseBoxPath splits a path like "moov/trak[2]/mdia" into its elements.
+func parseBoxPath(path string) ([]pathElem, error) {
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return nil, fmt.Errorf("empty box path")
+	}
+	parts := strings.Split(path, "/")
+	elems := make([]pathElem, 0, len(parts))
+	for _, part := range parts {
+		elem := pathElem{boxType: part}
+		if start := strings.Index(part, "["); start >= 0 {
+			if !strings.HasSuffix(part, "]") {
+				return nil, fmt.Errorf("box path %q: missing ] in %q", path, part)
+			}
+			index, err := strconv.Atoi(part[start+1 : len(part)-1])
+			if err != nil || index < 1 {
+				return nil, fmt.Errorf("box path %q: bad index in %q", path, part)
+			}
+			elem = pathElem{boxType: part[:start], index: index}
+		}
+		if elem.boxType == "" {
+			return nil, fmt.Errorf("box path %q: empty box type", path)
+		}
+		elems = append(elems, elem)
+	}
+	return elems, nil
+}
+
+// findBoxes appends the boxes matching elems to matches.
+// offset is the position of the first child.
+func findBoxes(children []Box, offset uint64, prefix string, elems []pathElem, matches []BoxMatch) []BoxMatch {
+	elem := elems[0]
+	typeCounts := make(map[string]int)
+	nrMatching := 0
+	pos := offset
+	for _, c := range children {
+		boxType := c.Type()
+		typeCounts[boxType]++
+		boxPos := pos
+		pos += c.Size()
+		if elem.boxType != "*" && boxType != elem.boxType {
+			continue
+		}
+		nrMatching++
+		if elem.index > 0 && nrMatching != elem.index {
+			continue
+		}
+		boxPath := fmt.Sprintf("%s%s[%d]", prefix, boxType, typeCounts[boxType])
+		if len(elems) == 1 {
+			matches = append(matches, BoxMatch{Box: c, Path: boxPath, Offset: boxPos})
+			continue
+		}
+		if cb, ok := c.(childrenGetter); ok {
+			grandChildren := cb.GetChildren()
+			matches = findBoxes(grandChildren, boxPos+childrenOffset(c, grandChildren), boxPath+"/", elems[1:], matches)
+		}
+	}
+	return matches
+}
+
+// childrenOffset returns the offset of the first child relative to the start of b.
+// The children are always at the end of the box, apart from possible trailing bytes.
+func childrenOffset(b Box, children []Box) uint64 {
+	end := b.Size()
+	if vse, ok := b.(*VisualSampleEntryBox); ok {
+		end -= uint64(len(vse.TrailingBytes))
+	}
+	for _, c := range children {
+		end -= c.Size()
+	}
+	return end
+}
@@ -0,0 +1,109 @@
+package mp4_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/bits"
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+// checkMatchOffsets checks that each match can be decoded at its offset in data.
+func checkMatchOffsets(t *testing.T, matches []mp4.BoxMatch, data []byte) {
+	t.Helper()
+	for _, m := range matches {
+		box, err := mp4.DecodeBoxSR(m.Offset, bits.NewFixedSliceReader(data[m.Offset:]))
+		if err != nil {
+			t.Fatalf("%s: %v", m.Path, err)
+		}
+		if box.Type() != m.Box.Type() || box.Size() != m.Box.Size() {
+			t.Errorf("%s: got %s box of size %d at offset %d", m.Path, box.Type(), box.Size(), m.Offset)
+		}
+	}
+}
+
+func TestFindBoxes(t *testing.T) {
+	data, err := os.ReadFile("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := mp4.DecodeFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		path        string
+		wantedPaths []string
+	}{
+		{"moov/trak/mdia/minf/stbl/stsd/*/avcC", []string{"moov[1]/trak[2]/mdia[1]/minf[1]/stbl[1]/stsd[1]/avc1[1]/avcC[1]"}},
+		{"/moov/trak[1]/mdia/minf/stbl/stsd/*/esds", []string{"moov[1]/trak[1]/mdia[1]/minf[1]/stbl[1]/stsd[1]/mp4a[1]/esds[1]"}},
+		{"moov/trak/tkhd", []string{"moov[1]/trak[1]/tkhd[1]", "moov[1]/trak[2]/tkhd[1]"}},
+		{"moov/*[2]", []string{"moov[1]/iods[1]"}},
+		{"moov/trak[3]", nil},
+		{"mdat", []string{"mdat[1]"}},
+	}
+	for _, tc := range testCases {
+		matches, err := f.Find(tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matches) != len(tc.wantedPaths) {
+			t.Fatalf("%s: got %d matches instead of %d", tc.path, len(matches), len(tc.wantedPaths))
+		}
+		for i, m := range matches {
+			if m.Path != tc.wantedPaths[i] {
+				t.Errorf("%s: got path %s instead of %s", tc.path, m.Path, tc.wantedPaths[i])
+			}
+		}
+		checkMatchOffsets(t, matches, data)
+	}
+	matches, err := f.Find("moov/trak/mdia/minf/stbl/stsd/avc1/avcC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := matches[0].Box.(*mp4.AvcCBox); !ok {
+		t.Errorf("got %T instead of *mp4.AvcCBox", matches[0].Box)
+	}
+
+	// Relative to a box
+	moovMatches, err := f.Find("moov")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stblMatches, err := mp4.FindBoxes(f.Moov, "trak[2]/mdia/minf/stbl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stblMatches) != 1 || stblMatches[0].Box != f.Moov.Traks[1].Mdia.Minf.Stbl {
+		t.Fatalf("got %v", stblMatches)
+	}
+	stblMatches[0].Offset += moovMatches[0].Offset
+	checkMatchOffsets(t, stblMatches, data)
+
+	for _, badPath := range []string{"", "moov//trak", "trak[0]", "trak[1", "[2]"} {
+		if _, err := f.Find(badPath); err == nil {
+			t.Errorf("expected error for path %q", badPath)
+		}
+	}
+}
+
+func TestFindBoxesInFragment(t *testing.T) {
+	data, err := os.ReadFile("testdata/1.m4s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := mp4.DecodeFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	frag := f.Segments[0].Fragments[0]
+	matches, err := frag.Find("moof/traf/trun")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Box != frag.Moof.Traf.Trun {
+		t.Fatalf("got %v", matches)
+	}
+	checkMatchOffsets(t, matches, data)
+}
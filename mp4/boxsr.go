@@ -6,173 +6,37 @@ import (
 	"github.com/Eyevinn/mp4ff/bits"
 )
 
+// decodersSR is set from boxTypes in box.go
 var decodersSR map[string]BoxDecoderSR
 
-func init() {
-	decodersSR = map[string]BoxDecoderSR{
-		"\xa9ART": DecodeGenericContainerBoxSR,
-		"\xa9cpy": DecodeGenericContainerBoxSR,
-		"\xa9nam": DecodeGenericContainerBoxSR,
-		"\xa9too": DecodeGenericContainerBoxSR,
-		"ac-3":    DecodeAudioSampleEntrySR,
-		"ac-4":    DecodeAudioSampleEntrySR,
-		"alou":    DecodeLoudnessBaseBoxSR,
-		"av01":    DecodeVisualSampleEntrySR,
-		"av1C":    DecodeAv1CSR,
-		"avc1":    DecodeVisualSampleEntrySR,
-		"avc3":    DecodeVisualSampleEntrySR,
-		"av3c":    DecodeAv3cSR,
-		"avcC":    DecodeAvcCSR,
-		"avs3":    DecodeVisualSampleEntrySR,
-		"btrt":    DecodeBtrtSR,
-		"cdat":    DecodeCdatSR,
-		"cdsc":    DecodeTrefTypeSR,
-		"clap":    DecodeClapSR,
-		"co64":    DecodeCo64SR,
-		"CoLL":    DecodeCoLLSR,
-		"colr":    DecodeColrSR,
-		"cslg":    DecodeCslgSR,
-		"ctim":    DecodeCtimSR,
-		"ctts":    DecodeCttsSR,
-		"dac3":    DecodeDac3SR,
-		"dac4":    DecodeDac4SR,
-		"mhaC":    DecodeMhaCSR,
-		"data":    DecodeDataSR,
-		"dec3":    DecodeDec3SR,
-		"dOps":    DecodeDopsSR,
-		"desc":    DecodeGenericContainerBoxSR,
-		"dinf":    DecodeDinfSR,
-		"dpnd":    DecodeTrefTypeSR,
-		"dref":    DecodeDrefSR,
-		"ec-3":    DecodeAudioSampleEntrySR,
-		"edts":    DecodeEdtsSR,
-		"elng":    DecodeElngSR,
-		"elst":    DecodeElstSR,
-		"emeb":    DecodeEmebSR,
-		"emib":    DecodeEmibSR,
-		"emsg":    DecodeEmsgSR,
-		"enca":    DecodeAudioSampleEntrySR,
-		"encv":    DecodeVisualSampleEntrySR,
-		"esds":    DecodeEsdsSR,
-		"evte":    DecodeEvteSR,
-		"font":    DecodeTrefTypeSR,
-		"free":    DecodeFreeSR,
-		"frma":    DecodeFrmaSR,
-		"ftyp":    DecodeFtypSR,
-		"hdlr":    DecodeHdlrSR,
-		"hev1":    DecodeVisualSampleEntrySR,
-		"hind":    DecodeTrefTypeSR,
-		"hint":    DecodeTrefTypeSR,
-		"hvc1":    DecodeVisualSampleEntrySR,
-		"hvcC":    DecodeHvcCSR,
-		"iden":    DecodeIdenSR,
-		"ilst":    DecodeIlstSR,
-		"iods":    DecodeUnknownSR,
-		"ipir":    DecodeTrefTypeSR,
-		"kind":    DecodeKindSR,
-		"leva":    DecodeLevaSR,
-		"ludt":    DecodeLudtSR,
-		"mdat":    DecodeMdatSR,
-		"mehd":    DecodeMehdSR,
-		"mdhd":    DecodeMdhdSR,
-		"mdia":    DecodeMdiaSR,
-		"meta":    DecodeMetaSR,
-		"mfhd":    DecodeMfhdSR,
-		"mfra":    DecodeMfraSR,
-		"mfro":    DecodeMfroSR,
-		"mha1":    DecodeAudioSampleEntrySR,
-		"mha2":    DecodeAudioSampleEntrySR,
-		"mhm1":    DecodeAudioSampleEntrySR,
-		"mhm2":    DecodeAudioSampleEntrySR,
-		"mime":    DecodeMimeSR,
-		"minf":    DecodeMinfSR,
-		"moof":    DecodeMoofSR,
-		"moov":    DecodeMoovSR,
-		"mp4a":    DecodeAudioSampleEntrySR,
-		"mpod":    DecodeTrefTypeSR,
-		"mvex":    DecodeMvexSR,
-		"mvhd":    DecodeMvhdSR,
-		"nmhd":    DecodeNmhdSR,
-		"Opus":    DecodeAudioSampleEntrySR,
-		"pasp":    DecodePaspSR,
-		"payl":    DecodePaylSR,
-		"prft":    DecodePrftSR,
-		"pssh":    DecodePsshSR,
-		"saio":    DecodeSaioSR,
-		"saiz":    DecodeSaizSR,
-		"sbgp":    DecodeSbgpSR,
-		"schi":    DecodeSchiSR,
-		"schm":    DecodeSchmSR,
-		"sdtp":    DecodeSdtpSR,
-		"senc":    DecodeSencSR,
-		"sgpd":    DecodeSgpdSR,
-		"sidx":    DecodeSidxSR,
-		"silb":    DecodeSilbSR,
-		"sinf":    DecodeSinfSR,
-		"skip":    DecodeFreeSR,
-		"SmDm":    DecodeSmDmSR,
-		"smhd":    DecodeSmhdSR,
-		"ssix":    DecodeSsixSR,
-		"stbl":    DecodeStblSR,
-		"stco":    DecodeStcoSR,
-		"sthd":    DecodeSthdSR,
-		"stpp":    DecodeStppSR,
-		"stsc":    DecodeStscSR,
-		"stsd":    DecodeStsdSR,
-		"stss":    DecodeStssSR,
-		"stsz":    DecodeStszSR,
-		"sttg":    DecodeSttgSR,
-		"stts":    DecodeSttsSR,
-		"styp":    DecodeStypSR,
-		"subs":    DecodeSubsSR,
-		"subt":    DecodeTrefTypeSR,
-		"sync":    DecodeTrefTypeSR,
-		"tenc":    DecodeTencSR,
-		"tfdt":    DecodeTfdtSR,
-		"tfhd":    DecodeTfhdSR,
-		"tfra":    DecodeTfraSR,
-		"tkhd":    DecodeTkhdSR,
-		"tlou":    DecodeLoudnessBaseBoxSR,
-		"traf":    DecodeTrafSR,
-		"trak":    DecodeTrakSR,
-		"tref":    DecodeTrefSR,
-		"trep":    DecodeTrepSR,
-		"trex":    DecodeTrexSR,
-		"trun":    DecodeTrunSR,
-		"udta":    DecodeUdtaSR,
-		"url ":    DecodeURLBoxSR,
-		"uuid":    DecodeUUIDBoxSR,
-		"vdep":    DecodeTrefTypeSR,
-		"vlab":    DecodeVlabSR,
-		"vmhd":    DecodeVmhdSR,
-		"vp08":    DecodeVisualSampleEntrySR,
-		"vp09":    DecodeVisualSampleEntrySR,
-		"vpcC":    DecodeVppCSR,
-		"vplx":    DecodeTrefTypeSR,
-		"vsid":    DecodeVsidSR,
-		"vtta":    DecodeVttaSR,
-		"vvc1":    DecodeVisualSampleEntrySR,
-		"vvcC":    DecodeVvcCSR,
-		"vvi1":    DecodeVisualSampleEntrySR,
-		"vttc":    DecodeVttcSR,
-		"vttC":    DecodeVttCSR,
-		"vtte":    DecodeVtteSR,
-		"wvtt":    DecodeWvttSR,
-	}
-}
-
 // BoxDecoderSR is function signature of the Box DecodeSR method
 type BoxDecoderSR func(hdr BoxHeader, startPos uint64, sw bits.SliceReader) (Box, error)
 
 // DecodeBoxSR - decode a box from SliceReader
 func DecodeBoxSR(startPos uint64, sr bits.SliceReader) (Box, error) {
-	var err error
-	var b Box
+	h, err := DecodeHeaderSR(sr)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBoxBodySR(h, startPos, sr)
+}
 
+// decodeChildBoxSR decodes a box inside another box. Size 0 is only allowed for top-level boxes.
+func decodeChildBoxSR(startPos uint64, sr bits.SliceReader) (Box, error) {
 	h, err := DecodeHeaderSR(sr)
 	if err != nil {
 		return nil, err
 	}
+	if h.SizeToEnd {
+		return nil, fmt.Errorf("decode %s pos %d: size 0 only allowed for top-level boxes", h.Name, startPos)
+	}
+	return decodeBoxBodySR(h, startPos, sr)
+}
+
+// decodeBoxBodySR decodes a box after its header
+func decodeBoxBodySR(h BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	var err error
+	var b Box
 
 	maxSize := uint64(sr.NrRemainingBytes()) + uint64(h.Hdrlen)
 	// In the following, we do not block mdat to allow for the case
@@ -197,7 +61,9 @@ func DecodeBoxSR(startPos uint64, sr bits.SliceReader) (Box, error) {
 	return b, nil
 }
 
-// DecodeHeaderSR - decode a box header (size + box type + possible largeSize) from sr
+// DecodeHeaderSR - decode a box header (size + box type + possible largeSize) from sr.
+// A size field of 0 is resolved to the header length plus the remaining bytes in sr.
+// It is only valid for the last top-level box, and is rejected when decoding child boxes.
 func DecodeHeaderSR(sr bits.SliceReader) (BoxHeader, error) {
 	if sr.NrRemainingBytes() < boxHeaderSize {
 		return BoxHeader{}, fmt.Errorf("not enough bytes to read box header, need %d, have %d", boxHeaderSize, sr.NrRemainingBytes())
@@ -210,12 +76,13 @@ func DecodeHeaderSR(sr bits.SliceReader) (BoxHeader, error) {
 		size = sr.ReadUint64()
 		headerLen += largeSizeLen
 	case 0: // size 0 means to end of file
-		return BoxHeader{}, fmt.Errorf("Size 0, meaning to end of file, not supported")
+		size = uint64(headerLen + sr.NrRemainingBytes())
+		return BoxHeader{Name: boxType, Size: size, Hdrlen: headerLen, SizeToEnd: true}, sr.AccError()
 	}
 	if uint64(headerLen) > size {
 		return BoxHeader{}, fmt.Errorf("box header size %d exceeds box size %d", headerLen, size)
 	}
-	return BoxHeader{boxType, size, headerLen}, sr.AccError()
+	return BoxHeader{Name: boxType, Size: size, Hdrlen: headerLen}, sr.AccError()
 }
 
 // DecodeFile - parse and decode a file from reader r with optional file options.
@@ -264,26 +131,9 @@ LoopBoxes:
 				}
 			}
 		case "moof":
-			moof := box.(*MoofBox)
-			for _, traf := range moof.Trafs {
-				if ok, parsed := traf.ContainsSencBox(); ok && !parsed {
-					isEncrypted := true
-					defaultIVSize := byte(0) // Should get this from tenc in sinf
-					if f.Moov != nil {
-						trackID := traf.Tfhd.TrackID
-						isEncrypted = f.Moov.IsEncrypted(trackID)
-						sinf := f.Moov.GetSinf(trackID)
-						if sinf != nil && sinf.Schi != nil && sinf.Schi.Tenc != nil {
-							defaultIVSize = sinf.Schi.Tenc.DefaultPerSampleIVSize
-						}
-					}
-					if isEncrypted {
-						err = traf.ParseReadSenc(defaultIVSize, moof.StartPos)
-						if err != nil {
-							return nil, err
-						}
-					}
-				}
+			err = parseReadSencBoxes(f.Moov, box.(*MoofBox))
+			if err != nil {
+				return nil, err
 			}
 		}
 		f.AddChild(box, boxStartPos)
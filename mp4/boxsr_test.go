@@ -37,9 +37,9 @@ func TestDecodeHeaderSRInsufficientBytes(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name:    "zero size not supported",
+			name:    "zero size extends to end of file",
 			data:    []byte{0x00, 0x00, 0x00, 0x00, 't', 'e', 's', 't'},
-			wantErr: true,
+			wantErr: false,
 		},
 	}
 
@@ -83,3 +83,19 @@ func TestDecodeEncodeSRW(t *testing.T) {
 		}
 	}
 }
+
+// TestDecodeChildSizeZero checks that size 0 is rejected for a box inside another box
+func TestDecodeChildSizeZero(t *testing.T) {
+	// udta (size 16) with a child box of size 0
+	data := []byte{0, 0, 0, 16, 'u', 'd', 't', 'a', 0, 0, 0, 0, 't', 'e', 's', 't'}
+	if _, err := mp4.DecodeBoxSR(0, bits.NewFixedSliceReader(data)); err == nil {
+		t.Error("DecodeBoxSR: expected error for child box with size 0")
+	}
+	if _, err := mp4.DecodeBox(0, bytes.NewReader(data)); err == nil {
+		t.Error("DecodeBox: expected error for child box with size 0")
+	}
+	// Size 0 is fine at top level
+	if _, err := mp4.DecodeBoxSR(0, bits.NewFixedSliceReader(data[8:])); err != nil {
+		t.Errorf("DecodeBoxSR: top-level box with size 0: %v", err)
+	}
+}
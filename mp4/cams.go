@@ -0,0 +1,82 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// CamsBox - Stereo Camera System Box (cams)
+//
+// Contained in : Stereo View Box (eyes)
+//
+// Defined in Apple HEVC Stereo Video - ISOBMFF Extensions
+type CamsBox struct {
+	Blin     *BlinBox
+	Children []Box
+}
+
+// AddChild - add a child box
+func (b *CamsBox) AddChild(child Box) {
+	switch box := child.(type) {
+	case *BlinBox:
+		b.Blin = box
+	}
+	b.Children = append(b.Children, child)
+}
+
+// DecodeCams - box-specific decode
+func DecodeCams(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.Size, r)
+	if err != nil {
+		return nil, err
+	}
+	b := &CamsBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// DecodeCamsSR - box-specific decode
+func DecodeCamsSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.Size, sr)
+	if err != nil {
+		return nil, err
+	}
+	b := &CamsBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// Type - return box type
+func (b *CamsBox) Type() string {
+	return "cams"
+}
+
+// Size - return calculated size
+func (b *CamsBox) Size() uint64 {
+	return containerSize(b.Children)
+}
+
+// GetChildren - list of child boxes
+func (b *CamsBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write cams container to w
+func (b *CamsBox) Encode(w io.Writer) error {
+	return EncodeContainer(b, w)
+}
+
+// EncodeSW - write cams container via sw
+func (b *CamsBox) EncodeSW(sw bits.SliceWriter) error {
+	return EncodeContainerSW(b, sw)
+}
+
+// Info - write box-specific information
+func (b *CamsBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
+}
@@ -0,0 +1,85 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// CbmpBox - Cubemap Projection Box (cbmp)
+//
+// Layout 0 is the only layout defined, and Padding is the number of pixels around each face.
+//
+// Contained in : Projection Box (proj)
+//
+// Defined in Google Spherical Video V2 RFC
+type CbmpBox struct {
+	Version byte
+	Flags   uint32
+	Layout  uint32
+	Padding uint32
+}
+
+// DecodeCbmp - box-specific decode
+func DecodeCbmp(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeCbmpSR(hdr, startPos, sr)
+}
+
+// DecodeCbmpSR - box-specific decode
+func DecodeCbmpSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &CbmpBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.Layout = sr.ReadUint32()
+	b.Padding = sr.ReadUint32()
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *CbmpBox) Type() string {
+	return "cbmp"
+}
+
+// Size - return calculated size
+func (b *CbmpBox) Size() uint64 {
+	return uint64(boxHeaderSize + 12)
+}
+
+// Encode - write box to w
+func (b *CbmpBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *CbmpBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint32(b.Layout)
+	sw.WriteUint32(b.Padding)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *CbmpBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - layout: %d", b.Layout)
+	bd.write(" - padding: %d", b.Padding)
+	return bd.err
+}
@@ -0,0 +1,237 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+)
+
+// ChunkWriterOptions - options for ChunkWriter
+type ChunkWriterOptions struct {
+	// SamplesPerChunk ends a chunk when the reference track has this many samples in it (0 means no limit)
+	SamplesPerChunk int
+	// ChunkDurationMS ends a chunk when the reference track samples in it reach this duration (0 means no limit)
+	ChunkDurationMS uint32
+	// SegmentDurationMS starts a new segment at the first sync sample of the reference track
+	// at least this duration after the segment start. If 0, segments are only ended by EndSegment
+	SegmentDurationMS uint32
+	// StartSequenceNumber is the mfhd sequence number of the first chunk. Default is 1
+	StartSequenceNumber uint32
+	// Styp is written before the first chunk of each segment. Default is CreateStyp()
+	Styp *StypBox
+	// NoStyp turns off the styp box
+	NoStyp bool
+	// PrftClock adds a prft box before each chunk if set
+	PrftClock PrftClock
+	// PrftFlags are the flags of the prft boxes
+	PrftFlags uint32
+}
+
+// CMAFChunk - a CMAF chunk (moof + mdat) produced by ChunkWriter
+type CMAFChunk struct {
+	SegmentNr uint32 // zero-based number of the segment the chunk belongs to
+	ChunkNr   uint32 // zero-based number of the chunk in its segment
+	// Segment has one fragment with the chunk. Styp is only set for the first chunk of a segment
+	Segment *MediaSegment
+}
+
+// Fragment returns the fragment of the chunk.
+func (c *CMAFChunk) Fragment() *Fragment {
+	return c.Segment.Fragments[0]
+}
+
+// Encode writes the chunk, starting with styp for the first chunk of a segment.
+func (c *CMAFChunk) Encode(w io.Writer) error {
+	return c.Segment.Encode(w)
+}
+
+// ChunkHandler is called by ChunkWriter for every chunk as soon as it is complete.
+type ChunkHandler func(c *CMAFChunk) error
+
+// EncodeChunksTo returns a ChunkHandler that writes every chunk to w.
+func EncodeChunksTo(w io.Writer) ChunkHandler {
+	return func(c *CMAFChunk) error {
+		return c.Encode(w)
+	}
+}
+
+type chunkTrack struct {
+	trackID   uint32
+	timescale uint32
+	samples   []FullSample
+}
+
+// ChunkWriter creates low-latency media segments consisting of many small chunks.
+// Samples are added per track, and every chunk is handed over to the handler
+// as soon as it is complete, so only the samples of the current chunk are kept in memory.
+// The first track is the reference track which decides where chunks and segments end.
+// Samples of other tracks added before the end of a chunk are put in the same chunk.
+type ChunkWriter struct {
+	opts         ChunkWriterOptions
+	handler      ChunkHandler
+	tracks       []*chunkTrack
+	seqNr        uint32
+	segNr        uint32
+	chunkNr      uint32
+	inSegment    bool
+	segStart     uint64
+	chunkTicks   uint64
+	segmentTicks uint64
+}
+
+// NewChunkWriter returns a ChunkWriter for the tracks in init. The handler is called for every chunk.
+func NewChunkWriter(init *InitSegment, handler ChunkHandler, opts ChunkWriterOptions) (*ChunkWriter, error) {
+	if init == nil || init.Moov == nil || len(init.Moov.Traks) == 0 {
+		return nil, fmt.Errorf("no tracks in init segment")
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("no chunk handler")
+	}
+	if opts.SamplesPerChunk <= 0 && opts.ChunkDurationMS == 0 {
+		return nil, fmt.Errorf("neither SamplesPerChunk nor ChunkDurationMS set")
+	}
+	cw := &ChunkWriter{
+		opts:    opts,
+		handler: handler,
+		seqNr:   opts.StartSequenceNumber,
+	}
+	if cw.seqNr == 0 {
+		cw.seqNr = 1
+	}
+	for _, trak := range init.Moov.Traks {
+		cw.tracks = append(cw.tracks, &chunkTrack{
+			trackID:   trak.Tkhd.TrackID,
+			timescale: trak.Mdia.Mdhd.Timescale,
+		})
+	}
+	refTimescale := uint64(cw.tracks[0].timescale)
+	cw.chunkTicks = uint64(opts.ChunkDurationMS) * refTimescale / 1000
+	cw.segmentTicks = uint64(opts.SegmentDurationMS) * refTimescale / 1000
+	return cw, nil
+}
+
+// SequenceNumber returns the mfhd sequence number of the next chunk.
+func (cw *ChunkWriter) SequenceNumber() uint32 {
+	return cw.seqNr
+}
+
+// AddSample adds a sample to the track with trackID.
+// A chunk is emitted when a sample of the reference track completes it.
+func (cw *ChunkWriter) AddSample(trackID uint32, s FullSample) error {
+	ct := cw.track(trackID)
+	if ct == nil {
+		return fmt.Errorf("unknown trackID %d", trackID)
+	}
+	isRef := ct == cw.tracks[0]
+	if isRef && cw.inSegment && cw.segmentTicks > 0 && s.IsSync() && s.DecodeTime >= cw.segStart+cw.segmentTicks {
+		err := cw.EndSegment()
+		if err != nil {
+			return err
+		}
+	}
+	if isRef && !cw.inSegment {
+		cw.inSegment = true
+		cw.segStart = s.DecodeTime
+	}
+	ct.samples = append(ct.samples, s)
+	if isRef && cw.chunkFull() {
+		return cw.Flush()
+	}
+	return nil
+}
+
+// chunkFull returns true if the reference track samples fill a chunk.
+func (cw *ChunkWriter) chunkFull() bool {
+	samples := cw.tracks[0].samples
+	if cw.opts.SamplesPerChunk > 0 && len(samples) >= cw.opts.SamplesPerChunk {
+		return true
+	}
+	if cw.chunkTicks > 0 {
+		var dur uint64
+		for _, s := range samples {
+			dur += uint64(s.Dur)
+		}
+		if dur >= cw.chunkTicks {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush emits a chunk with all samples added since the previous chunk. Nothing is done if there are none.
+func (cw *ChunkWriter) Flush() error {
+	var trackIDs []uint32
+	for _, t := range cw.tracks {
+		if len(t.samples) > 0 {
+			trackIDs = append(trackIDs, t.trackID)
+		}
+	}
+	if len(trackIDs) == 0 {
+		return nil
+	}
+	frag, err := CreateMultiTrackFragment(cw.seqNr, trackIDs)
+	if err != nil {
+		return err
+	}
+	for _, t := range cw.tracks {
+		for _, s := range t.samples {
+			err = frag.AddFullSampleToTrack(s, t.trackID)
+			if err != nil {
+				return err
+			}
+		}
+		t.samples = nil
+	}
+	if cw.opts.PrftClock != nil {
+		// The reference track if it is in the chunk, since trackIDs are in track order
+		ref := cw.track(trackIDs[0])
+		err = frag.AddPrft(ref.trackID, ref.timescale, cw.opts.PrftFlags, cw.opts.PrftClock)
+		if err != nil {
+			return err
+		}
+	}
+	seg := NewMediaSegmentWithoutStyp()
+	if cw.chunkNr == 0 && !cw.opts.NoStyp {
+		seg.Styp = cw.opts.Styp
+		if seg.Styp == nil {
+			seg.Styp = CreateStyp()
+		}
+	}
+	seg.AddFragment(frag)
+	chunk := &CMAFChunk{
+		SegmentNr: cw.segNr,
+		ChunkNr:   cw.chunkNr,
+		Segment:   seg,
+	}
+	cw.seqNr++
+	cw.chunkNr++
+	return cw.handler(chunk)
+}
+
+// EndSegment emits the remaining samples as the last chunk of the segment.
+// The next chunk starts a new segment.
+func (cw *ChunkWriter) EndSegment() error {
+	err := cw.Flush()
+	if err != nil {
+		return err
+	}
+	if cw.chunkNr > 0 {
+		cw.segNr++
+		cw.chunkNr = 0
+	}
+	cw.inSegment = false
+	return nil
+}
+
+// Close ends the current segment.
+func (cw *ChunkWriter) Close() error {
+	return cw.EndSegment()
+}
+
+func (cw *ChunkWriter) track(trackID uint32) *chunkTrack {
+	for _, t := range cw.tracks {
+		if t.trackID == trackID {
+			return t
+		}
+	}
+	return nil
+}
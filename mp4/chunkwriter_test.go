@@ -0,0 +1,116 @@
+package mp4_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestChunkWriter(t *testing.T) {
+	fd, err := os.Open("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	inFile, err := mp4.DecodeFile(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outFiles, err := inFile.Fragmentify(fd, mp4.FragmenterOptions{SegmentDurationMS: 2000, Muxed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inFrag := outFiles[0]
+	init := inFrag.Init
+
+	buf := bytes.Buffer{}
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	encodeChunk := mp4.EncodeChunksTo(&buf)
+	var chunks []*mp4.CMAFChunk
+	handler := func(c *mp4.CMAFChunk) error {
+		chunks = append(chunks, c)
+		return encodeChunk(c)
+	}
+	opts := mp4.ChunkWriterOptions{ChunkDurationMS: 500, SegmentDurationMS: 2000, StartSequenceNumber: 10}
+	cw, err := mp4.NewChunkWriter(init, handler, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nrSamples := make(map[uint32]int)
+	for _, seg := range inFrag.Segments {
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				trackID := traf.Tfhd.TrackID
+				trex, _ := init.Moov.Mvex.GetTrex(trackID)
+				samples, err := frag.GetFullSamples(trex)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, s := range samples {
+					if err := cw.AddSample(trackID, s); err != nil {
+						t.Fatal(err)
+					}
+					nrSamples[trackID]++
+				}
+			}
+		}
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(chunks) < 16 {
+		t.Errorf("got %d chunks, expected at least 16", len(chunks))
+	}
+	for i, c := range chunks {
+		if c.Fragment().Moof.Mfhd.SequenceNumber != uint32(10+i) {
+			t.Errorf("chunk %d: sequence number %d", i, c.Fragment().Moof.Mfhd.SequenceNumber)
+		}
+		if (c.ChunkNr == 0) != (c.Segment.Styp != nil) {
+			t.Errorf("chunk %d: styp present %t for chunk number %d", i, c.Segment.Styp != nil, c.ChunkNr)
+		}
+	}
+	if lastSegNr := chunks[len(chunks)-1].SegmentNr; lastSegNr != 3 {
+		t.Errorf("got %d segments instead of 4", lastSegNr+1)
+	}
+
+	decFile, err := mp4.DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decFile.Segments) != 4 {
+		t.Errorf("got %d segments instead of 4", len(decFile.Segments))
+	}
+	gotSamples := make(map[uint32]int)
+	nextDecodeTime := make(map[uint32]uint64)
+	for i, seg := range decFile.Segments {
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				trackID := traf.Tfhd.TrackID
+				if traf.Tfdt.BaseMediaDecodeTime() != nextDecodeTime[trackID] {
+					t.Errorf("track %d: tfdt %d instead of %d", trackID, traf.Tfdt.BaseMediaDecodeTime(), nextDecodeTime[trackID])
+				}
+				trex, _ := decFile.Init.Moov.Mvex.GetTrex(trackID)
+				samples, err := frag.GetFullSamples(trex)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if trackID == init.Moov.Traks[0].Tkhd.TrackID && frag == seg.Fragments[0] && !samples[0].IsSync() {
+					t.Errorf("segment %d does not start with sync sample", i)
+				}
+				gotSamples[trackID] += len(samples)
+				last := samples[len(samples)-1]
+				nextDecodeTime[trackID] = last.DecodeTime + uint64(last.Dur)
+			}
+		}
+	}
+	for trackID, n := range nrSamples {
+		if gotSamples[trackID] != n {
+			t.Errorf("track %d: got %d samples instead of %d", trackID, gotSamples[trackID], n)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package mp4
+
+import (
+	"fmt"
+)
+
+// Severity - severity level of a validation finding
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// ValidationFinding - a deviation or remark found by ValidateCMAF.
+// Path is a box path like "segment[2]/moof[1]/traf[1]/tfdt", where indices start at 1.
+type ValidationFinding struct {
+	Severity Severity
+	Path     string
+	Message  string
+}
+
+func (f ValidationFinding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.Severity, f.Path, f.Message)
+}
+
+// ValidateCMAF checks an init segment and media segments against rules of CMAF (ISO/IEC 23000-19).
+//
+// The checks include the cmfc/cmf2 brands, a single track with empty sample tables in the header,
+// a trex box per track with a valid sample description index, one traf per fragment,
+// default-base-is-moof, tfdt continuity, a sync sample first in each segment, sample data inside the mdat box,
+// and consistency of senc, saiz, and saio for encrypted tracks.
+// The findings are returned in the order of the boxes.
+func ValidateCMAF(init *InitSegment, segments []*MediaSegment) []ValidationFinding {
+	v := cmafValidator{tracks: make(map[uint32]*cmafTrack)}
+	if init == nil {
+		v.add(SeverityError, "", "no init segment")
+		return v.findings
+	}
+	v.validateInit(init)
+	for i, seg := range segments {
+		v.validateSegment(i+1, seg)
+	}
+	return v.findings
+}
+
+// cmafValidator - state during validation
+type cmafValidator struct {
+	findings []ValidationFinding
+	cmf2     bool
+	tracks   map[uint32]*cmafTrack
+}
+
+// cmafTrack - track information from init segment and timing from previous fragments
+type cmafTrack struct {
+	trex           *TrexBox
+	nrEntries      int
+	encrypted      bool
+	defaultIVSize  byte
+	nextDecodeTime uint64
+	hasTime        bool
+}
+
+func (v *cmafValidator) add(severity Severity, path, format string, args ...interface{}) {
+	v.findings = append(v.findings, ValidationFinding{severity, path, fmt.Sprintf(format, args...)})
+}
+
+func (v *cmafValidator) validateInit(init *InitSegment) {
+	if init.Ftyp == nil {
+		v.add(SeverityError, "ftyp", "missing ftyp box")
+	} else {
+		brands := append([]string{init.Ftyp.MajorBrand()}, init.Ftyp.CompatibleBrands()...)
+		v.cmf2 = hasBrand(brands, "cmf2")
+		if !v.cmf2 && !hasBrand(brands, "cmfc") {
+			v.add(SeverityError, "ftyp", "none of the CMAF brands cmfc or cmf2 present")
+		}
+	}
+	moov := init.Moov
+	if moov == nil {
+		v.add(SeverityError, "moov", "missing moov box")
+		return
+	}
+	switch len(moov.Traks) {
+	case 0:
+		v.add(SeverityError, "moov", "no trak box")
+	case 1:
+	default:
+		v.add(SeverityError, "moov", "CMAF header shall contain one track, got %d", len(moov.Traks))
+	}
+	if moov.Mvex == nil {
+		v.add(SeverityError, "moov/mvex", "missing mvex box")
+	}
+	for i, trak := range moov.Traks {
+		path := fmt.Sprintf("moov/trak[%d]", i+1)
+		trackID := trak.Tkhd.TrackID
+		track := &cmafTrack{}
+		v.tracks[trackID] = track
+		stbl := trak.Mdia.Minf.Stbl
+		if stbl.Stsz != nil && stbl.Stsz.GetNrSamples() > 0 {
+			v.add(SeverityError, path+"/mdia/minf/stbl/stsz", "%d samples, but sample tables shall be empty",
+				stbl.Stsz.GetNrSamples())
+		}
+		if stbl.Stts != nil && len(stbl.Stts.SampleCount) > 0 {
+			v.add(SeverityError, path+"/mdia/minf/stbl/stts", "stts has entries, but sample tables shall be empty")
+		}
+		if stbl.Stsd == nil || len(stbl.Stsd.Children) == 0 {
+			v.add(SeverityError, path+"/mdia/minf/stbl/stsd", "no sample entry")
+		} else {
+			track.nrEntries = len(stbl.Stsd.Children)
+		}
+		if moov.Mvex != nil {
+			trex, ok := moov.Mvex.GetTrex(trackID)
+			if !ok {
+				v.add(SeverityError, "moov/mvex", "no trex box for track %d", trackID)
+			} else {
+				track.trex = trex
+				if trex.DefaultSampleDescriptionIndex < 1 || int(trex.DefaultSampleDescriptionIndex) > track.nrEntries {
+					v.add(SeverityError, "moov/mvex/trex", "track %d: default sample description index %d not in range 1-%d",
+						trackID, trex.DefaultSampleDescriptionIndex, track.nrEntries)
+				}
+			}
+		}
+		if track.nrEntries > 0 && moov.IsEncrypted(trackID) {
+			track.encrypted = true
+			v.validateSinf(path+"/mdia/minf/stbl/stsd", moov.GetSinf(trackID), track)
+		}
+	}
+}
+
+func (v *cmafValidator) validateSinf(path string, sinf *SinfBox, track *cmafTrack) {
+	if sinf == nil {
+		v.add(SeverityError, path, "encrypted sample entry without sinf box")
+		return
+	}
+	if sinf.Schm == nil {
+		v.add(SeverityError, path+"/*/sinf", "missing schm box")
+	} else if sinf.Schm.SchemeType != "cenc" && sinf.Schm.SchemeType != "cbcs" {
+		v.add(SeverityError, path+"/*/sinf/schm", "scheme type %q is not cenc or cbcs", sinf.Schm.SchemeType)
+	}
+	if sinf.Schi == nil || sinf.Schi.Tenc == nil {
+		v.add(SeverityError, path+"/*/sinf", "missing schi/tenc box")
+		return
+	}
+	track.defaultIVSize = sinf.Schi.Tenc.DefaultPerSampleIVSize
+}
+
+func (v *cmafValidator) validateSegment(segNr int, seg *MediaSegment) {
+	segPath := fmt.Sprintf("segment[%d]", segNr)
+	if seg.Styp == nil {
+		v.add(SeverityInfo, segPath, "no styp box")
+	} else {
+		brands := append([]string{seg.Styp.MajorBrand()}, seg.Styp.CompatibleBrands()...)
+		if !hasBrand(brands, "cmfs") {
+			v.add(SeverityWarning, segPath+"/styp", "CMAF segment brand cmfs not present")
+		}
+	}
+	if len(seg.Fragments) == 0 {
+		v.add(SeverityError, segPath, "no fragments")
+	}
+	for i, frag := range seg.Fragments {
+		path := fmt.Sprintf("%s/moof[%d]", segPath, i+1)
+		if frag.Moof == nil {
+			v.add(SeverityError, path, "missing moof box")
+			continue
+		}
+		if len(frag.Moof.Trafs) != 1 {
+			v.add(SeverityError, path, "CMAF fragment shall contain one traf, got %d", len(frag.Moof.Trafs))
+		}
+		if frag.Mdat == nil {
+			v.add(SeverityError, path, "no mdat box after moof")
+		}
+		for j, traf := range frag.Moof.Trafs {
+			v.validateTraf(fmt.Sprintf("%s/traf[%d]", path, j+1), frag, traf, i == 0)
+		}
+	}
+}
+
+// validateTraf checks a traf box. A sync sample is only required first in the segment,
+// since later moof boxes may be chunks of a fragment.
+func (v *cmafValidator) validateTraf(path string, frag *Fragment, traf *TrafBox, firstInSegment bool) {
+	tfhd := traf.Tfhd
+	if tfhd == nil {
+		v.add(SeverityError, path, "missing tfhd box")
+		return
+	}
+	track, ok := v.tracks[tfhd.TrackID]
+	if !ok {
+		v.add(SeverityError, path+"/tfhd", "track %d not in init segment", tfhd.TrackID)
+		return
+	}
+	if tfhd.HasBaseDataOffset() {
+		v.add(SeverityError, path+"/tfhd", "base-data-offset shall not be present")
+	}
+	if !tfhd.DefaultBaseIfMoof() {
+		v.add(SeverityError, path+"/tfhd", "default-base-is-moof flag shall be set")
+	}
+	if tfhd.HasSampleDescriptionIndex() &&
+		(tfhd.SampleDescriptionIndex < 1 || int(tfhd.SampleDescriptionIndex) > track.nrEntries) {
+		v.add(SeverityError, path+"/tfhd", "sample description index %d not in range 1-%d",
+			tfhd.SampleDescriptionIndex, track.nrEntries)
+	}
+	defaultDur, defaultSize, defaultFlags := uint32(0), uint32(0), uint32(0)
+	if track.trex != nil {
+		defaultDur, defaultSize, defaultFlags = track.trex.DefaultSampleDuration, track.trex.DefaultSampleSize,
+			track.trex.DefaultSampleFlags
+	}
+	if tfhd.HasDefaultSampleDuration() {
+		defaultDur = tfhd.DefaultSampleDuration
+	}
+	if tfhd.HasDefaultSampleSize() {
+		defaultSize = tfhd.DefaultSampleSize
+	}
+	if tfhd.HasDefaultSampleFlags() {
+		defaultFlags = tfhd.DefaultSampleFlags
+	}
+
+	if len(traf.Truns) == 0 {
+		v.add(SeverityError, path, "missing trun box")
+	}
+	if v.cmf2 && len(traf.Truns) > 1 {
+		v.add(SeverityError, path, "cmf2 brand requires one trun, got %d", len(traf.Truns))
+	}
+	var totalDur uint64
+	var nrSamples uint32
+	for i, trun := range traf.Truns {
+		trunPath := fmt.Sprintf("%s/trun[%d]", path, i+1)
+		if !trun.HasSampleDuration() && defaultDur == 0 {
+			v.add(SeverityError, trunPath, "sample duration 0 since not given by trun, tfhd, or trex")
+		}
+		if !trun.HasSampleSize() && defaultSize == 0 {
+			v.add(SeverityWarning, trunPath, "sample size 0 since not given by trun, tfhd, or trex")
+		}
+		var dataSize uint64
+		for _, s := range trun.Samples {
+			dur, size := defaultDur, defaultSize
+			if trun.HasSampleDuration() {
+				dur = s.Dur
+			}
+			if trun.HasSampleSize() {
+				size = s.Size
+			}
+			totalDur += uint64(dur)
+			dataSize += uint64(size)
+		}
+		if firstInSegment && i == 0 && trun.SampleCount() > 0 {
+			flags := defaultFlags
+			if firstFlags, present := trun.FirstSampleFlags(); present {
+				flags = firstFlags
+			} else if trun.HasSampleFlags() {
+				flags = trun.Samples[0].Flags
+			}
+			if DecodeSampleFlags(flags).SampleIsNonSync {
+				v.add(SeverityError, trunPath, "first sample of segment is not a sync sample")
+			}
+		}
+		nrSamples += trun.SampleCount()
+		if !trun.HasDataOffset() {
+			v.add(SeverityError, trunPath, "data-offset shall be present")
+			continue
+		}
+		if frag.Mdat != nil {
+			dataStart := int64(frag.Moof.StartPos) + int64(trun.DataOffset)
+			dataEnd := dataStart + int64(dataSize)
+			mdatStart := int64(frag.Mdat.PayloadAbsoluteOffset())
+			mdatEnd := int64(frag.Mdat.StartPos + frag.Mdat.Size())
+			if dataStart < mdatStart || dataEnd > mdatEnd {
+				v.add(SeverityError, trunPath, "sample data %d-%d not inside mdat payload %d-%d",
+					dataStart, dataEnd, mdatStart, mdatEnd)
+			}
+		}
+	}
+
+	if traf.Tfdt == nil {
+		v.add(SeverityError, path, "missing tfdt box")
+		track.hasTime = false
+	} else {
+		baseTime := traf.Tfdt.BaseMediaDecodeTime()
+		if track.hasTime && baseTime != track.nextDecodeTime {
+			v.add(SeverityError, path+"/tfdt", "baseMediaDecodeTime %d, but %d expected from previous fragment",
+				baseTime, track.nextDecodeTime)
+		}
+		track.nextDecodeTime = baseTime + totalDur
+		track.hasTime = true
+	}
+	v.validateEncryption(path, frag.Moof, traf, track, nrSamples)
+}
+
+// validateEncryption checks that senc, saiz, and saio are present and consistent for encrypted tracks.
+func (v *cmafValidator) validateEncryption(path string, moof *MoofBox, traf *TrafBox, track *cmafTrack, nrSamples uint32) {
+	if !track.encrypted {
+		if traf.Senc != nil || traf.UUIDSenc != nil {
+			v.add(SeverityWarning, path, "senc box in unencrypted track")
+		}
+		return
+	}
+	if traf.Senc == nil {
+		if traf.UUIDSenc != nil {
+			v.add(SeverityError, path, "PIFF uuid senc box instead of senc box")
+		} else {
+			v.add(SeverityError, path, "missing senc box")
+		}
+	}
+	if traf.Saiz == nil {
+		v.add(SeverityError, path, "missing saiz box")
+	}
+	if traf.Saio == nil {
+		v.add(SeverityError, path, "missing saio box")
+	}
+	if traf.Senc == nil || traf.Saiz == nil || traf.Saio == nil {
+		return
+	}
+	senc, saiz, saio := traf.Senc, traf.Saiz, traf.Saio
+	if senc.ReadButNotParsed() {
+		ivSize, err := traf.sencPerSampleIVSize(track.defaultIVSize)
+		if err != nil {
+			v.add(SeverityError, path+"/senc", "cannot parse: %v", err)
+			return
+		}
+		// Parse a copy to leave the validated file unchanged
+		parsed := *senc
+		if err := parsed.ParseReadBox(ivSize, saiz); err != nil {
+			v.add(SeverityError, path+"/senc", "cannot parse: %v", err)
+			return
+		}
+		senc = &parsed
+	}
+	if senc.SampleCount != nrSamples {
+		v.add(SeverityError, path+"/senc", "sample count %d differs from %d in trun", senc.SampleCount, nrSamples)
+	}
+	if saiz.SampleCount != nrSamples {
+		v.add(SeverityError, path+"/saiz", "sample count %d differs from %d in trun", saiz.SampleCount, nrSamples)
+	}
+	if len(saio.Offset) != 1 {
+		v.add(SeverityError, path+"/saio", "%d offsets instead of 1", len(saio.Offset))
+	} else {
+		wantedOffset := int64(senc.StartPos) + 16 - int64(moof.StartPos)
+		if saio.Offset[0] != wantedOffset {
+			v.add(SeverityError, path+"/saio", "offset %d does not point to senc data at offset %d",
+				saio.Offset[0], wantedOffset)
+		}
+	}
+	useSubSamples := senc.Flags&UseSubSampleEncryption != 0
+	if useSubSamples && len(senc.SubSamples) < int(senc.SampleCount) {
+		v.add(SeverityError, path+"/senc", "subsample info for %d samples instead of %d", len(senc.SubSamples), senc.SampleCount)
+		return
+	}
+	if saiz.DefaultSampleInfoSize == 0 && len(saiz.SampleInfo) < int(saiz.SampleCount) {
+		v.add(SeverityError, path+"/saiz", "%d sample info sizes instead of %d", len(saiz.SampleInfo), saiz.SampleCount)
+		return
+	}
+	ivSize := uint32(senc.GetPerSampleIVSize())
+	for i := uint32(0); i < senc.SampleCount && i < saiz.SampleCount; i++ {
+		sencSize := ivSize
+		if useSubSamples {
+			sencSize += 2 + 6*uint32(len(senc.SubSamples[i]))
+		}
+		saizSize := uint32(saiz.DefaultSampleInfoSize)
+		if saizSize == 0 {
+			saizSize = uint32(saiz.SampleInfo[i])
+		}
+		if saizSize != sencSize {
+			v.add(SeverityError, path+"/saiz", "sample %d: size %d differs from %d in senc", i+1, saizSize, sencSize)
+			break
+		}
+	}
+}
+
+func hasBrand(brands []string, brand string) bool {
+	for _, b := range brands {
+		if b == brand {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package mp4_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func readCMAFTrack(t *testing.T, initPath, segPath string) (*mp4.InitSegment, []*mp4.MediaSegment) {
+	t.Helper()
+	var files []*mp4.File
+	for _, path := range []string{initPath, segPath} {
+		fh, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := mp4.DecodeFile(fh)
+		fh.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	return files[0].Init, files[1].Segments
+}
+
+func TestValidateCMAF(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		modify       func(init *mp4.InitSegment, segs []*mp4.MediaSegment) []*mp4.MediaSegment
+		wantedErrors []string // path: message prefix
+	}{
+		{
+			desc:   "conforming",
+			modify: func(init *mp4.InitSegment, segs []*mp4.MediaSegment) []*mp4.MediaSegment { return segs },
+		},
+		{
+			desc: "no cmaf brand",
+			modify: func(init *mp4.InitSegment, segs []*mp4.MediaSegment) []*mp4.MediaSegment {
+				init.Ftyp = mp4.NewFtyp("isom", 0, []string{"iso6"})
+				return segs
+			},
+			wantedErrors: []string{"ftyp: none of the CMAF brands"},
+		},
+		{
+			desc: "tfdt discontinuity",
+			modify: func(init *mp4.InitSegment, segs []*mp4.MediaSegment) []*mp4.MediaSegment {
+				return append(segs, segs[0])
+			},
+			wantedErrors: []string{"segment[2]/moof[1]/traf[1]/tfdt: baseMediaDecodeTime"},
+		},
+		{
+			desc: "base data offset and non-sync first sample",
+			modify: func(init *mp4.InitSegment, segs []*mp4.MediaSegment) []*mp4.MediaSegment {
+				traf := segs[0].Fragments[0].Moof.Traf
+				traf.Tfhd.Flags = mp4.TfhdBaseDataOffsetPresentFlag
+				traf.Trun.SetFirstSampleFlags(mp4.NonSyncSampleFlags)
+				return segs
+			},
+			wantedErrors: []string{
+				"segment[1]/moof[1]/traf[1]/tfhd: base-data-offset shall not be present",
+				"segment[1]/moof[1]/traf[1]/tfhd: default-base-is-moof flag shall be set",
+				"segment[1]/moof[1]/traf[1]/trun[1]: first sample of segment is not a sync sample",
+			},
+		},
+		{
+			desc: "non-sync first sample in chunk",
+			modify: func(init *mp4.InitSegment, segs []*mp4.MediaSegment) []*mp4.MediaSegment {
+				_, chunkSegs := readCMAFTrack(t, "testdata/hvc1_init.mp4", "testdata/hvc1_seg_1.m4s")
+				traf := segs[0].Fragments[0].Moof.Traf
+				dur := traf.Trun.Duration(traf.Tfhd.DefaultSampleDuration)
+				chunk := chunkSegs[0].Fragments[0]
+				chunk.Moof.Traf.Tfdt.SetBaseMediaDecodeTime(traf.Tfdt.BaseMediaDecodeTime() + dur)
+				chunk.Moof.Traf.Trun.SetFirstSampleFlags(mp4.NonSyncSampleFlags)
+				segs[0].Fragments = append(segs[0].Fragments, chunk)
+				return segs
+			},
+		},
+		{
+			desc: "missing trex",
+			modify: func(init *mp4.InitSegment, segs []*mp4.MediaSegment) []*mp4.MediaSegment {
+				init.Moov.Mvex.Trex.TrackID = 17
+				init.Moov.Mvex.Trexs[0].TrackID = 17
+				return segs
+			},
+			wantedErrors: []string{"moov/mvex: no trex box for track 1"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			init, segs := readCMAFTrack(t, "testdata/hvc1_init.mp4", "testdata/hvc1_seg_1.m4s")
+			segs = tc.modify(init, segs)
+			findings := mp4.ValidateCMAF(init, segs)
+			var gotErrors []string
+			for _, f := range findings {
+				if f.Severity == mp4.SeverityError {
+					gotErrors = append(gotErrors, f.Path+": "+f.Message)
+				}
+			}
+			if len(gotErrors) != len(tc.wantedErrors) {
+				t.Fatalf("got errors %q, wanted %q", gotErrors, tc.wantedErrors)
+			}
+			for i, wanted := range tc.wantedErrors {
+				if !strings.HasPrefix(gotErrors[i], wanted) {
+					t.Errorf("got error %q, wanted %q", gotErrors[i], wanted)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateCMAFEncryption(t *testing.T) {
+	f, err := mp4.ReadMP4File("testdata/cbcs.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSaio := func(wantedBad bool) {
+		t.Helper()
+		gotBad := false
+		for _, finding := range mp4.ValidateCMAF(f.Init, f.Segments) {
+			if strings.Contains(finding.Path, "saio") || strings.Contains(finding.Path, "senc") ||
+				strings.Contains(finding.Path, "saiz") {
+				if !wantedBad {
+					t.Errorf("unexpected finding %s", finding)
+				}
+				gotBad = true
+			}
+		}
+		if wantedBad && !gotBad {
+			t.Error("no saio finding")
+		}
+	}
+	checkSaio(false)
+	f.Segments[0].Fragments[0].Moof.Traf.Saio.Offset[0] += 4
+	checkSaio(true)
+}
+
+func TestValidateCMAFNoInit(t *testing.T) {
+	findings := mp4.ValidateCMAF(nil, nil)
+	if len(findings) != 1 || findings[0].Severity != mp4.SeverityError {
+		t.Errorf("got %v", findings)
+	}
+	if got := findings[0].String(); got != "error: : no init segment" {
+		t.Errorf("got %q", got)
+	}
+}
+
+// TestValidateCMAFUnparsedSenc checks that senc is left unparsed and that inconsistent saiz is reported.
+func TestValidateCMAFUnparsedSenc(t *testing.T) {
+	f, err := mp4.ReadMP4File("testdata/cbcs.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	for _, seg := range f.Segments {
+		if err := seg.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Add boxes one by one, since DecodeFile parses senc
+	segFile := mp4.NewFile()
+	var pos uint64
+	for buf.Len() > 0 {
+		box, err := mp4.DecodeBox(pos, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segFile.AddChild(box, pos)
+		pos += box.Size()
+	}
+	traf := segFile.Segments[0].Fragments[0].Moof.Traf
+	if !traf.Senc.ReadButNotParsed() {
+		t.Fatal("senc already parsed")
+	}
+	for _, finding := range mp4.ValidateCMAF(f.Init, segFile.Segments) {
+		if strings.Contains(finding.Path, "senc") || strings.Contains(finding.Path, "saiz") {
+			t.Errorf("unexpected finding %s", finding)
+		}
+	}
+	if !traf.Senc.ReadButNotParsed() {
+		t.Error("senc parsed by validation")
+	}
+	traf.Saiz.DefaultSampleInfoSize = 0
+	traf.Saiz.SampleInfo = nil
+	gotSaizFinding := false
+	for _, finding := range mp4.ValidateCMAF(f.Init, segFile.Segments) {
+		if strings.HasSuffix(finding.Path, "saiz") {
+			gotSaizFinding = true
+		}
+	}
+	if !gotSaizFinding {
+		t.Error("no saiz finding")
+	}
+}
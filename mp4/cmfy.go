@@ -0,0 +1,82 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// CmfyBox - Stereo Comfort Box (cmfy)
+//
+// Contained in : Stereo View Box (eyes)
+//
+// Defined in Apple HEVC Stereo Video - ISOBMFF Extensions
+type CmfyBox struct {
+	Dadj     *DadjBox
+	Children []Box
+}
+
+// AddChild - add a child box
+func (b *CmfyBox) AddChild(child Box) {
+	switch box := child.(type) {
+	case *DadjBox:
+		b.Dadj = box
+	}
+	b.Children = append(b.Children, child)
+}
+
+// DecodeCmfy - box-specific decode
+func DecodeCmfy(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.Size, r)
+	if err != nil {
+		return nil, err
+	}
+	b := &CmfyBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// DecodeCmfySR - box-specific decode
+func DecodeCmfySR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.Size, sr)
+	if err != nil {
+		return nil, err
+	}
+	b := &CmfyBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// Type - return box type
+func (b *CmfyBox) Type() string {
+	return "cmfy"
+}
+
+// Size - return calculated size
+func (b *CmfyBox) Size() uint64 {
+	return containerSize(b.Children)
+}
+
+// GetChildren - list of child boxes
+func (b *CmfyBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write cmfy container to w
+func (b *CmfyBox) Encode(w io.Writer) error {
+	return EncodeContainer(b, w)
+}
+
+// EncodeSW - write cmfy container via sw
+func (b *CmfyBox) EncodeSW(sw bits.SliceWriter) error {
+	return EncodeContainerSW(b, sw)
+}
+
+// Info - write box-specific information
+func (b *CmfyBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
+}
@@ -99,7 +99,7 @@ func DecodeContainerChildren(hdr BoxHeader, startPos, endPos uint64, r io.Reader
 	children := make([]Box, 0, 8)
 	pos := startPos
 	for {
-		child, err := DecodeBox(pos, r)
+		child, err := decodeChildBox(pos, r)
 		if err == io.EOF {
 			return children, nil
 		}
@@ -136,7 +136,7 @@ func DecodeContainerChildrenSR(hdr BoxHeader, startPos, endPos uint64, sr bits.S
 		if pos == endPos {
 			break
 		}
-		child, err := DecodeBoxSR(pos, sr)
+		child, err := decodeChildBoxSR(pos, sr)
 		if err != nil {
 			return children, err
 		}
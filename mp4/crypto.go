@@ -320,12 +320,12 @@ func InitProtect(init *InitSegment, key, iv []byte, scheme string, kid UUID, pss
 		sinf.AddChild(&frma)
 		se.AddChild(&sinf)
 		switch veType {
-		case "avc1", "avc3":
+		case "avc1", "avc3", "dva1", "dvav":
 			ipd.ProtFunc, err = getAVCProtFunc(se.AvcC)
 			if err != nil {
 				return nil, fmt.Errorf("get avc protect func: %w", err)
 			}
-		case "hvc1", "hev1":
+		case "hvc1", "hev1", "dvh1", "dvhe":
 			ipd.ProtFunc, err = getHEVCProtFunc(se.HvcC)
 			if err != nil {
 				return nil, fmt.Errorf("get hevc protect func: %w", err)
@@ -753,12 +753,12 @@ func ExtractInitProtectData(inSeg *InitSegment) (*InitProtectData, error) {
 			sinf = box.Sinf
 			frma := sinf.Frma
 			switch frma.DataFormat {
-			case "avc1":
+			case "avc1", "dva1":
 				ipd.ProtFunc, err = getAVCProtFunc(box.AvcC)
 				if err != nil {
 					return nil, fmt.Errorf("get AVC protect func: %w", err)
 				}
-			case "hvc1":
+			case "hvc1", "dvh1":
 				ipd.ProtFunc, err = getHEVCProtFunc(box.HvcC)
 				if err != nil {
 					return nil, fmt.Errorf("get HEVC protect func: %w", err)
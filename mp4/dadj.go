@@ -0,0 +1,81 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// DadjBox - Stereo Comfort Disparity Adjustment Box (dadj)
+//
+// DisparityAdjustment is a horizontal shift in units of 1/10000 of the image width.
+//
+// Contained in : Stereo Comfort Box (cmfy)
+//
+// Defined in Apple HEVC Stereo Video - ISOBMFF Extensions
+type DadjBox struct {
+	Version             byte
+	Flags               uint32
+	DisparityAdjustment int32
+}
+
+// DecodeDadj - box-specific decode
+func DecodeDadj(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeDadjSR(hdr, startPos, sr)
+}
+
+// DecodeDadjSR - box-specific decode
+func DecodeDadjSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &DadjBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.DisparityAdjustment = sr.ReadInt32()
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *DadjBox) Type() string {
+	return "dadj"
+}
+
+// Size - return calculated size
+func (b *DadjBox) Size() uint64 {
+	return uint64(boxHeaderSize + 8)
+}
+
+// Encode - write box to w
+func (b *DadjBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *DadjBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteInt32(b.DisparityAdjustment)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *DadjBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - disparityAdjustment: %d", b.DisparityAdjustment)
+	return bd.err
+}
@@ -0,0 +1,726 @@
+package mp4
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// DefragmentOptions - options for converting a fragmented file to a progressive file
+type DefragmentOptions struct {
+	// MoovLast - put the moov box after the mdat box. Default is to have moov before mdat.
+	MoovLast bool
+}
+
+// defragTrack collects the samples and related information of one track.
+type defragTrack struct {
+	inTrak      *TrakBox
+	trex        *TrexBox
+	samples     []Sample
+	chunks      []*defragChunk
+	nextDecTime uint64
+	startTime   uint64 // decode time of first sample
+	startOffset uint64 // startTime relative to the earliest track, written as an empty edit
+	senc        *SencBox
+	auxSizes    []byte // sample auxiliary information sizes from senc
+	auxData     []byte // sample auxiliary information from senc, stored in mdat
+	auxOffset   uint64 // file offset of auxData
+	groups      []*defragGroup
+}
+
+// defragChunk is the data of one traf in a fragment. It becomes a chunk in the output.
+type defragChunk struct {
+	nrSamples uint32
+	sdi       uint32
+	data      [][]byte
+	size      uint64
+	offset    uint64
+}
+
+// defragGroup is a sample group with descriptions from moov and all trafs.
+type defragGroup struct {
+	sgpd    *SgpdBox
+	sbgp    *SbgpBox // template for version and grouping type parameter
+	indices []uint32 // per sample. 0 means not in any group
+}
+
+// Defragment converts a fragmented file to a progressive file with one mdat box.
+//
+// The sample tables (stts, ctts, stss, stsz, stsc, stco/co64, and sdtp) are built from
+// the samples of all fragments using the trex defaults. Each track fragment becomes a chunk,
+// and the chunks are interleaved in fragment order. Sample groups (sbgp/sgpd) are merged
+// into the stbl box, with traf-local group descriptions appended to the moov-level ones.
+// The senc information is stored as sample auxiliary information after the samples in the mdat box,
+// and is referenced by saiz and saio boxes.
+// Decode times are rebased by the same amount for all tracks, and tracks starting later than
+// the earliest one get an empty edit, so that the tracks stay in sync.
+// The mdat data must be available, i.e. the file should not be decoded in lazy mode.
+func (f *File) Defragment(opts DefragmentOptions) (*File, error) {
+	if !f.IsFragmented() || f.Init == nil {
+		return nil, fmt.Errorf("input file is not fragmented with an init segment")
+	}
+	moov := f.Init.Moov
+	if moov.Mvex == nil {
+		return nil, fmt.Errorf("no mvex box in init segment")
+	}
+	tracks := make([]*defragTrack, 0, len(moov.Traks))
+	for _, trak := range moov.Traks {
+		trex, ok := moov.Mvex.GetTrex(trak.Tkhd.TrackID)
+		if !ok {
+			return nil, fmt.Errorf("no trex box for track %d", trak.Tkhd.TrackID)
+		}
+		tracks = append(tracks, &defragTrack{inTrak: trak, trex: trex})
+	}
+
+	var chunkOrder []*defragChunk
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			if frag.Mdat == nil || frag.Mdat.IsLazy() {
+				return nil, fmt.Errorf("fragment at %d: mdat data not available", frag.StartPos)
+			}
+			for _, traf := range frag.Moof.Trafs {
+				track := findDefragTrack(tracks, traf.Tfhd.TrackID)
+				if track == nil {
+					return nil, fmt.Errorf("no track for traf with trackID %d", traf.Tfhd.TrackID)
+				}
+				chunk, err := track.addTraf(frag, traf)
+				if err != nil {
+					return nil, fmt.Errorf("track %d: %w", traf.Tfhd.TrackID, err)
+				}
+				if chunk != nil {
+					chunkOrder = append(chunkOrder, chunk)
+				}
+			}
+		}
+	}
+	if len(chunkOrder) == 0 {
+		return nil, fmt.Errorf("no samples in fragments")
+	}
+	setDefragStartOffsets(tracks, moov.Mvhd.Timescale)
+	for _, track := range tracks {
+		err := track.createAuxInfo()
+		if err != nil {
+			return nil, fmt.Errorf("track %d: %w", track.inTrak.Tkhd.TrackID, err)
+		}
+	}
+
+	outMoov, err := createDefragMoov(moov, tracks, false)
+	if err != nil {
+		return nil, err
+	}
+	mdat := &MdatBox{}
+	for _, chunk := range chunkOrder {
+		for _, data := range chunk.data {
+			mdat.AddSampleData(data)
+		}
+	}
+	for _, track := range tracks {
+		if len(track.auxData) > 0 {
+			mdat.AddSampleData(track.auxData)
+		}
+	}
+	ftyp := NewFtyp("isom", 0x200, []string{"isom", "iso2", "mp41"})
+
+	// Switch to co64 if the file gets too big for 32-bit offsets
+	totSize := ftyp.Size() + outMoov.Size() + mdat.Size()
+	if totSize > math.MaxUint32 {
+		outMoov, err = createDefragMoov(moov, tracks, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	mdatPayloadStart := ftyp.Size() + mdat.HeaderSize()
+	moovStart := ftyp.Size()
+	if opts.MoovLast {
+		moovStart = ftyp.Size() + mdat.Size()
+	} else {
+		mdatPayloadStart += outMoov.Size()
+	}
+	offset := mdatPayloadStart
+	for _, chunk := range chunkOrder {
+		chunk.offset = offset
+		offset += chunk.size
+	}
+	for _, track := range tracks {
+		track.auxOffset = offset
+		offset += uint64(len(track.auxData))
+	}
+	for i, track := range tracks {
+		setDefragOffsets(outMoov.Traks[i], track)
+	}
+
+	outFile := NewFile()
+	outFile.AddChild(ftyp, 0)
+	if opts.MoovLast {
+		outFile.AddChild(mdat, ftyp.Size())
+		outFile.AddChild(outMoov, moovStart)
+	} else {
+		outFile.AddChild(outMoov, moovStart)
+		outFile.AddChild(mdat, moovStart+outMoov.Size())
+	}
+	return outFile, nil
+}
+
+func findDefragTrack(tracks []*defragTrack, trackID uint32) *defragTrack {
+	for _, track := range tracks {
+		if track.inTrak.Tkhd.TrackID == trackID {
+			return track
+		}
+	}
+	return nil
+}
+
+// addTraf adds the samples, sample groups, and encryption information of a traf.
+func (d *defragTrack) addTraf(frag *Fragment, traf *TrafBox) (*defragChunk, error) {
+	samples, err := getTrafFullSamples(frag, traf, d.trex)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, nil
+	}
+	nrPrevSamples := len(d.samples)
+	if nrPrevSamples == 0 {
+		d.nextDecTime = samples[0].DecodeTime
+		d.startTime = samples[0].DecodeTime
+	} else if samples[0].DecodeTime != d.nextDecTime {
+		// Adjust the duration of the previous sample to close gaps or overlaps
+		last := &d.samples[nrPrevSamples-1]
+		newDur := int64(last.Dur) + int64(samples[0].DecodeTime) - int64(d.nextDecTime)
+		if newDur < 0 || newDur > math.MaxUint32 {
+			return nil, fmt.Errorf("decode time %d does not match end of previous fragment %d",
+				samples[0].DecodeTime, d.nextDecTime)
+		}
+		last.Dur = uint32(newDur)
+		d.nextDecTime = samples[0].DecodeTime
+	}
+	sdi := d.trex.DefaultSampleDescriptionIndex
+	if traf.Tfhd.HasSampleDescriptionIndex() {
+		sdi = traf.Tfhd.SampleDescriptionIndex
+	}
+	chunk := &defragChunk{nrSamples: uint32(len(samples)), sdi: sdi}
+	for _, s := range samples {
+		d.samples = append(d.samples, s.Sample)
+		chunk.data = append(chunk.data, s.Data)
+		chunk.size += uint64(len(s.Data))
+		d.nextDecTime += uint64(s.Dur)
+	}
+	d.chunks = append(d.chunks, chunk)
+
+	err = d.addSencSamples(traf, nrPrevSamples, len(samples))
+	if err != nil {
+		return nil, err
+	}
+	err = d.addSampleGroups(traf, nrPrevSamples, len(samples))
+	if err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
+
+// getTrafFullSamples returns the samples of a specific traf.
+// Fragment.GetFullSamples only handles the first traf of a track.
+func getTrafFullSamples(frag *Fragment, traf *TrafBox, trex *TrexBox) ([]FullSample, error) {
+	moof := &MoofBox{Mfhd: frag.Moof.Mfhd, StartPos: frag.Moof.StartPos, Traf: traf, Trafs: []*TrafBox{traf}}
+	tmpFrag := Fragment{Moof: moof, Mdat: frag.Mdat}
+	return tmpFrag.GetFullSamples(trex)
+}
+
+// addSencSamples copies the senc information of a traf.
+func (d *defragTrack) addSencSamples(traf *TrafBox, nrPrevSamples, nrSamples int) error {
+	senc := traf.Senc
+	switch {
+	case senc == nil && d.senc == nil:
+		return nil
+	case senc == nil:
+		return fmt.Errorf("senc box missing in traf")
+	case d.senc == nil && nrPrevSamples > 0:
+		return fmt.Errorf("senc box not present in all trafs")
+	case senc.ReadButNotParsed():
+		return fmt.Errorf("senc box not parsed")
+	case int(senc.SampleCount) != nrSamples:
+		return fmt.Errorf("senc sample count %d differs from %d samples", senc.SampleCount, nrSamples)
+	}
+	if d.senc == nil {
+		d.senc = NewSencBox(0, 0)
+		d.senc.SetPerSampleIVSize(byte(senc.GetPerSampleIVSize()))
+	}
+	for i := 0; i < nrSamples; i++ {
+		var sencSample SencSample
+		if len(senc.IVs) > 0 {
+			sencSample.IV = senc.IVs[i]
+		}
+		if len(senc.SubSamples) > 0 {
+			sencSample.SubSamples = senc.SubSamples[i]
+		}
+		err := d.senc.AddSample(sencSample)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addSampleGroups maps the sample group indices of a traf to moov-level indices.
+func (d *defragTrack) addSampleGroups(traf *TrafBox, nrPrevSamples, nrSamples int) error {
+	for _, child := range traf.Children {
+		sbgp, ok := child.(*SbgpBox)
+		if !ok {
+			continue
+		}
+		group := d.getGroup(sbgp, traf, nrPrevSamples)
+		var localSgpd *SgpdBox
+		for _, c := range traf.Children {
+			if sgpd, ok := c.(*SgpdBox); ok && sgpd.GroupingType == sbgp.GroupingType {
+				localSgpd = sgpd
+				break
+			}
+		}
+		localToGlobal := make(map[uint32]uint32)
+		for i, count := range sbgp.SampleCounts {
+			idx := sbgp.GroupDescriptionIndices[i]
+			if idx > 0x10000 {
+				localIdx := idx - 0x10000
+				globalIdx, ok := localToGlobal[localIdx]
+				if !ok {
+					if localSgpd == nil || int(localIdx) > len(localSgpd.SampleGroupEntries) {
+						return fmt.Errorf("sbgp %s: no traf sgpd entry %d", sbgp.GroupingType, localIdx)
+					}
+					globalIdx = addSgpdEntry(group.sgpd, localSgpd.SampleGroupEntries[localIdx-1])
+					localToGlobal[localIdx] = globalIdx
+				}
+				idx = globalIdx
+			}
+			for j := uint32(0); j < count; j++ {
+				group.indices = append(group.indices, idx)
+			}
+		}
+		if len(group.indices) > nrPrevSamples+nrSamples {
+			return fmt.Errorf("sbgp %s: more samples than in traf", sbgp.GroupingType)
+		}
+	}
+	for _, group := range d.groups {
+		for len(group.indices) < nrPrevSamples+nrSamples {
+			group.indices = append(group.indices, 0)
+		}
+	}
+	return nil
+}
+
+// getGroup returns the group for the grouping type of sbgp, and creates it if needed.
+func (d *defragTrack) getGroup(sbgp *SbgpBox, traf *TrafBox, nrPrevSamples int) *defragGroup {
+	for _, group := range d.groups {
+		if group.sgpd.GroupingType == sbgp.GroupingType {
+			return group
+		}
+	}
+	var sgpd *SgpdBox
+	for _, s := range d.inTrak.Mdia.Minf.Stbl.Sgpds {
+		if s.GroupingType == sbgp.GroupingType {
+			sgpdCopy := *s
+			sgpdCopy.DescriptionLengths = append([]uint32(nil), s.DescriptionLengths...)
+			sgpdCopy.SampleGroupEntries = append([]SampleGroupEntry(nil), s.SampleGroupEntries...)
+			sgpd = &sgpdCopy
+			break
+		}
+	}
+	if sgpd == nil {
+		sgpd = &SgpdBox{Version: 1, GroupingType: sbgp.GroupingType}
+		for _, c := range traf.Children {
+			if s, ok := c.(*SgpdBox); ok && s.GroupingType == sbgp.GroupingType {
+				sgpd.Version = s.Version
+				sgpd.Flags = s.Flags
+				sgpd.DefaultLength = s.DefaultLength
+				break
+			}
+		}
+	}
+	group := &defragGroup{sgpd: sgpd, sbgp: sbgp, indices: make([]uint32, nrPrevSamples)}
+	d.groups = append(d.groups, group)
+	return group
+}
+
+// addSgpdEntry returns the one-based index of an identical entry, or adds the entry
+// and keeps the description lengths consistent.
+func addSgpdEntry(sgpd *SgpdBox, entry SampleGroupEntry) uint32 {
+	entryBytes := encodeSampleGroupEntry(entry)
+	for i, e := range sgpd.SampleGroupEntries {
+		if bytes.Equal(encodeSampleGroupEntry(e), entryBytes) {
+			return uint32(i + 1)
+		}
+	}
+	size := uint32(entry.Size())
+	if sgpd.Version >= 1 && sgpd.DefaultLength != 0 && size != sgpd.DefaultLength {
+		sgpd.DescriptionLengths = make([]uint32, len(sgpd.SampleGroupEntries))
+		for i := range sgpd.DescriptionLengths {
+			sgpd.DescriptionLengths[i] = sgpd.DefaultLength
+		}
+		sgpd.DefaultLength = 0
+	}
+	sgpd.SampleGroupEntries = append(sgpd.SampleGroupEntries, entry)
+	if sgpd.Version >= 1 && sgpd.DefaultLength == 0 {
+		sgpd.DescriptionLengths = append(sgpd.DescriptionLengths, size)
+	}
+	return uint32(len(sgpd.SampleGroupEntries))
+}
+
+func encodeSampleGroupEntry(entry SampleGroupEntry) []byte {
+	sw := bits.NewFixedSliceWriter(int(entry.Size()))
+	entry.Encode(sw)
+	return sw.Bytes()
+}
+
+// createDefragMoov creates a moov box with sample tables for all tracks.
+// Chunk offsets and the saio offset are set to zero and are filled in by setDefragOffsets.
+func createDefragMoov(inMoov *MoovBox, tracks []*defragTrack, useCo64 bool) (*MoovBox, error) {
+	moov := NewMoovBox()
+	mvhd := *inMoov.Mvhd
+	var movieDur uint64
+	for _, child := range inMoov.Children {
+		switch child.(type) {
+		case *MvhdBox:
+			moov.AddChild(&mvhd)
+		case *MvexBox:
+			// Not used in progressive files
+		case *TrakBox:
+			track := tracks[len(moov.Traks)]
+			trak, err := createDefragTrak(track, mvhd.Timescale, useCo64)
+			if err != nil {
+				return nil, err
+			}
+			if trak.Tkhd.Duration > movieDur {
+				movieDur = trak.Tkhd.Duration
+			}
+			moov.AddChild(trak)
+		default:
+			moov.AddChild(child)
+		}
+	}
+	mvhd.Duration = movieDur
+	if movieDur > math.MaxUint32 {
+		mvhd.Version = 1
+	}
+	return moov, nil
+}
+
+// createDefragTrak creates a trak box with the same boxes as the input trak, except for stbl.
+func createDefragTrak(track *defragTrack, movieTimescale uint32, useCo64 bool) (*TrakBox, error) {
+	inTrak := track.inTrak
+	var mediaDur uint64
+	for _, s := range track.samples {
+		mediaDur += uint64(s.Dur)
+	}
+	movieDur := mediaDur * uint64(movieTimescale) / uint64(inTrak.Mdia.Mdhd.Timescale)
+	trak := NewTrakBox()
+	for _, child := range inTrak.Children {
+		switch box := child.(type) {
+		case *TkhdBox:
+			tkhd := *box
+			tkhd.Duration = movieDur + track.startOffset
+			if tkhd.Duration > math.MaxUint32 {
+				tkhd.Version = 1
+			}
+			trak.AddChild(&tkhd)
+			if track.startOffset > 0 && inTrak.Edts == nil {
+				trak.AddChild(createDefragEdts(nil, track.startOffset, movieDur))
+			}
+		case *EdtsBox:
+			if track.startOffset > 0 {
+				trak.AddChild(createDefragEdts(box, track.startOffset, movieDur))
+				continue
+			}
+			trak.AddChild(box)
+		case *MdiaBox:
+			mdia := NewMdiaBox()
+			for _, mc := range box.Children {
+				switch mbox := mc.(type) {
+				case *MdhdBox:
+					mdhd := *mbox
+					mdhd.Duration = mediaDur
+					if mediaDur > math.MaxUint32 {
+						mdhd.Version = 1
+					}
+					mdia.AddChild(&mdhd)
+				case *MinfBox:
+					minf := NewMinfBox()
+					for _, c := range mbox.Children {
+						if _, ok := c.(*StblBox); ok {
+							stbl, err := track.createStbl(useCo64)
+							if err != nil {
+								return nil, err
+							}
+							minf.AddChild(stbl)
+							continue
+						}
+						minf.AddChild(c)
+					}
+					mdia.AddChild(minf)
+				default:
+					mdia.AddChild(mc)
+				}
+			}
+			trak.AddChild(mdia)
+		default:
+			trak.AddChild(child)
+		}
+	}
+	return trak, nil
+}
+
+// createDefragEdts creates an edit list starting with an empty edit of duration startOffset,
+// followed by the edits of in, or by an edit covering the whole media if in is nil.
+func createDefragEdts(in *EdtsBox, startOffset, movieDur uint64) *EdtsBox {
+	elst := &ElstBox{}
+	elst.Entries = append(elst.Entries, ElstEntry{SegmentDuration: startOffset, MediaTime: -1, MediaRateInteger: 1})
+	if in != nil && len(in.Elst) > 0 {
+		elst.Entries = append(elst.Entries, in.Elst[0].Entries...)
+	} else {
+		elst.Entries = append(elst.Entries, ElstEntry{SegmentDuration: movieDur, MediaTime: 0, MediaRateInteger: 1})
+	}
+	for _, e := range elst.Entries {
+		if e.SegmentDuration > math.MaxUint32 || e.MediaTime > math.MaxInt32 {
+			elst.Version = 1
+		}
+	}
+	return &EdtsBox{Elst: []*ElstBox{elst}, Children: []Box{elst}}
+}
+
+// createStbl creates the sample table from the collected samples.
+func (d *defragTrack) createStbl(useCo64 bool) (*StblBox, error) {
+	stbl := NewStblBox()
+	stbl.AddChild(d.inTrak.Mdia.Minf.Stbl.Stsd)
+
+	stts := &SttsBox{}
+	for i, s := range d.samples {
+		n := len(stts.SampleCount)
+		if i > 0 && stts.SampleTimeDelta[n-1] == s.Dur {
+			stts.SampleCount[n-1]++
+			continue
+		}
+		stts.SampleCount = append(stts.SampleCount, 1)
+		stts.SampleTimeDelta = append(stts.SampleTimeDelta, s.Dur)
+	}
+	stbl.AddChild(stts)
+
+	needCtts := false
+	for _, s := range d.samples {
+		if s.CompositionTimeOffset != 0 {
+			needCtts = true
+			break
+		}
+	}
+	if needCtts {
+		ctts := &CttsBox{}
+		var counts []uint32
+		var offsets []int32
+		for i, s := range d.samples {
+			if s.CompositionTimeOffset < 0 {
+				ctts.Version = 1
+			}
+			n := len(counts)
+			if i > 0 && offsets[n-1] == s.CompositionTimeOffset {
+				counts[n-1]++
+				continue
+			}
+			counts = append(counts, 1)
+			offsets = append(offsets, s.CompositionTimeOffset)
+		}
+		err := ctts.AddSampleCountsAndOffset(counts, offsets)
+		if err != nil {
+			return nil, err
+		}
+		stbl.AddChild(ctts)
+	}
+
+	stsc := &StscBox{}
+	for i, chunk := range d.chunks {
+		if i > 0 {
+			prev := d.chunks[i-1]
+			if chunk.nrSamples == prev.nrSamples && chunk.sdi == prev.sdi {
+				continue
+			}
+		}
+		err := stsc.AddEntry(uint32(i+1), chunk.nrSamples, chunk.sdi)
+		if err != nil {
+			return nil, err
+		}
+	}
+	stbl.AddChild(stsc)
+
+	stsz := &StszBox{SampleNumber: uint32(len(d.samples))}
+	uniformSize := len(d.samples) > 0 && d.samples[0].Size > 0
+	for _, s := range d.samples {
+		if s.Size != d.samples[0].Size {
+			uniformSize = false
+			break
+		}
+	}
+	if uniformSize {
+		stsz.SampleUniformSize = d.samples[0].Size
+	} else {
+		stsz.SampleSize = make([]uint32, len(d.samples))
+		for i, s := range d.samples {
+			stsz.SampleSize[i] = s.Size
+		}
+	}
+	stbl.AddChild(stsz)
+
+	allSync := true
+	needSdtp := false
+	for _, s := range d.samples {
+		flags := DecodeSampleFlags(s.Flags)
+		if flags.SampleIsNonSync {
+			allSync = false
+		}
+		if flags.IsLeading != 0 || flags.SampleIsDependedOn != 0 || flags.SampleHasRedundancy != 0 {
+			needSdtp = true
+		}
+	}
+	if !allSync {
+		stss := &StssBox{}
+		for i, s := range d.samples {
+			if !DecodeSampleFlags(s.Flags).SampleIsNonSync {
+				stss.SampleNumber = append(stss.SampleNumber, uint32(i+1))
+			}
+		}
+		stbl.AddChild(stss)
+	}
+
+	if useCo64 {
+		stbl.AddChild(&Co64Box{ChunkOffset: make([]uint64, len(d.chunks))})
+	} else {
+		stbl.AddChild(&StcoBox{ChunkOffset: make([]uint32, len(d.chunks))})
+	}
+
+	if needSdtp {
+		entries := make([]SdtpEntry, len(d.samples))
+		for i, s := range d.samples {
+			flags := DecodeSampleFlags(s.Flags)
+			entries[i] = NewSdtpEntry(flags.IsLeading, flags.SampleDependsOn,
+				flags.SampleIsDependedOn, flags.SampleHasRedundancy)
+		}
+		stbl.AddChild(CreateSdtpBox(entries))
+	}
+
+	for _, group := range d.groups {
+		hasGroup := false
+		for _, idx := range group.indices {
+			if idx != 0 {
+				hasGroup = true
+				break
+			}
+		}
+		if !hasGroup {
+			continue
+		}
+		sbgp := &SbgpBox{Version: group.sbgp.Version, GroupingType: group.sbgp.GroupingType,
+			GroupingTypeParameter: group.sbgp.GroupingTypeParameter}
+		for i, idx := range group.indices {
+			n := len(sbgp.SampleCounts)
+			if i > 0 && sbgp.GroupDescriptionIndices[n-1] == idx {
+				sbgp.SampleCounts[n-1]++
+				continue
+			}
+			sbgp.SampleCounts = append(sbgp.SampleCounts, 1)
+			sbgp.GroupDescriptionIndices = append(sbgp.GroupDescriptionIndices, idx)
+		}
+		stbl.AddChild(group.sgpd)
+		stbl.AddChild(sbgp)
+	}
+
+	if len(d.auxSizes) > 0 {
+		saiz := NewSaizBox(len(d.auxSizes))
+		saiz.SampleCount = uint32(len(d.auxSizes))
+		saiz.SampleInfo = append(saiz.SampleInfo, d.auxSizes...)
+		uniformSize := true
+		for _, size := range saiz.SampleInfo {
+			if size != saiz.SampleInfo[0] {
+				uniformSize = false
+				break
+			}
+		}
+		if uniformSize {
+			saiz.DefaultSampleInfoSize = saiz.SampleInfo[0]
+			saiz.SampleInfo = nil
+		}
+		saio := NewSaioBox()
+		if useCo64 {
+			saio.Version = 1
+		}
+		stbl.AddChild(saiz)
+		stbl.AddChild(saio)
+	}
+	return stbl, nil
+}
+
+// setDefragStartOffsets sets the start offset of each track relative to the earliest track.
+// Offsets are compared in the movie timescale.
+func setDefragStartOffsets(tracks []*defragTrack, movieTimescale uint32) {
+	minStart := uint64(math.MaxUint64)
+	for _, track := range tracks {
+		if len(track.samples) == 0 {
+			continue
+		}
+		start := track.startTime * uint64(movieTimescale) / uint64(track.inTrak.Mdia.Mdhd.Timescale)
+		if start < minStart {
+			minStart = start
+		}
+	}
+	for _, track := range tracks {
+		if len(track.samples) == 0 {
+			continue
+		}
+		start := track.startTime * uint64(movieTimescale) / uint64(track.inTrak.Mdia.Mdhd.Timescale)
+		track.startOffset = start - minStart
+	}
+}
+
+// createAuxInfo creates the sample auxiliary information (IV and subsamples) from the senc information.
+func (d *defragTrack) createAuxInfo() error {
+	senc := d.senc
+	if senc == nil || (senc.GetPerSampleIVSize() == 0 && senc.Flags&UseSubSampleEncryption == 0) {
+		return nil
+	}
+	useSubSamples := senc.Flags&UseSubSampleEncryption != 0
+	d.auxSizes = make([]byte, 0, senc.SampleCount)
+	for i := 0; i < int(senc.SampleCount); i++ {
+		size := len(d.auxData)
+		if i < len(senc.IVs) {
+			d.auxData = append(d.auxData, senc.IVs[i]...)
+		}
+		if useSubSamples {
+			var subSamples []SubSamplePattern
+			if i < len(senc.SubSamples) {
+				subSamples = senc.SubSamples[i]
+			}
+			d.auxData = append(d.auxData, byte(len(subSamples)>>8), byte(len(subSamples)))
+			for _, ss := range subSamples {
+				c, p := ss.BytesOfClearData, ss.BytesOfProtectedData
+				d.auxData = append(d.auxData, byte(c>>8), byte(c), byte(p>>24), byte(p>>16), byte(p>>8), byte(p))
+			}
+		}
+		size = len(d.auxData) - size
+		if size > math.MaxUint8 {
+			return fmt.Errorf("sample %d: auxiliary information size %d too large for saiz", i+1, size)
+		}
+		d.auxSizes = append(d.auxSizes, byte(size))
+	}
+	return nil
+}
+
+// setDefragOffsets sets the chunk offsets and the saio offset pointing to the auxiliary information.
+func setDefragOffsets(trak *TrakBox, track *defragTrack) {
+	stbl := trak.Mdia.Minf.Stbl
+	for i, chunk := range track.chunks {
+		if stbl.Co64 != nil {
+			stbl.Co64.ChunkOffset[i] = chunk.offset
+		} else {
+			stbl.Stco.ChunkOffset[i] = uint32(chunk.offset)
+		}
+	}
+	if stbl.Saio != nil {
+		stbl.Saio.SetOffset(int64(track.auxOffset))
+	}
+}
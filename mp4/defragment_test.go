@@ -0,0 +1,315 @@
+package mp4_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestDefragment(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     string
+		moovLast bool
+	}{
+		{name: "encrypted moov first", file: "testdata/cbcs.mp4", moovLast: false},
+		{name: "encrypted moov last", file: "testdata/cbcs.mp4", moovLast: true},
+		{name: "fragmentified", file: "testdata/prog_8s.mp4", moovLast: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			inFile := readDefragInput(t, tc.file)
+			outFile, err := inFile.Defragment(mp4.DefragmentOptions{MoovLast: tc.moovLast})
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := bytes.Buffer{}
+			err = outFile.Encode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := buf.Bytes()
+			decFile, err := mp4.DecodeFile(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decFile.IsFragmented() {
+				t.Fatal("output file is fragmented")
+			}
+			wantedTypes := []string{"ftyp", "moov", "mdat"}
+			if tc.moovLast {
+				wantedTypes = []string{"ftyp", "mdat", "moov"}
+			}
+			for i, box := range decFile.Children {
+				if box.Type() != wantedTypes[i] {
+					t.Errorf("box %d is %s instead of %s", i, box.Type(), wantedTypes[i])
+				}
+			}
+			for _, trak := range decFile.Moov.Traks {
+				compareDefragTrack(t, inFile, decFile, trak, data)
+			}
+		})
+	}
+}
+
+// readDefragInput reads a fragmented file, or fragments a progressive one.
+func readDefragInput(t *testing.T, path string) *mp4.File {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs := bytes.NewReader(data)
+	inFile, err := mp4.DecodeFile(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inFile.IsFragmented() {
+		return inFile
+	}
+	fragFiles, err := inFile.Fragmentify(rs, mp4.FragmenterOptions{SegmentDurationMS: 2000, Muxed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	err = fragFiles[0].Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fragFile, err := mp4.DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fragFile
+}
+
+// compareDefragTrack checks that all samples and senc information of a track are the same as in the input.
+func compareDefragTrack(t *testing.T, inFile, outFile *mp4.File, trak *mp4.TrakBox, data []byte) {
+	t.Helper()
+	trackID := trak.Tkhd.TrackID
+	trex, _ := inFile.Init.Moov.Mvex.GetTrex(trackID)
+	var inSamples []mp4.FullSample
+	var inSencSamples []mp4.SencSample
+	for _, seg := range inFile.Segments {
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				if traf.Tfhd.TrackID != trackID {
+					continue
+				}
+				moof := &mp4.MoofBox{Mfhd: frag.Moof.Mfhd, StartPos: frag.Moof.StartPos, Traf: traf, Trafs: []*mp4.TrafBox{traf}}
+				tmpFrag := mp4.Fragment{Moof: moof, Mdat: frag.Mdat}
+				samples, err := tmpFrag.GetFullSamples(trex)
+				if err != nil {
+					t.Fatal(err)
+				}
+				inSamples = append(inSamples, samples...)
+				if traf.Senc != nil {
+					for i := 0; i < int(traf.Senc.SampleCount); i++ {
+						var s mp4.SencSample
+						if len(traf.Senc.IVs) > 0 {
+							s.IV = traf.Senc.IVs[i]
+						}
+						if len(traf.Senc.SubSamples) > 0 {
+							s.SubSamples = traf.Senc.SubSamples[i]
+						}
+						inSencSamples = append(inSencSamples, s)
+					}
+				}
+			}
+		}
+	}
+	stbl := trak.Mdia.Minf.Stbl
+	if int(trak.GetNrSamples()) != len(inSamples) {
+		t.Fatalf("track %d: %d samples instead of %d", trackID, trak.GetNrSamples(), len(inSamples))
+	}
+	startTime := inSamples[0].DecodeTime
+	for i, in := range inSamples {
+		nr := uint32(i + 1)
+		decTime, dur := stbl.Stts.GetDecodeTime(nr)
+		if decTime != in.DecodeTime-startTime || dur != in.Dur {
+			t.Errorf("track %d sample %d: time %d dur %d instead of %d %d", trackID, nr,
+				decTime, dur, in.DecodeTime-startTime, in.Dur)
+		}
+		var cto int32
+		if stbl.Ctts != nil {
+			cto = stbl.Ctts.GetCompositionTimeOffset(nr)
+		}
+		if cto != in.CompositionTimeOffset {
+			t.Errorf("track %d sample %d: cto %d instead of %d", trackID, nr, cto, in.CompositionTimeOffset)
+		}
+		isSync := stbl.Stss == nil || stbl.Stss.IsSyncSample(nr)
+		if isSync != !mp4.DecodeSampleFlags(in.Flags).SampleIsNonSync {
+			t.Errorf("track %d sample %d: sync %t differs", trackID, nr, isSync)
+		}
+		ranges, err := trak.GetRangesForSampleInterval(nr, nr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := ranges[0]
+		if !bytes.Equal(data[r.Offset:r.Offset+r.Size], in.Data) {
+			t.Errorf("track %d sample %d: data differs", trackID, nr)
+		}
+	}
+	if len(inSencSamples) == 0 {
+		if stbl.Saiz != nil {
+			t.Errorf("track %d: unexpected saiz box", trackID)
+		}
+		return
+	}
+	if stbl.Saiz == nil || stbl.Saio == nil {
+		t.Fatalf("track %d: saiz or saio missing", trackID)
+	}
+	for _, c := range stbl.Children {
+		if c.Type() == "senc" {
+			t.Errorf("track %d: senc box in stbl", trackID)
+		}
+	}
+	// The sample iterator reads the auxiliary information that saio points to
+	it, err := outFile.NewSampleIterator(trackID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := 0
+	for it.Next() {
+		enc := it.Sample().Encryption
+		if enc == nil {
+			t.Fatalf("track %d sample %d: no encryption information", trackID, i+1)
+		}
+		in := inSencSamples[i]
+		if len(in.IV) > 0 && !bytes.Equal(enc.IV, in.IV) {
+			t.Errorf("track %d sample %d: IV differs", trackID, i+1)
+		}
+		if len(enc.SubSamples) != len(in.SubSamples) {
+			t.Fatalf("track %d sample %d: %d subsamples instead of %d", trackID, i+1, len(enc.SubSamples), len(in.SubSamples))
+		}
+		for j := range in.SubSamples {
+			if enc.SubSamples[j] != in.SubSamples[j] {
+				t.Errorf("track %d sample %d: subsample %d differs", trackID, i+1, j)
+			}
+		}
+		i++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != len(inSencSamples) {
+		t.Errorf("track %d: %d samples with encryption information instead of %d", trackID, i, len(inSencSamples))
+	}
+}
+
+func TestDefragmentProgressiveInput(t *testing.T) {
+	data, err := os.ReadFile("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inFile, err := mp4.DecodeFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = inFile.Defragment(mp4.DefragmentOptions{})
+	if err == nil {
+		t.Error("expected error for progressive input")
+	}
+}
+
+func TestDefragmentSampleGroups(t *testing.T) {
+	inFile := readDefragInput(t, "testdata/prog_8s.mp4")
+	trackID := inFile.Init.Moov.Traks[0].Tkhd.TrackID
+	nrSamples := uint32(0)
+	for _, seg := range inFile.Segments {
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				if traf.Tfhd.TrackID != trackID {
+					continue
+				}
+				sgpd := &mp4.SgpdBox{Version: 1, GroupingType: "roll", DefaultLength: 2,
+					SampleGroupEntries: []mp4.SampleGroupEntry{&mp4.RollSampleGroupEntry{RollDistance: -1}}}
+				count := traf.Trun.SampleCount()
+				sbgp := &mp4.SbgpBox{GroupingType: "roll", SampleCounts: []uint32{count},
+					GroupDescriptionIndices: []uint32{65537}}
+				if err := traf.AddChild(sgpd); err != nil {
+					t.Fatal(err)
+				}
+				if err := traf.AddChild(sbgp); err != nil {
+					t.Fatal(err)
+				}
+				nrSamples += count
+			}
+		}
+	}
+	outFile, err := inFile.Defragment(mp4.DefragmentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stbl := outFile.Moov.Traks[0].Mdia.Minf.Stbl
+	if stbl.Sgpd == nil || len(stbl.Sgpd.SampleGroupEntries) != 1 {
+		t.Fatalf("expected one sgpd entry, got %+v", stbl.Sgpd)
+	}
+	sbgp := stbl.Sbgp
+	if sbgp == nil || len(sbgp.SampleCounts) != 1 || sbgp.SampleCounts[0] != nrSamples ||
+		sbgp.GroupDescriptionIndices[0] != 1 {
+		t.Errorf("got sbgp %+v instead of one entry with %d samples", sbgp, nrSamples)
+	}
+	if outFile.Moov.Traks[1].Mdia.Minf.Stbl.Sbgp != nil {
+		t.Error("unexpected sbgp in second track")
+	}
+}
+
+func TestDefragmentStartOffset(t *testing.T) {
+	inFile := readDefragInput(t, "testdata/prog_8s.mp4")
+	// Let the second track start one second later than the first
+	audioTrak := inFile.Init.Moov.Traks[1]
+	shift := uint64(audioTrak.Mdia.Mdhd.Timescale)
+	for _, seg := range inFile.Segments {
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				if traf.Tfhd.TrackID == audioTrak.Tkhd.TrackID {
+					traf.Tfdt.SetBaseMediaDecodeTime(traf.Tfdt.BaseMediaDecodeTime() + shift)
+				}
+			}
+		}
+	}
+	outFile, err := inFile.Defragment(mp4.DefragmentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	movieTimescale := uint64(outFile.Moov.Mvhd.Timescale)
+	for i, trak := range outFile.Moov.Traks {
+		inEdts := inFile.Init.Moov.Traks[i].Edts
+		if i == 0 {
+			if trak.Edts != inEdts {
+				t.Errorf("track %d: edit list changed", trak.Tkhd.TrackID)
+			}
+			continue
+		}
+		if trak.Edts == nil || len(trak.Edts.Elst) == 0 {
+			t.Fatalf("track %d: no edit list", trak.Tkhd.TrackID)
+		}
+		first := trak.Edts.Elst[0].Entries[0]
+		if first.MediaTime != -1 || first.SegmentDuration != movieTimescale {
+			t.Errorf("track %d: first edit %+v is not an empty edit of 1s", trak.Tkhd.TrackID, first)
+		}
+	}
+}
+
+func TestDefragmentLargeAuxInfo(t *testing.T) {
+	inFile := readDefragInput(t, "testdata/cbcs.mp4")
+	var senc *mp4.SencBox
+	for _, traf := range inFile.Segments[0].Fragments[0].Moof.Trafs {
+		if traf.Senc != nil && len(traf.Senc.SubSamples) > 0 {
+			senc = traf.Senc
+			break
+		}
+	}
+	if senc == nil {
+		t.Fatal("no senc with subsamples in test file")
+	}
+	// 50 subsamples need 2+6*50 bytes, which does not fit in a saiz entry
+	senc.SubSamples[0] = make([]mp4.SubSamplePattern, 50)
+	_, err := inFile.Defragment(mp4.DefragmentOptions{})
+	if err == nil {
+		t.Error("expected error for too large auxiliary information")
+	}
+}
@@ -152,8 +152,9 @@ The interfaces define common Box methods including encode (writing),
 but not the decode (parsing) methods which have distinct names for each box type and are
 dispatched from the parsed box name.
 
-That dispatch based on box name is defined by the tables "mp4.decodersSR" and "mp4.decoders"
-for the functions "mp4.DecodeBoxSR" and "mp4.DecodeBox", respectively.
+That dispatch based on box name is defined by the table "mp4.boxTypes", from which
+the tables "mp4.decodersSR" and "mp4.decoders" for the functions "mp4.DecodeBoxSR" and "mp4.DecodeBox"
+are set. The same table gives the box struct used by [mp4.BuildBox].
 The "SR" variant that uses [bits/SliceReader] should normally be used for better performance.
 If a box name is unknown, it will result in an [mp4.UnknownBox] being created.
 
@@ -163,7 +164,7 @@ To implement a new box "fooo", the following is needed.
  1. Create a new file "fooo.go" and create a struct type "FoooBox".
  2. "FoooBox" must implement the [mp4.Box] interface methods
  3. It also needs its own decode methods "DecodeFoooSR" and  "DecodeFooo",
-    which must be added in the "decodersSR" map and "decoders" map, respectively
+    which must be added together with a nil "*FoooBox" pointer in the "boxTypes" map
     For a simple example, look at the [mp4.PrftBox].
  4. A test file `fooo_test.go` should also have a test using the method "boxDiffAfterEncodeAndDecode"
     to check that the box information is equal after encoding and decoding.
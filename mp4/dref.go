@@ -93,6 +93,11 @@ func DecodeDrefSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	return dref, sr.AccError()
 }
 
+// GetChildren - list of child boxes
+func (d *DrefBox) GetChildren() []Box {
+	return d.Children
+}
+
 // Type - box type
 func (d *DrefBox) Type() string {
 	return "dref"
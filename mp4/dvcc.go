@@ -0,0 +1,84 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+	"github.com/Eyevinn/mp4ff/dovi"
+)
+
+// DvcCBox - Dolby Vision configuration box (dvcC, dvvC, or dvwC)
+// Specified in Dolby Vision Streams within the ISO Base Media File Format
+type DvcCBox struct {
+	// Name is the box type: dvcC, dvvC, or dvwC
+	Name string
+	dovi.DecConfRec
+}
+
+// CreateDvcC - create a Dolby Vision configuration box with type given by the profile.
+func CreateDvcC(rec dovi.DecConfRec) *DvcCBox {
+	return &DvcCBox{Name: rec.BoxType(), DecConfRec: rec}
+}
+
+// DecodeDvcC - box-specific decode
+func DecodeDvcC(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeDvcCSR(hdr, startPos, sr)
+}
+
+// DecodeDvcCSR - box-specific decode
+func DecodeDvcCSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	rec, err := dovi.DecodeDecConfRec(sr.ReadBytes(hdr.payloadLen()))
+	if err != nil {
+		return nil, err
+	}
+	return &DvcCBox{Name: hdr.Name, DecConfRec: rec}, sr.AccError()
+}
+
+// Type - return box type
+func (b *DvcCBox) Type() string {
+	return b.Name
+}
+
+// Size - return calculated size
+func (b *DvcCBox) Size() uint64 {
+	return uint64(boxHeaderSize) + b.DecConfRec.Size()
+}
+
+// Encode - write box to w
+func (b *DvcCBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *DvcCBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	return b.DecConfRec.EncodeSW(sw)
+}
+
+// Info - write box-specific information
+func (b *DvcCBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - version: %d.%d", b.VersionMajor, b.VersionMinor)
+	bd.write(" - profile: %d", b.Profile)
+	bd.write(" - level: %d", b.Level)
+	bd.write(" - rpuPresent: %t", b.RPUPresent)
+	bd.write(" - elPresent: %t", b.ELPresent)
+	bd.write(" - blPresent: %t", b.BLPresent)
+	bd.write(" - blSignalCompatibilityID: %d", b.BLSignalCompatibilityID)
+	bd.write(" - mdCompression: %d", b.MDCompression)
+	return bd.err
+}
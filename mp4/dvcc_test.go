@@ -0,0 +1,132 @@
+package mp4_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/bits"
+	"github.com/Eyevinn/mp4ff/dovi"
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestDvcC(t *testing.T) {
+	recs := []dovi.DecConfRec{
+		{VersionMajor: 1, Profile: 5, Level: 6, RPUPresent: true, BLPresent: true},
+		{VersionMajor: 1, Profile: 8, Level: 9, RPUPresent: true, BLPresent: true, BLSignalCompatibilityID: 1},
+		{VersionMajor: 2, VersionMinor: 1, Profile: 20, Level: 4, RPUPresent: true, ELPresent: true, BLPresent: true},
+	}
+	wantedTypes := []string{"dvcC", "dvvC", "dvwC"}
+	for i, rec := range recs {
+		dvcC := mp4.CreateDvcC(rec)
+		if dvcC.Type() != wantedTypes[i] {
+			t.Errorf("got type %s instead of %s", dvcC.Type(), wantedTypes[i])
+		}
+		boxDiffAfterEncodeAndDecode(t, dvcC)
+	}
+}
+
+func TestSetDolbyVisionConfig(t *testing.T) {
+	init, err := createVideoHEVCInitSegment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := dovi.DecConfRec{VersionMajor: 1, Profile: 8, Level: 6, RPUPresent: true, BLPresent: true,
+		BLSignalCompatibilityID: 4}
+	err = init.SetDolbyVisionConfig(1, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stsd := init.Moov.Trak.Mdia.Minf.Stbl.Stsd
+	if stsd.HvcX.Type() != "hvc1" || stsd.HvcX.DvcC == nil || stsd.HvcX.DvcC.Type() != "dvvC" {
+		t.Errorf("profile 8: got %s with dvcC %v", stsd.HvcX.Type(), stsd.HvcX.DvcC)
+	}
+	codec, err := stsd.HvcX.DolbyVisionCodecString()
+	if err != nil || codec != "dvh1.08.06" {
+		t.Errorf("got codec %q, err %v", codec, err)
+	}
+
+	// The replaced configuration box keeps its position before btrt
+	stsd.HvcX.AddChild(&mp4.BtrtBox{})
+	dvcCIdx := len(stsd.HvcX.Children) - 2
+	rec = dovi.DecConfRec{VersionMajor: 1, Profile: 5, Level: 7, RPUPresent: true, BLPresent: true}
+	err = init.Moov.Trak.SetDolbyVisionConfig(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nrDvcC := 0
+	for i, c := range stsd.HvcX.Children {
+		if _, ok := c.(*mp4.DvcCBox); ok {
+			nrDvcC++
+			if i != dvcCIdx {
+				t.Errorf("profile 5: config box at index %d instead of %d", i, dvcCIdx)
+			}
+		}
+	}
+	if stsd.HvcX.Type() != "dvh1" || stsd.HvcX.DvcC.Type() != "dvcC" || nrDvcC != 1 {
+		t.Errorf("profile 5: got %s with %d config boxes of type %s", stsd.HvcX.Type(), nrDvcC, stsd.HvcX.DvcC.Type())
+	}
+
+	buf := bytes.Buffer{}
+	err = init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := mp4.DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hvcX := f.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.HvcX
+	if hvcX == nil || hvcX.Type() != "dvh1" || hvcX.DvcC == nil || hvcX.DvcC.Profile != 5 {
+		t.Errorf("decoded sample entry %v does not match", hvcX)
+	}
+	codec, _ = hvcX.DolbyVisionCodecString()
+	if codec != "dvh1.05.07" {
+		t.Errorf("got codec %s", codec)
+	}
+
+	err = init.SetDolbyVisionConfig(1, dovi.DecConfRec{Profile: 10, Level: 6})
+	if err == nil {
+		t.Error("expected error for profile 10 with HEVC sample entry")
+	}
+	err = init.SetDolbyVisionConfig(2, rec)
+	if err == nil {
+		t.Error("expected error for missing track")
+	}
+}
+
+func TestDolbyVisionAVCSampleEntry(t *testing.T) {
+	rec := dovi.DecConfRec{VersionMajor: 1, Profile: 9, Level: 5, RPUPresent: true, BLPresent: true,
+		BLSignalCompatibilityID: 2}
+	for _, entryType := range []string{"dva1", "dvav"} {
+		vse := mp4.CreateVisualSampleEntryBox(entryType, 1920, 1080, nil)
+		if err := vse.SetDolbyVisionConfig(rec); err != nil {
+			t.Fatal(err)
+		}
+		buf := bytes.Buffer{}
+		if err := vse.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		for _, decodeSR := range []bool{false, true} {
+			var box mp4.Box
+			var err error
+			if decodeSR {
+				box, err = mp4.DecodeBoxSR(0, bits.NewFixedSliceReader(buf.Bytes()))
+			} else {
+				box, err = mp4.DecodeBox(0, bytes.NewReader(buf.Bytes()))
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			dec, ok := box.(*mp4.VisualSampleEntryBox)
+			if !ok || dec.Type() != entryType || dec.DvcC == nil || dec.DvcC.Profile != 9 {
+				t.Errorf("%s: got %T %v", entryType, box, box)
+				continue
+			}
+			stsd := mp4.NewStsdBox()
+			stsd.AddChild(dec)
+			if stsd.AvcX != dec {
+				t.Errorf("%s: not set as AvcX in stsd", entryType)
+			}
+		}
+	}
+}
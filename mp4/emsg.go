@@ -4,8 +4,20 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"math"
+	"strings"
 
 	"github.com/Eyevinn/mp4ff/bits"
+	"github.com/Eyevinn/mp4ff/id3"
+	"github.com/Eyevinn/mp4ff/scte35"
+)
+
+// Schemes for emsg message data
+const (
+	// ID3SchemeIDURI - ID3v2 tags (AOM Carriage of ID3 Timed Metadata in CMAF)
+	ID3SchemeIDURI = "https://aomedia.org/emsg/ID3"
+	// SCTE35SchemeIDURI - binary SCTE-35 splice_info_section (SCTE 214-1)
+	SCTE35SchemeIDURI = "urn:scte:scte35:2013:bin"
 )
 
 // EmsgBox - DASHEventMessageBox as defined in ISO/IEC 23009-1
@@ -22,6 +34,93 @@ type EmsgBox struct {
 	MessageData           []byte
 }
 
+// CreateID3Emsg - create a version 1 emsg box with tag as message data, to be added with Fragment.AddEmsg
+func CreateID3Emsg(tag *id3.Tag, timeScale uint32, presentationTime uint64, eventDuration, id uint32) (*EmsgBox, error) {
+	data, err := tag.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return &EmsgBox{
+		Version:          1,
+		TimeScale:        timeScale,
+		PresentationTime: presentationTime,
+		EventDuration:    eventDuration,
+		ID:               id,
+		SchemeIDURI:      ID3SchemeIDURI,
+		MessageData:      data,
+	}, nil
+}
+
+// ID3Tag - decode message data as ID3v2 tag. The scheme must be ID3SchemeIDURI.
+func (b *EmsgBox) ID3Tag() (*id3.Tag, error) {
+	if b.SchemeIDURI != ID3SchemeIDURI {
+		return nil, fmt.Errorf("emsg scheme %q is not ID3", b.SchemeIDURI)
+	}
+	return id3.DecodeTag(b.MessageData)
+}
+
+// CreateSCTE35Emsg - create a version 1 emsg box with sis as message data, to be added with Fragment.AddEmsg.
+// SetSplicePTS can be used to set the presentation time from the splice time of sis.
+func CreateSCTE35Emsg(sis *scte35.SpliceInfoSection, timeScale uint32, presentationTime uint64, eventDuration, id uint32) (*EmsgBox, error) {
+	data, err := sis.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return &EmsgBox{
+		Version:          1,
+		TimeScale:        timeScale,
+		PresentationTime: presentationTime,
+		EventDuration:    eventDuration,
+		ID:               id,
+		SchemeIDURI:      SCTE35SchemeIDURI,
+		MessageData:      data,
+	}, nil
+}
+
+// SpliceInfoSection - decode message data as SCTE-35 splice_info_section. The scheme must be SCTE35SchemeIDURI.
+func (b *EmsgBox) SpliceInfoSection() (*scte35.SpliceInfoSection, error) {
+	if b.SchemeIDURI != SCTE35SchemeIDURI {
+		return nil, fmt.Errorf("emsg scheme %q is not SCTE-35", b.SchemeIDURI)
+	}
+	return scte35.DecodeSpliceInfoSection(b.MessageData)
+}
+
+// EventTime - presentation time of the event in the emsg timescale.
+// For version 0, segmentStart is the earliest presentation time of the segment in the emsg timescale.
+func (b *EmsgBox) EventTime(segmentStart uint64) uint64 {
+	if b.Version == 1 {
+		return b.PresentationTime
+	}
+	return segmentStart + uint64(b.PresentationTimeDelta)
+}
+
+// SetEventTime - set the presentation time of the event in the emsg timescale.
+// For version 0, segmentStart is the earliest presentation time of the segment in the emsg timescale,
+// and t must not be before it.
+func (b *EmsgBox) SetEventTime(t, segmentStart uint64) error {
+	if b.Version == 1 {
+		b.PresentationTime = t
+		return nil
+	}
+	if t < segmentStart || t-segmentStart > math.MaxUint32 {
+		return fmt.Errorf("event time %d cannot be expressed relative to segment start %d", t, segmentStart)
+	}
+	b.PresentationTimeDelta = uint32(t - segmentStart)
+	return nil
+}
+
+// SplicePTS - 33-bit PTS of the event time, given ptsOffset as the PTS at media time 0.
+// segmentStart is used for version 0 as in EventTime.
+func (b *EmsgBox) SplicePTS(segmentStart, ptsOffset uint64) uint64 {
+	return scte35.PTSFromMediaTime(b.EventTime(segmentStart), b.TimeScale, ptsOffset)
+}
+
+// SetSplicePTS - set the event time from the 33-bit PTS of a splice, given ptsOffset as the PTS at media time 0.
+// segmentStart is used for version 0 as in SetEventTime.
+func (b *EmsgBox) SetSplicePTS(pts, segmentStart, ptsOffset uint64) error {
+	return b.SetEventTime(scte35.MediaTimeFromPTS(pts, b.TimeScale, ptsOffset), segmentStart)
+}
+
 // DecodeEmsg - box-specific decode
 func DecodeEmsg(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
@@ -147,10 +246,26 @@ func (b *EmsgBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 	msgDataLen := len(b.MessageData)
 
 	if msgDataLen > 0 {
-		if level > 0 {
-			bd.write(" - messageData size=%d: %s", msgDataLen, hex.EncodeToString(b.MessageData))
-		} else {
+		if level == 0 {
 			bd.write(" - messageData size=%d", msgDataLen)
+			return bd.err
+		}
+		bd.write(" - messageData size=%d: %s", msgDataLen, hex.EncodeToString(b.MessageData))
+		switch b.SchemeIDURI {
+		case ID3SchemeIDURI:
+			tag, err := id3.DecodeTag(b.MessageData)
+			if err != nil {
+				bd.write(" - ID3 error: %s", err)
+			} else {
+				bd.write(" - %s", strings.ReplaceAll(tag.String(), "\n", "\n"+indent+indentStep))
+			}
+		case SCTE35SchemeIDURI:
+			sis, err := scte35.DecodeSpliceInfoSection(b.MessageData)
+			if err != nil {
+				bd.write(" - SCTE-35 error: %s", err)
+			} else {
+				bd.write(" - %s", strings.ReplaceAll(sis.String(), "\n", "\n"+indent+indentStep))
+			}
 		}
 	}
 
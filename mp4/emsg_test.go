@@ -1,12 +1,16 @@
 package mp4_test
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/go-test/deep"
 
 	"github.com/Eyevinn/mp4ff/bits"
+	"github.com/Eyevinn/mp4ff/id3"
 	"github.com/Eyevinn/mp4ff/mp4"
+	"github.com/Eyevinn/mp4ff/scte35"
 )
 
 func TestEmsg(t *testing.T) {
@@ -91,3 +95,87 @@ func TestEmsgMessageDataIsEncoded(t *testing.T) {
 		t.Error("Different emsg boxes have the same encoded data")
 	}
 }
+
+func TestID3Emsg(t *testing.T) {
+	tag := id3.NewTag(4)
+	tag.AddFrame(&id3.PrivFrame{Owner: "com.example.ad", Data: []byte{1, 2, 3}})
+	tag.AddFrame(id3.NewTxxxFrame(4, "adId", "1234"))
+	emsg, err := mp4.CreateID3Emsg(tag, 90000, 180000, 2700000, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if emsg.Version != 1 || emsg.SchemeIDURI != mp4.ID3SchemeIDURI {
+		t.Errorf("got emsg version %d scheme %q", emsg.Version, emsg.SchemeIDURI)
+	}
+	boxDiffAfterEncodeAndDecode(t, emsg)
+	gotTag, err := emsg.ID3Tag()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(gotTag, tag); diff != nil {
+		t.Error(diff)
+	}
+
+	frag, err := mp4.CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frag.AddEmsg(emsg)
+	if frag.Children[0] != emsg {
+		t.Error("emsg not first box in fragment")
+	}
+
+	other := mp4.EmsgBox{Version: 1, SchemeIDURI: "urn:other"}
+	if _, err := other.ID3Tag(); err == nil {
+		t.Error("expected error for non-ID3 scheme")
+	}
+}
+
+func TestSCTE35Emsg(t *testing.T) {
+	const ptsOffset = 900000
+	sis := scte35.NewSpliceInfoSection(scte35.NewSpliceInsert(42, true, ptsOffset+20*90000, 30*90000))
+	emsg, err := mp4.CreateSCTE35Emsg(sis, 1000, 0, 30000, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pts, _ := sis.SplicePTS()
+	if err := emsg.SetSplicePTS(pts, 0, ptsOffset); err != nil {
+		t.Fatal(err)
+	}
+	if emsg.PresentationTime != 20000 {
+		t.Errorf("got presentation time %d instead of 20000", emsg.PresentationTime)
+	}
+	if got := emsg.SplicePTS(0, ptsOffset); got != pts {
+		t.Errorf("got splice PTS %d instead of %d", got, pts)
+	}
+	boxDiffAfterEncodeAndDecode(t, emsg)
+	gotSis, err := emsg.SpliceInfoSection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(gotSis, sis); diff != nil {
+		t.Error(diff)
+	}
+	for _, level := range []string{"", "emsg:1"} {
+		buf := bytes.Buffer{}
+		if err := emsg.Info(&buf, level, "", "  "); err != nil {
+			t.Fatal(err)
+		}
+		decoded := strings.Contains(buf.String(), "splice_insert eventID=42 outOfNetwork=true")
+		if decoded != (level != "") {
+			t.Errorf("level %q: decoded cue in info output is %t:\n%s", level, decoded, buf.String())
+		}
+	}
+
+	// Version 0 timing is relative to the segment start
+	v0 := mp4.EmsgBox{Version: 0, TimeScale: 1000, SchemeIDURI: mp4.SCTE35SchemeIDURI}
+	if err := v0.SetSplicePTS(pts, 15000, ptsOffset); err != nil {
+		t.Fatal(err)
+	}
+	if v0.PresentationTimeDelta != 5000 {
+		t.Errorf("got presentation time delta %d instead of 5000", v0.PresentationTimeDelta)
+	}
+	if err := v0.SetSplicePTS(pts, 25000, ptsOffset); err == nil {
+		t.Error("expected error for event before segment start")
+	}
+}
@@ -0,0 +1,93 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// EquiBox - Equirectangular Projection Box (equi)
+//
+// The projection bounds are fractions of the image size as 0.32 fixed-point values.
+//
+// Contained in : Projection Box (proj)
+//
+// Defined in Google Spherical Video V2 RFC
+type EquiBox struct {
+	Version                byte
+	Flags                  uint32
+	ProjectionBoundsTop    uint32
+	ProjectionBoundsBottom uint32
+	ProjectionBoundsLeft   uint32
+	ProjectionBoundsRight  uint32
+}
+
+// DecodeEqui - box-specific decode
+func DecodeEqui(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeEquiSR(hdr, startPos, sr)
+}
+
+// DecodeEquiSR - box-specific decode
+func DecodeEquiSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &EquiBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.ProjectionBoundsTop = sr.ReadUint32()
+	b.ProjectionBoundsBottom = sr.ReadUint32()
+	b.ProjectionBoundsLeft = sr.ReadUint32()
+	b.ProjectionBoundsRight = sr.ReadUint32()
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *EquiBox) Type() string {
+	return "equi"
+}
+
+// Size - return calculated size
+func (b *EquiBox) Size() uint64 {
+	return uint64(boxHeaderSize + 20)
+}
+
+// Encode - write box to w
+func (b *EquiBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *EquiBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint32(b.ProjectionBoundsTop)
+	sw.WriteUint32(b.ProjectionBoundsBottom)
+	sw.WriteUint32(b.ProjectionBoundsLeft)
+	sw.WriteUint32(b.ProjectionBoundsRight)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *EquiBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - projectionBoundsTop: %d", b.ProjectionBoundsTop)
+	bd.write(" - projectionBoundsBottom: %d", b.ProjectionBoundsBottom)
+	bd.write(" - projectionBoundsLeft: %d", b.ProjectionBoundsLeft)
+	bd.write(" - projectionBoundsRight: %d", b.ProjectionBoundsRight)
+	return bd.err
+}
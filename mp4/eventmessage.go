@@ -47,7 +47,7 @@ func DecodeEvteSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 		if rest <= 0 {
 			break
 		}
-		box, err := DecodeBoxSR(pos, sr)
+		box, err := decodeChildBoxSR(pos, sr)
 		if err != nil {
 			return nil, err
 		}
@@ -74,6 +74,11 @@ func (b *EvteBox) AddChild(child Box) {
 	b.Children = append(b.Children, child)
 }
 
+// GetChildren - list of child boxes
+func (b *EvteBox) GetChildren() []Box {
+	return b.Children
+}
+
 func (b *EvteBox) Type() string {
 	return "evte"
 }
@@ -0,0 +1,282 @@
+package mp4
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// UnknownEventDuration - event duration value signaling an unknown duration
+const UnknownEventDuration = 0xffffffff
+
+// EventMessage - event from an emsg or emib box with absolute presentation time.
+//
+// Events with the same SchemeIDURI, Value, and ID are equivalent (ISO/IEC 23009-1 5.10.3.3.4).
+type EventMessage struct {
+	PresentationTime uint64
+	EventDuration    uint32 // UnknownEventDuration if unknown
+	ID               uint32
+	SchemeIDURI      string
+	Value            string
+	MessageData      []byte
+}
+
+// endTime returns the end of the event and false if the duration is unknown
+func (e EventMessage) endTime() (uint64, bool) {
+	if e.EventDuration == UnknownEventDuration {
+		return 0, false
+	}
+	return e.PresentationTime + uint64(e.EventDuration), true
+}
+
+// activeIn - event is active in [start, end). Events with zero duration are active at their presentation time.
+func (e EventMessage) activeIn(start, end uint64) bool {
+	if e.PresentationTime >= end {
+		return false
+	}
+	evEnd, known := e.endTime()
+	if !known {
+		return true
+	}
+	return evEnd > start || (e.EventDuration == 0 && e.PresentationTime >= start)
+}
+
+// sameEvent - same scheme, value, and id
+func (e EventMessage) sameEvent(o EventMessage) bool {
+	return e.ID == o.ID && e.SchemeIDURI == o.SchemeIDURI && e.Value == o.Value
+}
+
+// rescaleTime returns t converted from timescale from to timescale to
+func rescaleTime(t uint64, to, from uint32) uint64 {
+	if to == from || from == 0 {
+		return t
+	}
+	return t/uint64(from)*uint64(to) + t%uint64(from)*uint64(to)/uint64(from)
+}
+
+// EventMessage - event of the emsg box with times in timescale.
+// segmentStart is the earliest presentation time of the segment in timescale and is only used for version 0.
+func (b *EmsgBox) EventMessage(timescale uint32, segmentStart uint64) EventMessage {
+	var t uint64
+	if b.Version == 1 {
+		t = rescaleTime(b.PresentationTime, timescale, b.TimeScale)
+	} else {
+		t = segmentStart + rescaleTime(uint64(b.PresentationTimeDelta), timescale, b.TimeScale)
+	}
+	dur := b.EventDuration
+	if dur != UnknownEventDuration {
+		d := rescaleTime(uint64(dur), timescale, b.TimeScale)
+		if d >= UnknownEventDuration {
+			d = UnknownEventDuration - 1
+		}
+		dur = uint32(d)
+	}
+	return EventMessage{
+		PresentationTime: t,
+		EventDuration:    dur,
+		ID:               b.ID,
+		SchemeIDURI:      b.SchemeIDURI,
+		Value:            b.Value,
+		MessageData:      b.MessageData,
+	}
+}
+
+// EventMessages - events of all emsg boxes in the segment with times in timescale.
+// segmentStart is the earliest presentation time of the segment in timescale and is only used for version 0 emsg boxes.
+func (s *MediaSegment) EventMessages(timescale uint32, segmentStart uint64) []EventMessage {
+	var events []EventMessage
+	for _, frag := range s.Fragments {
+		for _, c := range frag.Children {
+			if emsg, ok := c.(*EmsgBox); ok {
+				events = append(events, emsg.EventMessage(timescale, segmentStart))
+			}
+		}
+	}
+	return events
+}
+
+// MergeEventMessages - add the events of newEvents that are not equivalent to any in events.
+// The result is sorted by presentation time.
+func MergeEventMessages(events, newEvents []EventMessage) []EventMessage {
+	for _, ne := range newEvents {
+		found := false
+		for _, e := range events {
+			if e.sameEvent(ne) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			events = append(events, ne)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].PresentationTime < events[j].PresentationTime
+	})
+	return events
+}
+
+// EventMessageSamples - event message track samples (ISO/IEC 23001-18) covering [startTime, endTime).
+// Samples start and end at startTime, endTime, and the start and end of events in between.
+// Each sample has an emib box for every event active in the sample, or an emeb box if there is none.
+func EventMessageSamples(events []EventMessage, startTime, endTime uint64) ([]FullSample, error) {
+	if endTime <= startTime {
+		return nil, fmt.Errorf("end time %d not after start time %d", endTime, startTime)
+	}
+	boundaries := []uint64{startTime, endTime}
+	for _, e := range events {
+		if e.PresentationTime > startTime && e.PresentationTime < endTime {
+			boundaries = append(boundaries, e.PresentationTime)
+		}
+		if evEnd, known := e.endTime(); known && evEnd > startTime && evEnd < endTime {
+			boundaries = append(boundaries, evEnd)
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i] < boundaries[j] })
+	samples := make([]FullSample, 0, len(boundaries)-1)
+	for i := 0; i+1 < len(boundaries); i++ {
+		start, end := boundaries[i], boundaries[i+1]
+		if end == start {
+			continue
+		}
+		if end-start > math.MaxUint32 {
+			return nil, fmt.Errorf("sample duration %d too large", end-start)
+		}
+		var boxes []Box
+		for _, e := range events {
+			if !e.activeIn(start, end) {
+				continue
+			}
+			boxes = append(boxes, &EmibBox{
+				PresentationTimeDelta: int64(e.PresentationTime) - int64(start),
+				EventDuration:         e.EventDuration,
+				Id:                    e.ID,
+				SchemeIdURI:           e.SchemeIDURI,
+				Value:                 e.Value,
+				MessageData:           e.MessageData,
+			})
+		}
+		if len(boxes) == 0 {
+			boxes = append(boxes, &EmebBox{})
+		}
+		buf := bytes.Buffer{}
+		for _, b := range boxes {
+			if err := b.Encode(&buf); err != nil {
+				return nil, err
+			}
+		}
+		samples = append(samples, FullSample{
+			Sample: Sample{
+				Flags: SyncSampleFlags,
+				Dur:   uint32(end - start),
+				Size:  uint32(buf.Len()),
+			},
+			DecodeTime: start,
+			Data:       buf.Bytes(),
+		})
+	}
+	return samples, nil
+}
+
+// CreateEventMessageFragment - fragment of an event message track with samples covering [startTime, endTime)
+// as created by EventMessageSamples.
+func CreateEventMessageFragment(seqNumber, trackID uint32, events []EventMessage, startTime, endTime uint64) (*Fragment, error) {
+	samples, err := EventMessageSamples(events, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	frag, err := CreateFragment(seqNumber, trackID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range samples {
+		frag.AddFullSample(s)
+	}
+	return frag, nil
+}
+
+// DecodeEventMessageSample - events of an event message track sample with presentation times
+// relative to the media timeline. sampleTime is the presentation time of the sample.
+func DecodeEventMessageSample(data []byte, sampleTime uint64) ([]EventMessage, error) {
+	var events []EventMessage
+	sr := bits.NewFixedSliceReader(data)
+	var pos uint64
+	for sr.NrRemainingBytes() > 0 {
+		box, err := decodeChildBoxSR(pos, sr)
+		if err != nil {
+			return nil, fmt.Errorf("event message sample: %w", err)
+		}
+		pos += box.Size()
+		switch b := box.(type) {
+		case *EmibBox:
+			t := int64(sampleTime) + b.PresentationTimeDelta
+			if t < 0 {
+				return nil, fmt.Errorf("event %d before media time 0", b.Id)
+			}
+			e := EventMessage{
+				PresentationTime: uint64(t),
+				EventDuration:    b.EventDuration,
+				ID:               b.Id,
+				SchemeIDURI:      b.SchemeIdURI,
+				Value:            b.Value,
+			}
+			if len(b.MessageData) > 0 {
+				e.MessageData = b.MessageData
+			}
+			events = append(events, e)
+		case *EmebBox:
+			// No active event
+		default:
+			return nil, fmt.Errorf("event message sample: unexpected box %s", box.Type())
+		}
+	}
+	return events, nil
+}
+
+// EventMessages - events in the event message track samples of the fragment, each event listed once.
+// trex selects the track and provides default values. If nil, the first track is used.
+func (f *Fragment) EventMessages(trex *TrexBox) ([]EventMessage, error) {
+	samples, err := f.GetFullSamples(trex)
+	if err != nil {
+		return nil, err
+	}
+	var events []EventMessage
+	for _, s := range samples {
+		sampleEvents, err := DecodeEventMessageSample(s.Data, s.DecodeTime)
+		if err != nil {
+			return nil, err
+		}
+		events = MergeEventMessages(events, sampleEvents)
+	}
+	return events, nil
+}
+
+// InsertEventMessages - add events starting in [startTime, endTime) as version 1 emsg boxes with timescale
+// to the first fragment of the segment. Returns the number of added emsg boxes.
+func (s *MediaSegment) InsertEventMessages(events []EventMessage, timescale uint32, startTime, endTime uint64) int {
+	if len(s.Fragments) == 0 {
+		return 0
+	}
+	frag := s.Fragments[0]
+	nrAdded := 0
+	for _, e := range events {
+		if e.PresentationTime < startTime || e.PresentationTime >= endTime {
+			continue
+		}
+		frag.AddEmsg(&EmsgBox{
+			Version:          1,
+			TimeScale:        timescale,
+			PresentationTime: e.PresentationTime,
+			EventDuration:    e.EventDuration,
+			ID:               e.ID,
+			SchemeIDURI:      e.SchemeIDURI,
+			Value:            e.Value,
+			MessageData:      e.MessageData,
+		})
+		nrAdded++
+	}
+	return nrAdded
+}
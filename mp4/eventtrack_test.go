@@ -0,0 +1,139 @@
+package mp4_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestEventMessageTrackRoundTrip(t *testing.T) {
+	const timescale = 1000
+	const segDur = 2000
+	// Two media segments with inband events. The second segment repeats the first event.
+	first := &mp4.EmsgBox{Version: 1, TimeScale: 90000, PresentationTime: 90000, EventDuration: 270000,
+		ID: 1, SchemeIDURI: "urn:scte:scte35:2013:bin", MessageData: []byte{0xfc, 0x30}}
+	second := &mp4.EmsgBox{Version: 0, TimeScale: 1000, PresentationTimeDelta: 500, EventDuration: 0,
+		ID: 2, SchemeIDURI: "urn:example", Value: "1"}
+	var segs []*mp4.MediaSegment
+	for i := 0; i < 2; i++ {
+		seg := mp4.NewMediaSegment()
+		frag, err := mp4.CreateFragment(uint32(i+1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frag.AddFullSample(mp4.FullSample{Sample: mp4.Sample{Flags: mp4.SyncSampleFlags, Dur: segDur, Size: 1},
+			DecodeTime: uint64(i * segDur), Data: []byte{0}})
+		frag.AddEmsg(first)
+		if i == 1 {
+			frag.AddEmsg(second)
+		}
+		seg.AddFragment(frag)
+		segs = append(segs, seg)
+	}
+
+	var events []mp4.EventMessage
+	for i, seg := range segs {
+		events = mp4.MergeEventMessages(events, seg.EventMessages(timescale, uint64(i*segDur)))
+	}
+	wantedEvents := []mp4.EventMessage{
+		{PresentationTime: 1000, EventDuration: 3000, ID: 1, SchemeIDURI: "urn:scte:scte35:2013:bin",
+			MessageData: []byte{0xfc, 0x30}},
+		{PresentationTime: 2500, EventDuration: 0, ID: 2, SchemeIDURI: "urn:example", Value: "1"},
+	}
+	if diff := deep.Equal(events, wantedEvents); diff != nil {
+		t.Fatal(diff)
+	}
+
+	init := mp4.CreateEmptyInit()
+	trak := init.AddEmptyTrack(timescale, "evte", "und")
+	err := trak.SetEvteDescriptor([]mp4.SilbEntry{{SchemeIdURI: "urn:scte:scte35:2013:bin", AtLeastOneFlag: true}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trak.Mdia.Hdlr.HandlerType != "meta" || trak.Mdia.Minf.Stbl.Stsd.Evte == nil {
+		t.Error("event message track not set up with meta handler and evte")
+	}
+	initBuf := bytes.Buffer{}
+	if err := init.Encode(&initBuf); err != nil {
+		t.Fatal(err)
+	}
+	decInit, err := mp4.DecodeFile(&initBuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evte := decInit.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.Evte; evte == nil || evte.Silb == nil {
+		t.Error("evte with silb not found in decoded init segment")
+	}
+
+	wantedSampleDurs := [][]uint32{{1000, 1000}, {500, 1500}}
+	var gotEvents []mp4.EventMessage
+	for i := range segs {
+		start := uint64(i * segDur)
+		frag, err := mp4.CreateEventMessageFragment(uint32(i+1), trak.Tkhd.TrackID, events, start, start+segDur)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var durs []uint32
+		for _, s := range frag.Moof.Traf.Trun.Samples {
+			durs = append(durs, s.Dur)
+		}
+		if diff := deep.Equal(durs, wantedSampleDurs[i]); diff != nil {
+			t.Errorf("segment %d sample durations: %v", i, diff)
+		}
+		buf := bytes.Buffer{}
+		if err := frag.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		f, err := mp4.DecodeFile(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fragEvents, err := f.Segments[0].Fragments[0].EventMessages(init.Moov.Mvex.Trex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotEvents = mp4.MergeEventMessages(gotEvents, fragEvents)
+	}
+	if diff := deep.Equal(gotEvents, wantedEvents); diff != nil {
+		t.Error(diff)
+	}
+
+	// Re-inject as emsg version 1 into new media segments
+	for i := range segs {
+		seg := mp4.NewMediaSegment()
+		frag, _ := mp4.CreateFragment(uint32(i+1), 1)
+		seg.AddFragment(frag)
+		start := uint64(i * segDur)
+		if n := seg.InsertEventMessages(gotEvents, timescale, start, start+segDur); n != 1 {
+			t.Errorf("segment %d: inserted %d emsg instead of 1", i, n)
+		}
+		emsg := frag.Children[0].(*mp4.EmsgBox)
+		if emsg.Version != 1 || emsg.ID != uint32(i+1) || emsg.PresentationTime != gotEvents[i].PresentationTime {
+			t.Errorf("segment %d: bad emsg %+v", i, emsg)
+		}
+	}
+}
+
+func TestEventMessageSampleUnknownDuration(t *testing.T) {
+	events := []mp4.EventMessage{{PresentationTime: 100, EventDuration: mp4.UnknownEventDuration, ID: 7, SchemeIDURI: "urn:x"}}
+	samples, err := mp4.EventMessageSamples(events, 200, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 1 {
+		t.Fatalf("got %d samples instead of 1", len(samples))
+	}
+	got, err := mp4.DecodeEventMessageSample(samples[0].Data, samples[0].DecodeTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(got, events); diff != nil {
+		t.Error(diff)
+	}
+	if _, err := mp4.EventMessageSamples(events, 300, 300); err == nil {
+		t.Error("expected error for empty interval")
+	}
+}
@@ -0,0 +1,94 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// EyesBox - Stereo View Box (eyes)
+//
+// Contained in : Video Extended Usage Box (vexu)
+//
+// Defined in Apple HEVC Stereo Video - ISOBMFF Extensions
+type EyesBox struct {
+	Must     *MustBox
+	Stri     *StriBox
+	Hero     *HeroBox
+	Cams     *CamsBox
+	Cmfy     *CmfyBox
+	Children []Box
+}
+
+// AddChild - add a child box
+func (b *EyesBox) AddChild(child Box) {
+	switch box := child.(type) {
+	case *MustBox:
+		b.Must = box
+	case *StriBox:
+		b.Stri = box
+	case *HeroBox:
+		b.Hero = box
+	case *CamsBox:
+		b.Cams = box
+	case *CmfyBox:
+		b.Cmfy = box
+	}
+	b.Children = append(b.Children, child)
+}
+
+// DecodeEyes - box-specific decode
+func DecodeEyes(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.Size, r)
+	if err != nil {
+		return nil, err
+	}
+	b := &EyesBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// DecodeEyesSR - box-specific decode
+func DecodeEyesSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.Size, sr)
+	if err != nil {
+		return nil, err
+	}
+	b := &EyesBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// Type - return box type
+func (b *EyesBox) Type() string {
+	return "eyes"
+}
+
+// Size - return calculated size
+func (b *EyesBox) Size() uint64 {
+	return containerSize(b.Children)
+}
+
+// GetChildren - list of child boxes
+func (b *EyesBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write eyes container to w
+func (b *EyesBox) Encode(w io.Writer) error {
+	return EncodeContainer(b, w)
+}
+
+// EncodeSW - write eyes container via sw
+func (b *EyesBox) EncodeSW(sw bits.SliceWriter) error {
+	return EncodeContainerSW(b, sw)
+}
+
+// Info - write box-specific information
+func (b *EyesBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
+}
@@ -2,6 +2,7 @@ package mp4
 
 // ffmpeg boxes according to https://kdenlive.org/en/project/adding-meta-data-to-mp4-video
 import (
+	"encoding/hex"
 	"io"
 
 	"github.com/Eyevinn/mp4ff/bits"
@@ -12,11 +13,37 @@ type CTooBox struct {
 	Children []Box
 }
 
-// DataBox - data box used by ffmpeg for providing information.
+// DataBox - data box with the value of an iTunes-style metadata item in ilst.
+//
+// DataType is the well-known type (e.g. DataTypeUTF8 or DataTypeJPEG) in the lower 24 bits,
+// with the type set indicator in the upper 8 bits. Locale is zero for the default locale.
+//
+// DataType is written as given, so a zero DataType means binary data.
+// Earlier versions always wrote the UTF-8 type. To migrate code like &DataBox{Data: []byte(text)},
+// use CreateTextDataBox(text) or set DataType to DataTypeUTF8.
 type DataBox struct {
-	Data []byte
+	DataType uint32
+	Locale   uint32
+	Data     []byte
 }
 
+// CreateTextDataBox creates a data box with UTF-8 text and the default locale.
+func CreateTextDataBox(text string) *DataBox {
+	return &DataBox{DataType: DataTypeUTF8, Data: []byte(text)}
+}
+
+// Well-known data types of DataBox
+const (
+	DataTypeBinary      = 0
+	DataTypeUTF8        = 1
+	DataTypeUTF16       = 2
+	DataTypeJPEG        = 13
+	DataTypePNG         = 14
+	DataTypeBESignedInt = 21
+	DataTypeBEUnsignInt = 22
+	DataTypeBMP         = 27
+)
+
 // DecodeData - decode Data (from mov_write_string_data_tag in movenc.c in ffmpeg)
 func DecodeData(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
@@ -29,9 +56,11 @@ func DecodeData(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 
 // DecodeDataSR - decode Data (from mov_write_string_data_tag in movenc.c in ffmpeg)
 func DecodeDataSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	_ = sr.ReadUint32() // Should be 1
-	_ = sr.ReadUint32() // Should be 0
-	return &DataBox{sr.ReadBytes(hdr.payloadLen() - 8)}, sr.AccError()
+	b := DataBox{}
+	b.DataType = sr.ReadUint32()
+	b.Locale = sr.ReadUint32()
+	b.Data = sr.ReadBytes(hdr.payloadLen() - 8)
+	return &b, sr.AccError()
 }
 
 // Type - box type
@@ -61,8 +90,8 @@ func (b *DataBox) EncodeSW(sw bits.SliceWriter) error {
 	if err != nil {
 		return err
 	}
-	sw.WriteUint32(0x00000001)
-	sw.WriteUint32(0x00000000)
+	sw.WriteUint32(b.DataType)
+	sw.WriteUint32(b.Locale)
 	sw.WriteBytes(b.Data)
 	return sw.AccError()
 }
@@ -70,6 +99,17 @@ func (b *DataBox) EncodeSW(sw bits.SliceWriter) error {
 // Info - box-specific Info
 func (b *DataBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	bd := newInfoDumper(w, indent, b, -1, 0)
-	bd.write(" - data: %s", string(b.Data))
+	bd.write(" - dataType: %d", b.DataType)
+	if b.Locale != 0 {
+		bd.write(" - locale: %d", b.Locale)
+	}
+	switch b.DataType & 0xffffff {
+	case DataTypeUTF8, DataTypeUTF16:
+		bd.write(" - data: %s", dataBoxText(b))
+	case DataTypeJPEG, DataTypePNG, DataTypeBMP:
+		bd.write(" - data: %d bytes image", len(b.Data))
+	default:
+		bd.write(" - data: %s", hex.EncodeToString(b.Data))
+	}
 	return bd.err
 }
@@ -36,4 +36,14 @@ func TestEncodeData(t *testing.T) {
 	data := []byte("dummy")
 	db := &mp4.DataBox{Data: data}
 	boxDiffAfterEncodeAndDecode(t, db)
+	db = mp4.CreateTextDataBox("dummy")
+	boxDiffAfterEncodeAndDecode(t, db)
+	buf := bytes.Buffer{}
+	if err := db.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	wanted := append([]byte{0, 0, 0, 21, 'd', 'a', 't', 'a', 0, 0, 0, 1, 0, 0, 0, 0}, data...)
+	if !bytes.Equal(buf.Bytes(), wanted) {
+		t.Errorf("got %x instead of %x", buf.Bytes(), wanted)
+	}
 }
@@ -3,6 +3,7 @@ package mp4
 import (
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"strings"
 
@@ -28,6 +29,7 @@ type File struct {
 	Ftyp         *FtypBox
 	Moov         *MoovBox
 	Mdat         *MdatBox        // mdat box for non-fragmented files. Extra empty boxes allowed.
+	Meta         *MetaBox        // Top-level meta box, like in HEIF image files
 	Init         *InitSegment    // Init data (ftyp + moov for fragmented file)
 	Sidx         *SidxBox        // The first sidx box for a DASH OnDemand file
 	Sidxs        []*SidxBox      // All sidx boxes for a DASH OnDemand file
@@ -209,26 +211,9 @@ LoopBoxes:
 				}
 			}
 		case "moof":
-			moof := box.(*MoofBox)
-			for _, traf := range moof.Trafs {
-				if ok, parsed := traf.ContainsSencBox(); ok && !parsed {
-					isEncrypted := true
-					defaultIVSize := byte(0) // Should get this from tenc in sinf
-					if f.Moov != nil {
-						trackID := traf.Tfhd.TrackID
-						isEncrypted = f.Moov.IsEncrypted(trackID)
-						sinf := f.Moov.GetSinf(trackID)
-						if sinf != nil && sinf.Schi != nil && sinf.Schi.Tenc != nil {
-							defaultIVSize = sinf.Schi.Tenc.DefaultPerSampleIVSize
-						}
-					}
-					if isEncrypted { // Don't do if encryption boxes still remain, but are not
-						err = traf.ParseReadSenc(defaultIVSize, moof.StartPos)
-						if err != nil {
-							return f, err
-						}
-					}
-				}
+			err = parseReadSencBoxes(f.Moov, box.(*MoofBox))
+			if err != nil {
+				return f, err
 			}
 		}
 		f.AddChild(box, boxStartPos)
@@ -239,6 +224,32 @@ LoopBoxes:
 	return f, nil
 }
 
+// parseReadSencBoxes parses senc boxes in moof that have been read but not parsed.
+// The per-sample IV size is taken from the tenc box in moov if available.
+func parseReadSencBoxes(moov *MoovBox, moof *MoofBox) error {
+	for _, traf := range moof.Trafs {
+		if ok, parsed := traf.ContainsSencBox(); ok && !parsed {
+			isEncrypted := true
+			defaultIVSize := byte(0) // Should get this from tenc in sinf
+			if moov != nil {
+				trackID := traf.Tfhd.TrackID
+				isEncrypted = moov.IsEncrypted(trackID)
+				sinf := moov.GetSinf(trackID)
+				if sinf != nil && sinf.Schi != nil && sinf.Schi.Tenc != nil {
+					defaultIVSize = sinf.Schi.Tenc.DefaultPerSampleIVSize
+				}
+			}
+			if isEncrypted { // Don't do if encryption boxes still remain, but are not
+				err := traf.ParseReadSenc(defaultIVSize, moof.StartPos)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // Size - total size of all boxes
 func (f *File) Size() uint64 {
 	var totSize uint64 = 0
@@ -248,6 +259,17 @@ func (f *File) Size() uint64 {
 	return totSize
 }
 
+// hasNoSamples returns true if the first track of moov has no samples.
+// Without a track with an stts box, it returns true if there is an mvex box.
+func hasNoSamples(moov *MoovBox) bool {
+	trak := moov.Trak
+	if trak == nil || trak.Mdia == nil || trak.Mdia.Minf == nil || trak.Mdia.Minf.Stbl == nil ||
+		trak.Mdia.Minf.Stbl.Stts == nil {
+		return moov.Mvex != nil
+	}
+	return len(trak.Mdia.Minf.Stbl.Stts.SampleCount) == 0
+}
+
 // AddChild - add child with start position
 func (f *File) AddChild(child Box, boxStartPos uint64) {
 	lastChildType := ""
@@ -259,7 +281,7 @@ func (f *File) AddChild(child Box, boxStartPos uint64) {
 		f.Ftyp = box
 	case *MoovBox:
 		f.Moov = box
-		if len(f.Moov.Trak.Mdia.Minf.Stbl.Stts.SampleCount) == 0 {
+		if hasNoSamples(box) {
 			f.isFragmented = true
 			f.Init = NewMP4Init()
 			f.Init.AddChild(f.Ftyp)
@@ -304,6 +326,15 @@ func (f *File) AddChild(child Box, boxStartPos uint64) {
 		}
 		frag := lastSeg.LastFragment()
 		frag.AddChild(box)
+	case *PrftBox:
+		// prft box belongs to the following moof box, so it starts a new fragment if needed.
+		f.startSegmentIfNeeded(box, boxStartPos)
+		currSeg := f.LastSegment()
+		lastFrag := currSeg.LastFragment()
+		if lastFrag == nil || lastFrag.Moof != nil {
+			currSeg.AddFragment(&Fragment{StartPos: boxStartPos})
+		}
+		currSeg.LastFragment().AddChild(box)
 	case *MoofBox:
 		f.isFragmented = true
 		moof := box
@@ -327,6 +358,8 @@ func (f *File) AddChild(child Box, boxStartPos uint64) {
 		}
 	case *MfraBox:
 		f.Mfra = box
+	case *MetaBox:
+		f.Meta = box
 	}
 	f.Children = append(f.Children, child)
 }
@@ -354,7 +387,7 @@ func (f *File) startSegmentIfNeeded(b Box, boxStartPos uint64) {
 			}
 		}
 	case f.tfra != nil:
-		if boxStartPos == uint64(f.tfra.Entries[segIdx].MoofOffset) {
+		if segIdx < len(f.tfra.Entries) && boxStartPos == f.tfra.Entries[segIdx].MoofOffset {
 			segStart = true
 		}
 	case (f.fileDecFlags & DecStartOnMoof) != 0:
@@ -436,6 +469,11 @@ func (f *File) AddSidx(sidx *SidxBox) {
 // Fragmented files are encoded based on InitSegment and MediaSegments, unless EncModeBoxTree is set.
 func (f *File) Encode(w io.Writer) error {
 	if f.isFragmented {
+		if f.Mfra != nil {
+			if err := f.updateMfraOffsets(); err != nil {
+				return err
+			}
+		}
 		switch f.FragEncMode {
 		case EncModeSegment:
 			if f.Init != nil {
@@ -493,6 +531,11 @@ func (f *File) Encode(w io.Writer) error {
 // Fragmented files are encoded based on InitSegment and MediaSegments, unless EncModeBoxTree is set.
 func (f *File) EncodeSW(sw bits.SliceWriter) error {
 	if f.isFragmented {
+		if f.Mfra != nil {
+			if err := f.updateMfraOffsets(); err != nil {
+				return err
+			}
+		}
 		switch f.FragEncMode {
 		case EncModeSegment:
 			if f.Init != nil {
@@ -518,6 +561,12 @@ func (f *File) EncodeSW(sw bits.SliceWriter) error {
 					return err
 				}
 			}
+			if f.Mfra != nil {
+				err := f.Mfra.EncodeSW(sw)
+				if err != nil {
+					return err
+				}
+			}
 		case EncModeBoxTree:
 			for _, b := range f.Children {
 				err := b.EncodeSW(sw)
@@ -860,9 +909,246 @@ func insertSidx(inFile *File, segDatas []segData, sidx *SidxBox) error {
 	return nil
 }
 
+// UpdateMfra creates an mfra box with one tfra box per track and sets it as f.Mfra.
+// Each tfra entry gives the time, moof offset, and traf, trun, and sample number of the first
+// sync sample in a fragment. The time is the presentation time without edit list.
+// The moof offsets are set again by Encode and EncodeSW, so changes that move the moof boxes
+// (like UpdateSidx) may also be done after UpdateMfra. Entries for removed moof boxes are then dropped.
+// The offsets of a decoded mfra box are not changed. The mfra box is written last.
+func (f *File) UpdateMfra() error {
+	if !f.IsFragmented() {
+		return fmt.Errorf("input file is not fragmented")
+	}
+	if f.Init == nil {
+		return fmt.Errorf("input file does not have an init segment")
+	}
+	if err := f.optimizeTrafs(); err != nil {
+		return err
+	}
+	moofOffsets := f.moofOffsets()
+	mfra := &MfraBox{}
+	for _, trak := range f.Init.Moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		trex, _ := f.Init.Moov.Mvex.GetTrex(trackID)
+		tfra := &TfraBox{TrackID: trackID}
+		var decodeTime uint64
+		for _, seg := range f.Segments {
+			for _, frag := range seg.Fragments {
+				if frag.Moof == nil {
+					continue
+				}
+				moofOffset, ok := moofOffsets[frag.Moof]
+				if !ok {
+					return fmt.Errorf("no position for moof box with sequence number %d", frag.Moof.Mfhd.SequenceNumber)
+				}
+				for trafIdx, traf := range frag.Moof.Trafs {
+					if traf.Tfhd.TrackID != trackID {
+						continue
+					}
+					if traf.Tfdt != nil {
+						decodeTime = traf.Tfdt.BaseMediaDecodeTime()
+					}
+					entryFound := false
+					for trunIdx, trun := range traf.Truns {
+						trun.AddSampleDefaultValues(traf.Tfhd, trex)
+						for sampleIdx, s := range trun.Samples {
+							if !entryFound && !DecodeSampleFlags(s.Flags).SampleIsNonSync {
+								presTime := int64(decodeTime) + int64(s.CompositionTimeOffset)
+								if presTime < 0 {
+									presTime = 0
+								}
+								tfra.moofs = append(tfra.moofs, frag.Moof)
+								tfra.Entries = append(tfra.Entries, TfraEntry{
+									Time:         uint64(presTime),
+									MoofOffset:   moofOffset,
+									TrafNumber:   uint32(trafIdx + 1),
+									TrunNumber:   uint32(trunIdx + 1),
+									SampleNumber: uint32(sampleIdx + 1),
+								})
+								entryFound = true
+							}
+							decodeTime += uint64(s.Dur)
+						}
+					}
+				}
+			}
+		}
+		tfra.setMinimalSizes()
+		err := mfra.AddChild(tfra)
+		if err != nil {
+			return err
+		}
+	}
+	mfro := &MfroBox{}
+	err := mfra.AddChild(mfro)
+	if err != nil {
+		return err
+	}
+	mfro.ParentSize = uint32(mfra.Size())
+
+	mfraIdx := len(f.Children)
+	for i, ch := range f.Children {
+		if ch == f.Mfra {
+			mfraIdx = i
+			break
+		}
+	}
+	if mfraIdx == len(f.Children) {
+		f.Children = append(f.Children, mfra)
+	} else {
+		f.Children[mfraIdx] = mfra
+	}
+	f.Mfra = mfra
+	return nil
+}
+
+// optimizeTrafs optimizes the traf boxes like Encode does for OptimizeTrun in EncModeSegment.
+// This is needed to get the right moof sizes before encoding.
+func (f *File) optimizeTrafs() error {
+	if f.FragEncMode != EncModeSegment || f.EncOptimize&OptimizeTrun == 0 {
+		return nil
+	}
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			if frag.Moof == nil || frag.Moof.Traf == nil {
+				continue
+			}
+			if err := frag.Moof.Traf.OptimizeTfhdTrun(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// updateMfraOffsets sets the moof offsets in tfra boxes made by UpdateMfra to the positions of
+// the moof boxes when encoded. Entries for moof boxes no longer in the file are removed.
+// Other tfra boxes, like decoded ones, are left unchanged.
+func (f *File) updateMfraOffsets() error {
+	if err := f.optimizeTrafs(); err != nil {
+		return err
+	}
+	moofOffsets := f.moofOffsets()
+	for _, tfra := range f.Mfra.Tfras {
+		if len(tfra.moofs) != len(tfra.Entries) {
+			continue
+		}
+		entries := tfra.Entries[:0]
+		moofs := tfra.moofs[:0]
+		for i, e := range tfra.Entries {
+			offset, ok := moofOffsets[tfra.moofs[i]]
+			if !ok {
+				continue
+			}
+			e.MoofOffset = offset
+			if offset > math.MaxUint32 {
+				tfra.Version = 1
+			}
+			entries = append(entries, e)
+			moofs = append(moofs, tfra.moofs[i])
+		}
+		tfra.Entries, tfra.moofs = entries, moofs
+	}
+	if f.Mfra.Mfro != nil {
+		f.Mfra.Mfro.ParentSize = uint32(f.Mfra.Size())
+	}
+	return nil
+}
+
+// moofOffsets returns the position of all moof boxes when encoded with the current FragEncMode.
+func (f *File) moofOffsets() map[*MoofBox]uint64 {
+	offsets := make(map[*MoofBox]uint64)
+	var pos uint64
+	if f.FragEncMode == EncModeBoxTree {
+		for _, b := range f.Children {
+			if moof, ok := b.(*MoofBox); ok {
+				offsets[moof] = pos
+			}
+			pos += b.Size()
+		}
+		return offsets
+	}
+	if f.Init != nil {
+		pos += f.Init.Size()
+	}
+	for _, sidx := range f.Sidxs {
+		pos += sidx.Size()
+	}
+	for _, seg := range f.Segments {
+		if seg.Styp != nil {
+			pos += seg.Styp.Size()
+		}
+		for _, sidx := range seg.Sidxs {
+			pos += sidx.Size()
+		}
+		for _, frag := range seg.Fragments {
+			for _, b := range frag.Children {
+				if moof, ok := b.(*MoofBox); ok {
+					offsets[moof] = pos
+				}
+				pos += b.Size()
+			}
+		}
+	}
+	return offsets
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
 	}
 	return b
 }
+
+// moovResized updates chunk offsets and mdat start positions in a progressive file
+// after the moov box has changed size from oldMoovSize. Data after the moov box is moved accordingly.
+func (f *File) moovResized(oldMoovSize uint64) error {
+	var moovStart uint64
+	for _, c := range f.Children {
+		if c == f.Moov {
+			break
+		}
+		moovStart += c.Size()
+	}
+	oldMoovEnd := moovStart + oldMoovSize
+	delta := int64(f.Moov.Size()) - int64(oldMoovSize)
+	if delta != 0 {
+		for _, trak := range f.Moov.Traks {
+			if err := shiftChunkOffsets(trak.Mdia.Minf.Stbl, oldMoovEnd, delta); err != nil {
+				return err
+			}
+		}
+	}
+	var pos uint64
+	for _, c := range f.Children {
+		if m, ok := c.(*MdatBox); ok {
+			m.StartPos = pos
+		}
+		pos += c.Size()
+	}
+	return nil
+}
+
+// shiftChunkOffsets adds delta to all chunk offsets at or after pos
+func shiftChunkOffsets(stbl *StblBox, pos uint64, delta int64) error {
+	if stbl.Stco != nil {
+		for i, offset := range stbl.Stco.ChunkOffset {
+			if uint64(offset) < pos {
+				continue
+			}
+			newOffset := int64(offset) + delta
+			if newOffset > math.MaxUint32 {
+				return fmt.Errorf("chunk offset %d too large for stco", newOffset)
+			}
+			stbl.Stco.ChunkOffset[i] = uint32(newOffset)
+		}
+	}
+	if stbl.Co64 != nil {
+		for i, offset := range stbl.Co64.ChunkOffset {
+			if offset >= pos {
+				stbl.Co64.ChunkOffset[i] = uint64(int64(offset) + delta)
+			}
+		}
+	}
+	return nil
+}
@@ -334,6 +334,135 @@ func TestUpdateSidx(t *testing.T) {
 	}
 }
 
+func TestUpdateMfra(t *testing.T) {
+	orig, err := mp4.ReadMP4File("testdata/bbb5s_aac.isma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantedEntries := orig.Mfra.Tfra.Entries
+	err = orig.UpdateMfra()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotEntries := orig.Mfra.Tfra.Entries
+	if len(gotEntries) != len(wantedEntries) {
+		t.Fatalf("got %d tfra entries instead of %d", len(gotEntries), len(wantedEntries))
+	}
+	for i := range wantedEntries {
+		if gotEntries[i] != wantedEntries[i] {
+			t.Errorf("tfra entry %d: got %+v instead of %+v", i, gotEntries[i], wantedEntries[i])
+		}
+	}
+
+	testCases := []struct {
+		desc     string
+		encMode  mp4.EncFragFileMode
+		optimize mp4.EncOptimize
+		dropSidx bool // remove sidx after UpdateMfra to move the moof boxes
+	}{
+		{"segment", mp4.EncModeSegment, mp4.OptimizeNone, false},
+		{"segment with trun optimization", mp4.EncModeSegment, mp4.OptimizeTrun, false},
+		{"box tree", mp4.EncModeBoxTree, mp4.OptimizeNone, false},
+		{"sidx removed after UpdateMfra", mp4.EncModeSegment, mp4.OptimizeNone, true},
+	}
+	for _, tc := range testCases {
+		f, err := mp4.ReadMP4File("testdata/prog_8s_enc_dashinit.mp4")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.FragEncMode = tc.encMode
+		f.EncOptimize = tc.optimize
+		err = f.UpdateMfra()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.dropSidx {
+			f.Sidx, f.Sidxs = nil, nil
+		}
+		buf := bytes.Buffer{}
+		err = f.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sw := bits.NewFixedSliceWriter(buf.Len())
+		err = f.EncodeSW(sw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), sw.Bytes()) {
+			t.Errorf("%s: Encode and EncodeSW differ", tc.desc)
+		}
+		data := buf.Bytes()
+		decoded, err := mp4.DecodeFile(bytes.NewReader(data), mp4.WithDecodeFlags(mp4.DecISMFlag))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Mfra == nil || len(decoded.Mfra.Tfras) != len(f.Init.Moov.Traks) {
+			t.Fatalf("%s: expected one tfra per track", tc.desc)
+		}
+		for _, tfra := range decoded.Mfra.Tfras {
+			if len(tfra.Entries) != len(decoded.Segments) {
+				t.Errorf("%s: got %d tfra entries for track %d instead of %d", tc.desc, len(tfra.Entries), tfra.TrackID, len(decoded.Segments))
+			}
+			for _, e := range tfra.Entries {
+				if string(data[e.MoofOffset+4:e.MoofOffset+8]) != "moof" {
+					t.Errorf("%s: no moof at offset %d", tc.desc, e.MoofOffset)
+				}
+			}
+			sp, err := mp4.FindSeekPoint(bytes.NewReader(data), tfra.TrackID, tfra.Entries[1].Time)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sp.Method != mp4.SeekMfra || sp.MoofOffset != tfra.Entries[1].MoofOffset {
+				t.Errorf("%s: got seek point %+v", tc.desc, sp)
+			}
+		}
+	}
+
+	// Entries made by UpdateMfra are dropped for removed moof boxes, but decoded entries are kept
+	f, err := mp4.ReadMP4File("testdata/bbb5s_aac.isma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = f.UpdateMfra(); err != nil {
+		t.Fatal(err)
+	}
+	nrEntries := len(f.Mfra.Tfra.Entries)
+	buf := bytes.Buffer{}
+	if err = f.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := mp4.DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Segments = f.Segments[:len(f.Segments)-1]
+	if err = f.Encode(&bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Mfra.Tfra.Entries) != nrEntries-1 {
+		t.Errorf("got %d tfra entries after removing a segment, wanted %d", len(f.Mfra.Tfra.Entries), nrEntries-1)
+	}
+	decodedEntries := append([]mp4.TfraEntry(nil), decoded.Mfra.Tfra.Entries...)
+	decoded.Segments = decoded.Segments[:len(decoded.Segments)-1]
+	if err = decoded.Encode(&bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range decoded.Mfra.Tfra.Entries {
+		if e != decodedEntries[i] {
+			t.Errorf("decoded tfra entry %d changed by Encode to %+v", i, e)
+		}
+	}
+
+	prog, err := mp4.ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = prog.UpdateMfra(); err == nil {
+		t.Error("expected error for progressive file")
+	}
+}
+
 func TestEmptyMdat(t *testing.T) {
 	testCases := []struct {
 		desc          string
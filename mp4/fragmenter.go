@@ -0,0 +1,574 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+)
+
+// FragmenterOptions configures the conversion of a progressive file to init and media segments.
+type FragmenterOptions struct {
+	// SegmentDurationMS is the target segment duration in milliseconds.
+	// Segments start at sync samples of the reference track, so actual durations vary.
+	SegmentDurationMS uint32
+	// Muxed results in one init segment and media segments including all tracks.
+	// Otherwise, there is one init segment and one sequence of media segments per track.
+	Muxed bool
+	// RefTrackID is the input track whose sync samples define the segment boundaries.
+	// If 0, the first video track is used, or the first track if there is no video track.
+	RefTrackID uint32
+	// PrftClock, if not nil, gives the wall-clock time of a prft box inserted before each moof.
+	// The prft box refers to the reference track if present in the fragment, and otherwise to the first track.
+	PrftClock PrftClock
+	// PrftFlags is the flags value of the prft boxes, e.g. PrftTimeEncoderOutput.
+	PrftFlags uint32
+}
+
+// ProgressiveFragmenter converts a progressive File into init and media segments.
+//
+// Any number of tracks is supported. Media segments are generated one at a time by NextSegments,
+// so if the file was decoded with DecModeLazyMdat, only the sample data of the current segment
+// is read into memory.
+type ProgressiveFragmenter struct {
+	inFile       *File
+	rs           io.ReadSeeker
+	muxed        bool
+	tracks       []*fragmenterTrack
+	inits        []*InitSegment
+	refTimescale uint32
+	segStarts    []uint64 // segment start times in reference track timescale
+	nextSegIdx   int
+	refTrack     *fragmenterTrack
+	prftClock    PrftClock
+	prftFlags    uint32
+}
+
+// fragmenterTrack keeps track of one input track and its output.
+type fragmenterTrack struct {
+	inTrak      *TrakBox
+	outTrackID  uint32
+	segStartNrs []uint32 // first sample number for each segment
+	stts        sttsCursor
+}
+
+// sttsCursor provides decode times and durations for consecutive samples
+// without scanning the stts table from the start for every sample.
+type sttsCursor struct {
+	stts     *SttsBox
+	sampleNr uint32 // next sample number (one-based)
+	entryIdx int
+	left     uint32 // samples left in current entry
+	decTime  uint64 // decode time of next sample
+}
+
+func newSttsCursor(stts *SttsBox) sttsCursor {
+	c := sttsCursor{stts: stts, sampleNr: 1}
+	if len(stts.SampleCount) > 0 {
+		c.left = stts.SampleCount[0]
+	}
+	return c
+}
+
+// next returns decode time and duration of the next sample.
+func (c *sttsCursor) next() (decTime uint64, dur uint32) {
+	for c.left == 0 && c.entryIdx < len(c.stts.SampleCount)-1 {
+		c.entryIdx++
+		c.left = c.stts.SampleCount[c.entryIdx]
+	}
+	if len(c.stts.SampleTimeDelta) > 0 {
+		dur = c.stts.SampleTimeDelta[c.entryIdx]
+	}
+	decTime = c.decTime
+	if c.left > 0 {
+		c.left--
+	}
+	c.decTime += uint64(dur)
+	c.sampleNr++
+	return decTime, dur
+}
+
+// seek positions the cursor at (one-based) sampleNr. Moving backwards restarts from the beginning.
+func (c *sttsCursor) seek(sampleNr uint32) {
+	if sampleNr < c.sampleNr {
+		*c = newSttsCursor(c.stts)
+	}
+	for c.sampleNr < sampleNr {
+		c.next()
+	}
+}
+
+// NewProgressiveFragmenter creates a fragmenter for the progressive file f.
+// rs is needed to read sample data if f was decoded with DecModeLazyMdat.
+func (f *File) NewProgressiveFragmenter(rs io.ReadSeeker, opts FragmenterOptions) (*ProgressiveFragmenter, error) {
+	if f.isFragmented {
+		return nil, fmt.Errorf("input file is already fragmented")
+	}
+	if f.Moov == nil || len(f.Moov.Traks) == 0 {
+		return nil, fmt.Errorf("no tracks in input file")
+	}
+	if f.Mdat == nil {
+		return nil, fmt.Errorf("no mdat in input file")
+	}
+	if f.Mdat.IsLazy() && rs == nil {
+		return nil, fmt.Errorf("no ReadSeeker for lazy mdat")
+	}
+	if opts.SegmentDurationMS == 0 {
+		return nil, fmt.Errorf("segment duration must be positive")
+	}
+	pf := &ProgressiveFragmenter{inFile: f, rs: rs, muxed: opts.Muxed, prftClock: opts.PrftClock, prftFlags: opts.PrftFlags}
+	var refTrack *fragmenterTrack
+	for _, trak := range f.Moov.Traks {
+		tr := &fragmenterTrack{inTrak: trak, stts: newSttsCursor(trak.Mdia.Minf.Stbl.Stts)}
+		pf.tracks = append(pf.tracks, tr)
+		if opts.RefTrackID != 0 && trak.Tkhd.TrackID == opts.RefTrackID {
+			refTrack = tr
+		}
+	}
+	if refTrack == nil {
+		if opts.RefTrackID != 0 {
+			return nil, fmt.Errorf("reference track %d not found", opts.RefTrackID)
+		}
+		refTrack = pf.tracks[0]
+		for _, tr := range pf.tracks {
+			if tr.inTrak.Mdia.Hdlr.HandlerType == "vide" {
+				refTrack = tr
+				break
+			}
+		}
+	}
+	pf.refTrack = refTrack
+	pf.findSegmentStarts(refTrack, opts.SegmentDurationMS)
+	for _, tr := range pf.tracks {
+		if tr == refTrack {
+			continue
+		}
+		tr.segStartNrs = pf.segStartNrsForTrack(tr)
+	}
+	err := pf.createInitSegments()
+	if err != nil {
+		return nil, err
+	}
+	return pf, nil
+}
+
+// findSegmentStarts finds sync samples in the reference track that start segments.
+// The segment start times are presentation times given by the edit list of the reference track.
+func (pf *ProgressiveFragmenter) findSegmentStarts(refTrack *fragmenterTrack, segDurMS uint32) {
+	stbl := refTrack.inTrak.Mdia.Minf.Stbl
+	pf.refTimescale = refTrack.inTrak.Mdia.Mdhd.Timescale
+	startOffset := refTrack.inTrak.PresentationTimeline(pf.inFile.Moov.Mvhd.Timescale).StartOffset()
+	segStep := uint64(segDurMS) * uint64(pf.refTimescale) / 1000
+	if segStep == 0 {
+		segStep = 1
+	}
+	nrSamples := stbl.Stsz.GetNrSamples()
+	nextStart := uint64(0)
+	c := newSttsCursor(stbl.Stts)
+	for nr := uint32(1); nr <= nrSamples; nr++ {
+		decTime, _ := c.next()
+		if stbl.Stss != nil && !stbl.Stss.IsSyncSample(nr) {
+			continue
+		}
+		presTime := int64(decTime) - startOffset
+		if stbl.Ctts != nil {
+			presTime += int64(stbl.Ctts.GetCompositionTimeOffset(nr))
+		}
+		if presTime < 0 || nr == 1 {
+			presTime = 0 // The first segment covers the start of all tracks
+		}
+		if nr == 1 || uint64(presTime) >= nextStart {
+			pf.segStarts = append(pf.segStarts, uint64(presTime))
+			refTrack.segStartNrs = append(refTrack.segStartNrs, nr)
+			for nextStart <= uint64(presTime) {
+				nextStart += segStep
+			}
+		}
+	}
+}
+
+// segStartNrsForTrack finds the first sample presented at or after each segment start time.
+func (pf *ProgressiveFragmenter) segStartNrsForTrack(tr *fragmenterTrack) []uint32 {
+	stbl := tr.inTrak.Mdia.Minf.Stbl
+	timescale := uint64(tr.inTrak.Mdia.Mdhd.Timescale)
+	startOffset := tr.inTrak.PresentationTimeline(pf.inFile.Moov.Mvhd.Timescale).StartOffset()
+	nrSamples := stbl.Stsz.GetNrSamples()
+	startNrs := make([]uint32, len(pf.segStarts))
+	c := newSttsCursor(stbl.Stts)
+	nr := uint32(1)
+	decTime, _ := c.next()
+	for i, segStart := range pf.segStarts {
+		startTime := int64(segStart*timescale/uint64(pf.refTimescale)) + startOffset
+		if startTime < 0 {
+			startTime = 0 // The segment starts during an initial empty edit
+		}
+		for nr <= nrSamples && int64(decTime) < startTime {
+			decTime, _ = c.next()
+			nr++
+		}
+		startNrs[i] = nr
+	}
+	return startNrs
+}
+
+// createInitSegments creates one init segment per track, or a single one if muxed.
+func (pf *ProgressiveFragmenter) createInitSegments() error {
+	var init *InitSegment
+	for _, tr := range pf.tracks {
+		if init == nil || !pf.muxed {
+			init = CreateEmptyInit()
+			init.Moov.Mvhd.Timescale = pf.inFile.Moov.Mvhd.Timescale
+			init.Moov.Mvex.AddChild(&MehdBox{FragmentDuration: int64(pf.inFile.Moov.Mvhd.Duration)})
+			pf.inits = append(pf.inits, init)
+		}
+		inMdia := tr.inTrak.Mdia
+		lang := inMdia.Mdhd.GetLanguage()
+		if inMdia.Elng != nil {
+			lang = inMdia.Elng.Language
+		}
+		outTrak := init.AddEmptyTrack(inMdia.Mdhd.Timescale, mediaTypeFromHandlerType(inMdia.Hdlr.HandlerType), lang)
+		outTrak.Mdia.Hdlr.HandlerType = inMdia.Hdlr.HandlerType
+		outTrak.Tkhd.Width = tr.inTrak.Tkhd.Width
+		outTrak.Tkhd.Height = tr.inTrak.Tkhd.Height
+		outTrak.Tkhd.Volume = tr.inTrak.Tkhd.Volume
+		if tr.inTrak.Edts != nil {
+			// Keep the presentation offsets. The edts box goes right after tkhd
+			edts := copyEdts(tr.inTrak.Edts)
+			outTrak.Edts = edts
+			outTrak.Children = append(outTrak.Children[:1], append([]Box{edts}, outTrak.Children[1:]...)...)
+		}
+		tr.outTrackID = outTrak.Tkhd.TrackID
+		outStsd := outTrak.Mdia.Minf.Stbl.Stsd
+		for _, se := range inMdia.Minf.Stbl.Stsd.Children {
+			outStsd.AddChild(se)
+		}
+	}
+	return nil
+}
+
+// copyEdts returns a copy of edts with its own edit lists, so that the output can be changed independently.
+func copyEdts(edts *EdtsBox) *EdtsBox {
+	out := &EdtsBox{}
+	for _, c := range edts.Children {
+		if elst, ok := c.(*ElstBox); ok {
+			e := *elst
+			e.Entries = append([]ElstEntry(nil), elst.Entries...)
+			out.Elst = append(out.Elst, &e)
+			c = &e
+		}
+		out.Children = append(out.Children, c)
+	}
+	return out
+}
+
+// mediaTypeFromHandlerType returns the mediaType used by CreateEmptyTrak for a handler type.
+func mediaTypeFromHandlerType(hdlrType string) string {
+	switch hdlrType {
+	case "vide":
+		return "video"
+	case "soun":
+		return "audio"
+	case "subt":
+		return "subtitle"
+	case "text":
+		return "text"
+	default:
+		return hdlrType
+	}
+}
+
+// InitSegments returns the init segments. There is one per track, or a single one if muxed.
+func (pf *ProgressiveFragmenter) InitSegments() []*InitSegment {
+	return pf.inits
+}
+
+// NrSegments returns the number of media segments per init segment.
+func (pf *ProgressiveFragmenter) NrSegments() int {
+	return len(pf.segStarts)
+}
+
+// NextSegments returns the next media segment for each init segment in the same order as InitSegments.
+// An entry is nil if a track has no samples in the segment.
+// io.EOF is returned after the last segment.
+func (pf *ProgressiveFragmenter) NextSegments() ([]*MediaSegment, error) {
+	if pf.nextSegIdx >= len(pf.segStarts) {
+		return nil, io.EOF
+	}
+	segIdx := pf.nextSegIdx
+	pf.nextSegIdx++
+	seqNr := uint32(segIdx + 1)
+	segs := make([]*MediaSegment, len(pf.inits))
+	frags := make([]*Fragment, len(pf.inits))
+	for i, tr := range pf.tracks {
+		outIdx := i
+		if pf.muxed {
+			outIdx = 0
+		}
+		startNr, endNr := pf.sampleInterval(tr, segIdx)
+		if startNr > endNr {
+			continue
+		}
+		samples, sdis, err := pf.getFullSamples(tr, startNr, endNr)
+		if err != nil {
+			return nil, fmt.Errorf("track %d: %w", tr.inTrak.Tkhd.TrackID, err)
+		}
+		frag := frags[outIdx]
+		if frag == nil {
+			trackIDs := []uint32{tr.outTrackID}
+			if pf.muxed {
+				trackIDs = trackIDs[:0]
+				for _, t := range pf.tracks[i:] {
+					trackIDs = append(trackIDs, t.outTrackID)
+				}
+			}
+			frag, err = CreateMultiTrackFragment(seqNr, trackIDs)
+			if err != nil {
+				return nil, err
+			}
+			frags[outIdx] = frag
+			segs[outIdx] = NewMediaSegment()
+			segs[outIdx].AddFragment(frag)
+		}
+		addTrackSamples(frag, tr.outTrackID, samples, sdis)
+	}
+	for outIdx, frag := range frags {
+		if frag == nil {
+			continue
+		}
+		if !removeEmptyTrafs(frag) {
+			frags[outIdx] = nil
+			segs[outIdx] = nil
+			continue
+		}
+		if pf.prftClock != nil {
+			if err := pf.addPrft(frag, outIdx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return segs, nil
+}
+
+// addPrft adds a prft box to the fragment of output outIdx. For muxed output, the prft box refers to
+// the reference track if present in frag, and otherwise to the first track.
+func (pf *ProgressiveFragmenter) addPrft(frag *Fragment, outIdx int) error {
+	tr := pf.tracks[outIdx]
+	if pf.muxed {
+		tr = pf.trackWithOutID(frag.Moof.Traf.Tfhd.TrackID)
+		for _, traf := range frag.Moof.Trafs {
+			if traf.Tfhd.TrackID == pf.refTrack.outTrackID {
+				tr = pf.refTrack
+				break
+			}
+		}
+	}
+	return frag.AddPrft(tr.outTrackID, tr.inTrak.Mdia.Mdhd.Timescale, pf.prftFlags, pf.prftClock)
+}
+
+// trackWithOutID returns the track with output track ID trackID
+func (pf *ProgressiveFragmenter) trackWithOutID(trackID uint32) *fragmenterTrack {
+	for _, tr := range pf.tracks {
+		if tr.outTrackID == trackID {
+			return tr
+		}
+	}
+	return nil
+}
+
+// addTrackSamples adds the samples of a track to its traf in frag.
+// A new traf is started each time the sample description index changes.
+func addTrackSamples(frag *Fragment, trackID uint32, samples []FullSample, sdis []uint32) {
+	moof := frag.Moof
+	var traf *TrafBox
+	var trun *TrunBox
+	for i, s := range samples {
+		if i == 0 || sdis[i] != sdis[i-1] {
+			if traf == nil {
+				for _, t := range moof.Trafs {
+					if t.Tfhd.TrackID == trackID {
+						traf = t
+						break
+					}
+				}
+			} else {
+				traf = insertTrafAfter(moof, traf)
+			}
+			if sdis[i] != traf.Tfhd.SampleDescriptionIndex {
+				traf.Tfhd.Flags |= TfhdSampleDescriptionIndexPresentFlag
+				traf.Tfhd.SampleDescriptionIndex = sdis[i]
+			}
+			traf.Tfdt.SetBaseMediaDecodeTime(s.DecodeTime)
+			trun = CreateTrun(frag.nextTrunNr)
+			frag.nextTrunNr++
+			_ = traf.AddChild(trun)
+		}
+		trun.AddSample(s.Sample)
+		frag.Mdat.AddSampleData(s.Data)
+	}
+}
+
+// insertTrafAfter inserts a new traf for the same track directly after prev in moof.
+func insertTrafAfter(moof *MoofBox, prev *TrafBox) *TrafBox {
+	traf := &TrafBox{}
+	_ = traf.AddChild(CreateTfhd(prev.Tfhd.TrackID))
+	_ = traf.AddChild(&TfdtBox{})
+	for i, t := range moof.Trafs {
+		if t == prev {
+			moof.Trafs = append(moof.Trafs[:i+1], append([]*TrafBox{traf}, moof.Trafs[i+1:]...)...)
+			break
+		}
+	}
+	for i, c := range moof.Children {
+		if c == prev {
+			moof.Children = append(moof.Children[:i+1], append([]Box{traf}, moof.Children[i+1:]...)...)
+			break
+		}
+	}
+	return traf
+}
+
+// removeEmptyTrafs removes traf boxes for tracks that got no samples.
+// It returns false if no traf is left.
+func removeEmptyTrafs(frag *Fragment) bool {
+	moof := frag.Moof
+	trafs := moof.Trafs[:0]
+	children := moof.Children[:0]
+	for _, c := range moof.Children {
+		if traf, ok := c.(*TrafBox); ok {
+			if len(traf.Truns) == 0 {
+				continue
+			}
+			trafs = append(trafs, traf)
+		}
+		children = append(children, c)
+	}
+	moof.Children = children
+	moof.Trafs = trafs
+	if len(trafs) == 0 {
+		moof.Traf = nil
+		return false
+	}
+	moof.Traf = trafs[0]
+	return true
+}
+
+// sampleInterval returns the sample numbers of segment segIdx for track tr.
+// startNr > endNr means that there are no samples.
+func (pf *ProgressiveFragmenter) sampleInterval(tr *fragmenterTrack, segIdx int) (startNr, endNr uint32) {
+	startNr = tr.segStartNrs[segIdx]
+	if segIdx == len(tr.segStartNrs)-1 {
+		endNr = tr.inTrak.Mdia.Minf.Stbl.Stsz.GetNrSamples()
+	} else {
+		endNr = tr.segStartNrs[segIdx+1] - 1
+	}
+	return startNr, endNr
+}
+
+// getFullSamples returns full samples with data for the interval [startNr, endNr],
+// and the sample description index of each sample.
+// With lazy mdat, the data is read chunk by chunk from pf.rs.
+func (pf *ProgressiveFragmenter) getFullSamples(tr *fragmenterTrack, startNr, endNr uint32) ([]FullSample, []uint32, error) {
+	trak := tr.inTrak
+	stbl := trak.Mdia.Minf.Stbl
+	ranges, err := trak.GetRangesForSampleInterval(startNr, endNr)
+	if err != nil {
+		return nil, nil, err
+	}
+	samples := make([]FullSample, 0, endNr-startNr+1)
+	sdis := make([]uint32, 0, endNr-startNr+1)
+	tr.stts.seek(startNr)
+	nr := startNr
+	for _, dr := range ranges {
+		chunkNr, _, err := stbl.Stsc.ChunkNrFromSampleNr(int(nr))
+		if err != nil {
+			return nil, nil, err
+		}
+		sdi := stbl.Stsc.GetSampleDescriptionID(chunkNr)
+		mdat := pf.mdatForRange(dr)
+		var data []byte
+		if mdat.IsLazy() {
+			data, err = mdat.ReadData(int64(dr.Offset), int64(dr.Size), pf.rs)
+			if err != nil {
+				return nil, nil, err
+			}
+		} else {
+			offsetInMdat := dr.Offset - mdat.PayloadAbsoluteOffset()
+			if dr.Offset < mdat.PayloadAbsoluteOffset() || offsetInMdat+dr.Size > uint64(len(mdat.Data)) {
+				return nil, nil, fmt.Errorf("sample data range %d-%d outside mdat", dr.Offset, dr.Offset+dr.Size)
+			}
+			data = mdat.Data[offsetInMdat : offsetInMdat+dr.Size]
+		}
+		pos := uint64(0)
+		for pos < dr.Size {
+			size := stbl.Stsz.GetSampleSize(int(nr))
+			if pos+uint64(size) > dr.Size {
+				return nil, nil, fmt.Errorf("sample %d extends beyond its chunk", nr)
+			}
+			decTime, dur := tr.stts.next()
+			var cto int32
+			if stbl.Ctts != nil {
+				cto = stbl.Ctts.GetCompositionTimeOffset(nr)
+			}
+			samples = append(samples, FullSample{
+				Sample: Sample{
+					Flags:                 createSampleFlagsFromProgressiveBoxes(stbl.Stss, stbl.Sdtp, nr),
+					Dur:                   dur,
+					Size:                  size,
+					CompositionTimeOffset: cto,
+				},
+				DecodeTime: decTime,
+				Data:       data[pos : pos+uint64(size)],
+			})
+			sdis = append(sdis, sdi)
+			pos += uint64(size)
+			nr++
+		}
+	}
+	return samples, sdis, nil
+}
+
+// mdatForRange returns the mdat box containing the data range, or the main mdat box if none does.
+func (pf *ProgressiveFragmenter) mdatForRange(dr DataRange) *MdatBox {
+	for _, c := range pf.inFile.Children {
+		m, ok := c.(*MdatBox)
+		if !ok {
+			continue
+		}
+		start := m.PayloadAbsoluteOffset()
+		if dr.Offset >= start && dr.Offset+dr.Size <= start+m.Size()-m.HeaderSize() {
+			return m
+		}
+	}
+	return pf.inFile.Mdat
+}
+
+// Fragmentify converts a progressive file into fragmented files, each consisting of an init segment
+// and media segments. There is one output file per track, or a single one if opts.Muxed is set.
+// rs is needed to read sample data if f was decoded with DecModeLazyMdat.
+// Since all segments are kept in memory, use NewProgressiveFragmenter for large files.
+func (f *File) Fragmentify(rs io.ReadSeeker, opts FragmenterOptions) ([]*File, error) {
+	pf, err := f.NewProgressiveFragmenter(rs, opts)
+	if err != nil {
+		return nil, err
+	}
+	outFiles := make([]*File, 0, len(pf.inits))
+	for _, init := range pf.inits {
+		of := NewFile()
+		of.AddChild(init.Ftyp, 0)
+		of.AddChild(init.Moov, init.Ftyp.Size())
+		outFiles = append(outFiles, of)
+	}
+	for {
+		segs, err := pf.NextSegments()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i, seg := range segs {
+			if seg != nil {
+				outFiles[i].AddMediaSegment(seg)
+			}
+		}
+	}
+	return outFiles, nil
+}
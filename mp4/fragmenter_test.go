@@ -0,0 +1,212 @@
+package mp4_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestFragmentify(t *testing.T) {
+	testCases := []struct {
+		name          string
+		muxed         bool
+		lazy          bool
+		wantedNrFiles int
+	}{
+		{name: "per track", muxed: false, lazy: false, wantedNrFiles: 2},
+		{name: "muxed", muxed: true, lazy: false, wantedNrFiles: 1},
+		{name: "muxed lazy", muxed: true, lazy: true, wantedNrFiles: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fd, err := os.Open("testdata/prog_8s.mp4")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer fd.Close()
+			mode := mp4.DecModeNormal
+			if tc.lazy {
+				mode = mp4.DecModeLazyMdat
+			}
+			inFile, err := mp4.DecodeFile(fd, mp4.WithDecodeMode(mode))
+			if err != nil {
+				t.Fatal(err)
+			}
+			outFiles, err := inFile.Fragmentify(fd, mp4.FragmenterOptions{SegmentDurationMS: 2000, Muxed: tc.muxed})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(outFiles) != tc.wantedNrFiles {
+				t.Fatalf("got %d output files instead of %d", len(outFiles), tc.wantedNrFiles)
+			}
+			// Check that all samples are there with the right data after encode and decode
+			for i, of := range outFiles {
+				if len(of.Segments) != 4 {
+					t.Errorf("got %d segments instead of 4", len(of.Segments))
+				}
+				buf := bytes.Buffer{}
+				err = of.Encode(&buf)
+				if err != nil {
+					t.Fatal(err)
+				}
+				decFile, err := mp4.DecodeFile(&buf)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, trak := range decFile.Init.Moov.Traks {
+					trackID := trak.Tkhd.TrackID
+					inTrak := inFile.Moov.Traks[trackID-1]
+					if !tc.muxed {
+						inTrak = inFile.Moov.Traks[i]
+					}
+					trex, _ := decFile.Init.Moov.Mvex.GetTrex(trackID)
+					sampleNr := uint32(1)
+					for _, seg := range decFile.Segments {
+						for _, frag := range seg.Fragments {
+							samples, err := frag.GetFullSamples(trex)
+							if err != nil {
+								t.Fatal(err)
+							}
+							if sampleNr == 1 && !samples[0].IsSync() && inTrak.Mdia.Hdlr.HandlerType == "vide" {
+								t.Error("first video sample is not sync")
+							}
+							for _, s := range samples {
+								ranges, err := inTrak.GetRangesForSampleInterval(sampleNr, sampleNr)
+								if err != nil {
+									t.Fatal(err)
+								}
+								data, err := inFile.Mdat.ReadData(int64(ranges[0].Offset), int64(ranges[0].Size), fd)
+								if err != nil {
+									t.Fatal(err)
+								}
+								if !bytes.Equal(data, s.Data) {
+									t.Errorf("track %d sample %d: data differs", trackID, sampleNr)
+								}
+								sampleNr++
+							}
+						}
+					}
+					if sampleNr-1 != inTrak.GetNrSamples() {
+						t.Errorf("track %d: got %d samples instead of %d", trackID, sampleNr-1, inTrak.GetNrSamples())
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFragmentifyFragmentedInput(t *testing.T) {
+	data, err := os.ReadFile("testdata/cbcs.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inFile, err := mp4.DecodeFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = inFile.Fragmentify(nil, mp4.FragmenterOptions{SegmentDurationMS: 2000})
+	if err == nil {
+		t.Error("expected error for fragmented input")
+	}
+}
+
+func TestFragmentifySampleDescriptionIndex(t *testing.T) {
+	inFile, err := mp4.ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trak := inFile.Moov.Traks[0]
+	stbl := trak.Mdia.Minf.Stbl
+	// Add a copy of the sample entry and use it for the second half of the chunks
+	buf := bytes.Buffer{}
+	if err := stbl.Stsd.Children[0].Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	se, err := mp4.DecodeBox(0, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stbl.Stsd.AddChild(se)
+	nrChunks := uint32(len(stbl.Stco.ChunkOffset))
+	half := nrChunks / 2
+	stsc := &mp4.StscBox{}
+	for nr := uint32(1); nr <= nrChunks; nr++ {
+		sdi := uint32(1)
+		if nr > half {
+			sdi = 2
+		}
+		if err := stsc.AddEntry(nr, stbl.Stsc.GetChunk(nr).NrSamples, sdi); err != nil {
+			t.Fatal(err)
+		}
+	}
+	firstSampleNrWith2 := stbl.Stsc.GetChunk(half + 1).StartSampleNr
+	stbl.Stsc = stsc
+
+	outFiles, err := inFile.Fragmentify(nil, mp4.FragmenterOptions{SegmentDurationMS: 2000, Muxed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := outFiles[0].Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decFile, err := mp4.DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trackID := decFile.Init.Moov.Traks[0].Tkhd.TrackID
+	if n := len(decFile.Init.Moov.Traks[0].Mdia.Minf.Stbl.Stsd.Children); n != 2 {
+		t.Fatalf("got %d sample entries instead of 2", n)
+	}
+	sampleNr := uint32(1)
+	for _, seg := range decFile.Segments {
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				if traf.Tfhd.TrackID != trackID {
+					continue
+				}
+				sdi := uint32(1)
+				if traf.Tfhd.HasSampleDescriptionIndex() {
+					sdi = traf.Tfhd.SampleDescriptionIndex
+				}
+				for i := uint32(0); i < traf.Trun.SampleCount(); i++ {
+					wanted := uint32(1)
+					if sampleNr >= firstSampleNrWith2 {
+						wanted = 2
+					}
+					if sdi != wanted {
+						t.Fatalf("sample %d: sample description index %d instead of %d", sampleNr, sdi, wanted)
+					}
+					sampleNr++
+				}
+			}
+		}
+	}
+	if sampleNr-1 != stbl.Stsz.GetNrSamples() {
+		t.Errorf("got %d samples instead of %d", sampleNr-1, stbl.Stsz.GetNrSamples())
+	}
+}
+
+func TestFragmentifyCopiesEdts(t *testing.T) {
+	inFile, err := mp4.ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inTrak := inFile.Moov.Traks[0]
+	elst := &mp4.ElstBox{Entries: []mp4.ElstEntry{{SegmentDuration: 0, MediaTime: 1024, MediaRateInteger: 1}}}
+	inTrak.AddChild(&mp4.EdtsBox{Elst: []*mp4.ElstBox{elst}, Children: []mp4.Box{elst}})
+	outFiles, err := inFile.Fragmentify(nil, mp4.FragmenterOptions{SegmentDurationMS: 2000, Muxed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outEdts := outFiles[0].Init.Moov.Traks[0].Edts
+	if outEdts == nil || outEdts == inTrak.Edts || outEdts.Elst[0] == elst {
+		t.Fatal("edts not copied to output")
+	}
+	outEdts.Elst[0].Entries[0].MediaTime = 0
+	if elst.Entries[0].MediaTime != 1024 {
+		t.Error("change of output edit list changed the input")
+	}
+}
@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"encoding/hex"
 	"io"
 
 	"github.com/Eyevinn/mp4ff/bits"
@@ -77,3 +78,15 @@ func (b *FreeBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 	bd := newInfoDumper(w, indent, b, -1, 0)
 	return bd.err
 }
+
+// addJSONFields - add payload as hex string
+func (b *FreeBox) addJSONFields(fields map[string]interface{}) {
+	fields["Payload"] = hex.EncodeToString(b.notDecoded)
+}
+
+// setJSONFields - set payload from hex string
+func (b *FreeBox) setJSONFields(fields map[string]interface{}) error {
+	payload, _, err := popBytes(fields, "Payload")
+	b.notDecoded = payload
+	return err
+}
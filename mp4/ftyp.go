@@ -116,3 +116,17 @@ func (b *FtypBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 	}
 	return bd.err
 }
+
+// addJSONFields - add brands stored in raw form
+func (b *FtypBox) addJSONFields(fields map[string]interface{}) {
+	fields["MajorBrand"] = b.MajorBrand()
+	fields["MinorVersion"] = uint64(b.MinorVersion())
+	fields["CompatibleBrands"] = b.CompatibleBrands()
+}
+
+// setJSONFields - set brands in raw form
+func (b *FtypBox) setJSONFields(fields map[string]interface{}) error {
+	data, err := popBrands(fields)
+	b.data = data
+	return err
+}
@@ -0,0 +1,97 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// GmhdBox - Base Media Information Header Box (gmhd)
+//
+// Used instead of vmhd/smhd/nmhd for QuickTime base media such as timecode tracks.
+//
+// Contained in : Media Information Box (minf)
+//
+// Defined in QuickTime File Format Specification
+type GmhdBox struct {
+	Gmin     *GminBox
+	Tmcd     *TmcdInfoBox
+	Children []Box
+}
+
+// CreateTimecodeGmhd - create gmhd box with gmin and tmcd/tcmi for a timecode track
+func CreateTimecodeGmhd() *GmhdBox {
+	tmcd := &TmcdInfoBox{}
+	tmcd.AddChild(CreateTcmi())
+	b := &GmhdBox{}
+	b.AddChild(CreateGmin())
+	b.AddChild(tmcd)
+	return b
+}
+
+// AddChild - Add a child box
+func (b *GmhdBox) AddChild(child Box) {
+	switch box := child.(type) {
+	case *GminBox:
+		b.Gmin = box
+	case *TmcdInfoBox:
+		b.Tmcd = box
+	}
+	b.Children = append(b.Children, child)
+}
+
+// DecodeGmhd - box-specific decode
+func DecodeGmhd(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.Size, r)
+	if err != nil {
+		return nil, err
+	}
+	b := &GmhdBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// DecodeGmhdSR - box-specific decode
+func DecodeGmhdSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.Size, sr)
+	if err != nil {
+		return nil, err
+	}
+	b := &GmhdBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// Type - box type
+func (b *GmhdBox) Type() string {
+	return "gmhd"
+}
+
+// Size - calculated size of box
+func (b *GmhdBox) Size() uint64 {
+	return containerSize(b.Children)
+}
+
+// GetChildren - list of child boxes
+func (b *GmhdBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write gmhd container to w
+func (b *GmhdBox) Encode(w io.Writer) error {
+	return EncodeContainer(b, w)
+}
+
+// EncodeSW - write gmhd container via sw
+func (b *GmhdBox) EncodeSW(sw bits.SliceWriter) error {
+	return EncodeContainerSW(b, sw)
+}
+
+// Info - write box-specific information
+func (b *GmhdBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
+}
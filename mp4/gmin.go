@@ -0,0 +1,101 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// GminBox - Base Media Info Box (gmin)
+//
+// Contained in : Base Media Information Header Box (gmhd)
+//
+// Defined in QuickTime File Format Specification
+type GminBox struct {
+	Version      byte
+	Flags        uint32
+	GraphicsMode uint16
+	OpColor      [3]uint16
+	Balance      int16
+}
+
+// CreateGmin - create gmin box with graphics mode copy
+func CreateGmin() *GminBox {
+	return &GminBox{
+		GraphicsMode: 0x40,
+		OpColor:      [3]uint16{0x8000, 0x8000, 0x8000},
+	}
+}
+
+// DecodeGmin - box-specific decode
+func DecodeGmin(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeGminSR(hdr, startPos, sr)
+}
+
+// DecodeGminSR - box-specific decode
+func DecodeGminSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := GminBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.GraphicsMode = sr.ReadUint16()
+	for i := 0; i < 3; i++ {
+		b.OpColor[i] = sr.ReadUint16()
+	}
+	b.Balance = sr.ReadInt16()
+	sr.SkipBytes(2) // Reserved
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *GminBox) Type() string {
+	return "gmin"
+}
+
+// Size - return calculated size
+func (b *GminBox) Size() uint64 {
+	return uint64(boxHeaderSize + 16)
+}
+
+// Encode - write box to w
+func (b *GminBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *GminBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint16(b.GraphicsMode)
+	for i := 0; i < 3; i++ {
+		sw.WriteUint16(b.OpColor[i])
+	}
+	sw.WriteInt16(b.Balance)
+	sw.WriteUint16(0)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *GminBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - graphicsMode: %d", b.GraphicsMode)
+	bd.write(" - opColor: %v", b.OpColor)
+	bd.write(" - balance: %d", b.Balance)
+	return bd.err
+}
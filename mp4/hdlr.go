@@ -36,12 +36,18 @@ func CreateHdlr(mediaOrHdlrType string) (*HdlrBox, error) {
 	case "subtitle", "subt":
 		hdlr.HandlerType = "subt"
 		hdlr.Name = "mp4ff subtitle handler"
-	case "text", "wvtt":
+	case "text", "wvtt", "tx3g":
 		hdlr.HandlerType = "text"
 		hdlr.Name = "mp4ff text handler"
-	case "meta":
+	case "meta", "mett", "metx", "urim", "evte":
 		hdlr.HandlerType = "meta"
 		hdlr.Name = "mp4ff timed metadata handler"
+	case "timecode", "tmcd":
+		hdlr.HandlerType = "tmcd"
+		hdlr.Name = "mp4ff timecode handler"
+	case "picture", "pict":
+		hdlr.HandlerType = "pict"
+		hdlr.Name = "mp4ff picture handler"
 	case "clcp":
 		hdlr.HandlerType = "subt"
 		hdlr.Name = "mp4ff closed captions handler"
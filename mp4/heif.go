@@ -0,0 +1,434 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// HEIF image items (ISO/IEC 23008-12) are described by boxes in a top-level meta box:
+// pitm gives the primary item, iinf the item types, iloc where the data is,
+// iref the references between items, and iprp the properties of each item.
+
+// imageItemTypes - item types that are coded or derived images
+var imageItemTypes = map[string]bool{
+	"av01": true,
+	"avc1": true,
+	"grid": true,
+	"hvc1": true,
+	"iden": true,
+	"iovl": true,
+	"jpeg": true,
+	"vvc1": true,
+}
+
+// ImageItem - image item in a HEIF file with its properties
+type ImageItem struct {
+	ItemID   uint32
+	ItemType string
+	Name     string
+	Primary  bool
+	Hidden   bool
+	// Width and Height are given by the ispe property
+	Width  uint32
+	Height uint32
+	// Properties are the associated properties in ipma order
+	Properties []Box
+	// InputItemIDs are the items referenced with dimg, like the tiles of a grid
+	InputItemIDs []uint32
+}
+
+// ImageItems lists the image items described by the meta box.
+func (b *MetaBox) ImageItems() []ImageItem {
+	if b.Iinf == nil {
+		return nil
+	}
+	var items []ImageItem
+	for _, infe := range b.Iinf.ItemInfos {
+		if !imageItemTypes[infe.ItemType] {
+			continue
+		}
+		item := ImageItem{
+			ItemID:   infe.ItemID,
+			ItemType: infe.ItemType,
+			Name:     infe.ItemName,
+			Primary:  b.Pitm != nil && b.Pitm.ItemID == infe.ItemID,
+			Hidden:   infe.IsHidden(),
+		}
+		if b.Iprp != nil {
+			item.Properties, _ = b.Iprp.GetItemProperties(infe.ItemID)
+		}
+		for _, p := range item.Properties {
+			if ispe, ok := p.(*IspeBox); ok {
+				item.Width, item.Height = ispe.Width, ispe.Height
+			}
+		}
+		if b.Iref != nil {
+			item.InputItemIDs = b.Iref.GetReferences("dimg", infe.ItemID)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// GetItemData returns the data of an item, like the coded bytes of an image or the description of a grid.
+// Data in idat is taken from the meta box. Data at file offsets is read from rs if it is not nil,
+// and otherwise from the mdat box, which must then have been read into memory.
+func (f *File) GetItemData(itemID uint32, rs io.ReadSeeker) ([]byte, error) {
+	meta := f.Meta
+	if meta == nil || meta.Iloc == nil {
+		return nil, fmt.Errorf("no top-level meta box with iloc")
+	}
+	item, ok := meta.Iloc.GetItem(itemID)
+	if !ok {
+		return nil, fmt.Errorf("no location for item %d", itemID)
+	}
+	if item.DataReferenceIndex != 0 {
+		return nil, fmt.Errorf("item %d: data in other file not supported", itemID)
+	}
+	var data []byte
+	for _, e := range item.Extents {
+		offset := item.BaseOffset + e.Offset
+		switch item.ConstructionMethod {
+		case IlocConstructionFileOffset:
+			extentData, err := f.readFileRange(offset, e.Length, rs)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", itemID, err)
+			}
+			data = append(data, extentData...)
+		case IlocConstructionIdatOffset:
+			if meta.Idat == nil {
+				return nil, fmt.Errorf("item %d: no idat box", itemID)
+			}
+			end := offset + e.Length
+			if e.Length == 0 {
+				end = uint64(len(meta.Idat.Data))
+			}
+			if end > uint64(len(meta.Idat.Data)) {
+				return nil, fmt.Errorf("item %d: extent %d-%d outside idat", itemID, offset, end)
+			}
+			data = append(data, meta.Idat.Data[offset:end]...)
+		default:
+			return nil, fmt.Errorf("item %d: construction method %d not supported", itemID, item.ConstructionMethod)
+		}
+	}
+	return data, nil
+}
+
+// readFileRange reads size bytes at offset from rs, or from the mdat box if rs is nil.
+func (f *File) readFileRange(offset, size uint64, rs io.ReadSeeker) ([]byte, error) {
+	if size == 0 {
+		return nil, fmt.Errorf("extent with length 0 not supported")
+	}
+	if rs != nil {
+		_, err := rs.Seek(int64(offset), io.SeekStart)
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size)
+		_, err = io.ReadFull(rs, data)
+		if err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	mdat := f.Mdat
+	if mdat == nil || mdat.IsLazy() {
+		return nil, fmt.Errorf("no mdat data in memory and no ReadSeeker")
+	}
+	start := mdat.PayloadAbsoluteOffset()
+	if offset < start || offset+size > start+uint64(len(mdat.Data)) {
+		return nil, fmt.Errorf("range %d-%d outside mdat payload", offset, offset+size)
+	}
+	return mdat.Data[offset-start : offset-start+size], nil
+}
+
+// ImageGrid - description of a grid derived image item (ISO/IEC 23008-12 Section 6.6.2.3).
+// The tiles are referenced with dimg in raster order, and Rows and Columns must be 1-256.
+type ImageGrid struct {
+	Rows         uint16
+	Columns      uint16
+	OutputWidth  uint32
+	OutputHeight uint32
+}
+
+// DecodeImageGrid decodes the item data of a grid item.
+func DecodeImageGrid(data []byte) (ImageGrid, error) {
+	sr := bits.NewFixedSliceReader(data)
+	version := sr.ReadUint8()
+	if version != 0 {
+		return ImageGrid{}, fmt.Errorf("grid: unknown version %d", version)
+	}
+	flags := sr.ReadUint8()
+	g := ImageGrid{
+		Rows:    uint16(sr.ReadUint8()) + 1,
+		Columns: uint16(sr.ReadUint8()) + 1,
+	}
+	if flags&1 == 0 {
+		g.OutputWidth = uint32(sr.ReadUint16())
+		g.OutputHeight = uint32(sr.ReadUint16())
+	} else {
+		g.OutputWidth = sr.ReadUint32()
+		g.OutputHeight = sr.ReadUint32()
+	}
+	return g, sr.AccError()
+}
+
+// Encode returns the item data of a grid item.
+func (g ImageGrid) Encode() []byte {
+	large := g.OutputWidth > math.MaxUint16 || g.OutputHeight > math.MaxUint16
+	size := 8
+	if large {
+		size = 12
+	}
+	sw := bits.NewFixedSliceWriter(size)
+	sw.WriteUint8(0) // version
+	if large {
+		sw.WriteUint8(1)
+	} else {
+		sw.WriteUint8(0)
+	}
+	sw.WriteUint8(byte(g.Rows - 1))
+	sw.WriteUint8(byte(g.Columns - 1))
+	if large {
+		sw.WriteUint32(g.OutputWidth)
+		sw.WriteUint32(g.OutputHeight)
+	} else {
+		sw.WriteUint16(uint16(g.OutputWidth))
+		sw.WriteUint16(uint16(g.OutputHeight))
+	}
+	return sw.Bytes()
+}
+
+// CreateImageFile creates a HEIC or AVIF file with one image, or with a grid of tiles if grid is not nil.
+// config is the *HvcCBox or *Av1CBox that applies to all tiles, and tiles is the coded data
+// of each image (length-prefixed NAL units for HEVC, OBUs for AV1) in raster order.
+// tileWidth and tileHeight are the size of each coded image.
+func CreateImageFile(config Box, tileWidth, tileHeight uint32, tiles [][]byte, grid *ImageGrid) (*File, error) {
+	itemType, pixi, err := imageConfigInfo(config)
+	if err != nil {
+		return nil, err
+	}
+	var ftyp *FtypBox
+	if itemType == "hvc1" {
+		ftyp = NewFtyp("heic", 0, []string{"mif1", "heic"})
+	} else {
+		ftyp = NewFtyp("avif", 0, []string{"avif", "mif1", "miaf"})
+	}
+	if grid == nil && len(tiles) != 1 {
+		return nil, fmt.Errorf("got %d images, but no grid", len(tiles))
+	}
+	if grid != nil {
+		if grid.Rows < 1 || grid.Rows > 256 || grid.Columns < 1 || grid.Columns > 256 {
+			return nil, fmt.Errorf("grid rows %d and columns %d must be 1-256", grid.Rows, grid.Columns)
+		}
+		if int(grid.Rows)*int(grid.Columns) != len(tiles) {
+			return nil, fmt.Errorf("got %d tiles for %dx%d grid", len(tiles), grid.Columns, grid.Rows)
+		}
+		if grid.OutputWidth > uint32(grid.Columns)*tileWidth || grid.OutputHeight > uint32(grid.Rows)*tileHeight {
+			return nil, fmt.Errorf("grid output %dx%d larger than tiles", grid.OutputWidth, grid.OutputHeight)
+		}
+	}
+
+	hdlr, err := CreateHdlr("pict")
+	if err != nil {
+		return nil, err
+	}
+	meta := CreateMetaBox(0, hdlr)
+	iinf := &IinfBox{}
+	iloc := &IlocBox{Version: 1, OffsetSize: 4, LengthSize: 4}
+	ipco := &IpcoBox{}
+	ipma := &IpmaBox{}
+	configIdx := ipco.AddProperty(config)
+	tileIspeIdx := ipco.AddProperty(&IspeBox{Width: tileWidth, Height: tileHeight})
+	pixiIdx := ipco.AddProperty(pixi)
+
+	var mdatSize uint64
+	tileIDs := make([]uint32, len(tiles))
+	for i, tile := range tiles {
+		itemID := uint32(i + 1)
+		tileIDs[i] = itemID
+		iinf.AddChild(CreateInfe(itemID, itemType, "", grid != nil))
+		iloc.Items = append(iloc.Items, IlocItem{
+			ItemID:  itemID,
+			Extents: []IlocExtent{{Offset: mdatSize, Length: uint64(len(tile))}},
+		})
+		ipma.AddEntry(itemID, PropertyAssociation{Essential: true, Index: configIdx},
+			PropertyAssociation{Index: tileIspeIdx}, PropertyAssociation{Index: pixiIdx})
+		mdatSize += uint64(len(tile))
+	}
+	primaryID := uint32(1)
+	var iref *IrefBox
+	var idat *IdatBox
+	if grid != nil {
+		primaryID = uint32(len(tiles) + 1)
+		iinf.AddChild(CreateInfe(primaryID, "grid", "", false))
+		idat = &IdatBox{Data: grid.Encode()}
+		iloc.Items = append(iloc.Items, IlocItem{
+			ItemID:             primaryID,
+			ConstructionMethod: IlocConstructionIdatOffset,
+			Extents:            []IlocExtent{{Length: uint64(len(idat.Data))}},
+		})
+		iref = &IrefBox{}
+		iref.AddReference("dimg", primaryID, tileIDs...)
+		gridIspeIdx := ipco.AddProperty(&IspeBox{Width: grid.OutputWidth, Height: grid.OutputHeight})
+		ipma.AddEntry(primaryID, PropertyAssociation{Index: gridIspeIdx}, PropertyAssociation{Index: pixiIdx})
+	}
+	if primaryID > math.MaxUint16 {
+		iinf.Version = 1
+		iloc.Version = 2
+		if iref != nil {
+			iref.Version = 1
+		}
+	}
+
+	meta.AddChild(&PitmBox{ItemID: primaryID})
+	if primaryID > math.MaxUint16 {
+		meta.Pitm.Version = 1
+	}
+	meta.AddChild(iinf)
+	meta.AddChild(iloc)
+	if iref != nil {
+		meta.AddChild(iref)
+	}
+	iprp := &IprpBox{}
+	iprp.AddChild(ipco)
+	iprp.AddChild(ipma)
+	meta.AddChild(iprp)
+	if idat != nil {
+		meta.AddChild(idat)
+	}
+
+	mdat := &MdatBox{}
+	for _, tile := range tiles {
+		mdat.AddSampleData(tile)
+	}
+	_ = mdat.Size() // Sets LargeSize if needed
+	if ftyp.Size()+meta.Size()+mdat.Size() > math.MaxUint32 {
+		iloc.OffsetSize, iloc.LengthSize = 8, 8
+	}
+	mdat.StartPos = ftyp.Size() + meta.Size()
+	for i := range tiles {
+		iloc.Items[i].Extents[0].Offset += mdat.PayloadAbsoluteOffset()
+	}
+
+	f := NewFile()
+	f.AddChild(ftyp, 0)
+	f.AddChild(meta, ftyp.Size())
+	f.AddChild(mdat, mdat.StartPos)
+	return f, nil
+}
+
+// imageConfigInfo returns the item type and the pixi property for an *HvcCBox or *Av1CBox.
+func imageConfigInfo(config Box) (itemType string, pixi *PixiBox, err error) {
+	switch c := config.(type) {
+	case *HvcCBox:
+		pixi = &PixiBox{BitsPerChannel: []byte{c.BitDepthLumaMinus8 + 8}}
+		if c.ChromaFormatIDC != 0 {
+			pixi.BitsPerChannel = append(pixi.BitsPerChannel, c.BitDepthChromaMinus8+8, c.BitDepthChromaMinus8+8)
+		}
+		return "hvc1", pixi, nil
+	case *Av1CBox:
+		depth := byte(8)
+		if c.HighBitdepth == 1 {
+			depth = 10
+			if c.TwelveBit == 1 {
+				depth = 12
+			}
+		}
+		pixi = &PixiBox{BitsPerChannel: []byte{depth}}
+		if c.MonoChrome == 0 {
+			pixi.BitsPerChannel = append(pixi.BitsPerChannel, depth, depth)
+		}
+		return "av01", pixi, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported image configuration box %s", config.Type())
+	}
+}
+
+// ImageSequenceTracks returns the image sequence tracks, which are tracks with handler type pict.
+// The coded images are the samples of the track and can be read with File.NewSampleIterator.
+func (m *MoovBox) ImageSequenceTracks() []*TrakBox {
+	var traks []*TrakBox
+	for _, trak := range m.Traks {
+		if trak.Mdia != nil && trak.Mdia.Hdlr != nil && trak.Mdia.Hdlr.HandlerType == "pict" {
+			traks = append(traks, trak)
+		}
+	}
+	return traks
+}
+
+// CreateImageSequenceFile creates a HEIF or AVIF image sequence file with one pict track.
+// config is the *HvcCBox or *Av1CBox that applies to all images, and images is the coded data
+// of each image (length-prefixed NAL units for HEVC, OBUs for AV1) in decoding order.
+// All images are sync samples with duration sampleDur in timescale.
+func CreateImageSequenceFile(config Box, width, height uint16, timescale, sampleDur uint32, images [][]byte) (*File, error) {
+	itemType, _, err := imageConfigInfo(config)
+	if err != nil {
+		return nil, err
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no images")
+	}
+	var ftyp *FtypBox
+	if itemType == "hvc1" {
+		ftyp = NewFtyp("msf1", 0, []string{"msf1", "iso8", "hevc"})
+	} else {
+		ftyp = NewFtyp("avis", 0, []string{"avis", "msf1", "iso8", "miaf"})
+	}
+	nrImages := uint32(len(images))
+	duration := uint64(nrImages) * uint64(sampleDur)
+
+	moov := NewMoovBox()
+	mvhd := CreateMvhd()
+	mvhd.Timescale = timescale
+	mvhd.Duration = duration
+	moov.AddChild(mvhd)
+	trak := CreateEmptyTrak(1, timescale, "pict", "und")
+	moov.AddChild(trak)
+	trak.Tkhd.Width = Fixed32(uint32(width) << 16)
+	trak.Tkhd.Height = Fixed32(uint32(height) << 16)
+	trak.Tkhd.Duration = duration
+	trak.Mdia.Mdhd.Duration = duration
+	if duration > math.MaxUint32 {
+		mvhd.Version, trak.Tkhd.Version, trak.Mdia.Mdhd.Version = 1, 1, 1
+	}
+	stbl := trak.Mdia.Minf.Stbl
+	stbl.Stsd.AddChild(CreateVisualSampleEntryBox(itemType, width, height, config))
+	stbl.Stts.SampleCount = []uint32{nrImages}
+	stbl.Stts.SampleTimeDelta = []uint32{sampleDur}
+	if err := stbl.Stsc.AddEntry(1, nrImages, 1); err != nil {
+		return nil, err
+	}
+	mdat := &MdatBox{}
+	for _, image := range images {
+		stbl.Stsz.SampleSize = append(stbl.Stsz.SampleSize, uint32(len(image)))
+		mdat.AddSampleData(image)
+	}
+	stbl.Stsz.SampleNumber = nrImages
+	stbl.Stco.ChunkOffset = []uint32{0}
+	_ = mdat.Size() // Sets LargeSize if needed
+	if ftyp.Size()+moov.Size()+mdat.Size() > math.MaxUint32 {
+		co64 := &Co64Box{ChunkOffset: []uint64{0}}
+		for i, c := range stbl.Children {
+			if c == Box(stbl.Stco) {
+				stbl.Children[i] = co64
+			}
+		}
+		stbl.Stco, stbl.Co64 = nil, co64
+	}
+	mdat.StartPos = ftyp.Size() + moov.Size()
+	if stbl.Co64 != nil {
+		stbl.Co64.ChunkOffset[0] = mdat.PayloadAbsoluteOffset()
+	} else {
+		stbl.Stco.ChunkOffset[0] = uint32(mdat.PayloadAbsoluteOffset())
+	}
+
+	f := NewFile()
+	f.AddChild(ftyp, 0)
+	f.AddChild(moov, ftyp.Size())
+	f.AddChild(mdat, mdat.StartPos)
+	return f, nil
+}
@@ -0,0 +1,220 @@
+package mp4_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/av1"
+	"github.com/Eyevinn/mp4ff/bits"
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestCreateImageFileHEIC(t *testing.T) {
+	init, err := mp4.ReadMP4File("testdata/hvc1_init.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hvcC := init.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.HvcX.HvcC
+	image := []byte{0, 0, 0, 4, 0x26, 0x01, 0xaf, 0x08}
+	f, err := mp4.CreateImageFile(hvcC, 640, 360, [][]byte{image}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := encodeAndCheckSW(t, f)
+	dec, err := mp4.DecodeFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec.Ftyp.MajorBrand() != "heic" || dec.Meta == nil {
+		t.Fatalf("got major brand %s and meta %v", dec.Ftyp.MajorBrand(), dec.Meta)
+	}
+	items := dec.Meta.ImageItems()
+	if len(items) != 1 {
+		t.Fatalf("got %d image items instead of 1", len(items))
+	}
+	item := items[0]
+	if item.ItemType != "hvc1" || !item.Primary || item.Hidden || item.Width != 640 || item.Height != 360 {
+		t.Errorf("unexpected item %+v", item)
+	}
+	if len(item.Properties) != 3 || item.Properties[0].Type() != "hvcC" || item.Properties[2].Type() != "pixi" {
+		t.Errorf("unexpected properties %v", item.Properties)
+	}
+	for _, rs := range []*bytes.Reader{nil, bytes.NewReader(data)} {
+		var got []byte
+		if rs == nil {
+			got, err = dec.GetItemData(item.ItemID, nil)
+		} else {
+			got, err = dec.GetItemData(item.ItemID, rs)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, image) {
+			t.Errorf("got item data %x instead of %x", got, image)
+		}
+	}
+	_, err = dec.GetItemData(7, nil)
+	if err == nil {
+		t.Error("expected error for missing item")
+	}
+}
+
+func TestCreateImageFileAVIFGrid(t *testing.T) {
+	av1C := &mp4.Av1CBox{CodecConfRec: av1.CodecConfRec{Version: 1, SeqLevelIdx0: 8, HighBitdepth: 1,
+		ChromaSubsamplingX: 1, ChromaSubsamplingY: 1}}
+	tiles := [][]byte{{0x12, 0x00, 0x0a}, {0x12, 0x00, 0x0b}, {0x12, 0x00, 0x0c, 0x0d}, {0x12, 0x00}}
+	grid := &mp4.ImageGrid{Rows: 2, Columns: 2, OutputWidth: 1000, OutputHeight: 500}
+	f, err := mp4.CreateImageFile(av1C, 512, 256, tiles, grid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := encodeAndCheckSW(t, f)
+	dec, err := mp4.DecodeFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec.Ftyp.MajorBrand() != "avif" {
+		t.Errorf("got major brand %s", dec.Ftyp.MajorBrand())
+	}
+	items := dec.Meta.ImageItems()
+	if len(items) != 5 {
+		t.Fatalf("got %d image items instead of 5", len(items))
+	}
+	for i, tile := range tiles {
+		item := items[i]
+		if item.ItemType != "av01" || !item.Hidden || item.Primary || item.Width != 512 {
+			t.Errorf("unexpected tile item %+v", item)
+		}
+		got, err := dec.GetItemData(item.ItemID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, tile) {
+			t.Errorf("tile %d: got %x instead of %x", i, got, tile)
+		}
+	}
+	gridItem := items[4]
+	if gridItem.ItemType != "grid" || !gridItem.Primary || gridItem.Width != 1000 || gridItem.Height != 500 ||
+		len(gridItem.InputItemIDs) != 4 || gridItem.InputItemIDs[0] != items[0].ItemID {
+		t.Errorf("unexpected grid item %+v", gridItem)
+	}
+	pixi := gridItem.Properties[1].(*mp4.PixiBox)
+	if !bytes.Equal(pixi.BitsPerChannel, []byte{10, 10, 10}) {
+		t.Errorf("got pixi %v", pixi.BitsPerChannel)
+	}
+	gridData, err := dec.GetItemData(gridItem.ItemID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotGrid, err := mp4.DecodeImageGrid(gridData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotGrid != *grid {
+		t.Errorf("got grid %+v instead of %+v", gotGrid, *grid)
+	}
+
+	_, err = mp4.CreateImageFile(av1C, 512, 256, tiles, nil)
+	if err == nil {
+		t.Error("expected error for several images without grid")
+	}
+	_, err = mp4.CreateImageFile(av1C, 512, 256, tiles[:3], grid)
+	if err == nil {
+		t.Error("expected error for wrong number of tiles")
+	}
+}
+
+func TestImageGridLargeOutput(t *testing.T) {
+	grid := mp4.ImageGrid{Rows: 256, Columns: 1, OutputWidth: 70000, OutputHeight: 20}
+	data := grid.Encode()
+	if len(data) != 12 {
+		t.Errorf("got %d bytes instead of 12", len(data))
+	}
+	got, err := mp4.DecodeImageGrid(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != grid {
+		t.Errorf("got %+v instead of %+v", got, grid)
+	}
+}
+
+func TestCreateImageSequenceFile(t *testing.T) {
+	init, err := mp4.ReadMP4File("testdata/hvc1_init.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hvcC := init.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.HvcX.HvcC
+	av1C := &mp4.Av1CBox{CodecConfRec: av1.CodecConfRec{Version: 1, SeqLevelIdx0: 8}}
+	images := [][]byte{{0, 0, 0, 2, 0x26, 0x01}, {0, 0, 0, 3, 0x02, 0x01, 0xd0}, {0, 0, 0, 1, 0x02}}
+	testCases := []struct {
+		config     mp4.Box
+		majorBrand string
+		entryType  string
+	}{
+		{hvcC, "msf1", "hvc1"},
+		{av1C, "avis", "av01"},
+	}
+	for _, tc := range testCases {
+		f, err := mp4.CreateImageSequenceFile(tc.config, 640, 360, 1000, 40, images)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := encodeAndCheckSW(t, f)
+		dec, err := mp4.DecodeFile(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dec.Ftyp.MajorBrand() != tc.majorBrand {
+			t.Errorf("got major brand %s instead of %s", dec.Ftyp.MajorBrand(), tc.majorBrand)
+		}
+		traks := dec.Moov.ImageSequenceTracks()
+		if len(traks) != 1 {
+			t.Fatalf("got %d image sequence tracks instead of 1", len(traks))
+		}
+		trak := traks[0]
+		if trak.Mdia.Minf.Vmhd == nil || trak.Mdia.Minf.Stbl.Stsd.Children[0].Type() != tc.entryType {
+			t.Errorf("%s: expected vmhd and %s sample entry", tc.entryType, tc.entryType)
+		}
+		it, err := dec.NewSampleIterator(trak.Tkhd.TrackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nr := 0
+		for it.Next() {
+			s := it.Sample()
+			if !bytes.Equal(s.Data, images[nr]) || !s.SyncSample || s.DecodeTime != uint64(nr*40) {
+				t.Errorf("%s: unexpected image %d: %+v", tc.entryType, nr+1, s)
+			}
+			nr++
+		}
+		if it.Err() != nil {
+			t.Fatal(it.Err())
+		}
+		if nr != len(images) {
+			t.Errorf("%s: got %d images instead of %d", tc.entryType, nr, len(images))
+		}
+	}
+	if _, err := mp4.CreateImageSequenceFile(av1C, 640, 360, 1000, 40, nil); err == nil {
+		t.Error("expected error for no images")
+	}
+}
+
+// encodeAndCheckSW encodes f with Encode and EncodeSW, checks that the results are equal and returns them.
+func encodeAndCheckSW(t *testing.T, f *mp4.File) []byte {
+	t.Helper()
+	buf := bytes.Buffer{}
+	err := f.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sw := bits.NewFixedSliceWriter(buf.Len())
+	err = f.EncodeSW(sw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), sw.Bytes()) {
+		t.Error("Encode and EncodeSW differ")
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,88 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// Hero eye indicator values
+const (
+	HeroEyeNone  = 0
+	HeroEyeLeft  = 1
+	HeroEyeRight = 2
+)
+
+// HeroBox - Hero Stereo Eye Description Box (hero)
+//
+// HeroEyeIndicator is 0 for none, 1 for left, and 2 for right eye.
+//
+// Contained in : Stereo View Box (eyes)
+//
+// Defined in Apple HEVC Stereo Video - ISOBMFF Extensions
+type HeroBox struct {
+	Version          byte
+	Flags            uint32
+	HeroEyeIndicator byte
+}
+
+// DecodeHero - box-specific decode
+func DecodeHero(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeHeroSR(hdr, startPos, sr)
+}
+
+// DecodeHeroSR - box-specific decode
+func DecodeHeroSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &HeroBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.HeroEyeIndicator = sr.ReadUint8()
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *HeroBox) Type() string {
+	return "hero"
+}
+
+// Size - return calculated size
+func (b *HeroBox) Size() uint64 {
+	return uint64(boxHeaderSize + 5)
+}
+
+// Encode - write box to w
+func (b *HeroBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *HeroBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint8(b.HeroEyeIndicator)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *HeroBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - heroEyeIndicator: %d", b.HeroEyeIndicator)
+	return bd.err
+}
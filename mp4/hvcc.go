@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"reflect"
 
 	"github.com/Eyevinn/mp4ff/bits"
 	"github.com/Eyevinn/mp4ff/hevc"
@@ -101,3 +102,50 @@ func (b *HvcCBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 	}
 	return bd.err
 }
+
+// addJSONFields - add NALU type and completeness of the NALU arrays
+func (b *HvcCBox) addJSONFields(fields map[string]interface{}) {
+	arrays := make([]interface{}, 0, len(b.NaluArrays))
+	for i := range b.NaluArrays {
+		na := &b.NaluArrays[i]
+		nalus := make([]interface{}, 0, len(na.Nalus))
+		for _, nalu := range na.Nalus {
+			nalus = append(nalus, hex.EncodeToString(nalu))
+		}
+		arrays = append(arrays, map[string]interface{}{
+			"Complete": na.Complete() == 1,
+			"NaluType": uint64(na.NaluType()),
+			"Nalus":    nalus,
+		})
+	}
+	fields["NaluArrays"] = arrays
+}
+
+// setJSONFields - set NALU arrays including NALU type and completeness
+func (b *HvcCBox) setJSONFields(fields map[string]interface{}) error {
+	value, ok := fields["NaluArrays"]
+	if !ok {
+		return nil
+	}
+	delete(fields, "NaluArrays")
+	values, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("NaluArrays: not an array")
+	}
+	for i, v := range values {
+		var na struct {
+			Complete bool
+			NaluType uint8
+			Nalus    [][]byte
+		}
+		arrayFields, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("NaluArrays[%d]: not an object", i)
+		}
+		if err := setStructFields(reflect.ValueOf(&na).Elem(), arrayFields, false); err != nil {
+			return fmt.Errorf("NaluArrays[%d]: %w", i, err)
+		}
+		b.NaluArrays = append(b.NaluArrays, hevc.NewNaluArray(na.Complete, hevc.NaluType(na.NaluType), na.Nalus))
+	}
+	return nil
+}
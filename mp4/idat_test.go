@@ -0,0 +1,11 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestIdat(t *testing.T) {
+	boxDiffAfterEncodeAndDecode(t, &mp4.IdatBox{Data: []byte{0, 0, 1, 1, 0x07, 0x80, 0x04, 0x38}})
+}
@@ -0,0 +1,138 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// IinfBox - Item Information Box (iinf)
+//
+// Contained in : Meta Box (meta)
+//
+// Defined in ISO/IEC 14496-12 Section 8.11.6
+type IinfBox struct {
+	Version   byte
+	Flags     uint32
+	ItemInfos []*InfeBox
+	Children  []Box
+}
+
+// AddChild - add a child box. infe boxes are also added to ItemInfos.
+func (b *IinfBox) AddChild(child Box) {
+	if infe, ok := child.(*InfeBox); ok {
+		b.ItemInfos = append(b.ItemInfos, infe)
+	}
+	b.Children = append(b.Children, child)
+}
+
+// DecodeIinf - box-specific decode
+func DecodeIinf(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeIinfSR(hdr, startPos, sr)
+}
+
+// DecodeIinfSR - box-specific decode
+func DecodeIinfSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &IinfBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	var entryCount uint32
+	offset := uint64(boxHeaderSize + 4)
+	if b.Version == 0 {
+		entryCount = uint32(sr.ReadUint16())
+		offset += 2
+	} else {
+		entryCount = sr.ReadUint32()
+		offset += 4
+	}
+	if sr.AccError() != nil {
+		return nil, sr.AccError()
+	}
+	children, err := DecodeContainerChildrenSR(hdr, startPos+offset, startPos+hdr.Size, sr)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	if uint32(len(b.ItemInfos)) != entryCount {
+		return nil, fmt.Errorf("iinf: entry count %d but %d infe boxes", entryCount, len(b.ItemInfos))
+	}
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *IinfBox) Type() string {
+	return "iinf"
+}
+
+// Size - return calculated size
+func (b *IinfBox) Size() uint64 {
+	size := containerSize(b.Children) + 4
+	if b.Version == 0 {
+		return size + 2
+	}
+	return size + 4
+}
+
+// GetChildren - list of child boxes
+func (b *IinfBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write box to w
+func (b *IinfBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *IinfBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	if b.Version == 0 {
+		sw.WriteUint16(uint16(len(b.ItemInfos)))
+	} else {
+		sw.WriteUint32(uint32(len(b.ItemInfos)))
+	}
+	for _, c := range b.Children {
+		err = c.EncodeSW(sw)
+		if err != nil {
+			return err
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *IinfBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - entryCount: %d", len(b.ItemInfos))
+	if bd.err != nil {
+		return bd.err
+	}
+	for _, c := range b.Children {
+		err := c.Info(w, specificBoxLevels, indent+indentStep, indentStep)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
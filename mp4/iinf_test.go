@@ -0,0 +1,30 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestIinf(t *testing.T) {
+	iinf := &mp4.IinfBox{}
+	iinf.AddChild(mp4.CreateInfe(1, "hvc1", "", true))
+	iinf.AddChild(mp4.CreateInfe(70000, "grid", "grid image", false))
+	iinf.AddChild(&mp4.InfeBox{Version: 2, ItemID: 3, ItemType: "mime", ContentType: "application/rdf+xml",
+		ContentEncoding: "deflate"})
+	iinf.AddChild(&mp4.InfeBox{Version: 2, ItemID: 4, ItemType: "uri ", ItemURIType: "urn:example"})
+	iinf.AddChild(&mp4.InfeBox{Version: 0, ItemID: 5, ItemName: "old", ContentType: "text/plain"})
+	boxDiffAfterEncodeAndDecode(t, iinf)
+	iinf.Version = 1
+	boxDiffAfterEncodeAndDecode(t, iinf)
+	if !iinf.ItemInfos[0].IsHidden() || iinf.ItemInfos[1].IsHidden() || iinf.ItemInfos[1].Version != 3 {
+		t.Error("wrong hidden flag or version in infe boxes")
+	}
+}
+
+func TestInfeWithEmptyContentEncoding(t *testing.T) {
+	data := []byte{0, 0, 0, 0x1a, 'i', 'n', 'f', 'e', 2, 0, 0, 0, 0, 1, 0, 0, 'm', 'i', 'm', 'e', 0,
+		't', 'e', 'x', 't', 0, 0}
+	data[3] = byte(len(data))
+	cmpAfterDecodeEncodeBox(t, data)
+}
@@ -0,0 +1,240 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// IlocBox - Item Location Box (iloc)
+//
+// Contained in : Meta Box (meta)
+//
+// Defined in ISO/IEC 14496-12 Section 8.11.3.
+// OffsetSize, LengthSize, BaseOffsetSize, and IndexSize are in bytes and must be 0, 4, or 8.
+type IlocBox struct {
+	Version        byte
+	Flags          uint32
+	OffsetSize     byte
+	LengthSize     byte
+	BaseOffsetSize byte
+	IndexSize      byte
+	Items          []IlocItem
+}
+
+// IlocItem - location of an item in one or more extents
+type IlocItem struct {
+	ItemID             uint32
+	ConstructionMethod byte
+	DataReferenceIndex uint16
+	BaseOffset         uint64
+	Extents            []IlocExtent
+}
+
+// IlocExtent - extent of item data
+type IlocExtent struct {
+	Index  uint64
+	Offset uint64
+	Length uint64
+}
+
+// Construction methods for item data
+const (
+	IlocConstructionFileOffset = 0
+	IlocConstructionIdatOffset = 1
+	IlocConstructionItemOffset = 2
+)
+
+// DecodeIloc - box-specific decode
+func DecodeIloc(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeIlocSR(hdr, startPos, sr)
+}
+
+// DecodeIlocSR - box-specific decode
+func DecodeIlocSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &IlocBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	if b.Version > 2 {
+		return nil, fmt.Errorf("iloc: unknown version %d", b.Version)
+	}
+	sizes := sr.ReadUint16()
+	b.OffsetSize = byte(sizes >> 12)
+	b.LengthSize = byte(sizes>>8) & 0xf
+	b.BaseOffsetSize = byte(sizes>>4) & 0xf
+	if b.Version > 0 {
+		b.IndexSize = byte(sizes) & 0xf
+	}
+	for _, s := range []byte{b.OffsetSize, b.LengthSize, b.BaseOffsetSize, b.IndexSize} {
+		if s != 0 && s != 4 && s != 8 {
+			return nil, fmt.Errorf("iloc: field size %d not 0, 4, or 8", s)
+		}
+	}
+	var itemCount uint32
+	if b.Version < 2 {
+		itemCount = uint32(sr.ReadUint16())
+	} else {
+		itemCount = sr.ReadUint32()
+	}
+	for i := uint32(0); i < itemCount; i++ {
+		if sr.AccError() != nil {
+			break
+		}
+		var item IlocItem
+		if b.Version < 2 {
+			item.ItemID = uint32(sr.ReadUint16())
+		} else {
+			item.ItemID = sr.ReadUint32()
+		}
+		if b.Version > 0 {
+			item.ConstructionMethod = byte(sr.ReadUint16() & 0xf)
+		}
+		item.DataReferenceIndex = sr.ReadUint16()
+		item.BaseOffset = readIlocValue(sr, b.BaseOffsetSize)
+		extentCount := sr.ReadUint16()
+		item.Extents = make([]IlocExtent, 0, extentCount)
+		for j := uint16(0); j < extentCount; j++ {
+			var e IlocExtent
+			if b.Version > 0 {
+				e.Index = readIlocValue(sr, b.IndexSize)
+			}
+			e.Offset = readIlocValue(sr, b.OffsetSize)
+			e.Length = readIlocValue(sr, b.LengthSize)
+			item.Extents = append(item.Extents, e)
+		}
+		b.Items = append(b.Items, item)
+	}
+	return b, sr.AccError()
+}
+
+func readIlocValue(sr bits.SliceReader, size byte) uint64 {
+	switch size {
+	case 4:
+		return uint64(sr.ReadUint32())
+	case 8:
+		return sr.ReadUint64()
+	default:
+		return 0
+	}
+}
+
+func writeIlocValue(sw bits.SliceWriter, size byte, value uint64) {
+	switch size {
+	case 4:
+		sw.WriteUint32(uint32(value))
+	case 8:
+		sw.WriteUint64(value)
+	}
+}
+
+// Type - return box type
+func (b *IlocBox) Type() string {
+	return "iloc"
+}
+
+// Size - return calculated size
+func (b *IlocBox) Size() uint64 {
+	size := uint64(boxHeaderSize + 4 + 2 + 2)
+	itemHeaderSize := uint64(2 + 2 + 2 + b.BaseOffsetSize)
+	extentSize := uint64(b.OffsetSize + b.LengthSize)
+	if b.Version > 0 {
+		itemHeaderSize += 2
+		extentSize += uint64(b.IndexSize)
+	}
+	if b.Version == 2 {
+		size += 2
+		itemHeaderSize += 2
+	}
+	for _, item := range b.Items {
+		size += itemHeaderSize + uint64(len(item.Extents))*extentSize
+	}
+	return size
+}
+
+// Encode - write box to w
+func (b *IlocBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *IlocBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sizes := uint16(b.OffsetSize)<<12 | uint16(b.LengthSize)<<8 | uint16(b.BaseOffsetSize)<<4
+	if b.Version > 0 {
+		sizes |= uint16(b.IndexSize)
+	}
+	sw.WriteUint16(sizes)
+	if b.Version < 2 {
+		sw.WriteUint16(uint16(len(b.Items)))
+	} else {
+		sw.WriteUint32(uint32(len(b.Items)))
+	}
+	for _, item := range b.Items {
+		if b.Version < 2 {
+			sw.WriteUint16(uint16(item.ItemID))
+		} else {
+			sw.WriteUint32(item.ItemID)
+		}
+		if b.Version > 0 {
+			sw.WriteUint16(uint16(item.ConstructionMethod))
+		}
+		sw.WriteUint16(item.DataReferenceIndex)
+		writeIlocValue(sw, b.BaseOffsetSize, item.BaseOffset)
+		sw.WriteUint16(uint16(len(item.Extents)))
+		for _, e := range item.Extents {
+			if b.Version > 0 {
+				writeIlocValue(sw, b.IndexSize, e.Index)
+			}
+			writeIlocValue(sw, b.OffsetSize, e.Offset)
+			writeIlocValue(sw, b.LengthSize, e.Length)
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *IlocBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - offsetSize: %d lengthSize: %d baseOffsetSize: %d indexSize: %d",
+		b.OffsetSize, b.LengthSize, b.BaseOffsetSize, b.IndexSize)
+	bd.write(" - itemCount: %d", len(b.Items))
+	for _, item := range b.Items {
+		bd.write(" - item %d: constructionMethod=%d dataReferenceIndex=%d baseOffset=%d",
+			item.ItemID, item.ConstructionMethod, item.DataReferenceIndex, item.BaseOffset)
+		if getInfoLevel(b, specificBoxLevels) > 0 {
+			for _, e := range item.Extents {
+				bd.write("   - extent: index=%d offset=%d length=%d", e.Index, e.Offset, e.Length)
+			}
+		}
+	}
+	return bd.err
+}
+
+// GetItem - get location of item with itemID
+func (b *IlocBox) GetItem(itemID uint32) (IlocItem, bool) {
+	for _, item := range b.Items {
+		if item.ItemID == itemID {
+			return item, true
+		}
+	}
+	return IlocItem{}, false
+}
@@ -0,0 +1,21 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestIloc(t *testing.T) {
+	items := []mp4.IlocItem{
+		{ItemID: 1, Extents: []mp4.IlocExtent{{Offset: 1000, Length: 200}, {Offset: 1400, Length: 20}}},
+		{ItemID: 2, ConstructionMethod: mp4.IlocConstructionIdatOffset, BaseOffset: 8,
+			Extents: []mp4.IlocExtent{{Length: 8}}},
+	}
+	boxDiffAfterEncodeAndDecode(t, &mp4.IlocBox{Version: 0, OffsetSize: 4, LengthSize: 4, BaseOffsetSize: 4,
+		Items: items[:1]})
+	boxDiffAfterEncodeAndDecode(t, &mp4.IlocBox{Version: 1, OffsetSize: 8, LengthSize: 4, BaseOffsetSize: 4,
+		IndexSize: 4, Items: items})
+	boxDiffAfterEncodeAndDecode(t, &mp4.IlocBox{Version: 2, OffsetSize: 4, LengthSize: 8, Items: []mp4.IlocItem{
+		{ItemID: 70000, Extents: []mp4.IlocExtent{{Offset: 10, Length: 20}}}}})
+}
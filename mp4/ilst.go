@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/Eyevinn/mp4ff/bits"
@@ -72,3 +73,188 @@ func (b *IlstBox) EncodeSW(sw bits.SliceWriter) error {
 func (b *IlstBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
 }
+
+// DecodeFreeFormItem - decode a free-form (----) metadata item with mean, name, and data boxes
+func DecodeFreeFormItem(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	b, err := DecodeGenericContainerBox(hdr, startPos, r)
+	if err != nil {
+		return nil, err
+	}
+	item := b.(*GenericContainerBox)
+	return item, decodeFreeFormChildren(item)
+}
+
+// DecodeFreeFormItemSR - decode a free-form (----) metadata item with mean, name, and data boxes
+func DecodeFreeFormItemSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b, err := DecodeGenericContainerBoxSR(hdr, startPos, sr)
+	if err != nil {
+		return nil, err
+	}
+	item := b.(*GenericContainerBox)
+	return item, decodeFreeFormChildren(item)
+}
+
+// decodeFreeFormChildren decodes the mean and name children of a free-form item.
+// They are only decoded here, since other boxes of type name, like in QuickTime udta, have another syntax.
+// Boxes too short for version and flags are kept as unknown boxes.
+func decodeFreeFormChildren(item *GenericContainerBox) error {
+	for i, c := range item.Children {
+		u, ok := c.(*UnknownBox)
+		if !ok || (u.name != "mean" && u.name != "name") {
+			continue
+		}
+		if len(u.notDecoded) < 4 || u.size != uint64(boxHeaderSize+len(u.notDecoded)) {
+			continue
+		}
+		hdr := BoxHeader{Name: u.name, Size: u.size, Hdrlen: boxHeaderSize}
+		sr := bits.NewFixedSliceReader(u.notDecoded)
+		var box Box
+		var err error
+		if u.name == "mean" {
+			box, err = DecodeMeanSR(hdr, 0, sr)
+		} else {
+			box, err = DecodeNameSR(hdr, 0, sr)
+		}
+		if err != nil {
+			return fmt.Errorf("decode %s in ----: %w", u.name, err)
+		}
+		item.Children[i] = box
+	}
+	return nil
+}
+
+// MeanBox - mean box with the reverse DNS domain of a free-form (----) metadata item in ilst
+type MeanBox struct {
+	Version byte
+	Flags   uint32
+	Meaning string
+}
+
+// DecodeMean - box-specific decode
+func DecodeMean(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeMeanSR(hdr, startPos, sr)
+}
+
+// DecodeMeanSR - box-specific decode
+func DecodeMeanSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := MeanBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.Meaning = sr.ReadFixedLengthString(hdr.payloadLen() - 4)
+	return &b, sr.AccError()
+}
+
+// Type - box type
+func (b *MeanBox) Type() string {
+	return "mean"
+}
+
+// Size - calculated size of box
+func (b *MeanBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4 + len(b.Meaning))
+}
+
+// Encode - write box to w
+func (b *MeanBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *MeanBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteString(b.Meaning, false)
+	return sw.AccError()
+}
+
+// Info - box-specific Info
+func (b *MeanBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - meaning: %s", b.Meaning)
+	return bd.err
+}
+
+// NameBox - name box with the name of a free-form (----) metadata item in ilst
+type NameBox struct {
+	Version byte
+	Flags   uint32
+	Name    string
+}
+
+// DecodeName - box-specific decode
+func DecodeName(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeNameSR(hdr, startPos, sr)
+}
+
+// DecodeNameSR - box-specific decode
+func DecodeNameSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := NameBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.Name = sr.ReadFixedLengthString(hdr.payloadLen() - 4)
+	return &b, sr.AccError()
+}
+
+// Type - box type
+func (b *NameBox) Type() string {
+	return "name"
+}
+
+// Size - calculated size of box
+func (b *NameBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4 + len(b.Name))
+}
+
+// Encode - write box to w
+func (b *NameBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *NameBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteString(b.Name, false)
+	return sw.AccError()
+}
+
+// Info - box-specific Info
+func (b *NameBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - name: %s", b.Name)
+	return bd.err
+}
@@ -0,0 +1,71 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// ImirBox - Image Mirroring Property (imir)
+//
+// Contained in : Item Property Container Box (ipco)
+//
+// Axis 0 means mirroring about a vertical axis (left-right), and 1 about a horizontal axis (top-bottom).
+// Defined in ISO/IEC 23008-12 Section 6.5.12
+type ImirBox struct {
+	Axis byte
+}
+
+// DecodeImir - box-specific decode
+func DecodeImir(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeImirSR(hdr, startPos, sr)
+}
+
+// DecodeImirSR - box-specific decode
+func DecodeImirSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := &ImirBox{Axis: sr.ReadUint8() & 0x01}
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *ImirBox) Type() string {
+	return "imir"
+}
+
+// Size - return calculated size
+func (b *ImirBox) Size() uint64 {
+	return uint64(boxHeaderSize + 1)
+}
+
+// Encode - write box to w
+func (b *ImirBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *ImirBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint8(b.Axis & 0x01)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *ImirBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - axis: %d", b.Axis)
+	return bd.err
+}
@@ -0,0 +1,11 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestImir(t *testing.T) {
+	boxDiffAfterEncodeAndDecode(t, &mp4.ImirBox{Axis: 1})
+}
@@ -0,0 +1,207 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// InfeBox - Item Information Entry Box (infe)
+//
+// Contained in : Item Information Box (iinf)
+//
+// Defined in ISO/IEC 14496-12 Section 8.11.6. Versions 0 and 1 are decoded without
+// the version 1 extension. Version 2 and 3 have an item type, and version 3 has 32-bit item IDs.
+type InfeBox struct {
+	Version             byte
+	Flags               uint32
+	ItemID              uint32
+	ItemProtectionIndex uint16
+	ItemType            string
+	ItemName            string
+	ContentType         string
+	ContentEncoding     string
+	ItemURIType         string
+	hasContentEncoding  bool
+}
+
+// InfeHiddenItemFlag - flag for items that should not be displayed
+const InfeHiddenItemFlag = 0x000001
+
+// CreateInfe - create an infe box of version 2, or version 3 if itemID does not fit in 16 bits
+func CreateInfe(itemID uint32, itemType, itemName string, hidden bool) *InfeBox {
+	b := &InfeBox{
+		Version:  2,
+		ItemID:   itemID,
+		ItemType: itemType,
+		ItemName: itemName,
+	}
+	if itemID > 0xffff {
+		b.Version = 3
+	}
+	if hidden {
+		b.Flags = InfeHiddenItemFlag
+	}
+	return b
+}
+
+// DecodeInfe - box-specific decode
+func DecodeInfe(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeInfeSR(hdr, startPos, sr)
+}
+
+// DecodeInfeSR - box-specific decode
+func DecodeInfeSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &InfeBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	endPos := sr.GetPos() + hdr.payloadLen() - 4
+	maxLen := func() int { return endPos - sr.GetPos() }
+	switch b.Version {
+	case 0, 1:
+		b.ItemID = uint32(sr.ReadUint16())
+		b.ItemProtectionIndex = sr.ReadUint16()
+		b.ItemName = sr.ReadZeroTerminatedString(maxLen())
+		b.ContentType = sr.ReadZeroTerminatedString(maxLen())
+		if maxLen() > 0 {
+			b.ContentEncoding = sr.ReadZeroTerminatedString(maxLen())
+			b.hasContentEncoding = true
+		}
+	case 2, 3:
+		if b.Version == 2 {
+			b.ItemID = uint32(sr.ReadUint16())
+		} else {
+			b.ItemID = sr.ReadUint32()
+		}
+		b.ItemProtectionIndex = sr.ReadUint16()
+		b.ItemType = sr.ReadFixedLengthString(4)
+		b.ItemName = sr.ReadZeroTerminatedString(maxLen())
+		switch b.ItemType {
+		case "mime":
+			b.ContentType = sr.ReadZeroTerminatedString(maxLen())
+			if maxLen() > 0 {
+				b.ContentEncoding = sr.ReadZeroTerminatedString(maxLen())
+				b.hasContentEncoding = true
+			}
+		case "uri ":
+			b.ItemURIType = sr.ReadZeroTerminatedString(maxLen())
+		}
+	default:
+		return nil, fmt.Errorf("infe: unknown version %d", b.Version)
+	}
+	if sr.AccError() == nil && maxLen() != 0 {
+		sr.SkipBytes(maxLen()) // Ignore version 1 extension
+	}
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *InfeBox) Type() string {
+	return "infe"
+}
+
+// Size - return calculated size
+func (b *InfeBox) Size() uint64 {
+	size := boxHeaderSize + 4 + 2 + 2 + len(b.ItemName) + 1
+	if b.Version == 3 {
+		size += 2
+	}
+	if b.Version >= 2 {
+		size += 4
+	}
+	switch {
+	case b.Version < 2 || b.ItemType == "mime":
+		size += len(b.ContentType) + 1
+		if b.writeContentEncoding() {
+			size += len(b.ContentEncoding) + 1
+		}
+	case b.ItemType == "uri ":
+		size += len(b.ItemURIType) + 1
+	}
+	return uint64(size)
+}
+
+func (b *InfeBox) writeContentEncoding() bool {
+	return b.hasContentEncoding || b.ContentEncoding != ""
+}
+
+// IsHidden - true if the hidden item flag is set
+func (b *InfeBox) IsHidden() bool {
+	return b.Flags&InfeHiddenItemFlag != 0
+}
+
+// Encode - write box to w
+func (b *InfeBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *InfeBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	if b.Version == 3 {
+		sw.WriteUint32(b.ItemID)
+	} else {
+		sw.WriteUint16(uint16(b.ItemID))
+	}
+	sw.WriteUint16(b.ItemProtectionIndex)
+	if b.Version >= 2 {
+		if len(b.ItemType) != 4 {
+			return fmt.Errorf("infe: item type %q is not 4 characters", b.ItemType)
+		}
+		sw.WriteString(b.ItemType, false)
+	}
+	sw.WriteString(b.ItemName, true)
+	switch {
+	case b.Version < 2 || b.ItemType == "mime":
+		sw.WriteString(b.ContentType, true)
+		if b.writeContentEncoding() {
+			sw.WriteString(b.ContentEncoding, true)
+		}
+	case b.ItemType == "uri ":
+		sw.WriteString(b.ItemURIType, true)
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *InfeBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - itemID: %d", b.ItemID)
+	bd.write(" - itemProtectionIndex: %d", b.ItemProtectionIndex)
+	if b.Version >= 2 {
+		bd.write(" - itemType: %q", b.ItemType)
+	}
+	bd.write(" - itemName: %q", b.ItemName)
+	switch {
+	case b.Version < 2 || b.ItemType == "mime":
+		bd.write(" - contentType: %q", b.ContentType)
+		if b.writeContentEncoding() {
+			bd.write(" - contentEncoding: %q", b.ContentEncoding)
+		}
+	case b.ItemType == "uri ":
+		bd.write(" - itemURIType: %q", b.ItemURIType)
+	}
+	if b.IsHidden() {
+		bd.write(" - hidden")
+	}
+	return bd.err
+}
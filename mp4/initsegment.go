@@ -8,6 +8,7 @@ import (
 	"github.com/Eyevinn/mp4ff/aac"
 	"github.com/Eyevinn/mp4ff/avc"
 	"github.com/Eyevinn/mp4ff/bits"
+	"github.com/Eyevinn/mp4ff/dovi"
 	"github.com/Eyevinn/mp4ff/hevc"
 )
 
@@ -153,14 +154,18 @@ func CreateEmptyTrak(trackID, timeScale uint32, mediaType, language string) *Tra
 	minf := NewMinfBox()
 	mdia.AddChild(minf)
 	switch mediaType {
-	case "video":
+	case "video", "picture", "pict":
 		minf.AddChild(CreateVmhd())
 	case "audio":
 		minf.AddChild(CreateSmhd())
 	case "subtitle", "subtitles", "stpp":
 		minf.AddChild(&SthdBox{})
-	case "text", "wvtt":
+	case "text", "wvtt", "tx3g":
 		minf.AddChild(&NmhdBox{})
+	case "meta", "mett", "metx", "urim", "evte":
+		minf.AddChild(&NmhdBox{})
+	case "timecode", "tmcd":
+		minf.AddChild(CreateTimecodeGmhd())
 	default:
 		minf.AddChild(&NmhdBox{})
 	}
@@ -237,6 +242,66 @@ func (t *TrakBox) SetHEVCDescriptor(sampleDescriptorType string, vpsNALUs, spsNA
 	return nil
 }
 
+// SetDolbyVisionConfig adds Dolby Vision configuration to the visual sample entry of the track.
+// See VisualSampleEntryBox.SetDolbyVisionConfig for changes of sample entry type.
+func (t *TrakBox) SetDolbyVisionConfig(rec dovi.DecConfRec) error {
+	vse, err := t.visualSampleEntry()
+	if err != nil {
+		return err
+	}
+	return vse.SetDolbyVisionConfig(rec)
+}
+
+// SetStereoMode adds or replaces the st3d box of the visual sample entry. See StereoModeMono etc.
+func (t *TrakBox) SetStereoMode(stereoMode byte) error {
+	vse, err := t.visualSampleEntry()
+	if err != nil {
+		return err
+	}
+	vse.replaceChild(&St3dBox{StereoMode: stereoMode}, "st3d")
+	return nil
+}
+
+// SetSphericalVideo adds or replaces the sv3d box of the visual sample entry.
+func (t *TrakBox) SetSphericalVideo(sv3d *Sv3dBox) error {
+	vse, err := t.visualSampleEntry()
+	if err != nil {
+		return err
+	}
+	vse.replaceChild(sv3d, "sv3d")
+	return nil
+}
+
+// SetVideoExtendedUsage adds or replaces the vexu box of the visual sample entry.
+func (t *TrakBox) SetVideoExtendedUsage(vexu *VexuBox) error {
+	vse, err := t.visualSampleEntry()
+	if err != nil {
+		return err
+	}
+	vse.replaceChild(vexu, "vexu")
+	return nil
+}
+
+// visualSampleEntry returns the first visual sample entry of the track.
+func (t *TrakBox) visualSampleEntry() (*VisualSampleEntryBox, error) {
+	for _, c := range t.Mdia.Minf.Stbl.Stsd.Children {
+		if vse, ok := c.(*VisualSampleEntryBox); ok {
+			return vse, nil
+		}
+	}
+	return nil, fmt.Errorf("no visual sample entry in track %d", t.Tkhd.TrackID)
+}
+
+// SetDolbyVisionConfig adds Dolby Vision configuration to the track with trackID.
+func (s *InitSegment) SetDolbyVisionConfig(trackID uint32, rec dovi.DecConfRec) error {
+	for _, trak := range s.Moov.Traks {
+		if trak.Tkhd.TrackID == trackID {
+			return trak.SetDolbyVisionConfig(rec)
+		}
+	}
+	return fmt.Errorf("no track with ID %d", trackID)
+}
+
 // GetMediaType - should return video or audio (at present)
 func (s *InitSegment) GetMediaType() string {
 	switch s.Moov.Trak.Mdia.Hdlr.HandlerType {
@@ -349,6 +414,62 @@ func (t *TrakBox) SetWvttDescriptor(config string) error {
 	return nil
 }
 
+// SetTx3gDescriptor - add tx3g box with an ftab box with one font. The default style uses the font and fontSize
+// with white text, and the text is centered at the bottom of the track region.
+func (t *TrakBox) SetTx3gDescriptor(fontID uint16, fontName string, fontSize byte) error {
+	if len(fontName) > 255 {
+		return fmt.Errorf("font name %q too long", fontName)
+	}
+	tx3g := NewTx3gBox()
+	tx3g.HorizontalJustification = 1
+	tx3g.VerticalJustification = -1
+	tx3g.DefaultStyle = StyleRecord{FontID: fontID, FontSize: fontSize, TextColorRGBA: 0xffffffff}
+	tx3g.AddChild(&FtabBox{Fonts: []FontRecord{{FontID: fontID, FontName: fontName}}})
+	t.Mdia.Minf.Stbl.Stsd.AddChild(tx3g)
+	return nil
+}
+
+// SetMettDescriptor - add mett box for text timed metadata with MIME type mimeFormat.
+// contentEncoding is optional, e.g. "application/zip".
+func (t *TrakBox) SetMettDescriptor(contentEncoding, mimeFormat string) error {
+	if mimeFormat == "" {
+		return fmt.Errorf("mett mimeFormat must not be empty")
+	}
+	t.Mdia.Minf.Stbl.Stsd.AddChild(NewMettBox(contentEncoding, mimeFormat))
+	return nil
+}
+
+// SetMetxDescriptor - add metx box for XML timed metadata with space-separated namespace and schemaLocation lists.
+// contentEncoding and schemaLocation are optional.
+func (t *TrakBox) SetMetxDescriptor(contentEncoding, namespace, schemaLocation string) error {
+	if namespace == "" {
+		return fmt.Errorf("metx namespace must not be empty")
+	}
+	t.Mdia.Minf.Stbl.Stsd.AddChild(NewMetxBox(contentEncoding, namespace, schemaLocation))
+	return nil
+}
+
+// SetUrimDescriptor - add urim box with uri box for timed metadata identified by uri,
+// and a uriI box if initData is not empty.
+func (t *TrakBox) SetUrimDescriptor(uri string, initData []byte) error {
+	if uri == "" {
+		return fmt.Errorf("urim uri must not be empty")
+	}
+	t.Mdia.Minf.Stbl.Stsd.AddChild(NewUrimBox(uri, initData))
+	return nil
+}
+
+// SetEvteDescriptor - add evte box for an event message track (ISO/IEC 23001-18).
+// A silb box is added if schemes is not empty or otherSchemes is true.
+func (t *TrakBox) SetEvteDescriptor(schemes []SilbEntry, otherSchemes bool) error {
+	evte := &EvteBox{DataReferenceIndex: 1}
+	if len(schemes) > 0 || otherSchemes {
+		evte.AddChild(&SilbBox{Schemes: schemes, OtherSchemesFlag: otherSchemes})
+	}
+	t.Mdia.Minf.Stbl.Stsd.AddChild(evte)
+	return nil
+}
+
 // SetStppDescriptor - add stpp box with utf8-lists namespace, schemaLocation and auxiliaryMimeType
 // The utf8-lists have space-separated items, but no zero-termination
 func (t *TrakBox) SetStppDescriptor(namespace, schemaLocation, auxiliaryMimeTypes string) error {
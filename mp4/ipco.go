@@ -0,0 +1,76 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// IpcoBox - Item Property Container Box (ipco)
+//
+// Contained in : Item Properties Box (iprp)
+//
+// The properties are referred to by their one-based index in ipma boxes.
+// Defined in ISO/IEC 23008-12 Section 9.3
+type IpcoBox struct {
+	Children []Box
+}
+
+// AddChild - add a property box
+func (b *IpcoBox) AddChild(child Box) {
+	b.Children = append(b.Children, child)
+}
+
+// AddProperty - add a property box and return its one-based index for ipma
+func (b *IpcoBox) AddProperty(property Box) uint16 {
+	b.AddChild(property)
+	return uint16(len(b.Children))
+}
+
+// DecodeIpco - box-specific decode
+func DecodeIpco(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.Size, r)
+	if err != nil {
+		return nil, err
+	}
+	return &IpcoBox{Children: children}, nil
+}
+
+// DecodeIpcoSR - box-specific decode
+func DecodeIpcoSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.Size, sr)
+	if err != nil {
+		return nil, err
+	}
+	return &IpcoBox{Children: children}, nil
+}
+
+// Type - return box type
+func (b *IpcoBox) Type() string {
+	return "ipco"
+}
+
+// Size - return calculated size
+func (b *IpcoBox) Size() uint64 {
+	return containerSize(b.Children)
+}
+
+// GetChildren - list of child boxes
+func (b *IpcoBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write ipco container to w
+func (b *IpcoBox) Encode(w io.Writer) error {
+	return EncodeContainer(b, w)
+}
+
+// EncodeSW - write ipco container via sw
+func (b *IpcoBox) EncodeSW(sw bits.SliceWriter) error {
+	return EncodeContainerSW(b, sw)
+}
+
+// Info - write box-specific information
+func (b *IpcoBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
+}
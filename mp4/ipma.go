@@ -0,0 +1,179 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// IpmaBox - Item Property Association Box (ipma)
+//
+// Contained in : Item Properties Box (iprp)
+//
+// Defined in ISO/IEC 23008-12 Section 9.3. Version 0 has 16-bit and version 1 has 32-bit item IDs.
+// If flags bit 0 is set, property indices have 15 bits instead of 7.
+type IpmaBox struct {
+	Version byte
+	Flags   uint32
+	Entries []IpmaEntry
+}
+
+// IpmaEntry - property associations of an item
+type IpmaEntry struct {
+	ItemID       uint32
+	Associations []PropertyAssociation
+}
+
+// PropertyAssociation - one-based index to a property in ipco. Index 0 means no property.
+type PropertyAssociation struct {
+	Essential bool
+	Index     uint16
+}
+
+// IpmaLargeIndexFlag - flag for 15-bit property indices
+const IpmaLargeIndexFlag = 0x000001
+
+// DecodeIpma - box-specific decode
+func DecodeIpma(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeIpmaSR(hdr, startPos, sr)
+}
+
+// DecodeIpmaSR - box-specific decode
+func DecodeIpmaSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &IpmaBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	entryCount := sr.ReadUint32()
+	for i := uint32(0); i < entryCount; i++ {
+		if sr.AccError() != nil {
+			break
+		}
+		var e IpmaEntry
+		if b.Version < 1 {
+			e.ItemID = uint32(sr.ReadUint16())
+		} else {
+			e.ItemID = sr.ReadUint32()
+		}
+		count := sr.ReadUint8()
+		for j := byte(0); j < count; j++ {
+			var a PropertyAssociation
+			if b.Flags&IpmaLargeIndexFlag != 0 {
+				v := sr.ReadUint16()
+				a.Essential = v>>15 == 1
+				a.Index = v & 0x7fff
+			} else {
+				v := sr.ReadUint8()
+				a.Essential = v>>7 == 1
+				a.Index = uint16(v & 0x7f)
+			}
+			e.Associations = append(e.Associations, a)
+		}
+		b.Entries = append(b.Entries, e)
+	}
+	return b, sr.AccError()
+}
+
+// AddEntry - add property associations for an item and set flags for large indices if needed
+func (b *IpmaBox) AddEntry(itemID uint32, associations ...PropertyAssociation) {
+	if itemID > 0xffff {
+		b.Version = 1
+	}
+	for _, a := range associations {
+		if a.Index > 0x7f {
+			b.Flags |= IpmaLargeIndexFlag
+		}
+	}
+	b.Entries = append(b.Entries, IpmaEntry{ItemID: itemID, Associations: associations})
+}
+
+// Type - return box type
+func (b *IpmaBox) Type() string {
+	return "ipma"
+}
+
+// Size - return calculated size
+func (b *IpmaBox) Size() uint64 {
+	size := uint64(boxHeaderSize + 4 + 4)
+	idSize, indexSize := uint64(2), uint64(1)
+	if b.Version >= 1 {
+		idSize = 4
+	}
+	if b.Flags&IpmaLargeIndexFlag != 0 {
+		indexSize = 2
+	}
+	for _, e := range b.Entries {
+		size += idSize + 1 + uint64(len(e.Associations))*indexSize
+	}
+	return size
+}
+
+// Encode - write box to w
+func (b *IpmaBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *IpmaBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint32(uint32(len(b.Entries)))
+	for _, e := range b.Entries {
+		if b.Version < 1 {
+			sw.WriteUint16(uint16(e.ItemID))
+		} else {
+			sw.WriteUint32(e.ItemID)
+		}
+		sw.WriteUint8(byte(len(e.Associations)))
+		for _, a := range e.Associations {
+			if b.Flags&IpmaLargeIndexFlag != 0 {
+				v := a.Index & 0x7fff
+				if a.Essential {
+					v |= 0x8000
+				}
+				sw.WriteUint16(v)
+			} else {
+				v := byte(a.Index & 0x7f)
+				if a.Essential {
+					v |= 0x80
+				}
+				sw.WriteUint8(v)
+			}
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *IpmaBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	for _, e := range b.Entries {
+		msg := ""
+		for _, a := range e.Associations {
+			if a.Essential {
+				msg += fmt.Sprintf(" %d!", a.Index)
+			} else {
+				msg += fmt.Sprintf(" %d", a.Index)
+			}
+		}
+		bd.write(" - item %d properties:%s", e.ItemID, msg)
+	}
+	return bd.err
+}
@@ -0,0 +1,34 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestIpma(t *testing.T) {
+	ipma := &mp4.IpmaBox{}
+	ipma.AddEntry(1, mp4.PropertyAssociation{Essential: true, Index: 1}, mp4.PropertyAssociation{Index: 2})
+	boxDiffAfterEncodeAndDecode(t, ipma)
+	ipma.AddEntry(70000, mp4.PropertyAssociation{Index: 300})
+	if ipma.Version != 1 || ipma.Flags != mp4.IpmaLargeIndexFlag {
+		t.Errorf("got version %d and flags %d", ipma.Version, ipma.Flags)
+	}
+	boxDiffAfterEncodeAndDecode(t, ipma)
+}
+
+func TestIprp(t *testing.T) {
+	ipco := &mp4.IpcoBox{}
+	ispeIdx := ipco.AddProperty(&mp4.IspeBox{Width: 64, Height: 32})
+	irotIdx := ipco.AddProperty(&mp4.IrotBox{Angle: 1})
+	ipma := &mp4.IpmaBox{}
+	ipma.AddEntry(1, mp4.PropertyAssociation{Index: ispeIdx}, mp4.PropertyAssociation{Essential: true, Index: irotIdx})
+	iprp := &mp4.IprpBox{}
+	iprp.AddChild(ipco)
+	iprp.AddChild(ipma)
+	boxDiffAfterEncodeAndDecode(t, iprp)
+	props, essential := iprp.GetItemProperties(1)
+	if len(props) != 2 || props[1].Type() != "irot" || essential[0] || !essential[1] {
+		t.Errorf("got properties %v essential %v", props, essential)
+	}
+}
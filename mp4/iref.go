@@ -0,0 +1,158 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// IrefBox - Item Reference Box (iref)
+//
+// Contained in : Meta Box (meta)
+//
+// Defined in ISO/IEC 14496-12 Section 8.11.12. The SingleItemTypeReferenceBoxes
+// are stored as References. Version 0 has 16-bit and version 1 has 32-bit item IDs.
+type IrefBox struct {
+	Version    byte
+	Flags      uint32
+	References []ItemReference
+}
+
+// ItemReference - reference of a type (like dimg, thmb, auxl, or cdsc) from one item to other items
+type ItemReference struct {
+	Type       string
+	FromItemID uint32
+	ToItemIDs  []uint32
+}
+
+// DecodeIref - box-specific decode
+func DecodeIref(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeIrefSR(hdr, startPos, sr)
+}
+
+// DecodeIrefSR - box-specific decode
+func DecodeIrefSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &IrefBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	endPos := sr.GetPos() + hdr.payloadLen() - 4
+	for sr.GetPos() < endPos && sr.AccError() == nil {
+		refStart := sr.GetPos()
+		size := int(sr.ReadUint32())
+		ref := ItemReference{Type: sr.ReadFixedLengthString(4)}
+		ref.FromItemID = b.readItemID(sr)
+		refCount := sr.ReadUint16()
+		for i := uint16(0); i < refCount; i++ {
+			ref.ToItemIDs = append(ref.ToItemIDs, b.readItemID(sr))
+		}
+		if sr.AccError() == nil && sr.GetPos()-refStart != size {
+			return nil, fmt.Errorf("iref: reference %s has size %d, but %d bytes read", ref.Type, size, sr.GetPos()-refStart)
+		}
+		b.References = append(b.References, ref)
+	}
+	return b, sr.AccError()
+}
+
+func (b *IrefBox) readItemID(sr bits.SliceReader) uint32 {
+	if b.Version == 0 {
+		return uint32(sr.ReadUint16())
+	}
+	return sr.ReadUint32()
+}
+
+func (b *IrefBox) writeItemID(sw bits.SliceWriter, itemID uint32) {
+	if b.Version == 0 {
+		sw.WriteUint16(uint16(itemID))
+	} else {
+		sw.WriteUint32(itemID)
+	}
+}
+
+func (b *IrefBox) referenceSize(ref ItemReference) uint64 {
+	idSize := uint64(2)
+	if b.Version != 0 {
+		idSize = 4
+	}
+	return boxHeaderSize + idSize + 2 + uint64(len(ref.ToItemIDs))*idSize
+}
+
+// AddReference - add a reference of refType from an item to other items
+func (b *IrefBox) AddReference(refType string, fromItemID uint32, toItemIDs ...uint32) {
+	b.References = append(b.References, ItemReference{Type: refType, FromItemID: fromItemID, ToItemIDs: toItemIDs})
+}
+
+// GetReferences - get item IDs referenced with refType from item fromItemID
+func (b *IrefBox) GetReferences(refType string, fromItemID uint32) []uint32 {
+	var ids []uint32
+	for _, ref := range b.References {
+		if ref.Type == refType && ref.FromItemID == fromItemID {
+			ids = append(ids, ref.ToItemIDs...)
+		}
+	}
+	return ids
+}
+
+// Type - return box type
+func (b *IrefBox) Type() string {
+	return "iref"
+}
+
+// Size - return calculated size
+func (b *IrefBox) Size() uint64 {
+	size := uint64(boxHeaderSize + 4)
+	for _, ref := range b.References {
+		size += b.referenceSize(ref)
+	}
+	return size
+}
+
+// Encode - write box to w
+func (b *IrefBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *IrefBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	for _, ref := range b.References {
+		if len(ref.Type) != 4 {
+			return fmt.Errorf("iref: reference type %q is not 4 characters", ref.Type)
+		}
+		sw.WriteUint32(uint32(b.referenceSize(ref)))
+		sw.WriteString(ref.Type, false)
+		b.writeItemID(sw, ref.FromItemID)
+		sw.WriteUint16(uint16(len(ref.ToItemIDs)))
+		for _, id := range ref.ToItemIDs {
+			b.writeItemID(sw, id)
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *IrefBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	for _, ref := range b.References {
+		bd.write(" - %s: from %d to %v", ref.Type, ref.FromItemID, ref.ToItemIDs)
+	}
+	return bd.err
+}
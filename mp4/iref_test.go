@@ -0,0 +1,19 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestIref(t *testing.T) {
+	for _, version := range []byte{0, 1} {
+		iref := &mp4.IrefBox{Version: version}
+		iref.AddReference("dimg", 5, 1, 2, 3, 4)
+		iref.AddReference("thmb", 6, 5)
+		boxDiffAfterEncodeAndDecode(t, iref)
+		if ids := iref.GetReferences("dimg", 5); len(ids) != 4 || ids[3] != 4 {
+			t.Errorf("got dimg references %v", ids)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// IrotBox - Image Rotation Property (irot)
+//
+// Contained in : Item Property Container Box (ipco)
+//
+// Angle is the anti-clockwise rotation in units of 90 degrees (0-3).
+// Defined in ISO/IEC 23008-12 Section 6.5.10
+type IrotBox struct {
+	Angle byte
+}
+
+// DecodeIrot - box-specific decode
+func DecodeIrot(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeIrotSR(hdr, startPos, sr)
+}
+
+// DecodeIrotSR - box-specific decode
+func DecodeIrotSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := &IrotBox{Angle: sr.ReadUint8() & 0x03}
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *IrotBox) Type() string {
+	return "irot"
+}
+
+// Size - return calculated size
+func (b *IrotBox) Size() uint64 {
+	return uint64(boxHeaderSize + 1)
+}
+
+// Encode - write box to w
+func (b *IrotBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *IrotBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint8(b.Angle & 0x03)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *IrotBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - angle: %d (%d degrees anti-clockwise)", b.Angle, 90*int(b.Angle))
+	return bd.err
+}
@@ -0,0 +1,11 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestIrot(t *testing.T) {
+	boxDiffAfterEncodeAndDecode(t, &mp4.IrotBox{Angle: 3})
+}
@@ -0,0 +1,83 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// IspeBox - Image Spatial Extents Property (ispe)
+//
+// Contained in : Item Property Container Box (ipco)
+//
+// Defined in ISO/IEC 23008-12 Section 6.5.3
+type IspeBox struct {
+	Version byte
+	Flags   uint32
+	Width   uint32
+	Height  uint32
+}
+
+// DecodeIspe - box-specific decode
+func DecodeIspe(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeIspeSR(hdr, startPos, sr)
+}
+
+// DecodeIspeSR - box-specific decode
+func DecodeIspeSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &IspeBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+		Width:   sr.ReadUint32(),
+		Height:  sr.ReadUint32(),
+	}
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *IspeBox) Type() string {
+	return "ispe"
+}
+
+// Size - return calculated size
+func (b *IspeBox) Size() uint64 {
+	return uint64(boxHeaderSize + 12)
+}
+
+// Encode - write box to w
+func (b *IspeBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *IspeBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint32(b.Width)
+	sw.WriteUint32(b.Height)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *IspeBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - width: %d", b.Width)
+	bd.write(" - height: %d", b.Height)
+	return bd.err
+}
@@ -0,0 +1,11 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestIspe(t *testing.T) {
+	boxDiffAfterEncodeAndDecode(t, &mp4.IspeBox{Width: 1920, Height: 1080})
+}
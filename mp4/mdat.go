@@ -11,13 +11,16 @@ import (
 // MdatBox - Media Data Box (mdat)
 // The mdat box contains media chunks/samples.
 // DataParts is to be able to gather output data without
-// new allocations
+// new allocations.
+// SizeToEnd is set when decoding an mdat box with size 0, meaning that it extends to the end of the file.
+// If set, the box is also encoded with size 0, so it must then be the last box in the output.
 type MdatBox struct {
-	StartPos     uint64
+	StartPos     uint64 `json:"-"`
 	Data         []byte
 	DataParts    [][]byte
 	lazyDataSize uint64
 	LargeSize    bool
+	SizeToEnd    bool
 }
 
 const maxNormalPayloadSize = (1 << 32) - 1 - 8
@@ -29,7 +32,7 @@ func DecodeMdat(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 		return nil, err
 	}
 	largeSize := hdr.Hdrlen > boxHeaderSize
-	return &MdatBox{startPos, data, nil, 0, largeSize}, nil
+	return &MdatBox{StartPos: startPos, Data: data, LargeSize: largeSize, SizeToEnd: hdr.SizeToEnd}, nil
 }
 
 // DecodeMdatSR decodes an mdat box
@@ -38,7 +41,8 @@ func DecodeMdat(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 // If not enough content, an accumulated error is stored in sr, though
 func DecodeMdatSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	largeSize := hdr.Hdrlen > boxHeaderSize
-	return &MdatBox{startPos, sr.ReadBytes(hdr.payloadLen()), nil, 0, largeSize}, nil
+	return &MdatBox{StartPos: startPos, Data: sr.ReadBytes(hdr.payloadLen()), LargeSize: largeSize,
+		SizeToEnd: hdr.SizeToEnd}, nil
 }
 
 // IsLazy - is the mdat data handled lazily (with separate writer/reader).
@@ -50,7 +54,8 @@ func (m *MdatBox) IsLazy() bool {
 func DecodeMdatLazily(hdr BoxHeader, startPos uint64) (Box, error) {
 	largeSize := hdr.Hdrlen > boxHeaderSize
 	decLazyDataSize := hdr.Size - uint64(hdr.Hdrlen)
-	return &MdatBox{startPos, nil, nil, decLazyDataSize, largeSize}, nil
+	return &MdatBox{StartPos: startPos, lazyDataSize: decLazyDataSize, LargeSize: largeSize,
+		SizeToEnd: hdr.SizeToEnd}, nil
 }
 
 // SetLazyDataSize - set size of mdat lazy data so that the data can be written separately
@@ -76,6 +81,9 @@ func (m *MdatBox) Size() uint64 {
 	if m.lazyDataSize > 0 {
 		dataSize = m.lazyDataSize
 	}
+	if m.SizeToEnd {
+		return boxHeaderSize + dataSize // No size in header, so no need for largeSize
+	}
 	if dataSize > maxNormalPayloadSize {
 		m.LargeSize = true
 	}
@@ -110,7 +118,7 @@ func (m *MdatBox) AddSampleDataPart(s []byte) {
 
 // Encode - write box to w. If m.lazyDataSize > 0, the mdat data needs to be written separately
 func (m *MdatBox) Encode(w io.Writer) error {
-	err := EncodeHeaderWithSize("mdat", m.Size(), m.LargeSize, w)
+	err := EncodeHeaderWithSize("mdat", m.headerSizeField(), m.LargeSize && !m.SizeToEnd, w)
 	if err != nil {
 		return err
 	}
@@ -130,7 +138,7 @@ func (m *MdatBox) Encode(w io.Writer) error {
 
 // Encode - write box to sw. If m.lazyDataSize > 0, the mdat data needs to be written separately
 func (m *MdatBox) EncodeSW(sw bits.SliceWriter) error {
-	err := EncodeHeaderWithSizeSW("mdat", m.Size(), m.LargeSize, sw)
+	err := EncodeHeaderWithSizeSW("mdat", m.headerSizeField(), m.LargeSize && !m.SizeToEnd, sw)
 	if err != nil {
 		return err
 	}
@@ -145,6 +153,14 @@ func (m *MdatBox) EncodeSW(sw bits.SliceWriter) error {
 	return sw.AccError()
 }
 
+// headerSizeField - size to write in header. 0 if SizeToEnd is set
+func (m *MdatBox) headerSizeField() uint64 {
+	if m.SizeToEnd {
+		return 0
+	}
+	return m.Size()
+}
+
 // DataLength - length of data stored in box either as one or multiple parts
 func (m *MdatBox) DataLength() uint64 {
 	dataLength := len(m.Data)
@@ -160,13 +176,16 @@ func (m *MdatBox) DataLength() uint64 {
 // Info - write box-specific information
 func (m *MdatBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	bd := newInfoDumper(w, indent, m, -1, 0)
+	if m.SizeToEnd {
+		bd.write(" - size 0: extends to end of file")
+	}
 	return bd.err
 }
 
 // HeaderSize - 8 or 16 (bytes) depending o whether largeSize is used
 func (m *MdatBox) HeaderSize() uint64 {
 	hSize := boxHeaderSize
-	if m.LargeSize {
+	if m.LargeSize && !m.SizeToEnd {
 		hSize += largeSizeLen
 	}
 	return uint64(hSize)
@@ -207,7 +226,7 @@ func (m *MdatBox) ReadData(start, size int64, rs io.ReadSeeker) ([]byte, error)
 
 	// validate if indexes are valid to avoid panics
 	dataLen := m.DataLength()
-	if offsetInMdatData >= dataLen || endIndexInMdatData >= dataLen {
+	if offsetInMdatData >= dataLen || endIndexInMdatData > dataLen {
 		return nil, fmt.Errorf("normal mdat mode - invalid range provided")
 	}
 	if len(m.DataParts) > 0 {
@@ -240,7 +259,7 @@ func (m *MdatBox) CopyData(start, size int64, rs io.ReadSeeker, w io.Writer) (nr
 
 	// validate if indexes are valid to avoid panics
 	dataLen := m.DataLength()
-	if offsetInMdatData >= dataLen || endIndexInMdatData >= dataLen {
+	if offsetInMdatData >= dataLen || endIndexInMdatData > dataLen {
 		return 0, fmt.Errorf("normal mdat mode - invalid range provided")
 	}
 	if len(m.DataParts) > 0 {
@@ -250,3 +269,14 @@ func (m *MdatBox) CopyData(start, size int64, rs io.ReadSeeker, w io.Writer) (nr
 	n, err = w.Write(m.Data[offsetInMdatData : offsetInMdatData+uint64(size)])
 	return int64(n), err
 }
+
+// addJSONFields - replace media data by its length
+func (m *MdatBox) addJSONFields(fields map[string]interface{}) {
+	delete(fields, "Data")
+	delete(fields, "DataParts")
+	dataLength := m.DataLength()
+	if m.IsLazy() {
+		dataLength = m.lazyDataSize
+	}
+	fields["DataLength"] = dataLength
+}
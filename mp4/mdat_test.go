@@ -2,8 +2,12 @@ package mp4_test
 
 import (
 	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
 	"testing"
 
+	"github.com/Eyevinn/mp4ff/bits"
 	"github.com/Eyevinn/mp4ff/mp4"
 	"github.com/go-test/deep"
 )
@@ -206,3 +210,97 @@ func TestAddParts(t *testing.T) {
 		t.Errorf("expected %v, got %v", outBufExp.Bytes(), outBuf.Bytes())
 	}
 }
+
+func TestDecodeMdatSizeToEnd(t *testing.T) {
+	data, err := os.ReadFile("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Drop the final free box and set the size of the last mdat box to 0
+	const mdatPos, mdatSize = 6360, 183146
+	origData := data[:mdatPos+mdatSize]
+	zeroData := make([]byte, len(origData))
+	copy(zeroData, origData)
+	binary.BigEndian.PutUint32(zeroData[mdatPos:], 0)
+
+	tbi, err := mp4.GetTopBoxInfoList(bytes.NewReader(zeroData), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tbi) != 3 || tbi[2].Type != "mdat" || tbi[2].Size != mdatSize {
+		t.Errorf("got top boxes %v", tbi)
+	}
+
+	testCases := []struct {
+		name   string
+		decode func() (*mp4.File, error)
+		lazy   bool
+	}{
+		{"bytes.Reader", func() (*mp4.File, error) { return mp4.DecodeFile(bytes.NewReader(zeroData)) }, false},
+		{"bytes.Buffer", func() (*mp4.File, error) { return mp4.DecodeFile(bytes.NewBuffer(zeroData)) }, false},
+		{"plain reader", func() (*mp4.File, error) { return mp4.DecodeFile(io.MultiReader(bytes.NewReader(zeroData))) }, false},
+		{"slice reader", func() (*mp4.File, error) { return mp4.DecodeFileSR(bits.NewFixedSliceReader(zeroData)) }, false},
+		{"lazy mdat", func() (*mp4.File, error) {
+			return mp4.DecodeFile(bytes.NewReader(zeroData), mp4.WithDecodeMode(mp4.DecModeLazyMdat))
+		}, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := tc.decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.Mdat == nil || !f.Mdat.SizeToEnd {
+				t.Fatal("mdat with SizeToEnd not found")
+			}
+			if f.Mdat.Size() != mdatSize {
+				t.Errorf("mdat size %d instead of %d", f.Mdat.Size(), mdatSize)
+			}
+			if tc.lazy {
+				if f.Mdat.GetLazyDataSize() != mdatSize-8 {
+					t.Errorf("lazy data size %d instead of %d", f.Mdat.GetLazyDataSize(), mdatSize-8)
+				}
+				return
+			}
+			var buf bytes.Buffer
+			if err = f.Encode(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), zeroData) {
+				t.Error("size 0 not kept in round trip")
+			}
+			f.Mdat.SizeToEnd = false
+			buf.Reset()
+			if err = f.Encode(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), origData) {
+				t.Error("explicit mdat size not written")
+			}
+		})
+	}
+}
+
+func TestMdatReadDataAtEnd(t *testing.T) {
+	mdat := &mp4.MdatBox{StartPos: 100}
+	mdat.AddSampleData([]byte{0, 1, 2, 3, 4, 5, 6, 7})
+	start := int64(mdat.PayloadAbsoluteOffset()) + 5
+	data, err := mdat.ReadData(start, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{5, 6, 7}) {
+		t.Errorf("got %v instead of [5 6 7]", data)
+	}
+	buf := bytes.Buffer{}
+	n, err := mdat.CopyData(start, 3, nil, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || !bytes.Equal(buf.Bytes(), []byte{5, 6, 7}) {
+		t.Errorf("copied %v instead of [5 6 7]", buf.Bytes())
+	}
+	if _, err := mdat.ReadData(start, 4, nil); err == nil {
+		t.Error("expected error for range beyond end of mdat")
+	}
+}
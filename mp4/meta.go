@@ -19,6 +19,12 @@ type MetaBox struct {
 	Version     byte
 	Flags       uint32
 	Hdlr        *HdlrBox
+	Pitm        *PitmBox
+	Iinf        *IinfBox
+	Iloc        *IlocBox
+	Iref        *IrefBox
+	Iprp        *IprpBox
+	Idat        *IdatBox
 	Children    []Box
 	isQuickTime bool // Has no version and flags
 }
@@ -43,6 +49,18 @@ func (b *MetaBox) AddChild(child Box) {
 	switch box := child.(type) {
 	case *HdlrBox:
 		b.Hdlr = box
+	case *PitmBox:
+		b.Pitm = box
+	case *IinfBox:
+		b.Iinf = box
+	case *IlocBox:
+		b.Iloc = box
+	case *IrefBox:
+		b.Iref = box
+	case *IprpBox:
+		b.Iprp = box
+	case *IdatBox:
+		b.Idat = box
 	}
 	b.Children = append(b.Children, child)
 }
@@ -0,0 +1,285 @@
+package mp4
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+)
+
+// Item types of iTunes-style metadata in ilst
+const (
+	IlstTitle       = "\xa9nam"
+	IlstArtist      = "\xa9ART"
+	IlstAlbum       = "\xa9alb"
+	IlstGenre       = "\xa9gen"
+	IlstDate        = "\xa9day"
+	IlstComment     = "\xa9cmt"
+	IlstTrackNumber = "trkn"
+	IlstCoverArt    = "covr"
+	IlstFreeForm    = "----"
+)
+
+// Metadata - typed iTunes-style metadata from moov/udta/meta/ilst.
+//
+// Empty strings, zero numbers, and empty lists mean that the item is not present.
+type Metadata struct {
+	Title       string
+	Artist      string
+	Album       string
+	Genre       string
+	Date        string
+	Comment     string
+	TrackNumber uint16
+	TrackTotal  uint16
+	CoverArt    []CoverArt
+	FreeForm    []FreeFormItem
+}
+
+// CoverArt - cover art image with DataType DataTypeJPEG, DataTypePNG, or DataTypeBMP
+type CoverArt struct {
+	DataType uint32
+	Data     []byte
+}
+
+// FreeFormItem - free-form (----) item identified by mean (reverse DNS domain) and name
+type FreeFormItem struct {
+	Mean     string
+	Name     string
+	DataType uint32
+	Locale   uint32
+	Data     []byte
+}
+
+// String - value as text if DataType is UTF-8 or UTF-16
+func (i FreeFormItem) String() string {
+	return dataBoxText(&DataBox{DataType: i.DataType, Data: i.Data})
+}
+
+// dataBoxText returns the text of a UTF-8 or UTF-16 data box
+func dataBoxText(b *DataBox) string {
+	if b.DataType&0xffffff != DataTypeUTF16 {
+		return string(b.Data)
+	}
+	u := make([]uint16, len(b.Data)/2)
+	for i := range u {
+		u[i] = binary.BigEndian.Uint16(b.Data[2*i:])
+	}
+	return string(utf16.Decode(u))
+}
+
+// Metadata - typed metadata from the ilst box
+func (b *IlstBox) Metadata() Metadata {
+	md := Metadata{}
+	for _, c := range b.Children {
+		item, ok := c.(*GenericContainerBox)
+		if !ok {
+			continue
+		}
+		var data []*DataBox
+		var mean, name string
+		for _, ic := range item.Children {
+			switch box := ic.(type) {
+			case *DataBox:
+				data = append(data, box)
+			case *MeanBox:
+				mean = box.Meaning
+			case *NameBox:
+				name = box.Name
+			}
+		}
+		if len(data) == 0 {
+			continue
+		}
+		switch item.Type() {
+		case IlstTitle:
+			md.Title = dataBoxText(data[0])
+		case IlstArtist:
+			md.Artist = dataBoxText(data[0])
+		case IlstAlbum:
+			md.Album = dataBoxText(data[0])
+		case IlstGenre:
+			md.Genre = dataBoxText(data[0])
+		case IlstDate:
+			md.Date = dataBoxText(data[0])
+		case IlstComment:
+			md.Comment = dataBoxText(data[0])
+		case IlstTrackNumber:
+			if len(data[0].Data) >= 6 {
+				md.TrackNumber = binary.BigEndian.Uint16(data[0].Data[2:4])
+				md.TrackTotal = binary.BigEndian.Uint16(data[0].Data[4:6])
+			}
+		case IlstCoverArt:
+			for _, d := range data {
+				md.CoverArt = append(md.CoverArt, CoverArt{DataType: d.DataType, Data: d.Data})
+			}
+		case IlstFreeForm:
+			md.FreeForm = append(md.FreeForm, FreeFormItem{
+				Mean: mean, Name: name, DataType: data[0].DataType, Locale: data[0].Locale, Data: data[0].Data})
+		}
+	}
+	return md
+}
+
+// SetMetadata - set the items of md in the ilst box.
+//
+// Items for empty values are removed, and items not covered by Metadata are kept.
+// The data type and locale of existing text and track number items are kept.
+// All cover art and free-form items are replaced by the ones in md.
+func (b *IlstBox) SetMetadata(md Metadata) {
+	b.setText(IlstTitle, md.Title)
+	b.setText(IlstArtist, md.Artist)
+	b.setText(IlstAlbum, md.Album)
+	b.setText(IlstGenre, md.Genre)
+	b.setText(IlstDate, md.Date)
+	b.setText(IlstComment, md.Comment)
+	if md.TrackNumber == 0 && md.TrackTotal == 0 {
+		b.removeItems(IlstTrackNumber)
+	} else {
+		trkn := make([]byte, 8)
+		binary.BigEndian.PutUint16(trkn[2:4], md.TrackNumber)
+		binary.BigEndian.PutUint16(trkn[4:6], md.TrackTotal)
+		d := b.itemData(IlstTrackNumber, DataTypeBinary)
+		d.Data = trkn
+	}
+	b.removeItems(IlstCoverArt)
+	if len(md.CoverArt) > 0 {
+		covr := NewGenericContainerBox(IlstCoverArt)
+		for _, c := range md.CoverArt {
+			covr.AddChild(&DataBox{DataType: c.DataType, Data: c.Data})
+		}
+		b.AddChild(covr)
+	}
+	b.removeItems(IlstFreeForm)
+	for _, ff := range md.FreeForm {
+		item := NewGenericContainerBox(IlstFreeForm)
+		item.AddChild(&MeanBox{Meaning: ff.Mean})
+		item.AddChild(&NameBox{Name: ff.Name})
+		item.AddChild(&DataBox{DataType: ff.DataType, Locale: ff.Locale, Data: ff.Data})
+		b.AddChild(item)
+	}
+}
+
+// setText sets a text item as UTF-8 unless it already is UTF-16, or removes it if value is empty
+func (b *IlstBox) setText(itemType, value string) {
+	if value == "" {
+		b.removeItems(itemType)
+		return
+	}
+	d := b.itemData(itemType, DataTypeUTF8)
+	if d.DataType&0xffffff == DataTypeUTF16 {
+		u := utf16.Encode([]rune(value))
+		d.Data = make([]byte, 2*len(u))
+		for i, v := range u {
+			binary.BigEndian.PutUint16(d.Data[2*i:], v)
+		}
+		return
+	}
+	d.DataType = DataTypeUTF8
+	d.Data = []byte(value)
+}
+
+// itemData returns the first data box of the item, which is created with dataType if not present
+func (b *IlstBox) itemData(itemType string, dataType uint32) *DataBox {
+	for _, c := range b.Children {
+		item, ok := c.(*GenericContainerBox)
+		if !ok || item.Type() != itemType {
+			continue
+		}
+		for _, ic := range item.Children {
+			if d, ok := ic.(*DataBox); ok {
+				return d
+			}
+		}
+		d := &DataBox{DataType: dataType}
+		item.AddChild(d)
+		return d
+	}
+	item := NewGenericContainerBox(itemType)
+	d := &DataBox{DataType: dataType}
+	item.AddChild(d)
+	b.AddChild(item)
+	return d
+}
+
+// removeItems removes all items of itemType
+func (b *IlstBox) removeItems(itemType string) {
+	children := b.Children[:0]
+	for _, c := range b.Children {
+		if c.Type() != itemType {
+			children = append(children, c)
+		}
+	}
+	b.Children = children
+}
+
+// Ilst - the ilst box in moov/udta/meta, or nil if not present
+func (m *MoovBox) Ilst() *IlstBox {
+	udta := m.udta()
+	if udta == nil {
+		return nil
+	}
+	for _, c := range udta.Children {
+		meta, ok := c.(*MetaBox)
+		if !ok {
+			continue
+		}
+		for _, mc := range meta.Children {
+			if ilst, ok := mc.(*IlstBox); ok {
+				return ilst
+			}
+		}
+	}
+	return nil
+}
+
+// udta returns the first udta child box or nil
+func (m *MoovBox) udta() *UdtaBox {
+	for _, c := range m.Children {
+		if udta, ok := c.(*UdtaBox); ok {
+			return udta
+		}
+	}
+	return nil
+}
+
+// Metadata - typed iTunes-style metadata from moov/udta/meta/ilst
+func (f *File) Metadata() Metadata {
+	if f.Moov == nil {
+		return Metadata{}
+	}
+	ilst := f.Moov.Ilst()
+	if ilst == nil {
+		return Metadata{}
+	}
+	return ilst.Metadata()
+}
+
+// SetMetadata - set iTunes-style metadata in moov/udta/meta/ilst, creating the boxes if needed.
+//
+// For progressive files, chunk offsets are updated if the moov box is before the media data.
+// For fragmented files, the moof offsets of an mfra box made by UpdateMfra are updated by Encode,
+// but a decoded mfra box is left unchanged, so UpdateMfra should be called to update it.
+func (f *File) SetMetadata(md Metadata) error {
+	moov := f.Moov
+	if moov == nil {
+		return fmt.Errorf("no moov box")
+	}
+	oldMoovSize := moov.Size()
+	ilst := moov.Ilst()
+	if ilst == nil {
+		udta := moov.udta()
+		if udta == nil {
+			udta = &UdtaBox{}
+			moov.AddChild(udta)
+		}
+		meta := CreateMetaBox(0, &HdlrBox{HandlerType: "mdir"})
+		ilst = &IlstBox{}
+		meta.AddChild(ilst)
+		udta.AddChild(meta)
+	}
+	ilst.SetMetadata(md)
+	if f.isFragmented {
+		return nil
+	}
+	return f.moovResized(oldMoovSize)
+}
@@ -0,0 +1,121 @@
+package mp4_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+	"github.com/go-test/deep"
+)
+
+func TestIlstItemBoxes(t *testing.T) {
+	boxDiffAfterEncodeAndDecode(t, &mp4.DataBox{DataType: mp4.DataTypePNG, Locale: 1, Data: []byte{0x89, 0x50}})
+	item := mp4.NewGenericContainerBox(mp4.IlstFreeForm)
+	item.AddChild(&mp4.MeanBox{Meaning: "com.apple.iTunes"})
+	item.AddChild(&mp4.NameBox{Name: "iTunNORM"})
+	item.AddChild(&mp4.DataBox{DataType: mp4.DataTypeUTF8, Data: []byte("x")})
+	boxDiffAfterEncodeAndDecode(t, item)
+}
+
+// TestQuickTimeNameBox checks that a QuickTime udta/name box is not decoded as the name box of ilst.
+func TestQuickTimeNameBox(t *testing.T) {
+	udta := &mp4.UdtaBox{}
+	udta.AddChild(mp4.CreateUnknownBox("name", 10, []byte("ab")))
+	boxDiffAfterEncodeAndDecode(t, udta)
+}
+
+func TestIlstMetadata(t *testing.T) {
+	ilst := &mp4.IlstBox{}
+	utf16Title := mp4.NewGenericContainerBox(mp4.IlstTitle)
+	utf16Title.AddChild(&mp4.DataBox{DataType: mp4.DataTypeUTF16, Locale: 5, Data: []byte{0, 'A'}})
+	ilst.AddChild(utf16Title)
+	tool := mp4.NewGenericContainerBox("\xa9too")
+	tool.AddChild(&mp4.DataBox{DataType: mp4.DataTypeUTF8, Data: []byte("mp4ff")})
+	ilst.AddChild(tool)
+	if md := ilst.Metadata(); md.Title != "A" {
+		t.Errorf("got title %q instead of A", md.Title)
+	}
+	md := mp4.Metadata{
+		Title:       "Tést",
+		Artist:      "Artist",
+		TrackNumber: 3,
+		TrackTotal:  12,
+		CoverArt:    []mp4.CoverArt{{DataType: mp4.DataTypeJPEG, Data: []byte{0xff, 0xd8, 0xff}}},
+		FreeForm: []mp4.FreeFormItem{
+			{Mean: "com.apple.iTunes", Name: "ISRC", DataType: mp4.DataTypeUTF8, Data: []byte("SE-ABC-24-00001")},
+		},
+	}
+	ilst.SetMetadata(md)
+	buf := bytes.Buffer{}
+	if err := ilst.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	box, err := mp4.DecodeBox(0, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decIlst := box.(*mp4.IlstBox)
+	if diff := deep.Equal(decIlst.Metadata(), md); diff != nil {
+		t.Error(diff)
+	}
+	if decIlst.Children[0].Type() != mp4.IlstTitle || decIlst.Children[1].Type() != "\xa9too" {
+		t.Error("existing items not kept in place")
+	}
+	title := decIlst.Children[0].(*mp4.GenericContainerBox).Children[0].(*mp4.DataBox)
+	if title.DataType != mp4.DataTypeUTF16 || title.Locale != 5 {
+		t.Errorf("title data type %d and locale %d not preserved", title.DataType, title.Locale)
+	}
+	if md.FreeForm[0].String() != "SE-ABC-24-00001" {
+		t.Errorf("bad free-form string %q", md.FreeForm[0].String())
+	}
+	decIlst.SetMetadata(mp4.Metadata{Title: "Only title"})
+	if len(decIlst.Children) != 2 {
+		t.Errorf("got %d ilst items instead of 2", len(decIlst.Children))
+	}
+}
+
+func TestFileMetadata(t *testing.T) {
+	f, err := mp4.ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trackID := f.Moov.Traks[0].Tkhd.TrackID
+	it, err := f.NewSampleIterator(trackID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var origData [][]byte
+	for it.Next() {
+		origData = append(origData, it.Sample().Data)
+	}
+	md := mp4.Metadata{Title: "Big Buck Bunny", Album: "Blender", Genre: "Animation", Date: "2008",
+		Comment: "Test clip", TrackNumber: 1}
+	if err := f.SetMetadata(md); err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	if err := f.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dec, err := mp4.DecodeFile(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(dec.Metadata(), md); diff != nil {
+		t.Error(diff)
+	}
+	it, err = dec.NewSampleIterator(trackID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nr := 0
+	for it.Next() {
+		if !bytes.Equal(it.Sample().Data, origData[nr]) {
+			t.Fatalf("sample %d data differs after setting metadata", nr+1)
+		}
+		nr++
+	}
+	if nr != len(origData) {
+		t.Errorf("got %d samples instead of %d", nr, len(origData))
+	}
+}
@@ -0,0 +1,226 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// MettBox - TextMetaDataSampleEntry (mett)
+// Defined in ISO/IEC 14496-12 Sec. 12.3.3.
+//
+// Contained in : Sample Description Box (stsd)
+type MettBox struct {
+	DataReferenceIndex uint16
+	ContentEncoding    string   // Optional, empty means no encoding
+	MimeFormat         string   // Mandatory MIME type of the samples
+	Btrt               *BtrtBox // Optional
+	TxtC               *TxtCBox // Optional
+	Children           []Box
+}
+
+// NewMettBox - Create new mett box
+func NewMettBox(contentEncoding, mimeFormat string) *MettBox {
+	return &MettBox{
+		DataReferenceIndex: 1,
+		ContentEncoding:    contentEncoding,
+		MimeFormat:         mimeFormat,
+	}
+}
+
+// AddChild - add a child box
+func (b *MettBox) AddChild(child Box) {
+	switch box := child.(type) {
+	case *BtrtBox:
+		b.Btrt = box
+	case *TxtCBox:
+		b.TxtC = box
+	}
+	b.Children = append(b.Children, child)
+}
+
+// DecodeMett - Decode TextMetaDataSampleEntry (mett)
+func DecodeMett(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeMettSR(hdr, startPos, sr)
+}
+
+// DecodeMettSR - Decode TextMetaDataSampleEntry (mett)
+func DecodeMettSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	payloadLen := hdr.payloadLen()
+	b := MettBox{}
+	initPos := sr.GetPos()
+	sr.SkipBytes(6) // Skip 6 reserved bytes
+	b.DataReferenceIndex = sr.ReadUint16()
+	b.ContentEncoding = sr.ReadZeroTerminatedString(payloadLen - 8)
+	b.MimeFormat = sr.ReadZeroTerminatedString(payloadLen - (sr.GetPos() - initPos))
+	if err := sr.AccError(); err != nil {
+		return nil, fmt.Errorf("DecodeMett: %w", err)
+	}
+	children, err := decodeSampleEntryChildrenSR(hdr, startPos, sr, initPos)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return &b, sr.AccError()
+}
+
+// decodeSampleEntryChildrenSR decodes the child boxes after the sample entry fields until the end of the box.
+func decodeSampleEntryChildrenSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader, initPos int) ([]Box, error) {
+	var children []Box
+	pos := startPos + uint64(hdr.Hdrlen+sr.GetPos()-initPos)
+	for sr.GetPos()-initPos < hdr.payloadLen() {
+		box, err := decodeChildBoxSR(pos, sr)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, box)
+		pos += box.Size()
+	}
+	return children, nil
+}
+
+// GetChildren - list of child boxes
+func (b *MettBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Type - return box type
+func (b *MettBox) Type() string {
+	return "mett"
+}
+
+// Size - return calculated size
+func (b *MettBox) Size() uint64 {
+	size := uint64(boxHeaderSize + 8 + len(b.ContentEncoding) + 1 + len(b.MimeFormat) + 1)
+	for _, child := range b.Children {
+		size += child.Size()
+	}
+	return size
+}
+
+// Encode - write box to w via a SliceWriter
+func (b *MettBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - write box to sw
+func (b *MettBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteZeroBytes(6)
+	sw.WriteUint16(b.DataReferenceIndex)
+	sw.WriteString(b.ContentEncoding, true)
+	sw.WriteString(b.MimeFormat, true)
+	for _, child := range b.Children {
+		err = child.EncodeSW(sw)
+		if err != nil {
+			return err
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write specific box info to w
+func (b *MettBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - dataReferenceIndex: %d", b.DataReferenceIndex)
+	bd.write(" - contentEncoding: %q", b.ContentEncoding)
+	bd.write(" - mimeFormat: %q", b.MimeFormat)
+	if bd.err != nil {
+		return bd.err
+	}
+	for _, child := range b.Children {
+		err := child.Info(w, specificBoxLevels, indent+indentStep, indentStep)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TxtCBox - TextConfigBox (txtC)
+// Defined in ISO/IEC 14496-12 Sec. 12.5.3.
+//
+// Contained in : TextMetaDataSampleEntry (mett) or SimpleTextSampleEntry (stxt)
+type TxtCBox struct {
+	Version    byte
+	Flags      uint32
+	TextConfig string
+}
+
+// DecodeTxtC - box-specific decode
+func DecodeTxtC(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeTxtCSR(hdr, startPos, sr)
+}
+
+// DecodeTxtCSR - box-specific decode
+func DecodeTxtCSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := TxtCBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.TextConfig = sr.ReadZeroTerminatedString(hdr.payloadLen() - 4)
+	return &b, sr.AccError()
+}
+
+// Type - box type
+func (b *TxtCBox) Type() string {
+	return "txtC"
+}
+
+// Size - calculated size of box
+func (b *TxtCBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4 + len(b.TextConfig) + 1)
+}
+
+// Encode - write box to w
+func (b *TxtCBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *TxtCBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteString(b.TextConfig, true)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *TxtCBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - textConfig: %q", b.TextConfig)
+	return bd.err
+}
@@ -0,0 +1,22 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestMett(t *testing.T) {
+	mett := mp4.NewMettBox("", "application/json")
+	boxDiffAfterEncodeAndDecode(t, mett)
+	mett = mp4.NewMettBox("application/zip", "text/csv")
+	txtC := &mp4.TxtCBox{TextConfig: "header"}
+	mett.AddChild(txtC)
+	btrt := &mp4.BtrtBox{AvgBitrate: 1000, MaxBitrate: 2000}
+	mett.AddChild(btrt)
+	if mett.TxtC != txtC || mett.Btrt != btrt {
+		t.Error("child box pointers not set")
+	}
+	boxDiffAfterEncodeAndDecode(t, mett)
+	boxDiffAfterEncodeAndDecode(t, txtC)
+}
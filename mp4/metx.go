@@ -0,0 +1,141 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// MetxBox - XMLMetaDataSampleEntry (metx)
+// Defined in ISO/IEC 14496-12 Sec. 12.3.3.
+//
+// Contained in : Sample Description Box (stsd)
+type MetxBox struct {
+	DataReferenceIndex uint16
+	ContentEncoding    string   // Optional, empty means no encoding
+	Namespace          string   // Mandatory space-separated list of XML namespaces
+	SchemaLocation     string   // Optional space-separated list of schema URLs
+	Btrt               *BtrtBox // Optional
+	Children           []Box
+}
+
+// NewMetxBox - Create new metx box
+func NewMetxBox(contentEncoding, namespace, schemaLocation string) *MetxBox {
+	return &MetxBox{
+		DataReferenceIndex: 1,
+		ContentEncoding:    contentEncoding,
+		Namespace:          namespace,
+		SchemaLocation:     schemaLocation,
+	}
+}
+
+// AddChild - add a child box
+func (b *MetxBox) AddChild(child Box) {
+	if btrt, ok := child.(*BtrtBox); ok {
+		b.Btrt = btrt
+	}
+	b.Children = append(b.Children, child)
+}
+
+// DecodeMetx - Decode XMLMetaDataSampleEntry (metx)
+func DecodeMetx(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeMetxSR(hdr, startPos, sr)
+}
+
+// DecodeMetxSR - Decode XMLMetaDataSampleEntry (metx)
+func DecodeMetxSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	payloadLen := hdr.payloadLen()
+	b := MetxBox{}
+	initPos := sr.GetPos()
+	sr.SkipBytes(6) // Skip 6 reserved bytes
+	b.DataReferenceIndex = sr.ReadUint16()
+	b.ContentEncoding = sr.ReadZeroTerminatedString(payloadLen - 8)
+	b.Namespace = sr.ReadZeroTerminatedString(payloadLen - (sr.GetPos() - initPos))
+	b.SchemaLocation = sr.ReadZeroTerminatedString(payloadLen - (sr.GetPos() - initPos))
+	if err := sr.AccError(); err != nil {
+		return nil, fmt.Errorf("DecodeMetx: %w", err)
+	}
+	children, err := decodeSampleEntryChildrenSR(hdr, startPos, sr, initPos)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return &b, sr.AccError()
+}
+
+// GetChildren - list of child boxes
+func (b *MetxBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Type - return box type
+func (b *MetxBox) Type() string {
+	return "metx"
+}
+
+// Size - return calculated size
+func (b *MetxBox) Size() uint64 {
+	size := uint64(boxHeaderSize + 8 + len(b.ContentEncoding) + 1 + len(b.Namespace) + 1 + len(b.SchemaLocation) + 1)
+	for _, child := range b.Children {
+		size += child.Size()
+	}
+	return size
+}
+
+// Encode - write box to w via a SliceWriter
+func (b *MetxBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - write box to sw
+func (b *MetxBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteZeroBytes(6)
+	sw.WriteUint16(b.DataReferenceIndex)
+	sw.WriteString(b.ContentEncoding, true)
+	sw.WriteString(b.Namespace, true)
+	sw.WriteString(b.SchemaLocation, true)
+	for _, child := range b.Children {
+		err = child.EncodeSW(sw)
+		if err != nil {
+			return err
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write specific box info to w
+func (b *MetxBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - dataReferenceIndex: %d", b.DataReferenceIndex)
+	bd.write(" - contentEncoding: %q", b.ContentEncoding)
+	bd.write(" - namespace: %q", b.Namespace)
+	bd.write(" - schemaLocation: %q", b.SchemaLocation)
+	if bd.err != nil {
+		return bd.err
+	}
+	for _, child := range b.Children {
+		err := child.Info(w, specificBoxLevels, indent+indentStep, indentStep)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
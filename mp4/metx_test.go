@@ -0,0 +1,18 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestMetx(t *testing.T) {
+	metx := mp4.NewMetxBox("", "http://www.onvif.org/ver10/schema", "")
+	boxDiffAfterEncodeAndDecode(t, metx)
+	metx = mp4.NewMetxBox("application/zip", "urn:a urn:b", "http://example.com/a.xsd http://example.com/b.xsd")
+	metx.AddChild(&mp4.BtrtBox{AvgBitrate: 1000})
+	if metx.Btrt == nil {
+		t.Error("btrt pointer not set")
+	}
+	boxDiffAfterEncodeAndDecode(t, metx)
+}
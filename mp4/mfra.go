@@ -13,7 +13,7 @@ type MfraBox struct {
 	Tfras    []*TfraBox
 	Mfro     *MfroBox
 	Children []Box
-	StartPos uint64
+	StartPos uint64 `json:"-"`
 }
 
 // DecodeMfra - box-specific decode
@@ -17,7 +17,7 @@ type MoofBox struct {
 	Pssh     *PsshBox
 	Psshs    []*PsshBox
 	Children []Box
-	StartPos uint64
+	StartPos uint64 `json:"-"`
 }
 
 // DecodeMoof - box-specific decode
@@ -18,7 +18,7 @@ type MoovBox struct {
 	Pssh     *PsshBox
 	Psshs    []*PsshBox
 	Children []Box
-	StartPos uint64
+	StartPos uint64 `json:"-"`
 }
 
 // NewMoovBox - Generate a new empty moov box
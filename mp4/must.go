@@ -0,0 +1,93 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// MustBox - Required Box Types Box (must)
+//
+// Lists box types that a reader must understand to use the track.
+//
+// Contained in : Video Extended Usage Box (vexu) and Stereo View Box (eyes)
+//
+// Defined in Apple HEVC Stereo Video - ISOBMFF Extensions
+type MustBox struct {
+	Version          byte
+	Flags            uint32
+	RequiredBoxTypes []string
+}
+
+// DecodeMust - box-specific decode
+func DecodeMust(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeMustSR(hdr, startPos, sr)
+}
+
+// DecodeMustSR - box-specific decode
+func DecodeMustSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &MustBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	payloadLen := hdr.payloadLen() - 4
+	if payloadLen%4 != 0 {
+		return nil, fmt.Errorf("must: payload size %d not a multiple of 4", payloadLen)
+	}
+	for i := 0; i < payloadLen/4; i++ {
+		b.RequiredBoxTypes = append(b.RequiredBoxTypes, sr.ReadFixedLengthString(4))
+	}
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *MustBox) Type() string {
+	return "must"
+}
+
+// Size - return calculated size
+func (b *MustBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4 + 4*len(b.RequiredBoxTypes))
+}
+
+// Encode - write box to w
+func (b *MustBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *MustBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	for _, boxType := range b.RequiredBoxTypes {
+		if len(boxType) != 4 {
+			return fmt.Errorf("must: box type %q is not 4 characters", boxType)
+		}
+		sw.WriteString(boxType, false)
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *MustBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - requiredBoxTypes: %v", b.RequiredBoxTypes)
+	return bd.err
+}
@@ -45,3 +45,10 @@ func NewNTP64(utcTime float64) NTP64 {
 func (n NTP64) String() string {
 	return n.Time().String()
 }
+
+// NTP64FromTime creates NTP64 from t with nanosecond precision.
+func NTP64FromTime(t time.Time) NTP64 {
+	seconds := uint64(t.Unix() + NTPEpochOffset)
+	fraction := (uint64(t.Nanosecond()) << 32) / 1e9
+	return NTP64(seconds<<32 | fraction)
+}
@@ -40,3 +40,14 @@ func TestNTP64String(t *testing.T) {
 		t.Errorf("Expected %s, got %s", expected, ntp.String())
 	}
 }
+
+func TestNTP64FromTime(t *testing.T) {
+	tm := time.Date(2023, 1, 2, 3, 4, 5, 250000000, time.UTC)
+	ntp := mp4.NTP64FromTime(tm)
+	if ntp.Fraction() != 1<<30 {
+		t.Errorf("got fraction %d instead of %d", ntp.Fraction(), 1<<30)
+	}
+	if !ntp.Time().Equal(tm) {
+		t.Errorf("got time %s instead of %s", ntp.Time(), tm)
+	}
+}
@@ -0,0 +1,12 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestPitm(t *testing.T) {
+	boxDiffAfterEncodeAndDecode(t, &mp4.PitmBox{ItemID: 1})
+	boxDiffAfterEncodeAndDecode(t, &mp4.PitmBox{Version: 1, ItemID: 70000})
+}
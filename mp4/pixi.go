@@ -0,0 +1,81 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// PixiBox - Pixel Information Property (pixi)
+//
+// Contained in : Item Property Container Box (ipco)
+//
+// Defined in ISO/IEC 23008-12 Section 6.5.6
+type PixiBox struct {
+	Version        byte
+	Flags          uint32
+	BitsPerChannel []byte
+}
+
+// DecodePixi - box-specific decode
+func DecodePixi(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodePixiSR(hdr, startPos, sr)
+}
+
+// DecodePixiSR - box-specific decode
+func DecodePixiSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &PixiBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	nrChannels := int(sr.ReadUint8())
+	b.BitsPerChannel = sr.ReadBytes(nrChannels)
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *PixiBox) Type() string {
+	return "pixi"
+}
+
+// Size - return calculated size
+func (b *PixiBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4 + 1 + len(b.BitsPerChannel))
+}
+
+// Encode - write box to w
+func (b *PixiBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *PixiBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint8(byte(len(b.BitsPerChannel)))
+	sw.WriteBytes(b.BitsPerChannel)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *PixiBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - bitsPerChannel: %v", b.BitsPerChannel)
+	return bd.err
+}
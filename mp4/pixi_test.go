@@ -0,0 +1,11 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestPixi(t *testing.T) {
+	boxDiffAfterEncodeAndDecode(t, &mp4.PixiBox{BitsPerChannel: []byte{8, 8, 8}})
+}
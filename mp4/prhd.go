@@ -0,0 +1,89 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// PrhdBox - Projection Header Box (prhd)
+//
+// The pose angles are in degrees as 16.16 fixed-point values.
+//
+// Contained in : Projection Box (proj)
+//
+// Defined in Google Spherical Video V2 RFC
+type PrhdBox struct {
+	Version          byte
+	Flags            uint32
+	PoseYawDegrees   int32
+	PosePitchDegrees int32
+	PoseRollDegrees  int32
+}
+
+// DecodePrhd - box-specific decode
+func DecodePrhd(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodePrhdSR(hdr, startPos, sr)
+}
+
+// DecodePrhdSR - box-specific decode
+func DecodePrhdSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &PrhdBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.PoseYawDegrees = sr.ReadInt32()
+	b.PosePitchDegrees = sr.ReadInt32()
+	b.PoseRollDegrees = sr.ReadInt32()
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *PrhdBox) Type() string {
+	return "prhd"
+}
+
+// Size - return calculated size
+func (b *PrhdBox) Size() uint64 {
+	return uint64(boxHeaderSize + 16)
+}
+
+// Encode - write box to w
+func (b *PrhdBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *PrhdBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteInt32(b.PoseYawDegrees)
+	sw.WriteInt32(b.PosePitchDegrees)
+	sw.WriteInt32(b.PoseRollDegrees)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *PrhdBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - poseYawDegrees: %.3f", float64(b.PoseYawDegrees)/65536)
+	bd.write(" - posePitchDegrees: %.3f", float64(b.PosePitchDegrees)/65536)
+	bd.write(" - poseRollDegrees: %.3f", float64(b.PoseRollDegrees)/65536)
+	return bd.err
+}
@@ -0,0 +1,81 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// PrjiBox - Projection Information Box (prji)
+//
+// ProjectionKind is a four-character code such as rect, equi, hequ, or fish.
+//
+// Contained in : Projection Box (proj) in Video Extended Usage Box (vexu)
+//
+// Defined in Apple HEVC Stereo Video - ISOBMFF Extensions
+type PrjiBox struct {
+	Version        byte
+	Flags          uint32
+	ProjectionKind string
+}
+
+// DecodePrji - box-specific decode
+func DecodePrji(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodePrjiSR(hdr, startPos, sr)
+}
+
+// DecodePrjiSR - box-specific decode
+func DecodePrjiSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &PrjiBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.ProjectionKind = sr.ReadFixedLengthString(4)
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *PrjiBox) Type() string {
+	return "prji"
+}
+
+// Size - return calculated size
+func (b *PrjiBox) Size() uint64 {
+	return uint64(boxHeaderSize + 8)
+}
+
+// Encode - write box to w
+func (b *PrjiBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *PrjiBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteString(b.ProjectionKind, false)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *PrjiBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - projectionKind: %s", b.ProjectionKind)
+	return bd.err
+}
@@ -0,0 +1,94 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// ProjBox - Projection Box (proj)
+//
+// In sv3d, it has a prhd box and a projection box such as equi or cbmp.
+// In vexu, it has a prji box.
+//
+// Contained in : Spherical Video Box (sv3d) or Video Extended Usage Box (vexu)
+//
+// Defined in Google Spherical Video V2 RFC and Apple HEVC Stereo Video - ISOBMFF Extensions
+type ProjBox struct {
+	Prhd     *PrhdBox
+	Equi     *EquiBox
+	Cbmp     *CbmpBox
+	Prji     *PrjiBox
+	Children []Box
+}
+
+// AddChild - add a child box
+func (b *ProjBox) AddChild(child Box) {
+	switch box := child.(type) {
+	case *PrhdBox:
+		b.Prhd = box
+	case *EquiBox:
+		b.Equi = box
+	case *CbmpBox:
+		b.Cbmp = box
+	case *PrjiBox:
+		b.Prji = box
+	}
+	b.Children = append(b.Children, child)
+}
+
+// DecodeProj - box-specific decode
+func DecodeProj(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.Size, r)
+	if err != nil {
+		return nil, err
+	}
+	b := &ProjBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// DecodeProjSR - box-specific decode
+func DecodeProjSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.Size, sr)
+	if err != nil {
+		return nil, err
+	}
+	b := &ProjBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// Type - return box type
+func (b *ProjBox) Type() string {
+	return "proj"
+}
+
+// Size - return calculated size
+func (b *ProjBox) Size() uint64 {
+	return containerSize(b.Children)
+}
+
+// GetChildren - list of child boxes
+func (b *ProjBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write proj container to w
+func (b *ProjBox) Encode(w io.Writer) error {
+	return EncodeContainer(b, w)
+}
+
+// EncodeSW - write proj container via sw
+func (b *ProjBox) EncodeSW(sw bits.SliceWriter) error {
+	return EncodeContainerSW(b, sw)
+}
+
+// Info - write box-specific information
+func (b *ProjBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
+}
@@ -184,7 +184,7 @@ func PsshBoxesFromBytes(psshData []byte) ([]*PsshBox, error) {
 	sr := bits.NewFixedSliceReader(psshData)
 	pos := uint64(0)
 	for pos < uint64(len(psshData)) {
-		box, err := DecodeBoxSR(pos, sr)
+		box, err := decodeChildBoxSR(pos, sr)
 		if err != nil {
 			return nil, fmt.Errorf("decode pssh box: %w", err)
 		}
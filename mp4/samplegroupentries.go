@@ -42,6 +42,22 @@ func decodeSampleGroupEntry(name string, length uint32, sr bits.SliceReader) (Sa
 	return DecodeUnknownSampleGroupEntry(name, length, sr)
 }
 
+// newSampleGroupEntry returns an empty sample group entry of type name
+func newSampleGroupEntry(name string) SampleGroupEntry {
+	switch name {
+	case "seig":
+		return &SeigSampleGroupEntry{}
+	case "roll":
+		return &RollSampleGroupEntry{}
+	case "rap ":
+		return &RapSampleGroupEntry{}
+	case "alst":
+		return &AlstSampleGroupEntry{}
+	default:
+		return &UnknownSampleGroupEntry{Name: name}
+	}
+}
+
 // SeigSampleGroupEntry - CencSampleEncryptionInformationGroupEntry as defined in
 // CEF ISO/IEC 23001-7 3rd edition 2016
 type SeigSampleGroupEntry struct {
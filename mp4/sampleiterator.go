@@ -0,0 +1,454 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+)
+
+// TrackSample - FullSample with its position in the track and the information needed to decode it.
+// Times are in mdhd timescale.
+type TrackSample struct {
+	FullSample
+	SampleNr               uint32      // One-based number of the sample in the track
+	SampleDescriptionIndex uint32      // One-based index of the sample entry in stsd
+	SyncSample             bool        // Signaled by stss in progressive files and sample flags in fragments
+	Encryption             *SencSample // IV and subsample patterns, nil if the sample is not encrypted
+}
+
+// SampleIterator - iterator over the samples of one track in a progressive or fragmented file.
+//
+// The same iterator is used for both layouts. Fragmented files are iterated over all segments and fragments.
+// If the file was decoded in lazy mdat mode, the sample data is read from the io.ReadSeeker
+// given to NewSampleIterator. Use it like
+//
+//	it, err := f.NewSampleIterator(trackID, rs)
+//	...
+//	for it.Next() {
+//		s := it.Sample()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type SampleIterator struct {
+	f       *File
+	rs      io.ReadSeeker
+	trackID uint32
+	trak    *TrakBox // nil for fragmented files without init segment
+	trex    *TrexBox
+	pending []pendingSample
+	sample  TrackSample
+	err     error
+	// Progressive state
+	chunks   []Chunk
+	chunkIdx int
+	senc     *SencBox // sample auxiliary information referenced by saiz and saio
+	sttsIdx  int
+	sttsLeft uint32
+	// Fragmented state
+	segIdx         int
+	fragIdx        int
+	nextDecodeTime uint64
+	nrSamples      uint32
+}
+
+// pendingSample - sample with metadata but without data
+type pendingSample struct {
+	TrackSample
+	offset uint64 // Absolute position of the sample data in the file
+	mdat   *MdatBox
+}
+
+// NewSampleIterator returns an iterator over the samples of the track with trackID.
+// rs is needed to read sample data if the file was decoded in lazy mdat mode, and may be nil otherwise.
+func (f *File) NewSampleIterator(trackID uint32, rs io.ReadSeeker) (*SampleIterator, error) {
+	it := &SampleIterator{f: f, rs: rs, trackID: trackID}
+	var moov *MoovBox
+	switch {
+	case f.Init != nil:
+		moov = f.Init.Moov
+	case f.Moov != nil:
+		moov = f.Moov
+	}
+	if moov != nil {
+		for _, trak := range moov.Traks {
+			if trak.Tkhd.TrackID == trackID {
+				it.trak = trak
+				break
+			}
+		}
+		if moov.Mvex != nil {
+			for _, trex := range moov.Mvex.Trexs {
+				if trex.TrackID == trackID {
+					it.trex = trex
+					break
+				}
+			}
+		}
+	}
+	if f.isFragmented {
+		if moov != nil && it.trak == nil {
+			return nil, fmt.Errorf("no track with ID %d", trackID)
+		}
+		return it, nil
+	}
+	if it.trak == nil {
+		return nil, fmt.Errorf("no track with ID %d", trackID)
+	}
+	stbl := it.trak.Mdia.Minf.Stbl
+	nrSamples := stbl.Stsz.GetNrSamples()
+	if nrSamples > 0 {
+		chunks, err := stbl.Stsc.GetContainingChunks(1, nrSamples)
+		if err != nil {
+			return nil, err
+		}
+		it.chunks = chunks
+	}
+	if tenc := it.tenc(1); tenc != nil && stbl.Saiz != nil && stbl.Saio != nil {
+		senc, err := it.readAuxInfo(stbl.Saiz, stbl.Saio, tenc)
+		if err != nil {
+			return nil, fmt.Errorf("sample auxiliary information: %w", err)
+		}
+		it.senc = senc
+	}
+	return it, nil
+}
+
+// readAuxInfo reads the sample auxiliary information of a progressive track and parses it as senc data.
+// The saio box has either one offset for all samples or one offset per chunk.
+func (it *SampleIterator) readAuxInfo(saiz *SaizBox, saio *SaioBox, tenc *TencBox) (*SencBox, error) {
+	sizes := make([]uint32, saiz.SampleCount)
+	for i := range sizes {
+		if saiz.DefaultSampleInfoSize != 0 {
+			sizes[i] = uint32(saiz.DefaultSampleInfoSize)
+		} else if i < len(saiz.SampleInfo) {
+			sizes[i] = uint32(saiz.SampleInfo[i])
+		}
+	}
+	// Sample index ranges read from each saio offset
+	type auxRange struct{ start, end int }
+	var ranges []auxRange
+	switch len(saio.Offset) {
+	case 1:
+		ranges = append(ranges, auxRange{0, len(sizes)})
+	case len(it.chunks):
+		for _, chunk := range it.chunks {
+			start := int(chunk.StartSampleNr) - 1
+			ranges = append(ranges, auxRange{start, start + int(chunk.NrSamples)})
+		}
+	default:
+		return nil, fmt.Errorf("%d saio offsets for %d chunks", len(saio.Offset), len(it.chunks))
+	}
+	senc := &SencBox{readButNotParsed: true, SampleCount: saiz.SampleCount}
+	for i, r := range ranges {
+		if r.end > len(sizes) {
+			return nil, fmt.Errorf("saiz has %d samples, less than the chunks", len(sizes))
+		}
+		var size uint32
+		for _, s := range sizes[r.start:r.end] {
+			size += s
+			if s > uint32(tenc.DefaultPerSampleIVSize) {
+				senc.Flags = UseSubSampleEncryption
+			}
+		}
+		if size == 0 {
+			continue
+		}
+		data, err := it.readData(it.f.Mdat, uint64(saio.Offset[i]), size)
+		if err != nil {
+			return nil, err
+		}
+		senc.rawData = append(senc.rawData, data...)
+	}
+	if err := senc.ParseReadBox(tenc.DefaultPerSampleIVSize, saiz); err != nil {
+		return nil, err
+	}
+	return senc, nil
+}
+
+// Next advances to the next sample. It returns false when there are no more samples or an error occurred.
+func (it *SampleIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for len(it.pending) == 0 {
+		var more bool
+		if it.f.isFragmented {
+			more, it.err = it.fillFromFragment()
+		} else {
+			more, it.err = it.fillFromChunk()
+		}
+		if it.err != nil || !more {
+			return false
+		}
+	}
+	ps := it.pending[0]
+	it.pending = it.pending[1:]
+	ps.Data, it.err = it.readData(ps.mdat, ps.offset, ps.Size)
+	if it.err != nil {
+		it.err = fmt.Errorf("sample %d: %w", ps.SampleNr, it.err)
+		return false
+	}
+	it.sample = ps.TrackSample
+	return true
+}
+
+// Sample returns the current sample. It is valid until the next call to Next.
+func (it *SampleIterator) Sample() *TrackSample {
+	return &it.sample
+}
+
+// Err returns the first error that occurred during iteration.
+func (it *SampleIterator) Err() error {
+	return it.err
+}
+
+// fillFromChunk adds the samples of the next chunk in a progressive file.
+func (it *SampleIterator) fillFromChunk() (bool, error) {
+	if it.chunkIdx >= len(it.chunks) {
+		return false, nil
+	}
+	chunk := it.chunks[it.chunkIdx]
+	it.chunkIdx++
+	stbl := it.trak.Mdia.Minf.Stbl
+	var offset uint64
+	var err error
+	switch {
+	case stbl.Stco != nil:
+		offset, err = stbl.Stco.GetOffset(int(chunk.ChunkNr))
+	case stbl.Co64 != nil:
+		offset, err = stbl.Co64.GetOffset(int(chunk.ChunkNr))
+	default:
+		err = fmt.Errorf("neither stco nor co64 available")
+	}
+	if err != nil {
+		return false, err
+	}
+	sdi := stscSampleDescriptionID(stbl.Stsc, chunk.StartSampleNr)
+	tenc := it.tenc(sdi)
+	for nr := chunk.StartSampleNr; nr < chunk.StartSampleNr+chunk.NrSamples; nr++ {
+		dur, err := it.nextSttsDur()
+		if err != nil {
+			return false, err
+		}
+		var cto int32
+		if stbl.Ctts != nil {
+			cto = stbl.Ctts.GetCompositionTimeOffset(nr)
+		}
+		ps := pendingSample{
+			TrackSample: TrackSample{
+				FullSample: FullSample{
+					Sample: Sample{
+						Flags:                 createSampleFlagsFromProgressiveBoxes(stbl.Stss, stbl.Sdtp, nr),
+						Dur:                   dur,
+						Size:                  stbl.Stsz.GetSampleSize(int(nr)),
+						CompositionTimeOffset: cto,
+					},
+					DecodeTime: it.nextDecodeTime,
+				},
+				SampleNr:               nr,
+				SampleDescriptionIndex: sdi,
+			},
+			offset: offset,
+			mdat:   it.f.Mdat,
+		}
+		ps.SyncSample = !DecodeSampleFlags(ps.Flags).SampleIsNonSync
+		if tenc != nil {
+			ps.Encryption = sencSample(it.senc, int(nr-1), tenc)
+		}
+		it.pending = append(it.pending, ps)
+		it.nextDecodeTime += uint64(dur)
+		offset += uint64(ps.Size)
+	}
+	return true, nil
+}
+
+// nextSttsDur returns the duration of the next sample in stts order.
+func (it *SampleIterator) nextSttsDur() (uint32, error) {
+	stts := it.trak.Mdia.Minf.Stbl.Stts
+	for it.sttsLeft == 0 {
+		if it.sttsIdx >= len(stts.SampleCount) {
+			return 0, fmt.Errorf("stts has fewer samples than stsz")
+		}
+		it.sttsLeft = stts.SampleCount[it.sttsIdx]
+		it.sttsIdx++
+	}
+	it.sttsLeft--
+	return stts.SampleTimeDelta[it.sttsIdx-1], nil
+}
+
+// fillFromFragment adds the samples of all trafs of the track in the next fragment containing the track.
+func (it *SampleIterator) fillFromFragment() (bool, error) {
+	segs := it.f.Segments
+	for it.segIdx < len(segs) {
+		frags := segs[it.segIdx].Fragments
+		if it.fragIdx >= len(frags) {
+			it.segIdx++
+			it.fragIdx = 0
+			continue
+		}
+		frag := frags[it.fragIdx]
+		it.fragIdx++
+		if frag.Moof == nil {
+			continue
+		}
+		found := false
+		for _, traf := range frag.Moof.Trafs {
+			if traf.Tfhd.TrackID != it.trackID {
+				continue
+			}
+			found = true
+			if err := it.addTrafSamples(frag, traf); err != nil {
+				return true, err
+			}
+		}
+		if found {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// addTrafSamples adds the samples of all truns in traf.
+func (it *SampleIterator) addTrafSamples(frag *Fragment, traf *TrafBox) error {
+	moof := frag.Moof
+	tfhd := traf.Tfhd
+	if traf.Tfdt != nil {
+		it.nextDecodeTime = traf.Tfdt.BaseMediaDecodeTime()
+	}
+	sdi := uint32(1)
+	switch {
+	case tfhd.HasSampleDescriptionIndex():
+		sdi = tfhd.SampleDescriptionIndex
+	case it.trex != nil:
+		sdi = it.trex.DefaultSampleDescriptionIndex
+	}
+	tenc := it.tenc(sdi)
+	var senc *SencBox
+	if tenc != nil {
+		switch {
+		case traf.Senc != nil:
+			senc = traf.Senc
+		case traf.UUIDSenc != nil:
+			senc = traf.UUIDSenc.Senc
+		}
+		if senc != nil && senc.ReadButNotParsed() {
+			ivSize, err := traf.sencPerSampleIVSize(tenc.DefaultPerSampleIVSize)
+			if err != nil {
+				return fmt.Errorf("parse senc: %w", err)
+			}
+			// Parse a copy to leave the iterated file unchanged
+			parsed := *senc
+			if err := parsed.ParseReadBox(ivSize, traf.Saiz); err != nil {
+				return fmt.Errorf("parse senc: %w", err)
+			}
+			senc = &parsed
+		}
+	}
+	// The default base is moofStartPos according to Section 8.8.7.1
+	baseOffset := moof.StartPos
+	if tfhd.HasBaseDataOffset() {
+		baseOffset = tfhd.BaseDataOffset
+	}
+	offset := baseOffset
+	sampleIdx := 0
+	for _, trun := range traf.Truns {
+		// A trun without data offset continues after the data of the previous trun
+		if trun.HasDataOffset() {
+			offset = uint64(int64(trun.DataOffset) + int64(baseOffset))
+		}
+		for _, s := range trun.samplesWithDefaults(tfhd, it.trex) {
+			it.nrSamples++
+			ps := pendingSample{
+				TrackSample: TrackSample{
+					FullSample:             FullSample{Sample: s, DecodeTime: it.nextDecodeTime},
+					SampleNr:               it.nrSamples,
+					SampleDescriptionIndex: sdi,
+					SyncSample:             !DecodeSampleFlags(s.Flags).SampleIsNonSync,
+				},
+				offset: offset,
+				mdat:   frag.Mdat,
+			}
+			if tenc != nil {
+				ps.Encryption = sencSample(senc, sampleIdx, tenc)
+			}
+			it.pending = append(it.pending, ps)
+			it.nextDecodeTime += uint64(s.Dur)
+			offset += uint64(s.Size)
+			sampleIdx++
+		}
+	}
+	return nil
+}
+
+// tenc returns the tenc box for the sample description index, or nil if the samples are not encrypted.
+func (it *SampleIterator) tenc(sampleDescriptionIndex uint32) *TencBox {
+	if it.trak == nil {
+		return nil
+	}
+	stsd := it.trak.Mdia.Minf.Stbl.Stsd
+	if sampleDescriptionIndex < 1 || int(sampleDescriptionIndex) > len(stsd.Children) {
+		return nil
+	}
+	var sinf *SinfBox
+	switch se := stsd.Children[sampleDescriptionIndex-1].(type) {
+	case *VisualSampleEntryBox:
+		sinf = se.Sinf
+	case *AudioSampleEntryBox:
+		sinf = se.Sinf
+	}
+	if sinf == nil || sinf.Schi == nil {
+		return nil
+	}
+	return sinf.Schi.Tenc
+}
+
+// readData returns size bytes at the absolute offset from mdat, or from the ReadSeeker if not available in mdat.
+func (it *SampleIterator) readData(mdat *MdatBox, offset uint64, size uint32) ([]byte, error) {
+	if mdat != nil && !mdat.IsLazy() {
+		start := mdat.PayloadAbsoluteOffset()
+		if offset >= start && offset+uint64(size) <= start+uint64(len(mdat.Data)) {
+			return mdat.Data[offset-start : offset-start+uint64(size)], nil
+		}
+	}
+	if it.rs == nil {
+		return nil, fmt.Errorf("data at offset %d not in mdat and no ReadSeeker", offset)
+	}
+	_, err := it.rs.Seek(int64(offset), io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, size)
+	_, err = io.ReadFull(it.rs, data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// stscSampleDescriptionID returns the sample description ID for a sample.
+func stscSampleDescriptionID(stsc *StscBox, sampleNr uint32) uint32 {
+	if stsc.singleSampleDescriptionID != 0 {
+		return stsc.singleSampleDescriptionID
+	}
+	entryNr := stsc.FindEntryNrForSampleNr(sampleNr, 0)
+	return stsc.SampleDescriptionID[entryNr]
+}
+
+// sencSample returns IV and subsample patterns for the sample with index idx.
+// The constant IV in tenc is used if there is no per-sample IV.
+func sencSample(senc *SencBox, idx int, tenc *TencBox) *SencSample {
+	ss := &SencSample{}
+	if senc != nil {
+		if idx < len(senc.IVs) {
+			ss.IV = senc.IVs[idx]
+		}
+		if idx < len(senc.SubSamples) {
+			ss.SubSamples = senc.SubSamples[idx]
+		}
+	}
+	if len(ss.IV) == 0 && tenc.DefaultConstantIV != nil {
+		ss.IV = InitializationVector(tenc.DefaultConstantIV)
+	}
+	return ss
+}
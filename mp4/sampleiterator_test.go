@@ -0,0 +1,266 @@
+package mp4_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestSampleIteratorProgressive(t *testing.T) {
+	for _, lazy := range []bool{false, true} {
+		fh, err := os.Open("testdata/prog_8s.mp4")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fh.Close()
+		var f *mp4.File
+		if lazy {
+			f, err = mp4.DecodeFile(fh, mp4.WithDecodeMode(mp4.DecModeLazyMdat))
+		} else {
+			f, err = mp4.DecodeFile(fh)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, trak := range f.Moov.Traks {
+			trackID := trak.Tkhd.TrackID
+			stbl := trak.Mdia.Minf.Stbl
+			nrSamples := trak.GetNrSamples()
+			wanted, err := trak.GetSampleData(1, nrSamples)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := bytes.Buffer{}
+			err = f.CopySampleData(&data, fh, trak, 1, nrSamples, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			it, err := f.NewSampleIterator(trackID, fh)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var nr uint32
+			var offset uint32
+			for it.Next() {
+				s := it.Sample()
+				nr++
+				if s.SampleNr != nr || s.Sample != wanted[nr-1] {
+					t.Fatalf("lazy=%t track %d sample %d: got %+v instead of %+v", lazy, trackID, nr, s.Sample, wanted[nr-1])
+				}
+				decTime, _ := stbl.Stts.GetDecodeTime(nr)
+				if s.DecodeTime != decTime {
+					t.Errorf("track %d sample %d: got decode time %d instead of %d", trackID, nr, s.DecodeTime, decTime)
+				}
+				wantedSync := stbl.Stss == nil || stbl.Stss.IsSyncSample(nr)
+				if s.SyncSample != wantedSync {
+					t.Errorf("track %d sample %d: got sync %t", trackID, nr, s.SyncSample)
+				}
+				if s.SampleDescriptionIndex != 1 || s.Encryption != nil {
+					t.Errorf("track %d sample %d: got sdi %d, encryption %v", trackID, nr, s.SampleDescriptionIndex, s.Encryption)
+				}
+				if !bytes.Equal(s.Data, data.Bytes()[offset:offset+s.Size]) {
+					t.Errorf("lazy=%t track %d sample %d: data differs", lazy, trackID, nr)
+				}
+				offset += s.Size
+			}
+			if it.Err() != nil {
+				t.Error(it.Err())
+			}
+			if nr != nrSamples {
+				t.Errorf("track %d: got %d samples instead of %d", trackID, nr, nrSamples)
+			}
+		}
+	}
+}
+
+func TestSampleIteratorFragmented(t *testing.T) {
+	for _, lazy := range []bool{false, true} {
+		fh, err := os.Open("testdata/prog_8s_enc_dashinit.mp4")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fh.Close()
+		var f *mp4.File
+		if lazy {
+			f, err = mp4.DecodeFile(fh, mp4.WithDecodeMode(mp4.DecModeLazyMdat))
+		} else {
+			f, err = mp4.DecodeFile(fh)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ref, err := mp4.ReadMP4File("testdata/prog_8s_enc_dashinit.mp4")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, trex := range ref.Init.Moov.Mvex.Trexs {
+			var wanted []mp4.FullSample
+			var wantedEnc []mp4.SencSample
+			for _, seg := range ref.Segments {
+				for _, frag := range seg.Fragments {
+					samples, err := frag.GetFullSamples(trex)
+					if err != nil {
+						t.Fatal(err)
+					}
+					wanted = append(wanted, samples...)
+					for _, traf := range frag.Moof.Trafs {
+						if traf.Tfhd.TrackID != trex.TrackID {
+							continue
+						}
+						for i := range traf.Senc.IVs {
+							ss := mp4.SencSample{IV: traf.Senc.IVs[i]}
+							if len(traf.Senc.SubSamples) > 0 {
+								ss.SubSamples = traf.Senc.SubSamples[i]
+							}
+							wantedEnc = append(wantedEnc, ss)
+						}
+					}
+				}
+			}
+			it, err := f.NewSampleIterator(trex.TrackID, fh)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nr := 0
+			for it.Next() {
+				s := it.Sample()
+				w := wanted[nr]
+				nr++
+				if s.SampleNr != uint32(nr) || s.Sample != w.Sample || s.DecodeTime != w.DecodeTime || !bytes.Equal(s.Data, w.Data) {
+					t.Fatalf("lazy=%t track %d sample %d differs", lazy, trex.TrackID, nr)
+				}
+				if s.SyncSample != !mp4.DecodeSampleFlags(w.Flags).SampleIsNonSync {
+					t.Errorf("track %d sample %d: got sync %t", trex.TrackID, nr, s.SyncSample)
+				}
+				if s.Encryption == nil || !bytes.Equal(s.Encryption.IV, wantedEnc[nr-1].IV) ||
+					len(s.Encryption.SubSamples) != len(wantedEnc[nr-1].SubSamples) {
+					t.Errorf("track %d sample %d: got encryption %v instead of %v", trex.TrackID, nr, s.Encryption, wantedEnc[nr-1])
+				}
+			}
+			if it.Err() != nil {
+				t.Error(it.Err())
+			}
+			if nr != len(wanted) || nr == 0 {
+				t.Errorf("track %d: got %d samples instead of %d", trex.TrackID, nr, len(wanted))
+			}
+		}
+	}
+}
+
+func TestSampleIteratorTrafsAndTruns(t *testing.T) {
+	// One moof with two trafs for the same track. The second trun of the first traf has no data offset,
+	// and sample durations are only given as tfhd defaults.
+	const dur = 1000
+	init := mp4.CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	trackID := init.Moov.Trak.Tkhd.TrackID
+	sampleData := [][]byte{{1, 1}, {2, 2, 2}, {3}, {4, 4, 4, 4}}
+	newTrun := func(flags uint32, sizes ...int) *mp4.TrunBox {
+		trun := &mp4.TrunBox{Flags: flags}
+		for _, size := range sizes {
+			trun.AddSample(mp4.Sample{Size: uint32(size)})
+		}
+		return trun
+	}
+	newTraf := func(decodeTime uint64, truns ...*mp4.TrunBox) *mp4.TrafBox {
+		tfhd := mp4.CreateTfhd(trackID)
+		tfhd.Flags |= mp4.TfhdDefaultSampleDurationPresentFlag
+		tfhd.DefaultSampleDuration = dur
+		traf := &mp4.TrafBox{}
+		for _, b := range []mp4.Box{tfhd, mp4.CreateTfdt(decodeTime)} {
+			if err := traf.AddChild(b); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, trun := range truns {
+			if err := traf.AddChild(trun); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return traf
+	}
+	withOffset := mp4.TrunDataOffsetPresentFlag | mp4.TrunSampleSizePresentFlag
+	trun1 := newTrun(withOffset, 2, 3)
+	trun2 := newTrun(mp4.TrunSampleSizePresentFlag, 1)
+	trun3 := newTrun(withOffset, 4)
+	moof := &mp4.MoofBox{}
+	for _, b := range []mp4.Box{mp4.CreateMfhd(1), newTraf(0, trun1, trun2), newTraf(3*dur, trun3)} {
+		if err := moof.AddChild(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mdat := &mp4.MdatBox{}
+	mdat.SetData(bytes.Join(sampleData, nil))
+	trun1.DataOffset = int32(moof.Size() + mdat.HeaderSize())
+	trun3.DataOffset = trun1.DataOffset + 6
+	var buf bytes.Buffer
+	for _, b := range []mp4.Box{init.Ftyp, init.Moov, moof, mdat} {
+		if err := b.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := mp4.DecodeFile(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err := f.NewSampleIterator(trackID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nr := 0
+	for it.Next() {
+		s := it.Sample()
+		if nr >= len(sampleData) {
+			t.Fatalf("more than %d samples", len(sampleData))
+		}
+		if !bytes.Equal(s.Data, sampleData[nr]) || s.DecodeTime != uint64(nr*dur) || s.Dur != dur {
+			t.Errorf("sample %d: got data %v decode time %d dur %d", nr+1, s.Data, s.DecodeTime, s.Dur)
+		}
+		nr++
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if nr != len(sampleData) {
+		t.Errorf("got %d samples instead of %d", nr, len(sampleData))
+	}
+	for _, traf := range f.Segments[0].Fragments[0].Moof.Trafs {
+		for _, trun := range traf.Truns {
+			for _, s := range trun.Samples {
+				if s.Dur != 0 {
+					t.Errorf("trun sample duration changed to %d", s.Dur)
+				}
+			}
+		}
+	}
+}
+
+func TestSampleIteratorErrors(t *testing.T) {
+	f, err := mp4.ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.NewSampleIterator(7, nil)
+	if err == nil {
+		t.Error("expected error for missing track")
+	}
+
+	fh, err := os.Open("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fh.Close()
+	f, err = mp4.DecodeFile(fh, mp4.WithDecodeMode(mp4.DecModeLazyMdat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err := f.NewSampleIterator(1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Next() || it.Err() == nil {
+		t.Error("expected error for lazy mdat without ReadSeeker")
+	}
+}
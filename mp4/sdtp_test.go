@@ -15,3 +15,10 @@ func TestSdtp(t *testing.T) {
 
 	boxDiffAfterEncodeAndDecode(t, mp4.CreateSdtpBox(entries))
 }
+
+func TestNewSdtpEntry(t *testing.T) {
+	e := mp4.NewSdtpEntry(1, 2, 3, 0)
+	if e.IsLeading() != 1 || e.SampleDependsOn() != 2 || e.SampleIsDependedOn() != 3 || e.SampleHasRedundancy() != 0 {
+		t.Errorf("got entry %08b", uint8(e))
+	}
+}
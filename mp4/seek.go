@@ -0,0 +1,389 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// SeekMethod - index used to find a SeekPoint
+type SeekMethod int
+
+const (
+	// SeekStss - sample tables with stss in a progressive file
+	SeekStss SeekMethod = iota
+	// SeekMfra - tfra box in mfra at the end of a fragmented file
+	SeekMfra
+	// SeekSidx - sidx box before the first moof box
+	SeekSidx
+	// SeekMoofScan - scan of moof boxes from the start of the file
+	SeekMoofScan
+)
+
+// String - name of the seek method
+func (m SeekMethod) String() string {
+	switch m {
+	case SeekStss:
+		return "stss"
+	case SeekMfra:
+		return "mfra"
+	case SeekSidx:
+		return "sidx"
+	case SeekMoofScan:
+		return "moof scan"
+	default:
+		return fmt.Sprintf("SeekMethod(%d)", int(m))
+	}
+}
+
+// SeekPoint - sync sample found by FindSeekPoint. Times are in mdhd timescale.
+type SeekPoint struct {
+	TrackID    uint32
+	DecodeTime uint64
+	// SampleNr is the one-based number of the sample in the track (progressive) or in the fragment (fragmented).
+	SampleNr uint32
+	// Offset is the absolute position of the sample data in the file
+	Offset uint64
+	// Size is the size of the sample data
+	Size uint32
+	// MoofOffset is the absolute position of the moof box containing the sample (fragmented files only)
+	MoofOffset uint64
+	Method     SeekMethod
+}
+
+// FindSeekPoint finds the nearest sync sample at or before time (decode time in mdhd timescale) for a track.
+//
+// Only the boxes needed are read from rs. For progressive files, the sample tables in moov are used.
+// For fragmented files, the tfra box in mfra (found via mfro at the end of the file) or a sidx box
+// before the first moof box gives the fragment to start from, and the moof boxes are then read until
+// the time has been passed. Without index, the moof boxes are read from the first one.
+func FindSeekPoint(rs io.ReadSeeker, trackID uint32, time uint64) (*SeekPoint, error) {
+	_, err := rs.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	boxInfos, err := GetTopBoxInfoList(rs, "moof")
+	if err != nil {
+		return nil, err
+	}
+	var moov *MoovBox
+	var sidxs []*SidxBox
+	firstMoofPos := uint64(0)
+	for _, bi := range boxInfos {
+		firstMoofPos = bi.StartPos + bi.Size
+		switch bi.Type {
+		case "moov", "sidx":
+			b, err := decodeBoxAt(rs, bi.StartPos)
+			if err != nil {
+				return nil, err
+			}
+			switch box := b.(type) {
+			case *MoovBox:
+				moov = box
+			case *SidxBox:
+				sidxs = append(sidxs, box)
+			}
+		}
+	}
+	if moov == nil {
+		return nil, fmt.Errorf("no moov box before first moof box")
+	}
+	var trak *TrakBox
+	for _, tr := range moov.Traks {
+		if tr.Tkhd.TrackID == trackID {
+			trak = tr
+			break
+		}
+	}
+	if trak == nil {
+		return nil, fmt.Errorf("no track with ID %d", trackID)
+	}
+	if moov.Mvex == nil {
+		return findProgressiveSeekPoint(trak, time)
+	}
+	var trex *TrexBox
+	for _, tr := range moov.Mvex.Trexs {
+		if tr.TrackID == trackID {
+			trex = tr
+			break
+		}
+	}
+
+	startPos, method := firstMoofPos, SeekMoofScan
+	if pos, ok, err := moofPosFromMfra(rs, trackID, time); err != nil {
+		return nil, err
+	} else if ok {
+		startPos, method = pos, SeekMfra
+	} else if pos, ok, err := moofPosFromSidx(rs, sidxs, trackID, time); err != nil {
+		return nil, err
+	} else if ok {
+		startPos, method = pos, SeekSidx
+	}
+	sp, err := scanMoofsForSeekPoint(rs, startPos, trackID, trex, time)
+	if err != nil {
+		return nil, err
+	}
+	if sp == nil && method != SeekMoofScan {
+		method = SeekMoofScan
+		sp, err = scanMoofsForSeekPoint(rs, firstMoofPos, trackID, trex, time)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if sp == nil {
+		return nil, fmt.Errorf("no sync sample at or before time %d in track %d", time, trackID)
+	}
+	sp.Method = method
+	return sp, nil
+}
+
+// decodeBoxAt decodes the box starting at pos.
+func decodeBoxAt(rs io.ReadSeeker, pos uint64) (Box, error) {
+	_, err := rs.Seek(int64(pos), io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBox(pos, rs)
+}
+
+// findProgressiveSeekPoint finds the last sync sample at or before time using the sample tables.
+func findProgressiveSeekPoint(trak *TrakBox, time uint64) (*SeekPoint, error) {
+	stbl := trak.Mdia.Minf.Stbl
+	nrSamples := stbl.Stsz.GetNrSamples()
+	if nrSamples == 0 {
+		return nil, fmt.Errorf("no samples in track %d", trak.Tkhd.TrackID)
+	}
+	sampleNr := sampleNrAtOrBefore(stbl.Stts, time)
+	if sampleNr > nrSamples {
+		sampleNr = nrSamples
+	}
+	if stbl.Stss != nil {
+		syncs := stbl.Stss.SampleNumber
+		idx := sort.Search(len(syncs), func(i int) bool { return syncs[i] > sampleNr })
+		if idx == 0 {
+			return nil, fmt.Errorf("no sync sample at or before time %d in track %d", time, trak.Tkhd.TrackID)
+		}
+		sampleNr = syncs[idx-1]
+	}
+	ranges, err := trak.GetRangesForSampleInterval(sampleNr, sampleNr)
+	if err != nil {
+		return nil, err
+	}
+	decodeTime, _ := stbl.Stts.GetDecodeTime(sampleNr)
+	return &SeekPoint{
+		TrackID:    trak.Tkhd.TrackID,
+		DecodeTime: decodeTime,
+		SampleNr:   sampleNr,
+		Offset:     ranges[0].Offset,
+		Size:       uint32(ranges[0].Size),
+		Method:     SeekStss,
+	}, nil
+}
+
+// sampleNrAtOrBefore returns the one-based number of the last sample starting at or before time.
+// The returned number is larger than the number of samples if time is after the last sample.
+func sampleNrAtOrBefore(stts *SttsBox, time uint64) uint32 {
+	var accTime uint64
+	var accNr uint32
+	for i, count := range stts.SampleCount {
+		delta := uint64(stts.SampleTimeDelta[i])
+		if delta > 0 && time < accTime+uint64(count)*delta {
+			return accNr + uint32((time-accTime)/delta) + 1
+		}
+		accNr += count
+		accTime += uint64(count) * delta
+	}
+	return accNr + 1
+}
+
+// moofPosFromMfra returns the position of the moof with the last tfra entry at or before time.
+// ok is false if there is no mfra box or no tfra box for the track.
+func moofPosFromMfra(rs io.ReadSeeker, trackID uint32, time uint64) (pos uint64, ok bool, err error) {
+	mfroPos, err := rs.Seek(-16, io.SeekEnd) // mfro has a fixed size of 16 bytes
+	if err != nil {
+		return 0, false, nil
+	}
+	mfro, err := TryDecodeMfro(uint64(mfroPos), rs)
+	if err != nil {
+		return 0, false, nil
+	}
+	mfraPos := mfroPos + 16 - int64(mfro.ParentSize)
+	if mfraPos < 0 {
+		return 0, false, fmt.Errorf("mfra size %d larger than file", mfro.ParentSize)
+	}
+	b, err := decodeBoxAt(rs, uint64(mfraPos))
+	if err != nil {
+		return 0, false, fmt.Errorf("decode mfra: %w", err)
+	}
+	mfra, isMfra := b.(*MfraBox)
+	if !isMfra {
+		return 0, false, fmt.Errorf("expected mfra box, but got %s", b.Type())
+	}
+	for _, tfra := range mfra.Tfras {
+		if tfra.TrackID != trackID {
+			continue
+		}
+		for _, e := range tfra.Entries {
+			if e.Time > time {
+				break
+			}
+			pos, ok = e.MoofOffset, true
+		}
+		return pos, ok, nil
+	}
+	return 0, false, nil
+}
+
+// moofPosFromSidx returns the start of the last subsegment with a decode time at or before time.
+// The sidx times are presentation times, so only the subsegment positions are taken from sidx.
+// The decode times are read from the tfdt boxes in the first moof box of the subsegments in a binary search.
+// Only sidx boxes referencing media are used, preferably the one with the track as reference ID.
+func moofPosFromSidx(rs io.ReadSeeker, sidxs []*SidxBox, trackID uint32, time uint64) (uint64, bool, error) {
+	var sidx *SidxBox
+	for _, s := range sidxs {
+		if sidx == nil || (s.ReferenceID == trackID && sidx.ReferenceID != trackID) {
+			sidx = s
+		}
+	}
+	if sidx == nil {
+		return 0, false, nil
+	}
+	positions := make([]uint64, 0, len(sidx.SidxRefs))
+	pos := sidx.AnchorPoint + sidx.FirstOffset
+	for _, ref := range sidx.SidxRefs {
+		if ref.ReferenceType != 0 {
+			return 0, false, nil
+		}
+		positions = append(positions, pos)
+		pos += uint64(ref.ReferencedSize)
+	}
+	var err error
+	idx := sort.Search(len(positions), func(i int) bool {
+		if err != nil {
+			return true
+		}
+		var decodeTime uint64
+		var ok bool
+		decodeTime, ok, err = moofDecodeTime(rs, positions[i], trackID)
+		return err != nil || (ok && decodeTime > time)
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("subsegment decode time: %w", err)
+	}
+	if idx == 0 {
+		return 0, false, nil
+	}
+	return positions[idx-1], true, nil
+}
+
+// moofDecodeTime returns the tfdt decode time of the track in the first moof box at or after pos.
+// ok is false if there is no such moof box, or if it has no traf with tfdt for the track.
+func moofDecodeTime(rs io.ReadSeeker, pos uint64, trackID uint32) (decodeTime uint64, ok bool, err error) {
+	for {
+		_, err = rs.Seek(int64(pos), io.SeekStart)
+		if err != nil {
+			return 0, false, err
+		}
+		hdr, err := DecodeHeader(rs)
+		if err == io.EOF {
+			return 0, false, nil
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		if hdr.Name != "moof" {
+			pos += hdr.Size
+			continue
+		}
+		b, err := decodeBoxAt(rs, pos)
+		if err != nil {
+			return 0, false, err
+		}
+		for _, traf := range b.(*MoofBox).Trafs {
+			if traf.Tfhd.TrackID == trackID && traf.Tfdt != nil {
+				return traf.Tfdt.BaseMediaDecodeTime(), true, nil
+			}
+		}
+		return 0, false, nil
+	}
+}
+
+// scanMoofsForSeekPoint reads moof boxes from pos until a fragment of the track starts after time.
+// It returns the last sync sample at or before time, or nil if none was found.
+func scanMoofsForSeekPoint(rs io.ReadSeeker, pos uint64, trackID uint32, trex *TrexBox, time uint64) (*SeekPoint, error) {
+	var sp *SeekPoint
+	for {
+		_, err := rs.Seek(int64(pos), io.SeekStart)
+		if err != nil {
+			return nil, err
+		}
+		hdr, err := DecodeHeader(rs)
+		if err == io.EOF {
+			return sp, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Name == "moof" {
+			b, err := decodeBoxAt(rs, pos)
+			if err != nil {
+				return nil, err
+			}
+			moof := b.(*MoofBox)
+			for _, traf := range moof.Trafs {
+				if traf.Tfhd.TrackID != trackID {
+					continue
+				}
+				if traf.Tfdt != nil && traf.Tfdt.BaseMediaDecodeTime() > time {
+					return sp, nil
+				}
+				if fsp := findSyncSampleInTraf(moof, traf, trex, time); fsp != nil {
+					sp = fsp
+				}
+			}
+		}
+		pos += hdr.Size
+	}
+}
+
+// findSyncSampleInTraf returns the last sync sample at or before time in traf, or nil.
+func findSyncSampleInTraf(moof *MoofBox, traf *TrafBox, trex *TrexBox, time uint64) *SeekPoint {
+	tfhd := traf.Tfhd
+	var decodeTime uint64
+	if traf.Tfdt != nil {
+		decodeTime = traf.Tfdt.BaseMediaDecodeTime()
+	}
+	// The default base is moofStartPos according to Section 8.8.7.1
+	baseOffset := moof.StartPos
+	if tfhd.HasBaseDataOffset() {
+		baseOffset = tfhd.BaseDataOffset
+	}
+	offset := baseOffset
+	var sp *SeekPoint
+	sampleNr := uint32(0)
+	for _, trun := range traf.Truns {
+		// A trun without data offset continues after the data of the previous trun
+		if trun.HasDataOffset() {
+			offset = uint64(int64(trun.DataOffset) + int64(baseOffset))
+		}
+		for _, s := range trun.samplesWithDefaults(tfhd, trex) {
+			sampleNr++
+			if decodeTime > time {
+				return sp
+			}
+			if !DecodeSampleFlags(s.Flags).SampleIsNonSync {
+				sp = &SeekPoint{
+					TrackID:    tfhd.TrackID,
+					DecodeTime: decodeTime,
+					SampleNr:   sampleNr,
+					Offset:     offset,
+					Size:       s.Size,
+					MoofOffset: moof.StartPos,
+				}
+			}
+			decodeTime += uint64(s.Dur)
+			offset += uint64(s.Size)
+		}
+	}
+	return sp
+}
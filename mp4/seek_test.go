@@ -0,0 +1,182 @@
+package mp4_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestFindSeekPointProgressive(t *testing.T) {
+	data, err := os.ReadFile("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := mp4.DecodeFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trak := range f.Moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		stbl := trak.Mdia.Minf.Stbl
+		timescale := uint64(trak.Mdia.Mdhd.Timescale)
+		for _, time := range []uint64{0, timescale / 2, 3 * timescale, 100 * timescale} {
+			sp, err := mp4.FindSeekPoint(bytes.NewReader(data), trackID, time)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var wantedNr uint32
+			for nr := uint32(1); nr <= trak.GetNrSamples(); nr++ {
+				decTime, _ := stbl.Stts.GetDecodeTime(nr)
+				if decTime > time {
+					break
+				}
+				if stbl.Stss == nil || stbl.Stss.IsSyncSample(nr) {
+					wantedNr = nr
+				}
+			}
+			wantedTime, _ := stbl.Stts.GetDecodeTime(wantedNr)
+			if sp.Method != mp4.SeekStss || sp.SampleNr != wantedNr || sp.DecodeTime != wantedTime {
+				t.Errorf("track %d time %d: got %+v, wanted sample %d at %d", trackID, time, sp, wantedNr, wantedTime)
+				continue
+			}
+			sample := bytes.Buffer{}
+			err = f.CopySampleData(&sample, bytes.NewReader(data), trak, wantedNr, wantedNr, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data[sp.Offset:sp.Offset+uint64(sp.Size)], sample.Bytes()) {
+				t.Errorf("track %d time %d: wrong sample range %d-%d", trackID, time, sp.Offset, sp.Size)
+			}
+		}
+	}
+}
+
+func TestFindSeekPointFragmented(t *testing.T) {
+	testCases := []struct {
+		file   string
+		method mp4.SeekMethod
+	}{
+		{"prog_8s_enc_dashinit.mp4", mp4.SeekSidx},
+		{"bbb5s_aac.isma", mp4.SeekMfra},
+		{"cbcs.mp4", mp4.SeekMoofScan},
+	}
+	for _, tc := range testCases {
+		data, err := os.ReadFile("testdata/" + tc.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := mp4.DecodeFile(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, trak := range f.Init.Moov.Traks {
+			trackID := trak.Tkhd.TrackID
+			var trex *mp4.TrexBox
+			for _, tr := range f.Init.Moov.Mvex.Trexs {
+				if tr.TrackID == trackID {
+					trex = tr
+				}
+			}
+			var syncSamples []mp4.FullSample
+			for _, seg := range f.Segments {
+				for _, frag := range seg.Fragments {
+					samples, err := frag.GetFullSamples(trex)
+					if err != nil {
+						t.Fatal(err)
+					}
+					for _, s := range samples {
+						if !mp4.DecodeSampleFlags(s.Flags).SampleIsNonSync {
+							syncSamples = append(syncSamples, s)
+						}
+					}
+				}
+			}
+			first := syncSamples[0].DecodeTime
+			last := syncSamples[len(syncSamples)-1].DecodeTime
+			for _, time := range []uint64{first, first + 1, (first + last) / 2, last, last + 1000} {
+				sp, err := mp4.FindSeekPoint(bytes.NewReader(data), trackID, time)
+				if err != nil {
+					t.Fatalf("%s track %d time %d: %v", tc.file, trackID, time, err)
+				}
+				var wanted mp4.FullSample
+				for _, s := range syncSamples {
+					if s.DecodeTime <= time {
+						wanted = s
+					}
+				}
+				if sp.Method != tc.method || sp.DecodeTime != wanted.DecodeTime || sp.Size != wanted.Size {
+					t.Errorf("%s track %d time %d: got %+v, wanted decode time %d", tc.file, trackID, time, sp, wanted.DecodeTime)
+					continue
+				}
+				if !bytes.Equal(data[sp.Offset:sp.Offset+uint64(sp.Size)], wanted.Data) {
+					t.Errorf("%s track %d time %d: wrong sample data", tc.file, trackID, time)
+				}
+				if string(data[sp.MoofOffset+4:sp.MoofOffset+8]) != "moof" {
+					t.Errorf("%s track %d time %d: no moof at %d", tc.file, trackID, time, sp.MoofOffset)
+				}
+			}
+		}
+	}
+}
+
+func TestFindSeekPointErrors(t *testing.T) {
+	data, err := os.ReadFile("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = mp4.FindSeekPoint(bytes.NewReader(data), 7, 0)
+	if err == nil {
+		t.Error("expected error for missing track")
+	}
+	_, err = mp4.FindSeekPoint(bytes.NewReader(data[:100]), 1, 0)
+	if err == nil {
+		t.Error("expected error for missing moov")
+	}
+}
+
+func TestFindSeekPointTrunWithoutDataOffset(t *testing.T) {
+	// The sync sample is the only sample in the second trun, which has no data offset.
+	const dur = 1000
+	init := mp4.CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	trackID := init.Moov.Trak.Tkhd.TrackID
+	tfhd := mp4.CreateTfhd(trackID)
+	tfhd.Flags |= mp4.TfhdDefaultSampleDurationPresentFlag | mp4.TfhdDefaultSampleFlagsPresentFlag
+	tfhd.DefaultSampleDuration = dur
+	tfhd.DefaultSampleFlags = mp4.NonSyncSampleFlags
+	trun1 := &mp4.TrunBox{Flags: mp4.TrunDataOffsetPresentFlag | mp4.TrunSampleSizePresentFlag}
+	trun1.AddSamples([]mp4.Sample{{Size: 2}, {Size: 3}})
+	trun2 := &mp4.TrunBox{Flags: mp4.TrunSampleSizePresentFlag | mp4.TrunSampleFlagsPresentFlag}
+	trun2.AddSample(mp4.Sample{Size: 1, Flags: mp4.SyncSampleFlags})
+	traf := &mp4.TrafBox{}
+	moof := &mp4.MoofBox{}
+	for _, b := range []mp4.Box{tfhd, mp4.CreateTfdt(0), trun1, trun2} {
+		if err := traf.AddChild(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, b := range []mp4.Box{mp4.CreateMfhd(1), traf} {
+		if err := moof.AddChild(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mdat := &mp4.MdatBox{}
+	mdat.SetData([]byte{1, 1, 2, 2, 2, 3})
+	trun1.DataOffset = int32(moof.Size() + mdat.HeaderSize())
+	var buf bytes.Buffer
+	for _, b := range []mp4.Box{init.Ftyp, init.Moov, moof, mdat} {
+		if err := b.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := buf.Bytes()
+	sp, err := mp4.FindSeekPoint(bytes.NewReader(data), trackID, 2*dur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sp.DecodeTime != 2*dur || sp.SampleNr != 3 || sp.Size != 1 || data[sp.Offset] != 3 {
+		t.Errorf("got %+v, wanted sample 3 with data 3", sp)
+	}
+}
@@ -32,7 +32,7 @@ type SencBox struct {
 	perSampleIVSize  byte
 	Flags            uint32
 	SampleCount      uint32
-	StartPos         uint64
+	StartPos         uint64                 `json:"-"`
 	rawData          []byte                 // intermediate storage when reading
 	IVs              []InitializationVector // 8 or 16 bytes if present
 	SubSamples       [][]SubSamplePattern
@@ -412,3 +412,15 @@ func (s *SencBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 func (s *SencBox) GetPerSampleIVSize() int {
 	return int(s.perSampleIVSize)
 }
+
+// addJSONFields - add per-sample IV size
+func (s *SencBox) addJSONFields(fields map[string]interface{}) {
+	fields["PerSampleIVSize"] = int64(s.GetPerSampleIVSize())
+}
+
+// setJSONFields - set per-sample IV size
+func (s *SencBox) setJSONFields(fields map[string]interface{}) error {
+	size, _, err := popUint64(fields, "PerSampleIVSize")
+	s.perSampleIVSize = byte(size)
+	return err
+}
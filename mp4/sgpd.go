@@ -3,6 +3,7 @@ package mp4
 import (
 	"fmt"
 	"io"
+	"reflect"
 
 	"github.com/Eyevinn/mp4ff/bits"
 )
@@ -158,3 +159,32 @@ func (b *SgpdBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 	}
 	return bd.err
 }
+
+// setJSONFields - set sample group entries of the type given by GroupingType
+func (b *SgpdBox) setJSONFields(fields map[string]interface{}) error {
+	value, ok := fields["SampleGroupEntries"]
+	if !ok {
+		return nil
+	}
+	delete(fields, "SampleGroupEntries")
+	if value == nil {
+		return nil
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("SampleGroupEntries: not an array")
+	}
+	groupingType, _ := fields["GroupingType"].(string)
+	for i, v := range values {
+		entryFields, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("SampleGroupEntries[%d]: not an object", i)
+		}
+		entry := newSampleGroupEntry(groupingType)
+		if err := setStructFields(reflect.ValueOf(entry).Elem(), entryFields, false); err != nil {
+			return fmt.Errorf("SampleGroupEntries[%d]: %w", i, err)
+		}
+		b.SampleGroupEntries = append(b.SampleGroupEntries, entry)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// Stereo modes of st3d box
+const (
+	StereoModeMono         = 0
+	StereoModeTopBottom    = 1
+	StereoModeLeftRight    = 2
+	StereoModeStereoCustom = 3
+	StereoModeRightLeft    = 4
+)
+
+// St3dBox - Stereoscopic 3D Video Box (st3d)
+//
+// Contained in : Visual Sample Entry
+//
+// Defined in Google Spherical Video V2 RFC
+type St3dBox struct {
+	Version    byte
+	Flags      uint32
+	StereoMode byte
+}
+
+// DecodeSt3d - box-specific decode
+func DecodeSt3d(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeSt3dSR(hdr, startPos, sr)
+}
+
+// DecodeSt3dSR - box-specific decode
+func DecodeSt3dSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &St3dBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.StereoMode = sr.ReadUint8()
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *St3dBox) Type() string {
+	return "st3d"
+}
+
+// Size - return calculated size
+func (b *St3dBox) Size() uint64 {
+	return uint64(boxHeaderSize + 5)
+}
+
+// Encode - write box to w
+func (b *St3dBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *St3dBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint8(b.StereoMode)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *St3dBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - stereoMode: %d (%s)", b.StereoMode, stereoModeName(b.StereoMode))
+	return bd.err
+}
+
+func stereoModeName(mode byte) string {
+	switch mode {
+	case StereoModeMono:
+		return "mono"
+	case StereoModeTopBottom:
+		return "top-bottom"
+	case StereoModeLeftRight:
+		return "left-right"
+	case StereoModeStereoCustom:
+		return "stereo-custom"
+	case StereoModeRightLeft:
+		return "right-left"
+	default:
+		return "unknown"
+	}
+}
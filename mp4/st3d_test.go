@@ -0,0 +1,12 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestSt3d(t *testing.T) {
+	st3d := &mp4.St3dBox{StereoMode: mp4.StereoModeTopBottom}
+	boxDiffAfterEncodeAndDecode(t, st3d)
+}
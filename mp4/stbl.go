@@ -17,7 +17,8 @@ type StblBox struct {
 	Stts  *SttsBox
 	Ctts  *CttsBox
 	Stsc  *StscBox
-	Stsz  *StszBox
+	Stsz  *StszBox // Set to a view of Stz2 if stz2 is used. Changes are written back to stz2 on encode
+	Stz2  *Stz2Box
 	Stss  *StssBox
 	Stco  *StcoBox
 	Co64  *Co64Box
@@ -52,6 +53,9 @@ func (s *StblBox) AddChild(child Box) {
 		s.Stsc = box
 	case *StszBox:
 		s.Stsz = box
+	case *Stz2Box:
+		s.Stz2 = box
+		s.Stsz = box.stszView()
 	case *StssBox:
 		s.Stss = box
 	case *StcoBox:
@@ -112,8 +116,16 @@ func (s *StblBox) Type() string {
 }
 
 // Size - box-specific size
+// With stz2, the size is that of the box written on encode after changes via the Stsz view.
 func (s *StblBox) Size() uint64 {
-	return containerSize(s.Children)
+	size := containerSize(s.Children)
+	if s.Stz2 == nil || s.Stsz == nil {
+		return size
+	}
+	if s.Stz2.fits(s.Stsz) {
+		return size - s.Stz2.Size() + s.Stz2.expectedSize(uint32(len(s.Stsz.SampleSize)))
+	}
+	return size - s.Stz2.Size() + s.Stsz.Size()
 }
 
 // GetChildren - list of child boxes
@@ -123,14 +135,34 @@ func (s *StblBox) GetChildren() []Box {
 
 // Encode - write stbl container to w
 func (s *StblBox) Encode(w io.Writer) error {
+	s.syncStz2()
 	return EncodeContainer(s, w)
 }
 
 // Encode - write stbl container to sw
 func (b *StblBox) EncodeSW(sw bits.SliceWriter) error {
+	b.syncStz2()
 	return EncodeContainerSW(b, sw)
 }
 
+// syncStz2 writes changes made via the Stsz view back to Stz2 before encoding.
+// If the sample sizes no longer fit in Stz2, it is replaced by Stsz.
+func (s *StblBox) syncStz2() {
+	if s.Stz2 == nil || s.Stsz == nil {
+		return
+	}
+	if s.Stz2.fits(s.Stsz) {
+		s.Stz2.SampleSize = s.Stsz.SampleSize
+		return
+	}
+	for i, c := range s.Children {
+		if c == Box(s.Stz2) {
+			s.Children[i] = s.Stsz
+		}
+	}
+	s.Stz2 = nil
+}
+
 // Info - write box-specific information
 func (s *StblBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	return ContainerInfo(s, w, specificBoxLevels, indent, indentStep)
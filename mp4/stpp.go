@@ -91,7 +91,7 @@ func DecodeStppSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 		if rest <= 0 {
 			break
 		}
-		box, err := DecodeBoxSR(pos, sr)
+		box, err := decodeChildBoxSR(pos, sr)
 		if err != nil {
 			return nil, err
 		}
@@ -105,6 +105,11 @@ func DecodeStppSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	return &b, sr.AccError()
 }
 
+// GetChildren - list of child boxes
+func (b *StppBox) GetChildren() []Box {
+	return b.Children
+}
+
 // Type - return box type
 func (b *StppBox) Type() string {
 	return "stpp"
@@ -0,0 +1,255 @@
+package mp4
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// StreamParser is a push-based parser for fragmented MP4 data that arrives in chunks,
+// e.g. CMAF chunks received over a socket.
+//
+// Data is fed to the parser via Write in pieces of arbitrary size.
+// As soon as a complete top-level box is available, it is decoded using the registered
+// BoxDecoderSR functions (so decoders set by SetBoxDecoder apply) and the corresponding
+// callback is called. Only the bytes of the current, incomplete box and a moof box
+// waiting for its mdat box are kept in memory.
+//
+// Boxes are decoded when they are complete, so an mdat box is buffered in full before
+// OnFragment is called. The latency and the memory used are therefore given by the
+// fragment size, and low-latency streams should be sent as small fragments (CMAF chunks).
+//
+// A styp box starts a new MediaSegment which is handed to OnSegment. Fragments are not
+// collected in the segment, but delivered one by one via OnFragment.
+//
+// Callbacks that are nil are not called. An error returned by a callback stops the
+// parsing and is returned by Write.
+type StreamParser struct {
+	// OnInit is called when a moov box (preceded by an optional ftyp box) has been decoded.
+	OnInit func(init *InitSegment) error
+	// OnFragment is called when a fragment (moof + mdat) is complete.
+	// Any emsg and prft boxes preceding the moof box are part of the fragment.
+	OnFragment func(frag *Fragment) error
+	// OnEmsg is called as soon as an emsg box has been decoded.
+	OnEmsg func(emsg *EmsgBox) error
+	// OnPrft is called as soon as a prft box has been decoded.
+	OnPrft func(prft *PrftBox) error
+	// OnSegment is called when a styp box starts a new media segment.
+	OnSegment func(seg *MediaSegment) error
+	// OnBox is called for all other top-level boxes such as ftyp, sidx, and free.
+	// An ftyp box is also part of the next init segment, and sidx boxes are added to the current segment.
+	OnBox func(box Box) error
+
+	init   *InitSegment
+	ftyp   *FtypBox
+	seg    *MediaSegment // segment started by the latest styp box
+	frag   *Fragment     // fragment waiting for more boxes
+	buf    []byte
+	offset int    // offset of first unconsumed byte in buf
+	pos    uint64 // absolute stream position of buf[offset]
+	err    error
+}
+
+// NewStreamParser creates a StreamParser without callbacks.
+func NewStreamParser() *StreamParser {
+	return &StreamParser{}
+}
+
+// SetInit sets the init segment to use for streams where it is provided out of band.
+// It is needed to parse senc boxes of encrypted tracks.
+func (p *StreamParser) SetInit(init *InitSegment) {
+	p.init = init
+}
+
+// Init returns the latest init segment, or nil if none has been parsed or set.
+func (p *StreamParser) Init() *InitSegment {
+	return p.init
+}
+
+// Segment returns the media segment started by the latest styp box, or nil if there is none.
+func (p *StreamParser) Segment() *MediaSegment {
+	return p.seg
+}
+
+// Pos returns the absolute stream position up to which boxes have been parsed.
+func (p *StreamParser) Pos() uint64 {
+	return p.pos
+}
+
+// NrBufferedBytes returns the number of bytes belonging to an incomplete box.
+func (p *StreamParser) NrBufferedBytes() int {
+	return len(p.buf) - p.offset
+}
+
+// Write adds data to the parser and decodes all top-level boxes that are complete.
+// It implements io.Writer, so a stream can be fed using io.Copy.
+// After an error, all subsequent calls return the same error.
+func (p *StreamParser) Write(data []byte) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	p.buf = append(p.buf, data...)
+	p.err = p.parse()
+	if p.err != nil {
+		return 0, p.err
+	}
+	return len(data), nil
+}
+
+// Close checks that the stream ended at a box boundary and that no fragment is incomplete.
+func (p *StreamParser) Close() error {
+	if p.err != nil {
+		return p.err
+	}
+	if n := p.NrBufferedBytes(); n > 0 {
+		return fmt.Errorf("stream ended with %d bytes of incomplete box at pos %d", n, p.pos)
+	}
+	if p.frag != nil {
+		return fmt.Errorf("stream ended with incomplete fragment starting at pos %d", p.frag.StartPos)
+	}
+	return nil
+}
+
+// parse decodes complete boxes in the buffer and drops their bytes.
+func (p *StreamParser) parse() error {
+	for {
+		avail := len(p.buf) - p.offset
+		if avail < boxHeaderSize {
+			break
+		}
+		size := uint64(binary.BigEndian.Uint32(p.buf[p.offset:]))
+		hdrLen := uint64(boxHeaderSize)
+		switch size {
+		case 1:
+			if avail < boxHeaderSize+largeSizeLen {
+				p.compact()
+				return nil
+			}
+			size = binary.BigEndian.Uint64(p.buf[p.offset+boxHeaderSize:])
+			hdrLen += largeSizeLen
+		case 0:
+			return fmt.Errorf("box at pos %d: size 0, meaning to end of file, not supported in stream", p.pos)
+		}
+		if size < hdrLen {
+			return fmt.Errorf("box at pos %d: header size %d exceeds box size %d", p.pos, hdrLen, size)
+		}
+		if uint64(avail) < size {
+			break
+		}
+		sr := bits.NewFixedSliceReader(p.buf[p.offset : p.offset+int(size)])
+		box, err := DecodeBoxSR(p.pos, sr)
+		if err != nil {
+			return err
+		}
+		p.offset += int(size)
+		startPos := p.pos
+		p.pos += size
+		err = p.handleBox(box, startPos)
+		if err != nil {
+			return err
+		}
+	}
+	p.compact()
+	return nil
+}
+
+// compact drops consumed bytes from the buffer.
+// The remaining bytes are copied to a new slice, since decoded boxes (like mdat)
+// may still refer to the old one.
+func (p *StreamParser) compact() {
+	if p.offset == 0 {
+		return
+	}
+	if p.offset == len(p.buf) {
+		p.buf = nil
+	} else {
+		p.buf = append([]byte(nil), p.buf[p.offset:]...)
+	}
+	p.offset = 0
+}
+
+// handleBox assembles init segments and fragments and calls the callbacks.
+func (p *StreamParser) handleBox(box Box, startPos uint64) error {
+	if p.frag != nil && p.frag.Moof != nil && box.Type() != "mdat" {
+		return fmt.Errorf("%s box at pos %d between moof and mdat", box.Type(), startPos)
+	}
+	switch b := box.(type) {
+	case *FtypBox:
+		p.ftyp = b
+	case *StypBox:
+		p.seg = NewMediaSegmentWithStyp(b)
+		p.seg.StartPos = startPos
+		if p.OnSegment != nil {
+			return p.OnSegment(p.seg)
+		}
+		return nil
+	case *SidxBox:
+		if p.seg != nil {
+			if p.seg.Sidx == nil {
+				p.seg.Sidx = b
+			}
+			p.seg.Sidxs = append(p.seg.Sidxs, b)
+		}
+	case *MoovBox:
+		init := NewMP4Init()
+		if p.ftyp != nil {
+			init.AddChild(p.ftyp)
+			p.ftyp = nil
+		}
+		init.AddChild(b)
+		p.init = init
+		if p.OnInit != nil {
+			return p.OnInit(init)
+		}
+		return nil
+	case *EmsgBox:
+		p.startFragmentIfNeeded(startPos)
+		p.frag.AddChild(b)
+		if p.OnEmsg != nil {
+			return p.OnEmsg(b)
+		}
+		return nil
+	case *PrftBox:
+		p.startFragmentIfNeeded(startPos)
+		p.frag.AddChild(b)
+		if p.OnPrft != nil {
+			return p.OnPrft(b)
+		}
+		return nil
+	case *MoofBox:
+		var moov *MoovBox
+		if p.init != nil {
+			moov = p.init.Moov
+		}
+		err := parseReadSencBoxes(moov, b)
+		if err != nil {
+			return err
+		}
+		p.startFragmentIfNeeded(startPos)
+		p.frag.AddChild(b)
+		return nil
+	case *MdatBox:
+		if p.frag == nil || p.frag.Moof == nil {
+			break // Not part of a fragment
+		}
+		frag := p.frag
+		frag.AddChild(b)
+		p.frag = nil
+		if p.OnFragment != nil {
+			return p.OnFragment(frag)
+		}
+		return nil
+	}
+	if p.OnBox != nil {
+		return p.OnBox(box)
+	}
+	return nil
+}
+
+// startFragmentIfNeeded starts a new fragment unless emsg or prft boxes have already started one.
+func (p *StreamParser) startFragmentIfNeeded(startPos uint64) {
+	if p.frag == nil {
+		p.frag = &Fragment{StartPos: startPos}
+	}
+}
@@ -0,0 +1,167 @@
+package mp4_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/aac"
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestStreamParserChunked(t *testing.T) {
+	data, err := os.ReadFile("testdata/cbcs.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decFile, err := mp4.DecodeFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wantedFrags []*mp4.Fragment
+	for _, seg := range decFile.Segments {
+		wantedFrags = append(wantedFrags, seg.Fragments...)
+	}
+
+	for _, chunkSize := range []int{1, 7, 1000, len(data)} {
+		p := mp4.NewStreamParser()
+		nrInits := 0
+		var frags []*mp4.Fragment
+		p.OnInit = func(init *mp4.InitSegment) error {
+			nrInits++
+			return nil
+		}
+		p.OnFragment = func(frag *mp4.Fragment) error {
+			frags = append(frags, frag)
+			return nil
+		}
+		for start := 0; start < len(data); start += chunkSize {
+			end := start + chunkSize
+			if end > len(data) {
+				end = len(data)
+			}
+			_, err := p.Write(data[start:end])
+			if err != nil {
+				t.Fatalf("chunkSize %d: %s", chunkSize, err)
+			}
+		}
+		if err := p.Close(); err != nil {
+			t.Error(err)
+		}
+		if nrInits != 1 {
+			t.Errorf("chunkSize %d: got %d init segments instead of 1", chunkSize, nrInits)
+		}
+		if len(frags) != len(wantedFrags) {
+			t.Fatalf("chunkSize %d: got %d fragments instead of %d", chunkSize, len(frags), len(wantedFrags))
+		}
+		trex, _ := p.Init().Moov.Mvex.GetTrex(wantedFrags[0].Moof.Traf.Tfhd.TrackID)
+		for i, frag := range frags {
+			if frag.StartPos != wantedFrags[i].StartPos {
+				t.Errorf("fragment %d: startPos %d instead of %d", i, frag.StartPos, wantedFrags[i].StartPos)
+			}
+			got, err := frag.GetFullSamples(trex)
+			if err != nil {
+				t.Error(err)
+			}
+			wanted, err := wantedFrags[i].GetFullSamples(trex)
+			if err != nil {
+				t.Error(err)
+			}
+			if len(got) != len(wanted) {
+				t.Fatalf("fragment %d: %d samples instead of %d", i, len(got), len(wanted))
+			}
+			for j := range got {
+				if !bytes.Equal(got[j].Data, wanted[j].Data) {
+					t.Errorf("fragment %d sample %d: data differs", i, j)
+				}
+			}
+		}
+	}
+}
+
+func TestStreamParserEmsg(t *testing.T) {
+	init := mp4.CreateEmptyInit()
+	init.AddEmptyTrack(48000, "audio", "en")
+	err := init.Moov.Trak.SetAACDescriptor(aac.AAClc, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	err = init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seg := mp4.NewMediaSegment()
+	frag := createFragment(t, 1, 1024, 0)
+	frag.AddEmsg(&mp4.EmsgBox{ID: 1})
+	seg.AddFragment(frag)
+	err = seg.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := mp4.NewStreamParser()
+	var boxTypes []string
+	var emsgIDs []uint32
+	var gotFrag *mp4.Fragment
+	var segs []*mp4.MediaSegment
+	p.OnSegment = func(seg *mp4.MediaSegment) error {
+		segs = append(segs, seg)
+		return nil
+	}
+	p.OnBox = func(b mp4.Box) error {
+		boxTypes = append(boxTypes, b.Type())
+		return nil
+	}
+	p.OnEmsg = func(emsg *mp4.EmsgBox) error {
+		if gotFrag != nil {
+			t.Error("emsg callback after fragment callback")
+		}
+		emsgIDs = append(emsgIDs, emsg.ID)
+		return nil
+	}
+	p.OnFragment = func(frag *mp4.Fragment) error {
+		gotFrag = frag
+		return nil
+	}
+	data := buf.Bytes()
+	// Feed everything but the last byte to check that the fragment is not complete
+	_, err = p.Write(data[:len(data)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotFrag != nil {
+		t.Error("fragment reported before mdat complete")
+	}
+	if len(emsgIDs) != 1 || emsgIDs[0] != 1 {
+		t.Errorf("got emsg IDs %v instead of [1]", emsgIDs)
+	}
+	if p.NrBufferedBytes() == 0 {
+		t.Error("no buffered bytes for incomplete mdat")
+	}
+	if p.Close() == nil {
+		t.Error("expected error for incomplete stream")
+	}
+	_, err = p.Write(data[len(data)-1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Close(); err != nil {
+		t.Error(err)
+	}
+	if gotFrag == nil || len(gotFrag.Emsgs) != 1 || gotFrag.Moof == nil || gotFrag.Mdat == nil {
+		t.Fatalf("fragment not complete: %+v", gotFrag)
+	}
+	if len(boxTypes) != 1 || boxTypes[0] != "ftyp" {
+		t.Errorf("got other boxes %v instead of [ftyp]", boxTypes)
+	}
+	if p.Init().Ftyp == nil {
+		t.Error("ftyp not part of init segment")
+	}
+	if len(segs) != 1 || segs[0].Styp == nil || p.Segment() != segs[0] {
+		t.Errorf("got %d segments instead of one with styp", len(segs))
+	}
+	if p.Pos() != uint64(len(data)) {
+		t.Errorf("pos %d instead of %d", p.Pos(), len(data))
+	}
+}
@@ -0,0 +1,93 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// StriBox - Stereo View Information Box (stri)
+//
+// Contained in : Stereo View Box (eyes)
+//
+// Defined in Apple HEVC Stereo Video - ISOBMFF Extensions
+type StriBox struct {
+	Version            byte
+	Flags              uint32
+	EyeViewsReversed   bool
+	HasAdditionalViews bool
+	HasRightEyeView    bool
+	HasLeftEyeView     bool
+}
+
+// DecodeStri - box-specific decode
+func DecodeStri(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeStriSR(hdr, startPos, sr)
+}
+
+// DecodeStriSR - box-specific decode
+func DecodeStriSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &StriBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	views := sr.ReadUint8()
+	b.EyeViewsReversed = views&0x08 != 0
+	b.HasAdditionalViews = views&0x04 != 0
+	b.HasRightEyeView = views&0x02 != 0
+	b.HasLeftEyeView = views&0x01 != 0
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *StriBox) Type() string {
+	return "stri"
+}
+
+// Size - return calculated size
+func (b *StriBox) Size() uint64 {
+	return uint64(boxHeaderSize + 5)
+}
+
+// Encode - write box to w
+func (b *StriBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *StriBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteBits(0, 4)
+	sw.WriteFlag(b.EyeViewsReversed)
+	sw.WriteFlag(b.HasAdditionalViews)
+	sw.WriteFlag(b.HasRightEyeView)
+	sw.WriteFlag(b.HasLeftEyeView)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *StriBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - eyeViewsReversed: %t", b.EyeViewsReversed)
+	bd.write(" - hasAdditionalViews: %t", b.HasAdditionalViews)
+	bd.write(" - hasRightEyeView: %t", b.HasRightEyeView)
+	bd.write(" - hasLeftEyeView: %t", b.HasLeftEyeView)
+	return bd.err
+}
@@ -293,3 +293,15 @@ func (b *StscBox) FindEntryNrForSampleNr(sampleNr, lowEntryIdx uint32) uint32 {
 	}
 	return low - 1
 }
+
+// addJSONFields - add single sample description ID used instead of SampleDescriptionID slice
+func (b *StscBox) addJSONFields(fields map[string]interface{}) {
+	fields["SingleSampleDescriptionID"] = uint64(b.singleSampleDescriptionID)
+}
+
+// setJSONFields - set single sample description ID
+func (b *StscBox) setJSONFields(fields map[string]interface{}) error {
+	id, _, err := popUint64(fields, "SingleSampleDescriptionID")
+	b.singleSampleDescriptionID = uint32(id)
+	return err
+}
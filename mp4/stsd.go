@@ -16,13 +16,13 @@ type StsdBox struct {
 	Version     byte
 	Flags       uint32
 	SampleCount uint32
-	// AvcX is a pointer to box with name avc1 or avc3
+	// AvcX is a pointer to box with name avc1, avc3, dva1, or dvav
 	AvcX *VisualSampleEntryBox
-	// HvcX is a pointer to a box with name hvc1 or hev1
+	// HvcX is a pointer to a box with name hvc1, hev1, dvh1, or dvhe
 	HvcX *VisualSampleEntryBox
 	// VvcX is apointer to a box with name vvc1 or vvi1
 	VvcX *VisualSampleEntryBox
-	// Av01 is a pointer to a box with name av01
+	// Av01 is a pointer to a box with name av01 or dav1
 	Av01 *VisualSampleEntryBox
 	// Avs3 is a pointer to a box with name avs3
 	Avs3 *VisualSampleEntryBox
@@ -49,7 +49,17 @@ type StsdBox struct {
 	// Stpp is a pointer to a StppBox
 	Stpp *StppBox
 	// Evte is a pointer to an EvteBox
-	Evte     *EvteBox
+	Evte *EvteBox
+	// Tx3g is a pointer to a Tx3gBox
+	Tx3g *Tx3gBox
+	// Tmcd is a pointer to a TmcdBox
+	Tmcd *TmcdBox
+	// Mett is a pointer to a MettBox
+	Mett *MettBox
+	// Metx is a pointer to a MetxBox
+	Metx *MetxBox
+	// Urim is a pointer to a UrimBox
+	Urim     *UrimBox
 	Children []Box
 }
 
@@ -61,15 +71,15 @@ func NewStsdBox() *StsdBox {
 // AddChild - Add a child box, set relevant pointer, and update SampleCount
 func (s *StsdBox) AddChild(box Box) {
 	switch box.Type() {
-	case "avc1", "avc3":
+	case "avc1", "avc3", "dva1", "dvav":
 		s.AvcX = box.(*VisualSampleEntryBox)
-	case "hvc1", "hev1":
+	case "hvc1", "hev1", "dvh1", "dvhe":
 		s.HvcX = box.(*VisualSampleEntryBox)
 	case "vvc1", "vvi1":
 		s.VvcX = box.(*VisualSampleEntryBox)
 	case "encv":
 		s.Encv = box.(*VisualSampleEntryBox)
-	case "av01":
+	case "av01", "dav1":
 		s.Av01 = box.(*VisualSampleEntryBox)
 	case "vp08", "vp09":
 		s.VpXX = box.(*VisualSampleEntryBox)
@@ -95,6 +105,16 @@ func (s *StsdBox) AddChild(box Box) {
 		s.Stpp = box.(*StppBox)
 	case "evte":
 		s.Evte = box.(*EvteBox)
+	case "tx3g":
+		s.Tx3g = box.(*Tx3gBox)
+	case "tmcd":
+		s.Tmcd = box.(*TmcdBox)
+	case "mett":
+		s.Mett = box.(*MettBox)
+	case "metx":
+		s.Metx = box.(*MetxBox)
+	case "urim":
+		s.Urim = box.(*UrimBox)
 	}
 	s.Children = append(s.Children, box)
 	s.SampleCount++
@@ -168,6 +188,11 @@ func DecodeStsdSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	return &stsd, nil
 }
 
+// GetChildren - list of child boxes
+func (s *StsdBox) GetChildren() []Box {
+	return s.Children
+}
+
 // Type - box-specific type
 func (s *StsdBox) Type() string {
 	return "stsd"
@@ -250,6 +275,14 @@ func (s *StsdBox) GetBtrt() *BtrtBox {
 			return child.Btrt
 		case *EvteBox:
 			return child.Btrt
+		case *Tx3gBox:
+			return child.Btrt
+		case *MettBox:
+			return child.Btrt
+		case *MetxBox:
+			return child.Btrt
+		case *UrimBox:
+			return child.Btrt
 		}
 	}
 	return nil
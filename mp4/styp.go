@@ -117,3 +117,17 @@ func (b *StypBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 	}
 	return bd.err
 }
+
+// addJSONFields - add brands stored in raw form
+func (b *StypBox) addJSONFields(fields map[string]interface{}) {
+	fields["MajorBrand"] = b.MajorBrand()
+	fields["MinorVersion"] = uint64(b.MinorVersion())
+	fields["CompatibleBrands"] = b.CompatibleBrands()
+}
+
+// setJSONFields - set brands in raw form
+func (b *StypBox) setJSONFields(fields map[string]interface{}) error {
+	data, err := popBrands(fields)
+	b.data = data
+	return err
+}
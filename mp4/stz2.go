@@ -0,0 +1,213 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// Stz2Box - Compact Sample Size Box (stz2)
+//
+// Contained in : Sample Table box (stbl)
+//
+// Alternative to stsz where the sample sizes are stored with 4, 8, or 16 bits.
+// When added to a StblBox, the Stsz field is set to a StszBox sharing the sample sizes,
+// so that all sample-size lookups work the same way for both boxes.
+// Changes to the sample sizes should be made via StblBox.Stsz. When the StblBox is encoded,
+// the stz2 box is updated to match, or replaced by the stsz box if the sizes no longer fit.
+type Stz2Box struct {
+	Version    byte
+	Flags      uint32
+	FieldSize  byte // 4, 8, or 16
+	SampleSize []uint32
+}
+
+// NewStz2Box creates a Stz2Box with the smallest field size that fits all sample sizes.
+func NewStz2Box(sampleSizes []uint32) (*Stz2Box, error) {
+	var maxSize uint32
+	for _, size := range sampleSizes {
+		if size > maxSize {
+			maxSize = size
+		}
+	}
+	b := &Stz2Box{SampleSize: sampleSizes}
+	switch {
+	case maxSize < 1<<4:
+		b.FieldSize = 4
+	case maxSize < 1<<8:
+		b.FieldSize = 8
+	case maxSize < 1<<16:
+		b.FieldSize = 16
+	default:
+		return nil, fmt.Errorf("stz2: sample size %d does not fit in 16 bits", maxSize)
+	}
+	return b, nil
+}
+
+// DecodeStz2 - box-specific decode
+func DecodeStz2(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeStz2SR(hdr, startPos, sr)
+}
+
+// DecodeStz2SR - box-specific decode
+func DecodeStz2SR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := Stz2Box{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	reservedAndFieldSize := sr.ReadUint32()
+	b.FieldSize = byte(reservedAndFieldSize)
+	sampleCount := sr.ReadUint32()
+	switch b.FieldSize {
+	case 4, 8, 16:
+	default:
+		return nil, fmt.Errorf("stz2: field size %d not 4, 8, or 16", b.FieldSize)
+	}
+	expectedPayloadLen := int(b.expectedSize(sampleCount)) - boxHeaderSize
+	if hdr.payloadLen() != expectedPayloadLen {
+		return nil, fmt.Errorf("stz2: expected payload size %d, got %d", expectedPayloadLen, hdr.payloadLen())
+	}
+	b.SampleSize = make([]uint32, sampleCount)
+	switch b.FieldSize {
+	case 4:
+		for i := 0; i < int(sampleCount); i += 2 {
+			val := sr.ReadUint8()
+			b.SampleSize[i] = uint32(val >> 4)
+			if i+1 < int(sampleCount) {
+				b.SampleSize[i+1] = uint32(val & 0x0f)
+			}
+		}
+	case 8:
+		for i := 0; i < int(sampleCount); i++ {
+			b.SampleSize[i] = uint32(sr.ReadUint8())
+		}
+	case 16:
+		for i := 0; i < int(sampleCount); i++ {
+			b.SampleSize[i] = uint32(sr.ReadUint16())
+		}
+	}
+	return &b, sr.AccError()
+}
+
+// Type - box-specific type
+func (b *Stz2Box) Type() string {
+	return "stz2"
+}
+
+// Size - box-specific size
+func (b *Stz2Box) Size() uint64 {
+	return b.expectedSize(uint32(len(b.SampleSize)))
+}
+
+// expectedSize - calculate size based on FieldSize and sample count
+func (b *Stz2Box) expectedSize(sampleCount uint32) uint64 {
+	// 12 = version + flags(4) + reserved(3) + fieldSize(1) + sampleCount(4)
+	return uint64(boxHeaderSize+12) + (uint64(sampleCount)*uint64(b.FieldSize)+7)/8
+}
+
+// Encode - write box to w
+func (b *Stz2Box) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *Stz2Box) EncodeSW(sw bits.SliceWriter) error {
+	switch b.FieldSize {
+	case 4, 8, 16:
+	default:
+		return fmt.Errorf("stz2: field size %d not 4, 8, or 16", b.FieldSize)
+	}
+	maxSize := uint32(1)<<b.FieldSize - 1
+	for i, size := range b.SampleSize {
+		if size > maxSize {
+			return fmt.Errorf("stz2: sample %d size %d does not fit in %d bits", i+1, size, b.FieldSize)
+		}
+	}
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint32(uint32(b.FieldSize))
+	sampleCount := len(b.SampleSize)
+	sw.WriteUint32(uint32(sampleCount))
+	switch b.FieldSize {
+	case 4:
+		for i := 0; i < sampleCount; i += 2 {
+			val := byte(b.SampleSize[i]) << 4
+			if i+1 < sampleCount {
+				val |= byte(b.SampleSize[i+1])
+			}
+			sw.WriteUint8(val)
+		}
+	case 8:
+		for _, size := range b.SampleSize {
+			sw.WriteUint8(byte(size))
+		}
+	case 16:
+		for _, size := range b.SampleSize {
+			sw.WriteUint16(uint16(size))
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *Stz2Box) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - fieldSize: %d", b.FieldSize)
+	bd.write(" - sampleCount: %d", len(b.SampleSize))
+	level := getInfoLevel(b, specificBoxLevels)
+	if level >= 1 {
+		for i := range b.SampleSize {
+			bd.write(" - sample[%d] size=%d", i+1, b.SampleSize[i])
+		}
+	}
+	return bd.err
+}
+
+// GetNrSamples - get number of samples
+func (b *Stz2Box) GetNrSamples() uint32 {
+	return uint32(len(b.SampleSize))
+}
+
+// GetSampleSize returns the size (in bytes) of a sample (one-based)
+func (b *Stz2Box) GetSampleSize(i int) uint32 {
+	return b.SampleSize[i-1]
+}
+
+// stszView returns a StszBox sharing the sample sizes.
+func (b *Stz2Box) stszView() *StszBox {
+	return &StszBox{
+		SampleNumber: uint32(len(b.SampleSize)),
+		SampleSize:   b.SampleSize,
+	}
+}
+
+// fits returns true if the sample sizes of stsz can be stored with the field size of the box.
+func (b *Stz2Box) fits(stsz *StszBox) bool {
+	if stsz.SampleUniformSize != 0 || stsz.SampleNumber != uint32(len(stsz.SampleSize)) {
+		return false
+	}
+	maxSize := uint32(1)<<b.FieldSize - 1
+	for _, size := range stsz.SampleSize {
+		if size > maxSize {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,192 @@
+package mp4_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/bits"
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestStz2EncDec(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		sizes       []uint32
+		wantedField byte
+	}{
+		{desc: "4 bits odd count", sizes: []uint32{1, 15, 7}, wantedField: 4},
+		{desc: "4 bits even count", sizes: []uint32{1, 15, 7, 0}, wantedField: 4},
+		{desc: "8 bits", sizes: []uint32{16, 255, 3}, wantedField: 8},
+		{desc: "16 bits", sizes: []uint32{256, 65535, 1000}, wantedField: 16},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			stz2, err := mp4.NewStz2Box(tc.sizes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stz2.FieldSize != tc.wantedField {
+				t.Errorf("got field size %d instead of %d", stz2.FieldSize, tc.wantedField)
+			}
+			boxDiffAfterEncodeAndDecode(t, stz2)
+		})
+	}
+	_, err := mp4.NewStz2Box([]uint32{65536})
+	if err == nil {
+		t.Error("expected error for too big sample size")
+	}
+	stz2 := &mp4.Stz2Box{FieldSize: 8, SampleSize: []uint32{256}}
+	if err := stz2.Encode(&bytes.Buffer{}); err == nil {
+		t.Error("expected error for sample size not fitting field size")
+	}
+}
+
+// TestStz2InFile replaces stsz by stz2 in a file and checks that samples are the same after encode and decode.
+func TestStz2InFile(t *testing.T) {
+	inFile, err := mp4.ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := make([][]mp4.Sample, len(inFile.Moov.Traks))
+	for i, trak := range inFile.Moov.Traks {
+		wanted[i], err = trak.GetSampleData(1, trak.GetNrSamples())
+		if err != nil {
+			t.Fatal(err)
+		}
+		stbl := trak.Mdia.Minf.Stbl
+		stz2, err := mp4.NewStz2Box(stbl.Stsz.SampleSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, c := range stbl.Children {
+			if c.Type() == "stsz" {
+				stbl.Children[j] = stz2
+			}
+		}
+	}
+	buf := bytes.Buffer{}
+	err = inFile.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decFile, err := mp4.DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, trak := range decFile.Moov.Traks {
+		stbl := trak.Mdia.Minf.Stbl
+		if stbl.Stz2 == nil {
+			t.Fatalf("track %d: no stz2 box", i+1)
+		}
+		got, err := trak.GetSampleData(1, trak.GetNrSamples())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(wanted[i]) {
+			t.Fatalf("track %d: %d samples instead of %d", i+1, len(got), len(wanted[i]))
+		}
+		for j := range got {
+			if got[j] != wanted[i][j] {
+				t.Errorf("track %d sample %d: got %+v instead of %+v", i+1, j+1, got[j], wanted[i][j])
+			}
+		}
+		part, err := trak.GetSampleData(2, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(part) != 2 || part[0] != wanted[i][1] || part[1] != wanted[i][2] {
+			t.Errorf("track %d: got %+v for samples 2-3", i+1, part)
+		}
+		ranges, err := trak.GetRangesForSampleInterval(2, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size, err := stbl.Stsz.GetTotalSampleSize(2, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rangeSize uint64
+		for _, r := range ranges {
+			rangeSize += r.Size
+		}
+		if rangeSize != size {
+			t.Errorf("track %d: range size %d instead of %d", i+1, rangeSize, size)
+		}
+	}
+}
+
+func TestStz2LargeSize(t *testing.T) {
+	stz2, err := mp4.NewStz2Box([]uint32{16, 255, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	if err := stz2.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	raw := buf.Bytes()
+	// Rewrite the header with size 1 and a 64-bit largesize
+	size := uint64(len(raw) + 8)
+	large := []byte{0, 0, 0, 1, 's', 't', 'z', '2'}
+	for i := 7; i >= 0; i-- {
+		large = append(large, byte(size>>(8*i)))
+	}
+	large = append(large, raw[8:]...)
+	box, err := mp4.DecodeBox(0, bytes.NewReader(large))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := box.(*mp4.Stz2Box).SampleSize; len(got) != 3 || got[1] != 255 {
+		t.Errorf("got sample sizes %v", got)
+	}
+	box, err = mp4.DecodeBoxSR(0, bits.NewFixedSliceReader(large))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := box.(*mp4.Stz2Box).SampleSize; len(got) != 3 || got[1] != 255 {
+		t.Errorf("got sample sizes %v", got)
+	}
+}
+
+// TestStz2ViewChanges checks that changes via StblBox.Stsz are encoded.
+func TestStz2ViewChanges(t *testing.T) {
+	stz2, err := mp4.NewStz2Box([]uint32{16, 255, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stbl := mp4.NewStblBox()
+	stbl.AddChild(stz2)
+	stbl.Stsz.SampleSize = stbl.Stsz.SampleSize[:2]
+	stbl.Stsz.SampleNumber = 2
+	decStbl := encodeAndDecodeStbl(t, stbl)
+	if decStbl.Stz2 == nil || decStbl.Stsz.GetNrSamples() != 2 {
+		t.Fatalf("cropped stz2 not encoded")
+	}
+	stbl.Stsz.SampleSize = append(stbl.Stsz.SampleSize, 1000)
+	stbl.Stsz.SampleNumber++
+	_ = stbl.Size()
+	if stbl.Stz2 == nil || stbl.Children[0] != mp4.Box(stz2) {
+		t.Errorf("Size changed the box tree")
+	}
+	decStbl = encodeAndDecodeStbl(t, stbl)
+	if decStbl.Stz2 != nil || decStbl.Stsz.GetNrSamples() != 3 || decStbl.Stsz.GetSampleSize(3) != 1000 {
+		t.Errorf("stz2 not replaced by stsz for sample size not fitting")
+	}
+}
+
+func encodeAndDecodeStbl(t *testing.T, stbl *mp4.StblBox) *mp4.StblBox {
+	t.Helper()
+	size := stbl.Size()
+	buf := bytes.Buffer{}
+	if err := stbl.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if uint64(buf.Len()) != size {
+		t.Errorf("encoded %d bytes, but size was %d", buf.Len(), size)
+	}
+	box, err := mp4.DecodeBox(0, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return box.(*mp4.StblBox)
+}
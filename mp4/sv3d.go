@@ -0,0 +1,101 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// Sv3dBox - Spherical Video Box (sv3d)
+//
+// Contained in : Visual Sample Entry
+//
+// Defined in Google Spherical Video V2 RFC
+type Sv3dBox struct {
+	Svhd     *SvhdBox
+	Proj     *ProjBox
+	Children []Box
+}
+
+// CreateSv3d - create a sv3d box with svhd, and a proj box with prhd and the projection box (e.g. equi or cbmp).
+func CreateSv3d(metadataSource string, prhd *PrhdBox, projection Box) *Sv3dBox {
+	proj := &ProjBox{}
+	proj.AddChild(prhd)
+	proj.AddChild(projection)
+	b := &Sv3dBox{}
+	b.AddChild(&SvhdBox{MetadataSource: metadataSource})
+	b.AddChild(proj)
+	return b
+}
+
+// CreateEquirectSv3d - create a sv3d box for a full equirectangular projection without pose rotation.
+func CreateEquirectSv3d(metadataSource string) *Sv3dBox {
+	return CreateSv3d(metadataSource, &PrhdBox{}, &EquiBox{})
+}
+
+// AddChild - add a child box
+func (b *Sv3dBox) AddChild(child Box) {
+	switch box := child.(type) {
+	case *SvhdBox:
+		b.Svhd = box
+	case *ProjBox:
+		b.Proj = box
+	}
+	b.Children = append(b.Children, child)
+}
+
+// DecodeSv3d - box-specific decode
+func DecodeSv3d(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.Size, r)
+	if err != nil {
+		return nil, err
+	}
+	b := &Sv3dBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// DecodeSv3dSR - box-specific decode
+func DecodeSv3dSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.Size, sr)
+	if err != nil {
+		return nil, err
+	}
+	b := &Sv3dBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// Type - return box type
+func (b *Sv3dBox) Type() string {
+	return "sv3d"
+}
+
+// Size - return calculated size
+func (b *Sv3dBox) Size() uint64 {
+	return containerSize(b.Children)
+}
+
+// GetChildren - list of child boxes
+func (b *Sv3dBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write sv3d container to w
+func (b *Sv3dBox) Encode(w io.Writer) error {
+	return EncodeContainer(b, w)
+}
+
+// EncodeSW - write sv3d container via sw
+func (b *Sv3dBox) EncodeSW(sw bits.SliceWriter) error {
+	return EncodeContainerSW(b, sw)
+}
+
+// Info - write box-specific information
+func (b *Sv3dBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
+}
@@ -0,0 +1,25 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestSv3d(t *testing.T) {
+	boxDiffAfterEncodeAndDecode(t, &mp4.SvhdBox{MetadataSource: "Spherical Metadata Tooling"})
+	boxDiffAfterEncodeAndDecode(t, &mp4.PrhdBox{PoseYawDegrees: 90 << 16, PosePitchDegrees: -(10 << 16)})
+	boxDiffAfterEncodeAndDecode(t, &mp4.EquiBox{ProjectionBoundsTop: 1 << 30, ProjectionBoundsBottom: 1 << 30})
+	boxDiffAfterEncodeAndDecode(t, &mp4.CbmpBox{Padding: 8})
+
+	sv3d := mp4.CreateEquirectSv3d("mp4ff")
+	boxDiffAfterEncodeAndDecode(t, sv3d)
+	if sv3d.Svhd == nil || sv3d.Proj == nil || sv3d.Proj.Prhd == nil || sv3d.Proj.Equi == nil {
+		t.Error("missing child box pointers in sv3d")
+	}
+	sv3d = mp4.CreateSv3d("mp4ff", &mp4.PrhdBox{PoseRollDegrees: 180 << 16}, &mp4.CbmpBox{})
+	boxDiffAfterEncodeAndDecode(t, sv3d)
+	if sv3d.Proj.Cbmp == nil || sv3d.Proj.Equi != nil {
+		t.Error("expected cbmp projection")
+	}
+}
@@ -0,0 +1,79 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// SvhdBox - Spherical Video Header Box (svhd)
+//
+// Contained in : Spherical Video Box (sv3d)
+//
+// Defined in Google Spherical Video V2 RFC
+type SvhdBox struct {
+	Version        byte
+	Flags          uint32
+	MetadataSource string
+}
+
+// DecodeSvhd - box-specific decode
+func DecodeSvhd(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeSvhdSR(hdr, startPos, sr)
+}
+
+// DecodeSvhdSR - box-specific decode
+func DecodeSvhdSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := &SvhdBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.MetadataSource = sr.ReadZeroTerminatedString(hdr.payloadLen() - 4)
+	return b, sr.AccError()
+}
+
+// Type - return box type
+func (b *SvhdBox) Type() string {
+	return "svhd"
+}
+
+// Size - return calculated size
+func (b *SvhdBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4 + len(b.MetadataSource) + 1)
+}
+
+// Encode - write box to w
+func (b *SvhdBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *SvhdBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteString(b.MetadataSource, true)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *SvhdBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - metadataSource: %q", b.MetadataSource)
+	return bd.err
+}
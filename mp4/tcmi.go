@@ -0,0 +1,127 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// TcmiBox - Timecode Media Information Box (tcmi)
+//
+// Defines the text style for displaying the timecode.
+//
+// Contained in : Timecode Media Information Box (tmcd) in Base Media Information Header Box (gmhd)
+//
+// Defined in QuickTime File Format Specification
+type TcmiBox struct {
+	Version         byte
+	Flags           uint32
+	TextFont        uint16
+	TextFace        uint16
+	TextSize        uint16
+	TextColor       [3]uint16
+	BackgroundColor [3]uint16
+	FontName        string
+}
+
+// CreateTcmi - create tcmi box with default text style
+func CreateTcmi() *TcmiBox {
+	return &TcmiBox{
+		TextSize: 12,
+		FontName: "Lucida Grande",
+	}
+}
+
+// DecodeTcmi - box-specific decode
+func DecodeTcmi(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeTcmiSR(hdr, startPos, sr)
+}
+
+// DecodeTcmiSR - box-specific decode
+func DecodeTcmiSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := TcmiBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.TextFont = sr.ReadUint16()
+	b.TextFace = sr.ReadUint16()
+	b.TextSize = sr.ReadUint16()
+	sr.SkipBytes(2) // Reserved
+	for i := 0; i < 3; i++ {
+		b.TextColor[i] = sr.ReadUint16()
+	}
+	for i := 0; i < 3; i++ {
+		b.BackgroundColor[i] = sr.ReadUint16()
+	}
+	if hdr.payloadLen() > 24 {
+		nameLen := int(sr.ReadUint8())
+		if nameLen > hdr.payloadLen()-25 {
+			return nil, fmt.Errorf("tcmi: font name length %d too large", nameLen)
+		}
+		b.FontName = sr.ReadFixedLengthString(nameLen)
+	}
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *TcmiBox) Type() string {
+	return "tcmi"
+}
+
+// Size - return calculated size
+func (b *TcmiBox) Size() uint64 {
+	return uint64(boxHeaderSize + 24 + 1 + len(b.FontName))
+}
+
+// Encode - write box to w
+func (b *TcmiBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *TcmiBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint16(b.TextFont)
+	sw.WriteUint16(b.TextFace)
+	sw.WriteUint16(b.TextSize)
+	sw.WriteUint16(0)
+	for i := 0; i < 3; i++ {
+		sw.WriteUint16(b.TextColor[i])
+	}
+	for i := 0; i < 3; i++ {
+		sw.WriteUint16(b.BackgroundColor[i])
+	}
+	sw.WriteUint8(byte(len(b.FontName)))
+	sw.WriteString(b.FontName, false)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *TcmiBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - textFont: %d", b.TextFont)
+	bd.write(" - textFace: %d", b.TextFace)
+	bd.write(" - textSize: %d", b.TextSize)
+	bd.write(" - textColor: %v", b.TextColor)
+	bd.write(" - backgroundColor: %v", b.BackgroundColor)
+	bd.write(" - fontName: %q", b.FontName)
+	return bd.err
+}
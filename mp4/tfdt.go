@@ -129,3 +129,15 @@ func (t *TfdtBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 	bd.write(" - baseMediaDecodeTime: %d", t.BaseMediaDecodeTime())
 	return bd.err
 }
+
+// addJSONFields - add baseMediaDecodeTime
+func (t *TfdtBox) addJSONFields(fields map[string]interface{}) {
+	fields["BaseMediaDecodeTime"] = t.baseMediaDecodeTime
+}
+
+// setJSONFields - set baseMediaDecodeTime without changing version
+func (t *TfdtBox) setJSONFields(fields map[string]interface{}) error {
+	bTime, _, err := popUint64(fields, "BaseMediaDecodeTime")
+	t.baseMediaDecodeTime = bTime
+	return err
+}
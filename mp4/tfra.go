@@ -3,6 +3,7 @@ package mp4
 import (
 	"fmt"
 	"io"
+	"math"
 
 	"github.com/Eyevinn/mp4ff/bits"
 )
@@ -17,6 +18,7 @@ type TfraBox struct {
 	LengthSizeOfTrunNum   byte
 	LengthSizeOfSampleNum byte
 	Entries               []TfraEntry
+	moofs                 []*MoofBox // moof box of each entry, set by File.UpdateMfra
 }
 
 // TfraEntry - reference as used inside TfraBox
@@ -222,3 +224,40 @@ func (b *TfraBox) FindEntry(moofStart uint64) *TfraEntry {
 	}
 	return nil
 }
+
+// setMinimalSizes sets version and number lengths to the smallest values that fit all entries.
+func (b *TfraBox) setMinimalSizes() {
+	var maxTraf, maxTrun, maxSample uint32
+	b.Version = 0
+	for _, e := range b.Entries {
+		if e.Time > math.MaxUint32 || e.MoofOffset > math.MaxUint32 {
+			b.Version = 1
+		}
+		if e.TrafNumber > maxTraf {
+			maxTraf = e.TrafNumber
+		}
+		if e.TrunNumber > maxTrun {
+			maxTrun = e.TrunNumber
+		}
+		if e.SampleNumber > maxSample {
+			maxSample = e.SampleNumber
+		}
+	}
+	b.LengthSizeOfTrafNum = lengthSizeMinusOne(maxTraf)
+	b.LengthSizeOfTrunNum = lengthSizeMinusOne(maxTrun)
+	b.LengthSizeOfSampleNum = lengthSizeMinusOne(maxSample)
+}
+
+// lengthSizeMinusOne returns the number of bytes minus one needed to write nr.
+func lengthSizeMinusOne(nr uint32) byte {
+	switch {
+	case nr < 1<<8:
+		return 0
+	case nr < 1<<16:
+		return 1
+	case nr < 1<<24:
+		return 2
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,272 @@
+package mp4
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/Eyevinn/mp4ff/sei"
+)
+
+// Timecode - SMPTE timecode HH:MM:SS:FF. DropFrame signals drop-frame counting as used for 29.97 and 59.94 Hz.
+type Timecode struct {
+	Hours     byte
+	Minutes   byte
+	Seconds   byte
+	Frames    byte
+	DropFrame bool
+}
+
+// String - timecode as HH:MM:SS:FF, or HH:MM:SS;FF for drop-frame
+func (tc Timecode) String() string {
+	sep := ":"
+	if tc.DropFrame {
+		sep = ";"
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%02d", tc.Hours, tc.Minutes, tc.Seconds, sep, tc.Frames)
+}
+
+// ParseTimecode - parse timecode of form HH:MM:SS:FF. A ';' or '.' before the frames signals drop-frame.
+func ParseTimecode(s string) (Timecode, error) {
+	var tc Timecode
+	if len(s) != 11 || s[2] != ':' || s[5] != ':' {
+		return tc, fmt.Errorf("timecode %q not of form HH:MM:SS:FF", s)
+	}
+	switch s[8] {
+	case ':':
+	case ';', '.':
+		tc.DropFrame = true
+	default:
+		return tc, fmt.Errorf("timecode %q: bad frame separator", s)
+	}
+	parts := []*byte{&tc.Hours, &tc.Minutes, &tc.Seconds, &tc.Frames}
+	for i, p := range parts {
+		hi, lo := s[3*i], s[3*i+1]
+		if hi < '0' || hi > '9' || lo < '0' || lo > '9' {
+			return tc, fmt.Errorf("timecode %q: bad digits", s)
+		}
+		*p = (hi-'0')*10 + lo - '0'
+	}
+	if tc.Minutes > 59 || tc.Seconds > 59 {
+		return tc, fmt.Errorf("timecode %q: minutes or seconds out of range", s)
+	}
+	return tc, nil
+}
+
+// nrDroppedFrames - number of frame numbers dropped each minute except every tenth minute.
+// Drop-frame is only defined for 30 and 60 frames per second (29.97 and 59.94 Hz).
+func nrDroppedFrames(fps byte, dropFrame bool) uint64 {
+	if !dropFrame || fps == 0 || fps%30 != 0 {
+		return 0
+	}
+	return uint64(fps / 15)
+}
+
+// FrameNumber - number of frames since 00:00:00:00 given the number of frames per second (rounded frame rate)
+func (tc Timecode) FrameNumber(fps byte) uint32 {
+	drop := nrDroppedFrames(fps, tc.DropFrame)
+	totalMinutes := 60*uint64(tc.Hours) + uint64(tc.Minutes)
+	frameNr := uint64(fps)*(60*totalMinutes+uint64(tc.Seconds)) + uint64(tc.Frames)
+	frameNr -= drop * (totalMinutes - totalMinutes/10)
+	return uint32(frameNr)
+}
+
+// TimecodeFromFrameNumber - timecode for frame number given the number of frames per second (rounded frame rate)
+func TimecodeFromFrameNumber(frameNr uint32, fps byte, dropFrame bool) Timecode {
+	tc := Timecode{DropFrame: dropFrame}
+	if fps == 0 {
+		return tc
+	}
+	n := uint64(frameNr)
+	drop := nrDroppedFrames(fps, dropFrame)
+	if drop > 0 {
+		framesPerMinute := uint64(fps)*60 - drop
+		framesPer10Minutes := uint64(fps)*600 - 9*drop
+		d := n / framesPer10Minutes
+		m := n % framesPer10Minutes
+		n += 9 * drop * d
+		if m > drop {
+			n += drop * ((m - drop) / framesPerMinute)
+		}
+	}
+	fpsU := uint64(fps)
+	tc.Frames = byte(n % fpsU)
+	tc.Seconds = byte(n / fpsU % 60)
+	tc.Minutes = byte(n / (fpsU * 60) % 60)
+	tc.Hours = byte(n / (fpsU * 3600))
+	return tc
+}
+
+// TimecodeFromClockTS - timecode from a clock timestamp in a TimeCode SEI message.
+// Counting type 4 signals drop-frame.
+func TimecodeFromClockTS(c sei.ClockTS) Timecode {
+	return Timecode{
+		Hours:     c.Hours,
+		Minutes:   c.Minutes,
+		Seconds:   c.Seconds,
+		Frames:    byte(c.NFrames),
+		DropFrame: c.CountingType == 4,
+	}
+}
+
+// ClockTS - clock timestamp with full timestamp for a TimeCode SEI message
+func (tc Timecode) ClockTS() sei.ClockTS {
+	c := sei.CreateClockTS()
+	c.ClockTimeStampFlag = true
+	c.FullTimeStampFlag = true
+	c.Hours = tc.Hours
+	c.Minutes = tc.Minutes
+	c.Seconds = tc.Seconds
+	c.NFrames = uint16(tc.Frames)
+	if tc.DropFrame {
+		c.CountingType = 4
+	}
+	return c
+}
+
+// EncodeSample - timecode sample data with the frame number of tc
+func (b *TmcdBox) EncodeSample(tc Timecode) []byte {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, b.FrameNumber(tc))
+	return data
+}
+
+// DecodeSample - timecode from timecode sample data
+func (b *TmcdBox) DecodeSample(data []byte) (Timecode, error) {
+	if len(data) < 4 {
+		return Timecode{}, fmt.Errorf("timecode sample size %d less than 4", len(data))
+	}
+	tc := b.Timecode(binary.BigEndian.Uint32(data))
+	if b.Flags&Tmcd24HourMax != 0 {
+		tc.Hours %= 24
+	}
+	return tc, nil
+}
+
+// AddTimecodeTrack - add a timecode track with tmcd sample entry, and a tmcd track reference from all video tracks.
+// numberOfFrames is the rounded frame rate, e.g. 30 for 29.97 Hz with timescale 30000 and frameDuration 1001.
+// The timecode samples are to be added to the media segments.
+func (s *InitSegment) AddTimecodeTrack(timescale, frameDuration uint32, numberOfFrames byte, dropFrame bool) *TrakBox {
+	trak := s.AddEmptyTrack(timescale, "timecode", "und")
+	trak.Mdia.Minf.Stbl.Stsd.AddChild(CreateTmcd(timescale, frameDuration, numberOfFrames, dropFrame))
+	addTimecodeReferences(s.Moov, trak.Tkhd.TrackID)
+	return trak
+}
+
+// AddTimecodeTrack - add a timecode track to a progressive file.
+// The track has one sample with the start timecode covering the movie duration.
+// The sample is appended to the mdat box, and chunk offsets are updated if the moov box grows in front of it.
+// The mdat data must be available, i.e. the file should not be decoded in lazy mode.
+func (f *File) AddTimecodeTrack(start Timecode, timescale, frameDuration uint32, numberOfFrames byte) (*TrakBox, error) {
+	if f.isFragmented || f.Moov == nil {
+		return nil, fmt.Errorf("not a progressive file")
+	}
+	if f.Mdat == nil || f.Mdat.IsLazy() || f.Mdat.SizeToEnd {
+		return nil, fmt.Errorf("mdat data not available")
+	}
+	if uint64(len(f.Mdat.Data))+4 > maxNormalPayloadSize && !f.Mdat.LargeSize {
+		return nil, fmt.Errorf("mdat too big for sample to be added")
+	}
+	moov := f.Moov
+	if moov.Mvhd.Timescale == 0 {
+		return nil, fmt.Errorf("mvhd timescale is zero")
+	}
+	dur := moov.Mvhd.Duration
+	mediaDur := dur * uint64(timescale) / uint64(moov.Mvhd.Timescale)
+	if mediaDur > math.MaxUint32 {
+		return nil, fmt.Errorf("movie duration too long for timecode sample")
+	}
+	oldMoovSize := moov.Size()
+
+	trackID := moov.Mvhd.NextTrackID
+	moov.Mvhd.NextTrackID = trackID + 1
+	trak := CreateEmptyTrak(trackID, timescale, "timecode", "und")
+	tmcd := CreateTmcd(timescale, frameDuration, numberOfFrames, start.DropFrame)
+	stbl := trak.Mdia.Minf.Stbl
+	stbl.Stsd.AddChild(tmcd)
+	trak.Tkhd.Duration = dur
+	if dur > math.MaxUint32 {
+		trak.Tkhd.Version = 1
+	}
+	trak.Mdia.Mdhd.Duration = mediaDur
+	stbl.Stts.SampleCount = []uint32{1}
+	stbl.Stts.SampleTimeDelta = []uint32{uint32(mediaDur)}
+	stbl.Stsz.SampleNumber = 1
+	stbl.Stsz.SampleUniformSize = 4
+	if err := stbl.Stsc.AddEntry(1, 1, 1); err != nil {
+		return nil, err
+	}
+	stbl.Stco.ChunkOffset = []uint32{0}
+	moov.AddChild(trak)
+	addTimecodeReferences(moov, trackID)
+
+	if err := f.moovResized(oldMoovSize); err != nil {
+		return nil, err
+	}
+	sampleOffset := f.Mdat.PayloadAbsoluteOffset() + f.Mdat.DataLength()
+	if sampleOffset > math.MaxUint32 {
+		return nil, fmt.Errorf("timecode sample offset %d too large for stco", sampleOffset)
+	}
+	stbl.Stco.ChunkOffset[0] = uint32(sampleOffset)
+	f.Mdat.AddSampleData(tmcd.EncodeSample(start))
+	return trak, nil
+}
+
+// StartTimecode - timecode of the first sample of the first timecode track
+func (f *File) StartTimecode(rs io.ReadSeeker) (Timecode, error) {
+	moov := f.Moov
+	if f.Init != nil {
+		moov = f.Init.Moov
+	}
+	if moov == nil {
+		return Timecode{}, fmt.Errorf("no moov box")
+	}
+	for _, trak := range moov.Traks {
+		tmcd := trak.Mdia.Minf.Stbl.Stsd.Tmcd
+		if tmcd == nil {
+			continue
+		}
+		it, err := f.NewSampleIterator(trak.Tkhd.TrackID, rs)
+		if err != nil {
+			return Timecode{}, err
+		}
+		if !it.Next() {
+			if err := it.Err(); err != nil {
+				return Timecode{}, err
+			}
+			return Timecode{}, fmt.Errorf("no sample in timecode track %d", trak.Tkhd.TrackID)
+		}
+		return tmcd.DecodeSample(it.Sample().Data)
+	}
+	return Timecode{}, fmt.Errorf("no timecode track")
+}
+
+// addTimecodeReferences - add tmcd track reference to timecodeTrackID from all video tracks
+func addTimecodeReferences(moov *MoovBox, timecodeTrackID uint32) {
+	for _, trak := range moov.Traks {
+		if trak.Mdia.Hdlr == nil || trak.Mdia.Hdlr.HandlerType != "vide" {
+			continue
+		}
+		var tref *TrefBox
+		for _, c := range trak.Children {
+			if t, ok := c.(*TrefBox); ok {
+				tref = t
+				break
+			}
+		}
+		if tref == nil {
+			// Insert tref directly after tkhd
+			tref = &TrefBox{}
+			children := make([]Box, 0, len(trak.Children)+1)
+			for _, c := range trak.Children {
+				children = append(children, c)
+				if c == trak.Tkhd {
+					children = append(children, tref)
+				}
+			}
+			trak.Children = children
+		}
+		tref.AddChild(&TrefTypeBox{Name: "tmcd", TrackIDs: []uint32{timecodeTrackID}})
+	}
+}
@@ -0,0 +1,157 @@
+package mp4_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+	"github.com/Eyevinn/mp4ff/sei"
+)
+
+func TestTimecodeFrameNumber(t *testing.T) {
+	testCases := []struct {
+		tc      string
+		fps     byte
+		frameNr uint32
+	}{
+		{"00:00:00:00", 25, 0},
+		{"01:00:00:00", 25, 90000},
+		{"00:00:59:29", 30, 1799},
+		{"00:00:59;29", 30, 1799},
+		{"00:01:00;02", 30, 1800},
+		{"00:09:59;29", 30, 17981},
+		{"00:10:00;00", 30, 17982},
+		{"00:11:00;02", 30, 19782},
+		{"01:00:00;00", 30, 107892},
+		{"00:01:00;04", 60, 3600},
+		{"23:59:59;29", 30, 2589407},
+	}
+	for _, c := range testCases {
+		tc, err := mp4.ParseTimecode(c.tc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.String() != c.tc {
+			t.Errorf("got %s instead of %s", tc, c.tc)
+		}
+		if got := tc.FrameNumber(c.fps); got != c.frameNr {
+			t.Errorf("%s: got frame number %d instead of %d", c.tc, got, c.frameNr)
+		}
+		if got := mp4.TimecodeFromFrameNumber(c.frameNr, c.fps, tc.DropFrame); got != tc {
+			t.Errorf("frame number %d: got %s instead of %s", c.frameNr, got, tc)
+		}
+	}
+	for _, bad := range []string{"00:00:00", "00:00:00-00", "00:61:00:00", "0a:00:00:00"} {
+		if _, err := mp4.ParseTimecode(bad); err == nil {
+			t.Errorf("no error for %q", bad)
+		}
+	}
+}
+
+func TestTimecodeSEI(t *testing.T) {
+	tc := mp4.Timecode{Hours: 10, Minutes: 1, Seconds: 0, Frames: 2, DropFrame: true}
+	seiTC := sei.TimeCodeSEI{Clocks: []sei.ClockTS{tc.ClockTS()}}
+	sd := sei.NewSEIData(sei.SEITimeCodeType, seiTC.Payload())
+	msg, err := sei.DecodeTimeCodeSEI(sd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := mp4.TimecodeFromClockTS(msg.(*sei.TimeCodeSEI).Clocks[0])
+	if got != tc {
+		t.Errorf("got %s instead of %s", got, tc)
+	}
+}
+
+func TestInitTimecodeTrack(t *testing.T) {
+	init := mp4.CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	trak := init.AddTimecodeTrack(30000, 1001, 30, true)
+	if trak.Tkhd.TrackID != 2 || trak.Mdia.Hdlr.HandlerType != "tmcd" || trak.Mdia.Minf.Gmhd == nil {
+		t.Error("bad timecode track")
+	}
+	buf := bytes.Buffer{}
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := mp4.DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moov := f.Init.Moov
+	tmcd := moov.Traks[1].Mdia.Minf.Stbl.Stsd.Tmcd
+	if tmcd == nil || !tmcd.DropFrame() || tmcd.NumberOfFrames != 30 {
+		t.Fatalf("bad decoded tmcd sample entry %+v", tmcd)
+	}
+	if moov.Traks[1].Mdia.Minf.Gmhd.Tmcd.Tcmi == nil {
+		t.Error("no tcmi in decoded gmhd")
+	}
+	videoTrak := moov.Traks[0]
+	tref, ok := videoTrak.Children[1].(*mp4.TrefBox)
+	if !ok {
+		t.Fatalf("no tref after tkhd in video track")
+	}
+	if ref := tref.Children[0].(*mp4.TrefTypeBox); ref.Type() != "tmcd" || ref.TrackIDs[0] != 2 {
+		t.Errorf("bad tmcd track reference")
+	}
+	tc := mp4.Timecode{Hours: 1, Frames: 3, DropFrame: true}
+	decTC, err := tmcd.DecodeSample(tmcd.EncodeSample(tc))
+	if err != nil || decTC != tc {
+		t.Errorf("got %s instead of %s", decTC, tc)
+	}
+}
+
+func TestProgressiveTimecodeTrack(t *testing.T) {
+	f, err := mp4.ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.StartTimecode(nil); err == nil {
+		t.Error("expected error for file without timecode track")
+	}
+	videoTrackID := f.Moov.Traks[0].Tkhd.TrackID
+	it, err := f.NewSampleIterator(videoTrackID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var origData [][]byte
+	for it.Next() {
+		origData = append(origData, it.Sample().Data)
+	}
+	start := mp4.Timecode{Hours: 10, Minutes: 9, Seconds: 59, Frames: 29, DropFrame: true}
+	trak, err := f.AddTimecodeTrack(start, 30000, 1001, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trak.Mdia.Mdhd.Duration == 0 {
+		t.Error("zero timecode track duration")
+	}
+	buf := bytes.Buffer{}
+	if err := f.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dec, err := mp4.DecodeFile(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := dec.StartTimecode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != start {
+		t.Errorf("got start timecode %s instead of %s", got, start)
+	}
+	it, err = dec.NewSampleIterator(videoTrackID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nr := 0
+	for it.Next() {
+		if !bytes.Equal(it.Sample().Data, origData[nr]) {
+			t.Fatalf("video sample %d data differs after adding timecode track", nr+1)
+		}
+		nr++
+	}
+	if nr != len(origData) {
+		t.Errorf("got %d video samples instead of %d", nr, len(origData))
+	}
+}
@@ -0,0 +1,146 @@
+package mp4
+
+// PresentationTimeline maps media times of a track to its presentation timeline
+// as given by the edit list (elst) of the track.
+//
+// Empty edits (mediaTime == -1) shift the media later in presentation, a positive mediaTime
+// skips the start of the media (e.g. AAC priming or B-frame delay), and media rates other than 1
+// change the speed. A rate of 0 gives a dwell edit, where one media time is shown for the edit duration.
+// Without an edit list, presentation time is equal to composition time.
+// All times are in the media timescale of the track.
+type PresentationTimeline struct {
+	edits []presentationEdit
+}
+
+// presentationEdit is an edit list entry with all times in the media timescale.
+type presentationEdit struct {
+	presStart uint64
+	dur       uint64 // 0 for the last edit means until the end of the media
+	mediaTime int64  // -1 for empty edit
+	rate      int64  // 16.16 fixed point
+}
+
+// NewPresentationTimeline creates a timeline from elst entries.
+// movieTimescale (from mvhd) is the timescale of the edit durations.
+// If elst is nil or has no entries, the timeline is the identity mapping.
+func NewPresentationTimeline(elst *ElstBox, movieTimescale, mediaTimescale uint32) *PresentationTimeline {
+	pt := &PresentationTimeline{}
+	if elst == nil {
+		return pt
+	}
+	var presStart uint64
+	for i, e := range elst.Entries {
+		dur := e.SegmentDuration
+		if movieTimescale != 0 && movieTimescale != mediaTimescale {
+			dur = e.SegmentDuration * uint64(mediaTimescale) / uint64(movieTimescale)
+		}
+		if dur == 0 && i < len(elst.Entries)-1 {
+			continue // Zero duration edits are only meaningful as last entry
+		}
+		pt.edits = append(pt.edits, presentationEdit{
+			presStart: presStart,
+			dur:       dur,
+			mediaTime: e.MediaTime,
+			rate:      int64(e.MediaRateInteger)<<16 | int64(uint16(e.MediaRateFraction)),
+		})
+		presStart += dur
+	}
+	return pt
+}
+
+// PresentationTimeline returns the presentation timeline of the track given by its edit list.
+// movieTimescale is the timescale of the mvhd box.
+func (t *TrakBox) PresentationTimeline(movieTimescale uint32) *PresentationTimeline {
+	var elst *ElstBox
+	if t.Edts != nil && len(t.Edts.Elst) > 0 {
+		elst = t.Edts.Elst[0]
+	}
+	return NewPresentationTimeline(elst, movieTimescale, t.Mdia.Mdhd.Timescale)
+}
+
+// HasEdits returns true if the timeline is given by an edit list.
+func (pt *PresentationTimeline) HasEdits() bool {
+	return len(pt.edits) > 0
+}
+
+// StartOffset returns the offset to subtract from a media time to get its presentation time,
+// for the first non-empty edit. It is positive if the start of the media is not presented,
+// as is the case for AAC priming and B-frame delays, and negative if there is an initial empty edit.
+func (pt *PresentationTimeline) StartOffset() int64 {
+	for _, e := range pt.edits {
+		if e.mediaTime >= 0 {
+			return e.mediaTime - int64(e.presStart)
+		}
+	}
+	return 0
+}
+
+// MediaToPresentation maps a media (composition) time to presentation time.
+// ok is false if the media time is not presented. If a media time is presented
+// more than once, the first presentation time is returned.
+func (pt *PresentationTimeline) MediaToPresentation(mediaTime int64) (presTime int64, ok bool) {
+	if len(pt.edits) == 0 {
+		return mediaTime, true
+	}
+	for i, e := range pt.edits {
+		if e.mediaTime < 0 || mediaTime < e.mediaTime {
+			continue
+		}
+		if e.rate == 0 {
+			if mediaTime == e.mediaTime {
+				return int64(e.presStart), true
+			}
+			continue
+		}
+		presDelta := (mediaTime - e.mediaTime) << 16 / e.rate
+		isOpenEnded := e.dur == 0 && i == len(pt.edits)-1
+		if isOpenEnded || presDelta < int64(e.dur) {
+			return int64(e.presStart) + presDelta, true
+		}
+	}
+	return 0, false
+}
+
+// PresentationTime returns the presentation time of a sample given its decode time and composition time offset.
+func (pt *PresentationTimeline) PresentationTime(decodeTime uint64, cto int32) (presTime int64, ok bool) {
+	return pt.MediaToPresentation(int64(decodeTime) + int64(cto))
+}
+
+// PresentationToMedia maps a presentation time to the media time presented then.
+// ok is false if the time is in an empty edit or after the end of the edit list.
+// The end time of the last edit is mapped to the end of the media in that edit.
+func (pt *PresentationTimeline) PresentationToMedia(presTime int64) (mediaTime int64, ok bool) {
+	if len(pt.edits) == 0 {
+		return presTime, true
+	}
+	if presTime < 0 {
+		return 0, false
+	}
+	for i, e := range pt.edits {
+		isLast := i == len(pt.edits)-1
+		end := e.presStart + e.dur
+		switch {
+		case uint64(presTime) < e.presStart:
+			return 0, false
+		case uint64(presTime) < end, isLast && (e.dur == 0 || uint64(presTime) == end):
+			if e.mediaTime < 0 {
+				return 0, false
+			}
+			return e.mediaTime + (presTime-int64(e.presStart))*e.rate>>16, true
+		}
+	}
+	return 0, false
+}
+
+// Duration returns the presentation duration given by the edit list in the media timescale.
+// ok is false if there is no edit list, or if the last edit extends to the end of the media.
+func (pt *PresentationTimeline) Duration() (dur uint64, ok bool) {
+	if len(pt.edits) == 0 {
+		return 0, false
+	}
+	last := pt.edits[len(pt.edits)-1]
+	if last.dur == 0 {
+		return 0, false
+	}
+	return last.presStart + last.dur, true
+}
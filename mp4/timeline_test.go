@@ -0,0 +1,133 @@
+package mp4_test
+
+import (
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestPresentationTimeline(t *testing.T) {
+	type mapping struct {
+		mediaTime int64
+		presTime  int64
+		ok        bool
+	}
+	testCases := []struct {
+		desc           string
+		entries        []mp4.ElstEntry
+		movieTimescale uint32
+		wantedOffset   int64
+		mappings       []mapping
+		wantedDur      uint64
+		wantedDurOK    bool
+	}{
+		{
+			desc:         "no edit list",
+			wantedOffset: 0,
+			mappings:     []mapping{{0, 0, true}, {1000, 1000, true}},
+		},
+		{
+			desc:           "aac priming",
+			entries:        []mp4.ElstEntry{{SegmentDuration: 1000, MediaTime: 1024, MediaRateInteger: 1}},
+			movieTimescale: 1000,
+			wantedOffset:   1024,
+			mappings:       []mapping{{0, 0, false}, {1024, 0, true}, {49024, 48000, false}, {48000, 46976, true}},
+			wantedDur:      48000,
+			wantedDurOK:    true,
+		},
+		{
+			desc: "initial empty edit",
+			entries: []mp4.ElstEntry{
+				{SegmentDuration: 500, MediaTime: -1, MediaRateInteger: 1},
+				{SegmentDuration: 1000, MediaTime: 0, MediaRateInteger: 1},
+			},
+			movieTimescale: 1000,
+			wantedOffset:   -24000,
+			mappings:       []mapping{{0, 24000, true}, {47999, 71999, true}, {48000, 0, false}},
+			wantedDur:      72000,
+			wantedDurOK:    true,
+		},
+		{
+			desc:           "open-ended last edit",
+			entries:        []mp4.ElstEntry{{SegmentDuration: 0, MediaTime: 2048, MediaRateInteger: 1}},
+			movieTimescale: 1000,
+			wantedOffset:   2048,
+			mappings:       []mapping{{2047, 0, false}, {1002048, 1000000, true}},
+			wantedDurOK:    false,
+		},
+		{
+			desc: "dwell and double speed",
+			entries: []mp4.ElstEntry{
+				{SegmentDuration: 1000, MediaTime: 0, MediaRateInteger: 0},
+				{SegmentDuration: 1000, MediaTime: 0, MediaRateInteger: 2},
+			},
+			movieTimescale: 1000,
+			wantedOffset:   0,
+			mappings:       []mapping{{0, 0, true}, {48000, 72000, true}, {96000, 0, false}},
+			wantedDur:      96000,
+			wantedDurOK:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var elst *mp4.ElstBox
+			if tc.entries != nil {
+				elst = &mp4.ElstBox{Entries: tc.entries}
+			}
+			pt := mp4.NewPresentationTimeline(elst, tc.movieTimescale, 48000)
+			if pt.HasEdits() != (tc.entries != nil) {
+				t.Errorf("HasEdits returned %t", pt.HasEdits())
+			}
+			if got := pt.StartOffset(); got != tc.wantedOffset {
+				t.Errorf("got start offset %d instead of %d", got, tc.wantedOffset)
+			}
+			for _, m := range tc.mappings {
+				presTime, ok := pt.MediaToPresentation(m.mediaTime)
+				if ok != m.ok || (ok && presTime != m.presTime) {
+					t.Errorf("media time %d: got %d, %t instead of %d, %t", m.mediaTime, presTime, ok, m.presTime, m.ok)
+				}
+				if !m.ok || tc.desc == "dwell and double speed" {
+					continue
+				}
+				mediaTime, ok := pt.PresentationToMedia(m.presTime)
+				if !ok || mediaTime != m.mediaTime {
+					t.Errorf("presentation time %d: got %d, %t instead of %d", m.presTime, mediaTime, ok, m.mediaTime)
+				}
+			}
+			dur, ok := pt.Duration()
+			if ok != tc.wantedDurOK || dur != tc.wantedDur {
+				t.Errorf("got duration %d, %t instead of %d, %t", dur, ok, tc.wantedDur, tc.wantedDurOK)
+			}
+		})
+	}
+}
+
+func TestTrakPresentationTimeline(t *testing.T) {
+	f, err := mp4.ReadMP4File("testdata/bbb_prog_10s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trak := range f.Moov.Traks {
+		pt := trak.PresentationTimeline(f.Moov.Mvhd.Timescale)
+		if pt.StartOffset() != 1024 {
+			t.Errorf("track %d: got start offset %d instead of 1024", trak.Tkhd.TrackID, pt.StartOffset())
+		}
+		// The first presented sample should start at 0. AAC priming samples are not presented.
+		stbl := trak.Mdia.Minf.Stbl
+		for nr := uint32(1); nr <= stbl.Stsz.SampleNumber; nr++ {
+			decTime, _ := stbl.Stts.GetDecodeTime(nr)
+			var cto int32
+			if stbl.Ctts != nil {
+				cto = stbl.Ctts.GetCompositionTimeOffset(nr)
+			}
+			presTime, ok := pt.PresentationTime(decTime, cto)
+			if !ok {
+				continue
+			}
+			if presTime != 0 {
+				t.Errorf("track %d: first presented sample %d at %d instead of 0", trak.Tkhd.TrackID, nr, presTime)
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// Boxes for QuickTime timecode tracks according to the QuickTime File Format Specification.
+// The box type tmcd is used for the TimecodeSampleEntry in stsd, for the timecode media
+// information container in gmhd, and for the track reference to a timecode track in tref.
+
+// Flags of TimecodeSampleEntry
+const (
+	TmcdDropFrame   = 0x0001
+	Tmcd24HourMax   = 0x0002
+	TmcdNegTimesOK  = 0x0004
+	TmcdCounter     = 0x0008
+	tmcdEntryFields = 18 // Bytes after the 8-byte SampleEntry header before the child boxes
+)
+
+// DecodeTmcd - decode tmcd box. The variant is detected from the content.
+func DecodeTmcd(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeTmcdSR(hdr, startPos, sr)
+}
+
+// DecodeTmcdSR - decode tmcd box. The variant is detected from the content.
+func DecodeTmcdSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	payloadLen := hdr.payloadLen()
+	if sr.NrRemainingBytes() < payloadLen {
+		return nil, fmt.Errorf("tmcd: not enough data")
+	}
+	start := sr.GetPos()
+	head := sr.ReadBytes(min(payloadLen, 8))
+	sr.SetPos(start)
+	switch {
+	case payloadLen >= 8+tmcdEntryFields && isZeroBytes(head[:6]):
+		return decodeTmcdSampleEntrySR(hdr, startPos, sr)
+	case payloadLen >= 8 && string(head[4:8]) == "tcmi":
+		return decodeTmcdInfoSR(hdr, startPos, sr)
+	default:
+		return DecodeTrefTypeSR(hdr, startPos, sr)
+	}
+}
+
+func isZeroBytes(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+////////////////////////////// tmcd sample entry //////////////////////////////
+
+// TmcdBox - TimecodeSampleEntry (tmcd) in stsd
+//
+// Each sample in the track is a 32-bit frame number for the timecode at the start of the sample.
+type TmcdBox struct {
+	DataReferenceIndex uint16
+	Flags              uint32
+	Timescale          uint32
+	FrameDuration      uint32
+	// NumberOfFrames is the number of frames per second in the timecode, e.g. 30 for 29.97 Hz
+	NumberOfFrames byte
+	Children       []Box
+}
+
+// CreateTmcd - create a TimecodeSampleEntry. numberOfFrames is the rounded frame rate.
+func CreateTmcd(timescale, frameDuration uint32, numberOfFrames byte, dropFrame bool) *TmcdBox {
+	b := &TmcdBox{
+		DataReferenceIndex: 1,
+		Timescale:          timescale,
+		FrameDuration:      frameDuration,
+		NumberOfFrames:     numberOfFrames,
+	}
+	if dropFrame {
+		b.Flags |= TmcdDropFrame
+	}
+	return b
+}
+
+// AddChild - add a child box
+func (b *TmcdBox) AddChild(child Box) {
+	b.Children = append(b.Children, child)
+}
+
+func decodeTmcdSampleEntrySR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := TmcdBox{}
+	initPos := sr.GetPos()
+	sr.SkipBytes(6) // Skip 6 reserved bytes
+	b.DataReferenceIndex = sr.ReadUint16()
+	sr.SkipBytes(4) // Reserved
+	b.Flags = sr.ReadUint32()
+	b.Timescale = sr.ReadUint32()
+	b.FrameDuration = sr.ReadUint32()
+	b.NumberOfFrames = sr.ReadUint8()
+	sr.SkipBytes(1) // Reserved
+	pos := startPos + uint64(hdr.Hdrlen+sr.GetPos()-initPos)
+	endPos := startPos + uint64(hdr.Hdrlen+hdr.payloadLen())
+	for pos < endPos {
+		box, err := decodeChildBoxSR(pos, sr)
+		if err != nil {
+			return nil, err
+		}
+		b.AddChild(box)
+		pos += box.Size()
+	}
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *TmcdBox) Type() string {
+	return "tmcd"
+}
+
+// Size - return calculated size
+func (b *TmcdBox) Size() uint64 {
+	return uint64(8+tmcdEntryFields) + containerSize(b.Children)
+}
+
+// GetChildren - list of child boxes
+func (b *TmcdBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write box to w
+func (b *TmcdBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - write box to sw
+func (b *TmcdBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteZeroBytes(6)
+	sw.WriteUint16(b.DataReferenceIndex)
+	sw.WriteUint32(0)
+	sw.WriteUint32(b.Flags)
+	sw.WriteUint32(b.Timescale)
+	sw.WriteUint32(b.FrameDuration)
+	sw.WriteUint8(b.NumberOfFrames)
+	sw.WriteUint8(0)
+	for _, c := range b.Children {
+		err = c.EncodeSW(sw)
+		if err != nil {
+			return err
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *TmcdBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - dataReferenceIndex: %d", b.DataReferenceIndex)
+	bd.write(" - flags: %08x (dropFrame=%t)", b.Flags, b.DropFrame())
+	bd.write(" - timescale: %d", b.Timescale)
+	bd.write(" - frameDuration: %d", b.FrameDuration)
+	bd.write(" - numberOfFrames: %d", b.NumberOfFrames)
+	if bd.err != nil {
+		return bd.err
+	}
+	for _, c := range b.Children {
+		err := c.Info(w, specificBoxLevels, indent+indentStep, indentStep)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropFrame - true if the drop-frame flag is set
+func (b *TmcdBox) DropFrame() bool {
+	return b.Flags&TmcdDropFrame != 0
+}
+
+// Timecode - timecode for a frame number such as the value of a timecode sample
+func (b *TmcdBox) Timecode(frameNr uint32) Timecode {
+	return TimecodeFromFrameNumber(frameNr, b.NumberOfFrames, b.DropFrame())
+}
+
+// FrameNumber - frame number for a timecode, to be used as timecode sample value
+func (b *TmcdBox) FrameNumber(tc Timecode) uint32 {
+	return tc.FrameNumber(b.NumberOfFrames)
+}
+
+////////////////////////////// tmcd in gmhd //////////////////////////////
+
+// TmcdInfoBox - Timecode media information container (tmcd) in gmhd with a tcmi box
+type TmcdInfoBox struct {
+	Tcmi     *TcmiBox
+	Children []Box
+}
+
+// AddChild - add a child box
+func (b *TmcdInfoBox) AddChild(child Box) {
+	if tcmi, ok := child.(*TcmiBox); ok {
+		b.Tcmi = tcmi
+	}
+	b.Children = append(b.Children, child)
+}
+
+func decodeTmcdInfoSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.Size, sr)
+	if err != nil {
+		return nil, err
+	}
+	b := &TmcdInfoBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// Type - return box type
+func (b *TmcdInfoBox) Type() string {
+	return "tmcd"
+}
+
+// Size - return calculated size
+func (b *TmcdInfoBox) Size() uint64 {
+	return containerSize(b.Children)
+}
+
+// GetChildren - list of child boxes
+func (b *TmcdInfoBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write tmcd container to w
+func (b *TmcdInfoBox) Encode(w io.Writer) error {
+	return EncodeContainer(b, w)
+}
+
+// EncodeSW - write tmcd container via sw
+func (b *TmcdInfoBox) EncodeSW(sw bits.SliceWriter) error {
+	return EncodeContainerSW(b, sw)
+}
+
+// Info - write box-specific information
+func (b *TmcdInfoBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
+}
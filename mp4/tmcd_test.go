@@ -0,0 +1,32 @@
+package mp4_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestTmcd(t *testing.T) {
+	boxDiffAfterEncodeAndDecode(t, mp4.CreateTmcd(30000, 1001, 30, true))
+	boxDiffAfterEncodeAndDecode(t, mp4.CreateTcmi())
+	boxDiffAfterEncodeAndDecode(t, mp4.CreateGmin())
+	boxDiffAfterEncodeAndDecode(t, mp4.CreateTimecodeGmhd())
+	boxDiffAfterEncodeAndDecode(t, &mp4.TrefTypeBox{Name: "tmcd", TrackIDs: []uint32{2}})
+
+	gmhd := mp4.CreateTimecodeGmhd()
+	if gmhd.Gmin == nil || gmhd.Tmcd == nil || gmhd.Tmcd.Tcmi == nil {
+		t.Error("missing child box pointers in gmhd")
+	}
+	buf := bytes.Buffer{}
+	if err := gmhd.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	box, err := mp4.DecodeBox(0, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec := box.(*mp4.GmhdBox); dec.Tmcd == nil || dec.Tmcd.Tcmi.FontName != "Lucida Grande" {
+		t.Error("tmcd in gmhd not decoded as TmcdInfoBox")
+	}
+}
@@ -94,26 +94,35 @@ func (t *TrafBox) ParseReadSenc(defaultIVSize byte, moofStartPos uint64) error {
 
 		}
 	}
+	perSampleIVSize, err := t.sencPerSampleIVSize(defaultIVSize)
+	if err != nil {
+		return err
+	}
+	err = senc.ParseReadBox(perSampleIVSize, t.Saiz)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sencPerSampleIVSize returns the per-sample IV size from a seig sample group, or defaultIVSize if there is none.
+func (t *TrafBox) sencPerSampleIVSize(defaultIVSize byte) (byte, error) {
 	perSampleIVSize := defaultIVSize
 	sbgp, sgpd := t.Sbgp, t.Sgpd
 	if sbgp != nil && sbgp.GroupingType == "seig" && sgpd != nil && sgpd.GroupingType == "seig" {
 		nrSbgpEntries := len(sbgp.SampleCounts)
 		if nrSbgpEntries != 1 {
-			return fmt.Errorf("sbgp entries = %d, only 1 supported for now", nrSbgpEntries)
+			return 0, fmt.Errorf("sbgp entries = %d, only 1 supported for now", nrSbgpEntries)
 		}
 		sgpdEntryNr := sbgp.GroupDescriptionIndices[0]
 		if sgpdEntryNr != sbgpInsideOffset+1 {
-			return fmt.Errorf("sgpd entry number must be first inside = 65536 + 1")
+			return 0, fmt.Errorf("sgpd entry number must be first inside = 65536 + 1")
 		}
 		sgpdEntry := sgpd.SampleGroupEntries[sgpdEntryNr-sbgpInsideOffset-1]
 		seigEntry := sgpdEntry.(*SeigSampleGroupEntry)
 		perSampleIVSize = seigEntry.PerSampleIVSize
 	}
-	err := senc.ParseReadBox(perSampleIVSize, t.Saiz)
-	if err != nil {
-		return err
-	}
-	return nil
+	return perSampleIVSize, nil
 }
 
 // AddChild - add child box
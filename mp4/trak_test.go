@@ -42,3 +42,29 @@ func TestTrakSampleFunctions(t *testing.T) {
 		t.Fatalf("expected 1 range, got %d", len(ranges))
 	}
 }
+
+func TestTrakGetSampleDataInterval(t *testing.T) {
+	f, err := os.Open("testdata/bbb_prog_10s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	mf, err := mp4.DecodeFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trak := mf.Moov.Traks[0]
+	samples, err := trak.GetSampleData(3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 3 {
+		t.Fatalf("expected 3 samples, got %d", len(samples))
+	}
+	for i, s := range samples {
+		nr := uint32(3 + i)
+		if size := trak.Mdia.Minf.Stbl.Stsz.GetSampleSize(int(nr)); s.Size != size {
+			t.Errorf("sample %d: size %d instead of %d", nr, s.Size, size)
+		}
+	}
+}
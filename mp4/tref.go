@@ -75,7 +75,7 @@ func (b *TrefBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 
 // TrefTypeBox - TrackReferenceTypeBox - ISO/IEC 14496-12 Ed. 9 Sec. 8.3
 // Name can be one of hint, cdsc, font, hind, vdep, vplx, subt (ISO/IEC 14496-12)
-// dpnd, ipir, mpod, sync (ISO/IEC 14496-14)
+// dpnd, ipir, mpod, sync (ISO/IEC 14496-14), tmcd (QuickTime)
 type TrefTypeBox struct {
 	Name     string
 	TrackIDs []uint32
@@ -52,6 +52,11 @@ func DecodeTrepSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	return &b, nil
 }
 
+// GetChildren - list of child boxes
+func (b *TrepBox) GetChildren() []Box {
+	return b.Children
+}
+
 // Type - box-specific type
 func (b *TrepBox) Type() string {
 	return "trep"
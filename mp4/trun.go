@@ -151,6 +151,20 @@ func CreateTrun(writeOrderNr uint32) *TrunBox {
 // AddSampleDefaultValues - add values from tfhd and trex boxes if needed
 // Return total duration
 func (t *TrunBox) AddSampleDefaultValues(tfhd *TfhdBox, trex *TrexBox) (totalDur uint64) {
+	return t.fillSampleDefaults(t.Samples, tfhd, trex)
+}
+
+// samplesWithDefaults returns a copy of the samples with missing values set from tfhd and trex defaults.
+// In contrast to AddSampleDefaultValues, the trun is not changed.
+func (t *TrunBox) samplesWithDefaults(tfhd *TfhdBox, trex *TrexBox) []Sample {
+	samples := make([]Sample, len(t.Samples))
+	copy(samples, t.Samples)
+	t.fillSampleDefaults(samples, tfhd, trex)
+	return samples
+}
+
+// fillSampleDefaults sets the values not present in the trun from tfhd and trex defaults.
+func (t *TrunBox) fillSampleDefaults(samples []Sample, tfhd *TfhdBox, trex *TrexBox) (totalDur uint64) {
 
 	var defaultSampleDuration uint32
 	var defaultSampleSize uint32
@@ -171,19 +185,18 @@ func (t *TrunBox) AddSampleDefaultValues(tfhd *TfhdBox, trex *TrexBox) (totalDur
 	} else if trex != nil {
 		defaultSampleFlags = trex.DefaultSampleFlags
 	}
-	var i uint32
 	totalDur = 0
-	for i = 0; i < t.SampleCount(); i++ {
+	for i := range samples {
 		if !t.HasSampleDuration() {
-			t.Samples[i].Dur = defaultSampleDuration
+			samples[i].Dur = defaultSampleDuration
 		}
-		totalDur += uint64(t.Samples[i].Dur)
+		totalDur += uint64(samples[i].Dur)
 		if !t.HasSampleSize() {
-			t.Samples[i].Size = defaultSampleSize
+			samples[i].Size = defaultSampleSize
 		}
 		if !t.HasSampleFlags() {
 			if i > 0 || !t.HasFirstSampleFlags() {
-				t.Samples[i].Flags = defaultSampleFlags
+				samples[i].Flags = defaultSampleFlags
 			}
 		}
 	}
@@ -517,3 +530,15 @@ func (t *TrunBox) SizeOfData() (totalSize uint64) {
 	}
 	return totalSize
 }
+
+// addJSONFields - add first sample flags
+func (t *TrunBox) addJSONFields(fields map[string]interface{}) {
+	fields["FirstSampleFlags"] = uint64(t.firstSampleFlags)
+}
+
+// setJSONFields - set first sample flags without changing the flags of the box
+func (t *TrunBox) setJSONFields(fields map[string]interface{}) error {
+	flags, _, err := popUint64(fields, "FirstSampleFlags")
+	t.firstSampleFlags = uint32(flags)
+	return err
+}
@@ -0,0 +1,331 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// Boxes needed for 3GPP timed text according to 3GPP TS 26.245
+
+////////////////////////////// tx3g //////////////////////////////
+
+// Display flags of tx3g sample entry
+const (
+	Tx3gScrollIn            = 0x00000020
+	Tx3gScrollOut           = 0x00000040
+	Tx3gScrollDirectionMask = 0x00000180
+	Tx3gContinuousKaraoke   = 0x00000800
+	Tx3gWriteTextVertical   = 0x00020000
+	Tx3gFillTextRegion      = 0x00040000
+)
+
+// Face style flags of StyleRecord
+const (
+	Tx3gFaceBold      = 0x01
+	Tx3gFaceItalic    = 0x02
+	Tx3gFaceUnderline = 0x04
+)
+
+// BoxRecord - text box position in pixels relative to the track region
+type BoxRecord struct {
+	Top    int16
+	Left   int16
+	Bottom int16
+	Right  int16
+}
+
+const boxRecordSize = 8
+
+func decodeBoxRecord(sr bits.SliceReader) BoxRecord {
+	return BoxRecord{
+		Top:    sr.ReadInt16(),
+		Left:   sr.ReadInt16(),
+		Bottom: sr.ReadInt16(),
+		Right:  sr.ReadInt16(),
+	}
+}
+
+func (r BoxRecord) encode(sw bits.SliceWriter) {
+	sw.WriteInt16(r.Top)
+	sw.WriteInt16(r.Left)
+	sw.WriteInt16(r.Bottom)
+	sw.WriteInt16(r.Right)
+}
+
+// StyleRecord - text style for characters [StartChar, EndChar). Colors are RGBA.
+type StyleRecord struct {
+	StartChar     uint16
+	EndChar       uint16
+	FontID        uint16
+	FaceStyle     byte
+	FontSize      byte
+	TextColorRGBA uint32
+}
+
+const styleRecordSize = 12
+
+func decodeStyleRecord(sr bits.SliceReader) StyleRecord {
+	return StyleRecord{
+		StartChar:     sr.ReadUint16(),
+		EndChar:       sr.ReadUint16(),
+		FontID:        sr.ReadUint16(),
+		FaceStyle:     sr.ReadUint8(),
+		FontSize:      sr.ReadUint8(),
+		TextColorRGBA: sr.ReadUint32(),
+	}
+}
+
+func (r StyleRecord) encode(sw bits.SliceWriter) {
+	sw.WriteUint16(r.StartChar)
+	sw.WriteUint16(r.EndChar)
+	sw.WriteUint16(r.FontID)
+	sw.WriteUint8(r.FaceStyle)
+	sw.WriteUint8(r.FontSize)
+	sw.WriteUint32(r.TextColorRGBA)
+}
+
+func (r StyleRecord) String() string {
+	return fmt.Sprintf("chars=[%d,%d) fontID=%d faceStyle=%d fontSize=%d color=%08x",
+		r.StartChar, r.EndChar, r.FontID, r.FaceStyle, r.FontSize, r.TextColorRGBA)
+}
+
+// Tx3gBox - TextSampleEntry (tx3g)
+// Extends SampleEntry
+type Tx3gBox struct {
+	DataReferenceIndex      uint16
+	DisplayFlags            uint32
+	HorizontalJustification int8
+	VerticalJustification   int8
+	BackgroundColorRGBA     uint32
+	DefaultTextBox          BoxRecord
+	DefaultStyle            StyleRecord
+	Ftab                    *FtabBox
+	Btrt                    *BtrtBox
+	Children                []Box
+}
+
+// NewTx3gBox - Create new empty tx3g box
+func NewTx3gBox() *Tx3gBox {
+	return &Tx3gBox{DataReferenceIndex: 1}
+}
+
+// AddChild - add a child box
+func (b *Tx3gBox) AddChild(child Box) {
+	switch box := child.(type) {
+	case *FtabBox:
+		b.Ftab = box
+	case *BtrtBox:
+		b.Btrt = box
+	default:
+		// Other box
+	}
+	b.Children = append(b.Children, child)
+}
+
+const nrTx3gBytesBeforeChildren = 8 + 8 + 4 + 2 + 4 + boxRecordSize + styleRecordSize
+
+// DecodeTx3g - Decode TextSampleEntry (tx3g)
+func DecodeTx3g(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeTx3gSR(hdr, startPos, sr)
+}
+
+// DecodeTx3gSR - Decode TextSampleEntry (tx3g)
+func DecodeTx3gSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := Tx3gBox{}
+	// 14496-12 8.5.2.2 Sample entry (8 bytes)
+	sr.SkipBytes(6) // Skip 6 reserved bytes
+	b.DataReferenceIndex = sr.ReadUint16()
+	b.DisplayFlags = sr.ReadUint32()
+	b.HorizontalJustification = int8(sr.ReadUint8())
+	b.VerticalJustification = int8(sr.ReadUint8())
+	b.BackgroundColorRGBA = sr.ReadUint32()
+	b.DefaultTextBox = decodeBoxRecord(sr)
+	b.DefaultStyle = decodeStyleRecord(sr)
+	if err := sr.AccError(); err != nil {
+		return nil, fmt.Errorf("DecodeTx3g: %w", err)
+	}
+	pos := startPos + nrTx3gBytesBeforeChildren
+	endPos := startPos + uint64(hdr.Hdrlen+hdr.payloadLen())
+	for pos < endPos {
+		box, err := decodeChildBoxSR(pos, sr)
+		if err != nil {
+			return nil, err
+		}
+		if box == nil {
+			return nil, fmt.Errorf("no child of tx3g")
+		}
+		b.AddChild(box)
+		pos += box.Size()
+	}
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *Tx3gBox) Type() string {
+	return "tx3g"
+}
+
+// Size - return calculated size
+func (b *Tx3gBox) Size() uint64 {
+	totalSize := uint64(nrTx3gBytesBeforeChildren)
+	for _, child := range b.Children {
+		totalSize += child.Size()
+	}
+	return totalSize
+}
+
+// GetChildren - list of child boxes
+func (b *Tx3gBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write box to w
+func (b *Tx3gBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - write box to sw
+func (b *Tx3gBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteZeroBytes(6)
+	sw.WriteUint16(b.DataReferenceIndex)
+	sw.WriteUint32(b.DisplayFlags)
+	sw.WriteUint8(byte(b.HorizontalJustification))
+	sw.WriteUint8(byte(b.VerticalJustification))
+	sw.WriteUint32(b.BackgroundColorRGBA)
+	b.DefaultTextBox.encode(sw)
+	b.DefaultStyle.encode(sw)
+	for _, child := range b.Children {
+		err = child.EncodeSW(sw)
+		if err != nil {
+			return err
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *Tx3gBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - dataReferenceIndex: %d", b.DataReferenceIndex)
+	bd.write(" - displayFlags: %08x", b.DisplayFlags)
+	bd.write(" - justification: horizontal=%d vertical=%d", b.HorizontalJustification, b.VerticalJustification)
+	bd.write(" - backgroundColor: %08x", b.BackgroundColorRGBA)
+	tb := b.DefaultTextBox
+	bd.write(" - defaultTextBox: top=%d left=%d bottom=%d right=%d", tb.Top, tb.Left, tb.Bottom, tb.Right)
+	bd.write(" - defaultStyle: %s", b.DefaultStyle)
+	if bd.err != nil {
+		return bd.err
+	}
+	var err error
+	for _, child := range b.Children {
+		err = child.Info(w, specificBoxLevels, indent+indentStep, indentStep)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+////////////////////////////// ftab //////////////////////////////
+
+// FontRecord - font ID and name in ftab box
+type FontRecord struct {
+	FontID   uint16
+	FontName string
+}
+
+// FtabBox - Font Table Box (ftab)
+type FtabBox struct {
+	Fonts []FontRecord
+}
+
+// DecodeFtab - box-specific decode
+func DecodeFtab(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeFtabSR(hdr, startPos, sr)
+}
+
+// DecodeFtabSR - box-specific decode
+func DecodeFtabSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := FtabBox{}
+	entryCount := int(sr.ReadUint16())
+	for i := 0; i < entryCount; i++ {
+		fontID := sr.ReadUint16()
+		nameLen := int(sr.ReadUint8())
+		b.Fonts = append(b.Fonts, FontRecord{FontID: fontID, FontName: sr.ReadFixedLengthString(nameLen)})
+	}
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *FtabBox) Type() string {
+	return "ftab"
+}
+
+// Size - return calculated size
+func (b *FtabBox) Size() uint64 {
+	size := uint64(boxHeaderSize + 2)
+	for _, f := range b.Fonts {
+		size += uint64(3 + len(f.FontName))
+	}
+	return size
+}
+
+// Encode - write box to w
+func (b *FtabBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - write box to sw
+func (b *FtabBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint16(uint16(len(b.Fonts)))
+	for _, f := range b.Fonts {
+		if len(f.FontName) > 255 {
+			return fmt.Errorf("ftab: font name %q too long", f.FontName)
+		}
+		sw.WriteUint16(f.FontID)
+		sw.WriteUint8(byte(len(f.FontName)))
+		sw.WriteString(f.FontName, false)
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *FtabBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	for _, f := range b.Fonts {
+		bd.write(" - font %d: %q", f.FontID, f.FontName)
+	}
+	return bd.err
+}
@@ -0,0 +1,76 @@
+package mp4_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+	"github.com/go-test/deep"
+)
+
+func TestTx3g(t *testing.T) {
+	tx3g := mp4.NewTx3gBox()
+	tx3g.DisplayFlags = mp4.Tx3gScrollIn | mp4.Tx3gFillTextRegion
+	tx3g.HorizontalJustification = 1
+	tx3g.VerticalJustification = -1
+	tx3g.BackgroundColorRGBA = 0x000000ff
+	tx3g.DefaultTextBox = mp4.BoxRecord{Top: 0, Left: 0, Bottom: 60, Right: 400}
+	tx3g.DefaultStyle = mp4.StyleRecord{FontID: 1, FaceStyle: mp4.Tx3gFaceItalic, FontSize: 24, TextColorRGBA: 0xffffffff}
+	tx3g.AddChild(&mp4.FtabBox{Fonts: []mp4.FontRecord{{FontID: 1, FontName: "Serif"}, {FontID: 2, FontName: "Sans-Serif"}}})
+	tx3g.AddChild(&mp4.BtrtBox{BufferSizeDB: 100, MaxBitrate: 2000, AvgBitrate: 1000})
+	boxDiffAfterEncodeAndDecode(t, tx3g)
+	if tx3g.Ftab == nil || tx3g.Btrt == nil {
+		t.Error("missing ftab or btrt pointer")
+	}
+
+	init := mp4.CreateEmptyInit()
+	init.AddEmptyTrack(1000, "tx3g", "eng")
+	err := init.Moov.Trak.SetTx3gDescriptor(1, "Sans-Serif", 18)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if init.Moov.Trak.Mdia.Hdlr.HandlerType != "text" || init.Moov.Trak.Mdia.Minf.Stbl.Stsd.Tx3g == nil {
+		t.Error("tx3g track not set up")
+	}
+}
+
+func TestTx3gSample(t *testing.T) {
+	samples := []mp4.Tx3gSample{
+		{Text: "Hello"},
+		{Text: "Blinking 字幕", UTF16: true, Modifiers: []mp4.Box{
+			&mp4.StylBox{Entries: []mp4.StyleRecord{{StartChar: 0, EndChar: 8, FontID: 1, FaceStyle: mp4.Tx3gFaceBold,
+				FontSize: 20, TextColorRGBA: 0xff0000ff}}},
+			&mp4.HlitBox{StartChar: 9, EndChar: 11},
+			&mp4.HclrBox{HighlightColorRGBA: 0x00ff00ff},
+			&mp4.KrokBox{HighlightStartTime: 10, Entries: []mp4.KaraokeEntry{{HighlightEndTime: 200, StartChar: 0, EndChar: 8}}},
+			&mp4.DlayBox{ScrollDelay: 50},
+			&mp4.HrefBox{StartChar: 0, EndChar: 8, URL: "https://example.com", AltString: "example"},
+			&mp4.TboxBox{TextBox: mp4.BoxRecord{Top: 1, Left: 2, Bottom: 3, Right: 4}},
+			&mp4.BlnkBox{StartChar: 0, EndChar: 8},
+		}},
+	}
+	for _, s := range samples {
+		for _, m := range s.Modifiers {
+			boxDiffAfterEncodeAndDecode(t, m)
+		}
+		buf := bytes.Buffer{}
+		err := s.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if uint64(buf.Len()) != s.Size() {
+			t.Errorf("encoded size %d differs from %d", buf.Len(), s.Size())
+		}
+		decoded, err := mp4.DecodeTx3gSample(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := deep.Equal(*decoded, s); diff != nil {
+			t.Error(diff)
+		}
+	}
+	_, err := mp4.DecodeTx3gSample([]byte{0, 10, 'a'})
+	if err == nil {
+		t.Error("expected error for too short sample")
+	}
+}
@@ -0,0 +1,674 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf16"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// Sample format and sample modifier boxes for 3GPP timed text according to 3GPP TS 26.245
+
+////////////////////////////// sample //////////////////////////////
+
+// Tx3gSample - 3GPP timed text sample with text and sample modifier boxes (styl, hlit, etc)
+type Tx3gSample struct {
+	Text string
+	// UTF16 is true if the text is UTF-16 encoded with a byte order mark, instead of UTF-8
+	UTF16     bool
+	Modifiers []Box
+}
+
+// DecodeTx3gSample - decode a tx3g sample with text and modifier boxes
+func DecodeTx3gSample(data []byte) (*Tx3gSample, error) {
+	sr := bits.NewFixedSliceReader(data)
+	textLen := int(sr.ReadUint16())
+	textBytes := sr.ReadBytes(textLen)
+	if err := sr.AccError(); err != nil {
+		return nil, fmt.Errorf("tx3g sample text: %w", err)
+	}
+	s := Tx3gSample{}
+	if len(textBytes) >= 2 && textBytes[0] == 0xfe && textBytes[1] == 0xff {
+		if len(textBytes)%2 != 0 {
+			return nil, fmt.Errorf("tx3g sample: odd UTF-16 text length %d", len(textBytes))
+		}
+		u16s := make([]uint16, 0, len(textBytes)/2-1)
+		for i := 2; i < len(textBytes); i += 2 {
+			u16s = append(u16s, uint16(textBytes[i])<<8|uint16(textBytes[i+1]))
+		}
+		s.Text = string(utf16.Decode(u16s))
+		s.UTF16 = true
+	} else {
+		s.Text = string(textBytes)
+	}
+	pos := uint64(2 + textLen)
+	for sr.NrRemainingBytes() > 0 {
+		box, err := decodeChildBoxSR(pos, sr)
+		if err != nil {
+			return nil, fmt.Errorf("tx3g sample modifier: %w", err)
+		}
+		s.Modifiers = append(s.Modifiers, box)
+		pos += box.Size()
+	}
+	return &s, sr.AccError()
+}
+
+// textBytes returns the encoded text
+func (s *Tx3gSample) textBytes() []byte {
+	if !s.UTF16 {
+		return []byte(s.Text)
+	}
+	u16s := utf16.Encode([]rune(s.Text))
+	buf := make([]byte, 0, 2+2*len(u16s))
+	buf = append(buf, 0xfe, 0xff)
+	for _, u := range u16s {
+		buf = append(buf, byte(u>>8), byte(u))
+	}
+	return buf
+}
+
+// Size - size of encoded sample
+func (s *Tx3gSample) Size() uint64 {
+	size := uint64(2 + len(s.textBytes()))
+	for _, m := range s.Modifiers {
+		size += m.Size()
+	}
+	return size
+}
+
+// Encode - write sample to w
+func (s *Tx3gSample) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(s.Size()))
+	err := s.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - write sample to sw
+func (s *Tx3gSample) EncodeSW(sw bits.SliceWriter) error {
+	text := s.textBytes()
+	if len(text) > 0xffff {
+		return fmt.Errorf("tx3g sample: text length %d too long", len(text))
+	}
+	sw.WriteUint16(uint16(len(text)))
+	sw.WriteBytes(text)
+	for _, m := range s.Modifiers {
+		err := m.EncodeSW(sw)
+		if err != nil {
+			return err
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write text and modifier boxes
+func (s *Tx3gSample) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	_, err := fmt.Fprintf(w, "%stext: %q\n", indent, s.Text)
+	if err != nil {
+		return err
+	}
+	for _, m := range s.Modifiers {
+		err = m.Info(w, specificBoxLevels, indent, indentStep)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+////////////////////////////// styl //////////////////////////////
+
+// StylBox - TextStyleBox (styl)
+type StylBox struct {
+	Entries []StyleRecord
+}
+
+// DecodeStyl - box-specific decode
+func DecodeStyl(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeStylSR(hdr, startPos, sr)
+}
+
+// DecodeStylSR - box-specific decode
+func DecodeStylSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := StylBox{}
+	entryCount := int(sr.ReadUint16())
+	if entryCount*styleRecordSize > hdr.payloadLen()-2 {
+		return nil, fmt.Errorf("styl: entry count %d too large for box size", entryCount)
+	}
+	b.Entries = make([]StyleRecord, entryCount)
+	for i := range b.Entries {
+		b.Entries[i] = decodeStyleRecord(sr)
+	}
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *StylBox) Type() string {
+	return "styl"
+}
+
+// Size - return calculated size
+func (b *StylBox) Size() uint64 {
+	return uint64(boxHeaderSize + 2 + styleRecordSize*len(b.Entries))
+}
+
+// Encode - write box to w
+func (b *StylBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *StylBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint16(uint16(len(b.Entries)))
+	for _, e := range b.Entries {
+		e.encode(sw)
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *StylBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	for _, e := range b.Entries {
+		bd.write(" - style: %s", e)
+	}
+	return bd.err
+}
+
+////////////////////////////// hlit //////////////////////////////
+
+// HlitBox - TextHighlightBox (hlit)
+type HlitBox struct {
+	StartChar uint16
+	EndChar   uint16
+}
+
+// DecodeHlit - box-specific decode
+func DecodeHlit(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeHlitSR(hdr, startPos, sr)
+}
+
+// DecodeHlitSR - box-specific decode
+func DecodeHlitSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := HlitBox{StartChar: sr.ReadUint16(), EndChar: sr.ReadUint16()}
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *HlitBox) Type() string {
+	return "hlit"
+}
+
+// Size - return calculated size
+func (b *HlitBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4)
+}
+
+// Encode - write box to w
+func (b *HlitBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *HlitBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint16(b.StartChar)
+	sw.WriteUint16(b.EndChar)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *HlitBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - startChar: %d", b.StartChar)
+	bd.write(" - endChar: %d", b.EndChar)
+	return bd.err
+}
+
+////////////////////////////// hclr //////////////////////////////
+
+// HclrBox - TextHilightColorBox (hclr)
+type HclrBox struct {
+	HighlightColorRGBA uint32
+}
+
+// DecodeHclr - box-specific decode
+func DecodeHclr(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeHclrSR(hdr, startPos, sr)
+}
+
+// DecodeHclrSR - box-specific decode
+func DecodeHclrSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := HclrBox{HighlightColorRGBA: sr.ReadUint32()}
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *HclrBox) Type() string {
+	return "hclr"
+}
+
+// Size - return calculated size
+func (b *HclrBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4)
+}
+
+// Encode - write box to w
+func (b *HclrBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *HclrBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint32(b.HighlightColorRGBA)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *HclrBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - highlightColor: %08x", b.HighlightColorRGBA)
+	return bd.err
+}
+
+////////////////////////////// dlay //////////////////////////////
+
+// DlayBox - TextScrollDelayBox (dlay)
+type DlayBox struct {
+	ScrollDelay uint32
+}
+
+// DecodeDlay - box-specific decode
+func DecodeDlay(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeDlaySR(hdr, startPos, sr)
+}
+
+// DecodeDlaySR - box-specific decode
+func DecodeDlaySR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := DlayBox{ScrollDelay: sr.ReadUint32()}
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *DlayBox) Type() string {
+	return "dlay"
+}
+
+// Size - return calculated size
+func (b *DlayBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4)
+}
+
+// Encode - write box to w
+func (b *DlayBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *DlayBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint32(b.ScrollDelay)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *DlayBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - scrollDelay: %d", b.ScrollDelay)
+	return bd.err
+}
+
+////////////////////////////// blnk //////////////////////////////
+
+// BlnkBox - TextBlinkBox (blnk)
+type BlnkBox struct {
+	StartChar uint16
+	EndChar   uint16
+}
+
+// DecodeBlnk - box-specific decode
+func DecodeBlnk(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeBlnkSR(hdr, startPos, sr)
+}
+
+// DecodeBlnkSR - box-specific decode
+func DecodeBlnkSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := BlnkBox{StartChar: sr.ReadUint16(), EndChar: sr.ReadUint16()}
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *BlnkBox) Type() string {
+	return "blnk"
+}
+
+// Size - return calculated size
+func (b *BlnkBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4)
+}
+
+// Encode - write box to w
+func (b *BlnkBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *BlnkBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint16(b.StartChar)
+	sw.WriteUint16(b.EndChar)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *BlnkBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - startChar: %d", b.StartChar)
+	bd.write(" - endChar: %d", b.EndChar)
+	return bd.err
+}
+
+////////////////////////////// krok //////////////////////////////
+
+// KaraokeEntry - highlight end time and characters in krok box
+type KaraokeEntry struct {
+	HighlightEndTime uint32
+	StartChar        uint16
+	EndChar          uint16
+}
+
+// KrokBox - TextKaraokeBox (krok)
+type KrokBox struct {
+	HighlightStartTime uint32
+	Entries            []KaraokeEntry
+}
+
+// DecodeKrok - box-specific decode
+func DecodeKrok(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeKrokSR(hdr, startPos, sr)
+}
+
+// DecodeKrokSR - box-specific decode
+func DecodeKrokSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := KrokBox{}
+	b.HighlightStartTime = sr.ReadUint32()
+	entryCount := int(sr.ReadUint16())
+	if entryCount*8 > hdr.payloadLen()-6 {
+		return nil, fmt.Errorf("krok: entry count %d too large for box size", entryCount)
+	}
+	b.Entries = make([]KaraokeEntry, entryCount)
+	for i := range b.Entries {
+		b.Entries[i].HighlightEndTime = sr.ReadUint32()
+		b.Entries[i].StartChar = sr.ReadUint16()
+		b.Entries[i].EndChar = sr.ReadUint16()
+	}
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *KrokBox) Type() string {
+	return "krok"
+}
+
+// Size - return calculated size
+func (b *KrokBox) Size() uint64 {
+	return uint64(boxHeaderSize + 6 + 8*len(b.Entries))
+}
+
+// Encode - write box to w
+func (b *KrokBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *KrokBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint32(b.HighlightStartTime)
+	sw.WriteUint16(uint16(len(b.Entries)))
+	for _, e := range b.Entries {
+		sw.WriteUint32(e.HighlightEndTime)
+		sw.WriteUint16(e.StartChar)
+		sw.WriteUint16(e.EndChar)
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *KrokBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - highlightStartTime: %d", b.HighlightStartTime)
+	for _, e := range b.Entries {
+		bd.write(" - highlightEndTime=%d chars=[%d,%d)", e.HighlightEndTime, e.StartChar, e.EndChar)
+	}
+	return bd.err
+}
+
+////////////////////////////// href //////////////////////////////
+
+// HrefBox - TextHyperTextBox (href)
+type HrefBox struct {
+	StartChar uint16
+	EndChar   uint16
+	URL       string
+	AltString string
+}
+
+// DecodeHref - box-specific decode
+func DecodeHref(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeHrefSR(hdr, startPos, sr)
+}
+
+// DecodeHrefSR - box-specific decode
+func DecodeHrefSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := HrefBox{}
+	b.StartChar = sr.ReadUint16()
+	b.EndChar = sr.ReadUint16()
+	b.URL = sr.ReadFixedLengthString(int(sr.ReadUint8()))
+	b.AltString = sr.ReadFixedLengthString(int(sr.ReadUint8()))
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *HrefBox) Type() string {
+	return "href"
+}
+
+// Size - return calculated size
+func (b *HrefBox) Size() uint64 {
+	return uint64(boxHeaderSize + 6 + len(b.URL) + len(b.AltString))
+}
+
+// Encode - write box to w
+func (b *HrefBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *HrefBox) EncodeSW(sw bits.SliceWriter) error {
+	if len(b.URL) > 255 || len(b.AltString) > 255 {
+		return fmt.Errorf("href: URL or alt string longer than 255 bytes")
+	}
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint16(b.StartChar)
+	sw.WriteUint16(b.EndChar)
+	sw.WriteUint8(byte(len(b.URL)))
+	sw.WriteString(b.URL, false)
+	sw.WriteUint8(byte(len(b.AltString)))
+	sw.WriteString(b.AltString, false)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *HrefBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - chars: [%d,%d)", b.StartChar, b.EndChar)
+	bd.write(" - URL: %q", b.URL)
+	bd.write(" - altString: %q", b.AltString)
+	return bd.err
+}
+
+////////////////////////////// tbox //////////////////////////////
+
+// TboxBox - TextBoxBox (tbox) overriding the default text box
+type TboxBox struct {
+	TextBox BoxRecord
+}
+
+// DecodeTbox - box-specific decode
+func DecodeTbox(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeTboxSR(hdr, startPos, sr)
+}
+
+// DecodeTboxSR - box-specific decode
+func DecodeTboxSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := TboxBox{TextBox: decodeBoxRecord(sr)}
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *TboxBox) Type() string {
+	return "tbox"
+}
+
+// Size - return calculated size
+func (b *TboxBox) Size() uint64 {
+	return uint64(boxHeaderSize + boxRecordSize)
+}
+
+// Encode - write box to w
+func (b *TboxBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *TboxBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	b.TextBox.encode(sw)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *TboxBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	tb := b.TextBox
+	bd.write(" - textBox: top=%d left=%d bottom=%d right=%d", tb.Top, tb.Left, tb.Bottom, tb.Right)
+	return bd.err
+}
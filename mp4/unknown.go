@@ -82,3 +82,16 @@ func (b *UnknownBox) Info(w io.Writer, specificBoxLevels, indent, indentStep str
 
 	return bd.err
 }
+
+// addJSONFields - add payload as hex string
+func (b *UnknownBox) addJSONFields(fields map[string]interface{}) {
+	fields["Payload"] = hex.EncodeToString(b.notDecoded)
+}
+
+// setJSONFields - set payload from hex string
+func (b *UnknownBox) setJSONFields(fields map[string]interface{}) error {
+	payload, _, err := popBytes(fields, "Payload")
+	b.notDecoded = payload
+	b.size = boxHeaderSize + uint64(len(payload))
+	return err
+}
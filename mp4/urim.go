@@ -0,0 +1,277 @@
+package mp4
+
+import (
+	"encoding/hex"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// UrimBox - URIMetaSampleEntry (urim)
+// Defined in ISO/IEC 14496-12 Sec. 12.3.3.
+//
+// Contained in : Sample Description Box (stsd)
+type UrimBox struct {
+	DataReferenceIndex uint16
+	URI                *URIBox     // Mandatory
+	URIInit            *URIInitBox // Optional
+	Btrt               *BtrtBox    // Optional
+	Children           []Box
+}
+
+// NewUrimBox - Create new urim box with a uri box and a uriI box if initData is not empty
+func NewUrimBox(uri string, initData []byte) *UrimBox {
+	b := &UrimBox{DataReferenceIndex: 1}
+	b.AddChild(&URIBox{URI: uri})
+	if len(initData) > 0 {
+		b.AddChild(&URIInitBox{InitData: initData})
+	}
+	return b
+}
+
+// AddChild - add a child box
+func (b *UrimBox) AddChild(child Box) {
+	switch box := child.(type) {
+	case *URIBox:
+		b.URI = box
+	case *URIInitBox:
+		b.URIInit = box
+	case *BtrtBox:
+		b.Btrt = box
+	}
+	b.Children = append(b.Children, child)
+}
+
+// DecodeUrim - Decode URIMetaSampleEntry (urim)
+func DecodeUrim(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeUrimSR(hdr, startPos, sr)
+}
+
+// DecodeUrimSR - Decode URIMetaSampleEntry (urim)
+func DecodeUrimSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := UrimBox{}
+	initPos := sr.GetPos()
+	sr.SkipBytes(6) // Skip 6 reserved bytes
+	b.DataReferenceIndex = sr.ReadUint16()
+	if err := sr.AccError(); err != nil {
+		return nil, err
+	}
+	children, err := decodeSampleEntryChildrenSR(hdr, startPos, sr, initPos)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return &b, sr.AccError()
+}
+
+// GetChildren - list of child boxes
+func (b *UrimBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Type - return box type
+func (b *UrimBox) Type() string {
+	return "urim"
+}
+
+// Size - return calculated size
+func (b *UrimBox) Size() uint64 {
+	size := uint64(boxHeaderSize + 8)
+	for _, child := range b.Children {
+		size += child.Size()
+	}
+	return size
+}
+
+// Encode - write box to w via a SliceWriter
+func (b *UrimBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - write box to sw
+func (b *UrimBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteZeroBytes(6)
+	sw.WriteUint16(b.DataReferenceIndex)
+	for _, child := range b.Children {
+		err = child.EncodeSW(sw)
+		if err != nil {
+			return err
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write specific box info to w
+func (b *UrimBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - dataReferenceIndex: %d", b.DataReferenceIndex)
+	if bd.err != nil {
+		return bd.err
+	}
+	for _, child := range b.Children {
+		err := child.Info(w, specificBoxLevels, indent+indentStep, indentStep)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// URIBox - URIBox (uri )
+// Defined in ISO/IEC 14496-12 Sec. 12.3.3.
+//
+// Contained in : URIMetaSampleEntry (urim)
+type URIBox struct {
+	Version byte
+	Flags   uint32
+	URI     string
+}
+
+// DecodeURI - box-specific decode
+func DecodeURI(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeURISR(hdr, startPos, sr)
+}
+
+// DecodeURISR - box-specific decode
+func DecodeURISR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := URIBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.URI = sr.ReadZeroTerminatedString(hdr.payloadLen() - 4)
+	return &b, sr.AccError()
+}
+
+// Type - box type
+func (b *URIBox) Type() string {
+	return "uri "
+}
+
+// Size - calculated size of box
+func (b *URIBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4 + len(b.URI) + 1)
+}
+
+// Encode - write box to w
+func (b *URIBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *URIBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteString(b.URI, true)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *URIBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - uri: %q", b.URI)
+	return bd.err
+}
+
+// URIInitBox - URIInitBox (uriI)
+// Defined in ISO/IEC 14496-12 Sec. 12.3.3.
+//
+// Contained in : URIMetaSampleEntry (urim)
+type URIInitBox struct {
+	Version  byte
+	Flags    uint32
+	InitData []byte
+}
+
+// DecodeURIInit - box-specific decode
+func DecodeURIInit(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeURIInitSR(hdr, startPos, sr)
+}
+
+// DecodeURIInitSR - box-specific decode
+func DecodeURIInitSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := URIInitBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.InitData = sr.ReadBytes(hdr.payloadLen() - 4)
+	return &b, sr.AccError()
+}
+
+// Type - box type
+func (b *URIInitBox) Type() string {
+	return "uriI"
+}
+
+// Size - calculated size of box
+func (b *URIInitBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4 + len(b.InitData))
+}
+
+// Encode - write box to w
+func (b *URIInitBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *URIInitBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteBytes(b.InitData)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *URIInitBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - initData: %s", hex.EncodeToString(b.InitData))
+	return bd.err
+}
@@ -0,0 +1,67 @@
+package mp4_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestUrim(t *testing.T) {
+	urim := mp4.NewUrimBox("urn:example:analytics", nil)
+	if urim.URI == nil || urim.URIInit != nil {
+		t.Error("bad child box pointers")
+	}
+	boxDiffAfterEncodeAndDecode(t, urim)
+	urim = mp4.NewUrimBox("urn:example:analytics", []byte{1, 2, 3})
+	if urim.URIInit == nil {
+		t.Error("no uriI box")
+	}
+	boxDiffAfterEncodeAndDecode(t, urim)
+	boxDiffAfterEncodeAndDecode(t, &mp4.URIBox{URI: "urn:a"})
+	boxDiffAfterEncodeAndDecode(t, &mp4.URIInitBox{InitData: []byte{4, 5}})
+}
+
+func TestTimedMetadataTracks(t *testing.T) {
+	init := mp4.CreateEmptyInit()
+	mett := init.AddEmptyTrack(1000, "meta", "und")
+	if err := mett.SetMettDescriptor("", "application/json"); err != nil {
+		t.Fatal(err)
+	}
+	metx := init.AddEmptyTrack(90000, "metx", "und")
+	if err := metx.SetMetxDescriptor("", "http://www.onvif.org/ver10/schema", ""); err != nil {
+		t.Fatal(err)
+	}
+	urim := init.AddEmptyTrack(1000, "urim", "und")
+	if err := urim.SetUrimDescriptor("urn:example:analytics", nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, trak := range init.Moov.Traks {
+		if trak.Mdia.Hdlr.HandlerType != "meta" {
+			t.Errorf("track %d: handler type %q instead of meta", trak.Tkhd.TrackID, trak.Mdia.Hdlr.HandlerType)
+		}
+		if _, ok := trak.Mdia.Minf.Children[0].(*mp4.NmhdBox); !ok {
+			t.Errorf("track %d: no nmhd box", trak.Tkhd.TrackID)
+		}
+	}
+	if mett.Mdia.Minf.Stbl.Stsd.Mett == nil || metx.Mdia.Minf.Stbl.Stsd.Metx == nil ||
+		urim.Mdia.Minf.Stbl.Stsd.Urim == nil {
+		t.Error("sample entry pointers not set")
+	}
+	if err := mett.SetMettDescriptor("", ""); err == nil {
+		t.Error("no error for empty mimeFormat")
+	}
+	buf := bytes.Buffer{}
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := mp4.DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	traks := f.Init.Moov.Traks
+	if traks[0].Mdia.Minf.Stbl.Stsd.Mett.MimeFormat != "application/json" ||
+		traks[1].Mdia.Minf.Stbl.Stsd.Metx == nil || traks[2].Mdia.Minf.Stbl.Stsd.Urim.URI.URI != "urn:example:analytics" {
+		t.Error("decoded sample entries differ")
+	}
+}
@@ -109,7 +109,7 @@ type UUIDBox struct {
 	Tfxd           *TfxdData
 	Tfrf           *TfrfData
 	Senc           *SencBox
-	StartPos       uint64
+	StartPos       uint64 `json:"-"`
 	UnknownPayload []byte
 }
 
@@ -178,7 +178,7 @@ func DecodeUUIDBoxSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box,
 			return nil, fmt.Errorf("uuid box size too small: %d < 16", hdr.Size)
 		}
 		// This is like a SencBox except that there is no size and type. Offset and sizes must be slightly adjusted.
-		subHdr := BoxHeader{"senc", hdr.Size - 16, 8}
+		subHdr := BoxHeader{Name: "senc", Size: hdr.Size - 16, Hdrlen: 8}
 		box, err := DecodeSencSR(subHdr, b.StartPos+16, sr)
 		if err != nil {
 			return nil, fmt.Errorf("failed to decode senc in UUID: %w", err)
@@ -406,3 +406,22 @@ func UnpackKey(inKey string) (key []byte, err error) {
 	}
 	return key, nil
 }
+
+// addJSONFields - add UUID as formatted string
+func (b *UUIDBox) addJSONFields(fields map[string]interface{}) {
+	fields["UUID"] = b.UUID()
+}
+
+// setJSONFields - set UUID from formatted string
+func (b *UUIDBox) setJSONFields(fields map[string]interface{}) error {
+	value, ok := fields["UUID"]
+	if !ok {
+		return fmt.Errorf("UUID missing")
+	}
+	delete(fields, "UUID")
+	uuid, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("UUID: not a string")
+	}
+	return b.SetUUID(uuid)
+}
@@ -0,0 +1,106 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// VexuBox - Video Extended Usage Box (vexu)
+//
+// Contained in : Visual Sample Entry
+//
+// Defined in Apple HEVC Stereo Video - ISOBMFF Extensions
+type VexuBox struct {
+	Must     *MustBox
+	Eyes     *EyesBox
+	Proj     *ProjBox
+	Children []Box
+}
+
+// CreateStereoVexu - create a vexu box for stereo video with left and right eye views.
+// The hero and cams boxes are only added if heroEye and baseline (in micrometers) are non-zero.
+func CreateStereoVexu(heroEye byte, baseline uint32) *VexuBox {
+	eyes := &EyesBox{}
+	eyes.AddChild(&StriBox{HasLeftEyeView: true, HasRightEyeView: true})
+	if heroEye != HeroEyeNone {
+		eyes.AddChild(&HeroBox{HeroEyeIndicator: heroEye})
+	}
+	if baseline > 0 {
+		cams := &CamsBox{}
+		cams.AddChild(&BlinBox{BaselineValue: baseline})
+		eyes.AddChild(cams)
+	}
+	b := &VexuBox{}
+	b.AddChild(eyes)
+	return b
+}
+
+// AddChild - add a child box
+func (b *VexuBox) AddChild(child Box) {
+	switch box := child.(type) {
+	case *MustBox:
+		b.Must = box
+	case *EyesBox:
+		b.Eyes = box
+	case *ProjBox:
+		b.Proj = box
+	}
+	b.Children = append(b.Children, child)
+}
+
+// DecodeVexu - box-specific decode
+func DecodeVexu(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.Size, r)
+	if err != nil {
+		return nil, err
+	}
+	b := &VexuBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// DecodeVexuSR - box-specific decode
+func DecodeVexuSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.Size, sr)
+	if err != nil {
+		return nil, err
+	}
+	b := &VexuBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// Type - return box type
+func (b *VexuBox) Type() string {
+	return "vexu"
+}
+
+// Size - return calculated size
+func (b *VexuBox) Size() uint64 {
+	return containerSize(b.Children)
+}
+
+// GetChildren - list of child boxes
+func (b *VexuBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write vexu container to w
+func (b *VexuBox) Encode(w io.Writer) error {
+	return EncodeContainer(b, w)
+}
+
+// EncodeSW - write vexu container via sw
+func (b *VexuBox) EncodeSW(sw bits.SliceWriter) error {
+	return EncodeContainerSW(b, sw)
+}
+
+// Info - write box-specific information
+func (b *VexuBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
+}
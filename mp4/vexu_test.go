@@ -0,0 +1,92 @@
+package mp4_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestVexu(t *testing.T) {
+	boxDiffAfterEncodeAndDecode(t, &mp4.MustBox{RequiredBoxTypes: []string{"eyes", "stri"}})
+	boxDiffAfterEncodeAndDecode(t, &mp4.StriBox{HasLeftEyeView: true, HasRightEyeView: true, EyeViewsReversed: true})
+	boxDiffAfterEncodeAndDecode(t, &mp4.HeroBox{HeroEyeIndicator: mp4.HeroEyeRight})
+	boxDiffAfterEncodeAndDecode(t, &mp4.BlinBox{BaselineValue: 63000})
+	boxDiffAfterEncodeAndDecode(t, &mp4.DadjBox{DisparityAdjustment: -200})
+	boxDiffAfterEncodeAndDecode(t, &mp4.PrjiBox{ProjectionKind: "rect"})
+
+	vexu := mp4.CreateStereoVexu(mp4.HeroEyeLeft, 19240)
+	cmfy := &mp4.CmfyBox{}
+	cmfy.AddChild(&mp4.DadjBox{DisparityAdjustment: 200})
+	vexu.Eyes.AddChild(cmfy)
+	proj := &mp4.ProjBox{}
+	proj.AddChild(&mp4.PrjiBox{ProjectionKind: "rect"})
+	vexu.AddChild(proj)
+	boxDiffAfterEncodeAndDecode(t, vexu)
+	eyes := vexu.Eyes
+	if eyes.Stri == nil || !eyes.Stri.HasLeftEyeView || eyes.Hero.HeroEyeIndicator != mp4.HeroEyeLeft ||
+		eyes.Cams.Blin.BaselineValue != 19240 || eyes.Cmfy.Dadj == nil || vexu.Proj.Prji == nil {
+		t.Error("missing or wrong child boxes in vexu")
+	}
+	vexu = mp4.CreateStereoVexu(mp4.HeroEyeNone, 0)
+	if vexu.Eyes.Hero != nil || vexu.Eyes.Cams != nil {
+		t.Error("expected no hero or cams box")
+	}
+}
+
+func TestSetStereoAndSphericalBoxes(t *testing.T) {
+	init, err := createVideoHEVCInitSegment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trak := init.Moov.Trak
+	for _, mode := range []byte{mp4.StereoModeLeftRight, mp4.StereoModeTopBottom} {
+		if err := trak.SetStereoMode(mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := trak.SetSphericalVideo(mp4.CreateEquirectSv3d("mp4ff")); err != nil {
+		t.Fatal(err)
+	}
+	if err := trak.SetVideoExtendedUsage(mp4.CreateStereoVexu(mp4.HeroEyeLeft, 63000)); err != nil {
+		t.Fatal(err)
+	}
+	nrSt3d := 0
+	for _, c := range trak.Mdia.Minf.Stbl.Stsd.HvcX.Children {
+		if c.Type() == "st3d" {
+			nrSt3d++
+		}
+	}
+	if nrSt3d != 1 {
+		t.Errorf("got %d st3d boxes instead of 1", nrSt3d)
+	}
+
+	buf := bytes.Buffer{}
+	err = init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := mp4.DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hvcX := f.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.HvcX
+	if hvcX.St3d == nil || hvcX.St3d.StereoMode != mp4.StereoModeTopBottom {
+		t.Errorf("got st3d %v", hvcX.St3d)
+	}
+	if hvcX.Sv3d == nil || hvcX.Sv3d.Proj.Equi == nil {
+		t.Errorf("got sv3d %v", hvcX.Sv3d)
+	}
+	if hvcX.Vexu == nil || hvcX.Vexu.Eyes.Cams.Blin.BaselineValue != 63000 {
+		t.Errorf("got vexu %v", hvcX.Vexu)
+	}
+
+	audio := mp4.CreateEmptyInit()
+	audio.AddEmptyTrack(48000, "audio", "en")
+	if err := audio.Moov.Trak.SetAACDescriptor(2, 48000); err != nil {
+		t.Fatal(err)
+	}
+	if err := audio.Moov.Trak.SetStereoMode(mp4.StereoModeMono); err == nil {
+		t.Error("expected error for audio track")
+	}
+}
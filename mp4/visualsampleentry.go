@@ -5,10 +5,11 @@ import (
 	"io"
 
 	"github.com/Eyevinn/mp4ff/bits"
+	"github.com/Eyevinn/mp4ff/dovi"
 	"github.com/Eyevinn/mp4ff/hevc"
 )
 
-// VisualSampleEntryBox Video Sample Description box (avc1/avc3/hvc1/hev1...)
+// VisualSampleEntryBox Video Sample Description box (avc1/avc3/hvc1/hev1/dvh1/dva1...)
 type VisualSampleEntryBox struct {
 	name               string
 	DataReferenceIndex uint16
@@ -23,6 +24,7 @@ type VisualSampleEntryBox struct {
 	Av1C               *Av1CBox
 	Av3c               *Av3cBox
 	VvcC               *VvcCBox
+	DvcC               *DvcCBox
 	VppC               *VppCBox
 	Btrt               *BtrtBox
 	Clap               *ClapBox
@@ -30,6 +32,9 @@ type VisualSampleEntryBox struct {
 	Sinf               *SinfBox
 	SmDm               *SmDmBox
 	CoLL               *CoLLBox
+	St3d               *St3dBox
+	Sv3d               *Sv3dBox
+	Vexu               *VexuBox
 	Children           []Box
 	TrailingBytes      []byte
 }
@@ -74,6 +79,8 @@ func (b *VisualSampleEntryBox) AddChild(child Box) {
 		b.Av3c = box
 	case *VvcCBox:
 		b.VvcC = box
+	case *DvcCBox:
+		b.DvcC = box
 	case *VppCBox:
 		b.VppC = box
 	case *BtrtBox:
@@ -88,6 +95,12 @@ func (b *VisualSampleEntryBox) AddChild(child Box) {
 		b.SmDm = box
 	case *CoLLBox:
 		b.CoLL = box
+	case *St3dBox:
+		b.St3d = box
+	case *Sv3dBox:
+		b.Sv3d = box
+	case *VexuBox:
+		b.Vexu = box
 	}
 	b.Children = append(b.Children, child)
 }
@@ -142,7 +155,7 @@ func DecodeVisualSampleEntrySR(hdr BoxHeader, startPos uint64, sr bits.SliceRead
 			b.TrailingBytes = sr.ReadBytes(int(remainingBytes))
 			break
 		}
-		box, err := DecodeBoxSR(pos, sr)
+		box, err := decodeChildBoxSR(pos, sr)
 		if err != nil {
 			return nil, fmt.Errorf("error decoding childBox of VisualSampleEntry: %w", err)
 		}
@@ -156,6 +169,11 @@ func DecodeVisualSampleEntrySR(hdr BoxHeader, startPos uint64, sr bits.SliceRead
 	return &b, sr.AccError()
 }
 
+// GetChildren - list of child boxes
+func (b *VisualSampleEntryBox) GetChildren() []Box {
+	return b.Children
+}
+
 // Type returns box type
 func (b *VisualSampleEntryBox) Type() string {
 	return b.name
@@ -324,3 +342,84 @@ func (b *VisualSampleEntryBox) ConvertAvc3ToAvc1(spss [][]byte, ppss [][]byte) e
 	b.AvcC.PPSnalus = ppss
 	return nil
 }
+
+// dataFormat returns the sample entry type, or the original format for encrypted entries
+func (b *VisualSampleEntryBox) dataFormat() string {
+	if b.name == "encv" && b.Sinf != nil && b.Sinf.Frma != nil {
+		return b.Sinf.Frma.DataFormat
+	}
+	return b.name
+}
+
+// setDataFormat sets the sample entry type, or the original format for encrypted entries
+func (b *VisualSampleEntryBox) setDataFormat(format string) {
+	if b.name == "encv" && b.Sinf != nil && b.Sinf.Frma != nil {
+		b.Sinf.Frma.DataFormat = format
+		return
+	}
+	b.name = format
+}
+
+// SetDolbyVisionConfig adds or replaces the Dolby Vision configuration box (dvcC, dvvC, or dvwC).
+// The sample entry type is changed to dvh1, dvhe, or dav1 if the profile does not allow
+// the base type (profiles 4, 5, and 7, and profile 10 without a compatible base layer).
+// For encrypted entries, the original format in the frma box is changed instead.
+func (b *VisualSampleEntryBox) SetDolbyVisionConfig(rec dovi.DecConfRec) error {
+	format := b.dataFormat()
+	switch {
+	case rec.Profile == 4 || rec.Profile == 5 || rec.Profile == 7:
+		switch format {
+		case "hvc1":
+			format = "dvh1"
+		case "hev1":
+			format = "dvhe"
+		}
+	case rec.Profile == 10 && rec.BLSignalCompatibilityID == 0:
+		if format == "av01" {
+			format = "dav1"
+		}
+	}
+	if err := rec.CheckSampleEntry(format); err != nil {
+		return err
+	}
+	b.setDataFormat(format)
+	b.replaceChild(CreateDvcC(rec), "dvcC", "dvvC", "dvwC")
+	return nil
+}
+
+// replaceChild replaces the first child of the given types by child at the same index,
+// and removes any other children of these types. If there is no such child, child is added last.
+func (b *VisualSampleEntryBox) replaceChild(child Box, types ...string) {
+	idx := -1
+	children := b.Children[:0]
+	for _, c := range b.Children {
+		replace := false
+		for _, t := range types {
+			if c.Type() == t {
+				replace = true
+				break
+			}
+		}
+		switch {
+		case !replace:
+			children = append(children, c)
+		case idx < 0:
+			idx = len(children)
+			children = append(children, child)
+		}
+	}
+	b.Children = children
+	b.AddChild(child) // Sets the pointer to child
+	if idx >= 0 {
+		b.Children = b.Children[:len(b.Children)-1]
+	}
+}
+
+// DolbyVisionCodecString returns the Dolby Vision codec string (e.g. dvh1.08.06) based on the dvcC/dvvC/dvwC box.
+// For backwards-compatible profiles, this is the supplemental codec string for the base sample entry.
+func (b *VisualSampleEntryBox) DolbyVisionCodecString() (string, error) {
+	if b.DvcC == nil {
+		return "", fmt.Errorf("no dolby vision configuration box in %s", b.name)
+	}
+	return b.DvcC.CodecString(dovi.SampleEntryCodecString(b.dataFormat())), nil
+}
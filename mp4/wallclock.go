@@ -0,0 +1,211 @@
+package mp4
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// PrftClock returns the wall-clock time for prft boxes given the media time of the reference track
+// with trackID and timescale.
+type PrftClock func(trackID, timescale uint32, mediaTime uint64) NTP64
+
+// UTCPrftClock returns a PrftClock giving the UTC time of now when called, e.g. time.Now for the system clock.
+func UTCPrftClock(now func() time.Time) PrftClock {
+	return func(trackID, timescale uint32, mediaTime uint64) NTP64 {
+		return NTP64FromTime(now())
+	}
+}
+
+// LinearPrftClock returns a PrftClock where media time 0 corresponds to start, e.g. the start of a camera recording.
+func LinearPrftClock(start time.Time) PrftClock {
+	return func(trackID, timescale uint32, mediaTime uint64) NTP64 {
+		return NTP64FromTime(start.Add(ticksToDuration(int64(mediaTime), timescale)))
+	}
+}
+
+// SetPrft sets the prft box of the fragment. It replaces any previous prft box and is placed directly before moof.
+func (f *Fragment) SetPrft(prft *PrftBox) {
+	children := make([]Box, 0, len(f.Children)+1)
+	for _, c := range f.Children {
+		switch c.(type) {
+		case *PrftBox:
+			continue
+		case *MoofBox:
+			children = append(children, prft)
+		}
+		children = append(children, c)
+	}
+	if f.Moof == nil {
+		children = append(children, prft)
+	}
+	f.Children = children
+	f.Prft = prft
+}
+
+// AddPrft adds a prft box referring to the track with trackID and timescale.
+// The media time is the baseMediaDecodeTime of the track, and the wall-clock time is given by clock.
+func (f *Fragment) AddPrft(trackID, timescale, flags uint32, clock PrftClock) error {
+	if f.Moof == nil {
+		return fmt.Errorf("no moof in fragment")
+	}
+	for _, traf := range f.Moof.Trafs {
+		if traf.Tfhd.TrackID != trackID || traf.Tfdt == nil {
+			continue
+		}
+		mediaTime := traf.Tfdt.BaseMediaDecodeTime()
+		var version byte
+		if mediaTime > math.MaxUint32 {
+			version = 1
+		}
+		f.SetPrft(CreatePrftBox(version, flags, trackID, clock(trackID, timescale, mediaTime), mediaTime))
+		return nil
+	}
+	return fmt.Errorf("no traf with tfdt for track %d", trackID)
+}
+
+// ticksToDuration converts ticks in timescale to time.Duration without overflow for long durations
+func ticksToDuration(ticks int64, timescale uint32) time.Duration {
+	ts := int64(timescale)
+	return time.Duration(ticks/ts)*time.Second + time.Duration(ticks%ts*int64(time.Second)/ts)
+}
+
+// durationToTicks converts d to ticks in timescale without overflow for long durations
+func durationToTicks(d time.Duration, timescale uint32) int64 {
+	ts := int64(timescale)
+	sec := int64(d / time.Second)
+	ns := int64(d % time.Second)
+	return sec*ts + ns*ts/int64(time.Second)
+}
+
+// Prfts returns all prft boxes in the media segments in order.
+func (f *File) Prfts() []*PrftBox {
+	var prfts []*PrftBox
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			if frag.Prft != nil {
+				prfts = append(prfts, frag.Prft)
+			}
+		}
+	}
+	return prfts
+}
+
+// wallClockRef is a prft reference point with media time in the timescale of a specific track
+type wallClockRef struct {
+	mediaTime uint64
+	time      time.Time
+}
+
+// wallClockRefs returns the prft reference points in the timescale of the track with trackID
+func (f *File) wallClockRefs(trackID uint32) ([]wallClockRef, uint32, error) {
+	if f.Init == nil {
+		return nil, 0, fmt.Errorf("not a fragmented file with init segment")
+	}
+	timescale, err := trackTimescale(f.Init.Moov, trackID)
+	if err != nil {
+		return nil, 0, err
+	}
+	var refs []wallClockRef
+	for _, prft := range f.Prfts() {
+		refTimescale, err := trackTimescale(f.Init.Moov, prft.ReferenceTrackID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("prft: %w", err)
+		}
+		refs = append(refs, wallClockRef{
+			mediaTime: rescaleTime(prft.MediaTime, timescale, refTimescale),
+			time:      prft.NTPTimestamp.Time(),
+		})
+	}
+	if len(refs) == 0 {
+		return nil, 0, fmt.Errorf("no prft box in file")
+	}
+	return refs, timescale, nil
+}
+
+// trackTimescale returns the media timescale of the track with trackID
+func trackTimescale(moov *MoovBox, trackID uint32) (uint32, error) {
+	for _, trak := range moov.Traks {
+		if trak.Tkhd.TrackID == trackID {
+			if trak.Mdia.Mdhd.Timescale == 0 {
+				return 0, fmt.Errorf("track %d has timescale 0", trackID)
+			}
+			return trak.Mdia.Mdhd.Timescale, nil
+		}
+	}
+	return 0, fmt.Errorf("track %d not found", trackID)
+}
+
+// MediaTimeToWallClock returns the UTC time for mediaTime of the track with trackID.
+// The time is extrapolated from the last prft box with a media time not after mediaTime, or the first prft box.
+// prft boxes referring to other tracks are converted to the timescale of the track.
+func (f *File) MediaTimeToWallClock(trackID uint32, mediaTime uint64) (time.Time, error) {
+	refs, timescale, err := f.wallClockRefs(trackID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	ref := refs[0]
+	for _, r := range refs[1:] {
+		if r.mediaTime <= mediaTime && r.mediaTime >= ref.mediaTime {
+			ref = r
+		}
+	}
+	return ref.time.Add(ticksToDuration(int64(mediaTime)-int64(ref.mediaTime), timescale)), nil
+}
+
+// WallClockToMediaTime returns the media time of the track with trackID for the UTC time t.
+// The time is extrapolated from the last prft box with a wall-clock time not after t, or the first prft box.
+func (f *File) WallClockToMediaTime(trackID uint32, t time.Time) (uint64, error) {
+	refs, timescale, err := f.wallClockRefs(trackID)
+	if err != nil {
+		return 0, err
+	}
+	ref := refs[0]
+	for _, r := range refs[1:] {
+		if !r.time.After(t) && !r.time.Before(ref.time) {
+			ref = r
+		}
+	}
+	mediaTime := int64(ref.mediaTime) + durationToTicks(t.Sub(ref.time), timescale)
+	if mediaTime < 0 {
+		return 0, fmt.Errorf("time %s is before media time 0", t)
+	}
+	return uint64(mediaTime), nil
+}
+
+// FragmentAtWallClock returns the fragment with samples of the track with trackID covering the UTC time t.
+func (f *File) FragmentAtWallClock(trackID uint32, t time.Time) (*Fragment, error) {
+	mediaTime, err := f.WallClockToMediaTime(trackID, t)
+	if err != nil {
+		return nil, err
+	}
+	var trex *TrexBox
+	if mvex := f.Init.Moov.Mvex; mvex != nil {
+		trex, _ = mvex.GetTrex(trackID)
+	}
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			if frag.Moof == nil {
+				continue
+			}
+			for _, traf := range frag.Moof.Trafs {
+				if traf.Tfhd.TrackID != trackID || traf.Tfdt == nil {
+					continue
+				}
+				defaultDur := traf.Tfhd.DefaultSampleDuration
+				if !traf.Tfhd.HasDefaultSampleDuration() && trex != nil {
+					defaultDur = trex.DefaultSampleDuration
+				}
+				start := traf.Tfdt.BaseMediaDecodeTime()
+				end := start
+				for _, trun := range traf.Truns {
+					end += trun.Duration(defaultDur)
+				}
+				if start <= mediaTime && mediaTime < end {
+					return frag, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("no fragment of track %d covers %s", trackID, t)
+}
@@ -0,0 +1,82 @@
+package mp4_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+func TestFragmentifyWithPrftAndWallClock(t *testing.T) {
+	fd, err := os.Open("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	inFile, err := mp4.DecodeFile(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 5, 17, 12, 0, 0, 0, time.UTC)
+	opts := mp4.FragmenterOptions{SegmentDurationMS: 2000, Muxed: true,
+		PrftClock: mp4.LinearPrftClock(start), PrftFlags: mp4.PrftTimeCaptured}
+	outFiles, err := inFile.Fragmentify(fd, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	if err := outFiles[0].Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := mp4.DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prfts := f.Prfts()
+	if len(prfts) != len(f.Segments) {
+		t.Fatalf("got %d prft boxes for %d segments", len(prfts), len(f.Segments))
+	}
+	for i, seg := range f.Segments {
+		frag := seg.Fragments[0]
+		if frag.Children[0] != frag.Prft || frag.Children[1] != frag.Moof {
+			t.Errorf("segment %d: prft not directly before moof", i)
+		}
+		if frag.Prft.Flags != mp4.PrftTimeCaptured {
+			t.Errorf("segment %d: prft flags %d", i, frag.Prft.Flags)
+		}
+	}
+
+	videoTrak := f.Init.Moov.Traks[0]
+	trackID := videoTrak.Tkhd.TrackID
+	timescale := videoTrak.Mdia.Mdhd.Timescale
+	mediaTime := uint64(5*timescale + timescale/2)
+	wallClock, err := f.MediaTimeToWallClock(trackID, mediaTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wanted := start.Add(5500 * time.Millisecond); !wallClock.Equal(wanted) {
+		t.Errorf("got wall-clock time %s instead of %s", wallClock, wanted)
+	}
+	gotMediaTime, err := f.WallClockToMediaTime(trackID, wallClock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotMediaTime != mediaTime {
+		t.Errorf("got media time %d instead of %d", gotMediaTime, mediaTime)
+	}
+	frag, err := f.FragmentAtWallClock(trackID, wallClock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frag != f.Segments[2].Fragments[0] {
+		t.Errorf("got fragment with sequence number %d instead of 3", frag.Moof.Mfhd.SequenceNumber)
+	}
+	if _, err := f.FragmentAtWallClock(trackID, start.Add(time.Hour)); err == nil {
+		t.Error("expected error for time after last fragment")
+	}
+	if _, err := f.WallClockToMediaTime(trackID, start.Add(-time.Second)); err == nil {
+		t.Error("expected error for time before media time 0")
+	}
+}
@@ -63,7 +63,7 @@ func DecodeWvttSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	pos := startPos + nrWvttBytesBeforeChildren
 	endPos := startPos + uint64(hdr.Hdrlen+hdr.payloadLen())
 	for pos < endPos {
-		box, err := DecodeBoxSR(pos, sr)
+		box, err := decodeChildBoxSR(pos, sr)
 		if err != nil {
 			return nil, err
 		}
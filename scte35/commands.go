@@ -0,0 +1,347 @@
+package scte35
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// Splice command types
+const (
+	SpliceNullType           = 0x00
+	SpliceScheduleType       = 0x04
+	SpliceInsertType         = 0x05
+	TimeSignalType           = 0x06
+	BandwidthReservationType = 0x07
+	PrivateCommandType       = 0xff
+)
+
+// ptsMask - mask for 33-bit PTS values
+const ptsMask = 1<<33 - 1
+
+// SpliceCommand - splice command in splice_info_section
+type SpliceCommand interface {
+	Type() byte
+	String() string
+	encode(bw *bits.Writer)
+}
+
+// decodeSpliceCommand decodes a command and returns it and the number of bytes consumed.
+// If knownLength is true, all of data must be consumed.
+func decodeSpliceCommand(cmdType byte, data []byte, knownLength bool) (SpliceCommand, int, error) {
+	br := bits.NewReader(bytes.NewReader(data))
+	var cmd SpliceCommand
+	switch cmdType {
+	case SpliceNullType:
+		cmd = &SpliceNull{}
+	case BandwidthReservationType:
+		cmd = &BandwidthReservation{}
+	case SpliceInsertType:
+		cmd = decodeSpliceInsert(br)
+	case TimeSignalType:
+		cmd = &TimeSignal{SpliceTime: decodeSpliceTime(br)}
+	default:
+		if !knownLength {
+			return nil, 0, fmt.Errorf("splice command type 0x%02x with unknown length", cmdType)
+		}
+		return &GenericCommand{CommandType: cmdType, Data: data}, len(data), nil
+	}
+	if err := br.AccError(); err != nil {
+		return nil, 0, fmt.Errorf("splice command type 0x%02x: %w", cmdType, err)
+	}
+	n := br.NrBytesRead()
+	if knownLength && n != len(data) {
+		return nil, 0, fmt.Errorf("splice command type 0x%02x: decoded %d of %d bytes", cmdType, n, len(data))
+	}
+	return cmd, n, nil
+}
+
+// encodeSpliceCommand encodes the command body
+func encodeSpliceCommand(cmd SpliceCommand) ([]byte, error) {
+	buf := bytes.Buffer{}
+	bw := bits.NewWriter(&buf)
+	cmd.encode(bw)
+	bw.Flush()
+	if err := bw.AccError(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SpliceTime - splice_time with optional 33-bit PTS in 90kHz
+type SpliceTime struct {
+	TimeSpecifiedFlag bool
+	PTSTime           uint64
+}
+
+// NewSpliceTime - splice time with specified PTS
+func NewSpliceTime(pts uint64) SpliceTime {
+	return SpliceTime{TimeSpecifiedFlag: true, PTSTime: pts & ptsMask}
+}
+
+func decodeSpliceTime(br *bits.Reader) SpliceTime {
+	st := SpliceTime{TimeSpecifiedFlag: br.ReadFlag()}
+	if st.TimeSpecifiedFlag {
+		_ = br.Read(6) // reserved
+		st.PTSTime = read33(br)
+	} else {
+		_ = br.Read(7) // reserved
+	}
+	return st
+}
+
+func (st SpliceTime) encode(bw *bits.Writer) {
+	if st.TimeSpecifiedFlag {
+		bw.Write(1, 1)
+		bw.Write(0x3f, 6)
+		write33(bw, st.PTSTime)
+	} else {
+		bw.Write(0x7f, 8)
+	}
+}
+
+// String - PTS or "unspecified"
+func (st SpliceTime) String() string {
+	if !st.TimeSpecifiedFlag {
+		return "unspecified"
+	}
+	return fmt.Sprintf("%d", st.PTSTime)
+}
+
+// BreakDuration - break_duration in 90kHz
+type BreakDuration struct {
+	AutoReturn bool
+	Duration   uint64 // 33 bits
+}
+
+// SpliceNull - splice_null command
+type SpliceNull struct{}
+
+// Type - SpliceNullType
+func (c *SpliceNull) Type() byte {
+	return SpliceNullType
+}
+
+func (c *SpliceNull) encode(bw *bits.Writer) {}
+
+// String - command name
+func (c *SpliceNull) String() string {
+	return "splice_null"
+}
+
+// BandwidthReservation - bandwidth_reservation command
+type BandwidthReservation struct{}
+
+// Type - BandwidthReservationType
+func (c *BandwidthReservation) Type() byte {
+	return BandwidthReservationType
+}
+
+func (c *BandwidthReservation) encode(bw *bits.Writer) {}
+
+// String - command name
+func (c *BandwidthReservation) String() string {
+	return "bandwidth_reservation"
+}
+
+// TimeSignal - time_signal command
+type TimeSignal struct {
+	SpliceTime SpliceTime
+}
+
+// NewTimeSignal - time_signal with specified PTS
+func NewTimeSignal(pts uint64) *TimeSignal {
+	return &TimeSignal{SpliceTime: NewSpliceTime(pts)}
+}
+
+// Type - TimeSignalType
+func (c *TimeSignal) Type() byte {
+	return TimeSignalType
+}
+
+func (c *TimeSignal) encode(bw *bits.Writer) {
+	c.SpliceTime.encode(bw)
+}
+
+// String - command name and splice time
+func (c *TimeSignal) String() string {
+	return fmt.Sprintf("time_signal pts=%s", c.SpliceTime)
+}
+
+// SpliceInsertComponent - component in a component-level splice_insert
+type SpliceInsertComponent struct {
+	ComponentTag byte
+	SpliceTime   SpliceTime // Present if not SpliceImmediateFlag
+}
+
+// SpliceInsert - splice_insert command
+type SpliceInsert struct {
+	SpliceEventID              uint32
+	SpliceEventCancelIndicator bool
+	OutOfNetworkIndicator      bool
+	ProgramSpliceFlag          bool
+	DurationFlag               bool
+	SpliceImmediateFlag        bool
+	EventIDComplianceFlag      bool
+	SpliceTime                 SpliceTime              // Present if ProgramSpliceFlag and not SpliceImmediateFlag
+	Components                 []SpliceInsertComponent // Present if not ProgramSpliceFlag
+	BreakDuration              BreakDuration           // Present if DurationFlag
+	UniqueProgramID            uint16
+	AvailNum                   byte
+	AvailsExpected             byte
+}
+
+// NewSpliceInsert - program-level splice_insert at pts. If duration > 0, a break duration with auto return is set.
+func NewSpliceInsert(eventID uint32, outOfNetwork bool, pts, duration uint64) *SpliceInsert {
+	c := &SpliceInsert{
+		SpliceEventID:         eventID,
+		OutOfNetworkIndicator: outOfNetwork,
+		ProgramSpliceFlag:     true,
+		EventIDComplianceFlag: true,
+		SpliceTime:            NewSpliceTime(pts),
+	}
+	if duration > 0 {
+		c.DurationFlag = true
+		c.BreakDuration = BreakDuration{AutoReturn: true, Duration: duration & ptsMask}
+	}
+	return c
+}
+
+// Type - SpliceInsertType
+func (c *SpliceInsert) Type() byte {
+	return SpliceInsertType
+}
+
+func decodeSpliceInsert(br *bits.Reader) *SpliceInsert {
+	c := &SpliceInsert{}
+	c.SpliceEventID = uint32(br.Read(32))
+	c.SpliceEventCancelIndicator = br.ReadFlag()
+	_ = br.Read(7) // reserved
+	if c.SpliceEventCancelIndicator {
+		return c
+	}
+	c.OutOfNetworkIndicator = br.ReadFlag()
+	c.ProgramSpliceFlag = br.ReadFlag()
+	c.DurationFlag = br.ReadFlag()
+	c.SpliceImmediateFlag = br.ReadFlag()
+	c.EventIDComplianceFlag = br.ReadFlag()
+	_ = br.Read(3) // reserved
+	if c.ProgramSpliceFlag {
+		if !c.SpliceImmediateFlag {
+			c.SpliceTime = decodeSpliceTime(br)
+		}
+	} else {
+		componentCount := int(br.Read(8))
+		for i := 0; i < componentCount; i++ {
+			comp := SpliceInsertComponent{ComponentTag: byte(br.Read(8))}
+			if !c.SpliceImmediateFlag {
+				comp.SpliceTime = decodeSpliceTime(br)
+			}
+			c.Components = append(c.Components, comp)
+		}
+	}
+	if c.DurationFlag {
+		c.BreakDuration.AutoReturn = br.ReadFlag()
+		_ = br.Read(6) // reserved
+		c.BreakDuration.Duration = read33(br)
+	}
+	c.UniqueProgramID = uint16(br.Read(16))
+	c.AvailNum = byte(br.Read(8))
+	c.AvailsExpected = byte(br.Read(8))
+	return c
+}
+
+func (c *SpliceInsert) encode(bw *bits.Writer) {
+	bw.Write(uint(c.SpliceEventID), 32)
+	bw.Write(boolBit(c.SpliceEventCancelIndicator), 1)
+	bw.Write(0x7f, 7)
+	if c.SpliceEventCancelIndicator {
+		return
+	}
+	bw.Write(boolBit(c.OutOfNetworkIndicator), 1)
+	bw.Write(boolBit(c.ProgramSpliceFlag), 1)
+	bw.Write(boolBit(c.DurationFlag), 1)
+	bw.Write(boolBit(c.SpliceImmediateFlag), 1)
+	bw.Write(boolBit(c.EventIDComplianceFlag), 1)
+	bw.Write(0x7, 3)
+	if c.ProgramSpliceFlag {
+		if !c.SpliceImmediateFlag {
+			c.SpliceTime.encode(bw)
+		}
+	} else {
+		bw.Write(uint(len(c.Components)), 8)
+		for _, comp := range c.Components {
+			bw.Write(uint(comp.ComponentTag), 8)
+			if !c.SpliceImmediateFlag {
+				comp.SpliceTime.encode(bw)
+			}
+		}
+	}
+	if c.DurationFlag {
+		bw.Write(boolBit(c.BreakDuration.AutoReturn), 1)
+		bw.Write(0x3f, 6)
+		write33(bw, c.BreakDuration.Duration)
+	}
+	bw.Write(uint(c.UniqueProgramID), 16)
+	bw.Write(uint(c.AvailNum), 8)
+	bw.Write(uint(c.AvailsExpected), 8)
+}
+
+// String - command name and main fields
+func (c *SpliceInsert) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "splice_insert eventID=%d", c.SpliceEventID)
+	if c.SpliceEventCancelIndicator {
+		sb.WriteString(" cancel")
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, " outOfNetwork=%t", c.OutOfNetworkIndicator)
+	switch {
+	case c.SpliceImmediateFlag:
+		sb.WriteString(" immediate")
+	case c.ProgramSpliceFlag:
+		fmt.Fprintf(&sb, " pts=%s", c.SpliceTime)
+	}
+	for _, comp := range c.Components {
+		fmt.Fprintf(&sb, " component[%d]", comp.ComponentTag)
+		if !c.SpliceImmediateFlag {
+			fmt.Fprintf(&sb, " pts=%s", comp.SpliceTime)
+		}
+	}
+	if c.DurationFlag {
+		fmt.Fprintf(&sb, " duration=%d autoReturn=%t", c.BreakDuration.Duration, c.BreakDuration.AutoReturn)
+	}
+	fmt.Fprintf(&sb, " uniqueProgramID=%d avail=%d/%d", c.UniqueProgramID, c.AvailNum, c.AvailsExpected)
+	return sb.String()
+}
+
+// GenericCommand - splice command that is not decoded, like splice_schedule and private_command
+type GenericCommand struct {
+	CommandType byte
+	Data        []byte
+}
+
+// Type - command type
+func (c *GenericCommand) Type() byte {
+	return c.CommandType
+}
+
+func (c *GenericCommand) encode(bw *bits.Writer) {
+	for _, b := range c.Data {
+		bw.Write(uint(b), 8)
+	}
+}
+
+// String - command type and data size
+func (c *GenericCommand) String() string {
+	return fmt.Sprintf("splice_command type=0x%02x data=%d bytes", c.CommandType, len(c.Data))
+}
+
+func boolBit(b bool) uint {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,30 @@
+package scte35
+
+// crcTable - table for the MPEG-2 CRC-32 with polynomial 0x04c11db7
+var crcTable = makeCRCTable()
+
+func makeCRCTable() [256]uint32 {
+	var table [256]uint32
+	for i := range table {
+		crc := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04c11db7
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}
+
+// CRC32 - MPEG-2 CRC-32 as used in splice_info_section.
+// The CRC of a complete section including its CRC_32 field is zero.
+func CRC32(data []byte) uint32 {
+	crc := uint32(0xffffffff)
+	for _, b := range data {
+		crc = crc<<8 ^ crcTable[byte(crc>>24)^b]
+	}
+	return crc
+}
@@ -0,0 +1,308 @@
+package scte35
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// CUEIdentifier - identifier "CUEI" of SCTE-35 splice descriptors
+const CUEIdentifier = 0x43554549
+
+// Splice descriptor tags
+const (
+	AvailDescriptorTag        = 0x00
+	DTMFDescriptorTag         = 0x01
+	SegmentationDescriptorTag = 0x02
+	TimeDescriptorTag         = 0x03
+	AudioDescriptorTag        = 0x04
+)
+
+// Segmentation type IDs
+const (
+	SegTypeNotIndicated                           = 0x00
+	SegTypeProgramStart                           = 0x10
+	SegTypeProgramEnd                             = 0x11
+	SegTypeChapterStart                           = 0x20
+	SegTypeChapterEnd                             = 0x21
+	SegTypeBreakStart                             = 0x22
+	SegTypeBreakEnd                               = 0x23
+	SegTypeProviderAdvertisementStart             = 0x30
+	SegTypeProviderAdvertisementEnd               = 0x31
+	SegTypeDistributorAdvertisementStart          = 0x32
+	SegTypeDistributorAdvertisementEnd            = 0x33
+	SegTypeProviderPlacementOpportunityStart      = 0x34
+	SegTypeProviderPlacementOpportunityEnd        = 0x35
+	SegTypeDistributorPlacementOpportunityStart   = 0x36
+	SegTypeDistributorPlacementOpportunityEnd     = 0x37
+	SegTypeProviderOverlayPlacementOpportunity    = 0x38
+	SegTypeDistributorOverlayPlacementOpportunity = 0x3a
+	SegTypeProviderAdBlockStart                   = 0x44
+	SegTypeDistributorAdBlockStart                = 0x46
+)
+
+// SpliceDescriptor - descriptor in splice_info_section
+type SpliceDescriptor interface {
+	Tag() byte
+	String() string
+	encodeBody(bw *bits.Writer)
+}
+
+// decodeSpliceDescriptors decodes all descriptors in the descriptor loop
+func decodeSpliceDescriptors(data []byte) ([]SpliceDescriptor, error) {
+	var descs []SpliceDescriptor
+	for len(data) > 0 {
+		if len(data) < 6 {
+			return nil, fmt.Errorf("splice descriptor too short: %d bytes", len(data))
+		}
+		tag := data[0]
+		length := int(data[1])
+		if length < 4 || 2+length > len(data) {
+			return nil, fmt.Errorf("splice descriptor tag %d: bad length %d", tag, length)
+		}
+		identifier := uint32(data[2])<<24 | uint32(data[3])<<16 | uint32(data[4])<<8 | uint32(data[5])
+		body := data[6 : 2+length]
+		var d SpliceDescriptor
+		if tag == SegmentationDescriptorTag && identifier == CUEIdentifier {
+			sd, err := decodeSegmentationDescriptor(body)
+			if err != nil {
+				return nil, err
+			}
+			d = sd
+		} else {
+			d = &GenericDescriptor{DescriptorTag: tag, Identifier: identifier, Data: body}
+		}
+		descs = append(descs, d)
+		data = data[2+length:]
+	}
+	return descs, nil
+}
+
+// encodeSpliceDescriptor encodes tag, length, identifier, and body
+func encodeSpliceDescriptor(d SpliceDescriptor) ([]byte, error) {
+	buf := bytes.Buffer{}
+	bw := bits.NewWriter(&buf)
+	d.encodeBody(bw)
+	bw.Flush()
+	if err := bw.AccError(); err != nil {
+		return nil, err
+	}
+	identifier := uint32(CUEIdentifier)
+	if g, ok := d.(*GenericDescriptor); ok {
+		identifier = g.Identifier
+	}
+	length := 4 + buf.Len()
+	if length > 0xff {
+		return nil, fmt.Errorf("splice descriptor tag %d: length %d too large", d.Tag(), length)
+	}
+	out := []byte{d.Tag(), byte(length),
+		byte(identifier >> 24), byte(identifier >> 16), byte(identifier >> 8), byte(identifier)}
+	return append(out, buf.Bytes()...), nil
+}
+
+// SegmentationComponent - component in a component-level segmentation_descriptor
+type SegmentationComponent struct {
+	ComponentTag byte
+	PTSOffset    uint64 // 33 bits
+}
+
+// SegmentationDescriptor - segmentation_descriptor with identifier CUEI
+type SegmentationDescriptor struct {
+	SegmentationEventID                    uint32
+	SegmentationEventCancelIndicator       bool
+	SegmentationEventIDComplianceIndicator bool
+	ProgramSegmentationFlag                bool
+	SegmentationDurationFlag               bool
+	DeliveryNotRestrictedFlag              bool
+	WebDeliveryAllowedFlag                 bool // Present if not DeliveryNotRestrictedFlag
+	NoRegionalBlackoutFlag                 bool // Present if not DeliveryNotRestrictedFlag
+	ArchiveAllowedFlag                     bool // Present if not DeliveryNotRestrictedFlag
+	DeviceRestrictions                     byte // Present if not DeliveryNotRestrictedFlag
+	Components                             []SegmentationComponent
+	SegmentationDuration                   uint64 // 40 bits in 90kHz. Present if SegmentationDurationFlag
+	SegmentationUPIDType                   byte
+	SegmentationUPID                       []byte
+	SegmentationTypeID                     byte
+	SegmentNum                             byte
+	SegmentsExpected                       byte
+	SubSegmentsPresent                     bool
+	SubSegmentNum                          byte
+	SubSegmentsExpected                    byte
+}
+
+// NewSegmentationDescriptor - program-level segmentation_descriptor without delivery restrictions.
+// If duration > 0, the segmentation duration is set.
+func NewSegmentationDescriptor(eventID uint32, segmentationTypeID byte, duration uint64) *SegmentationDescriptor {
+	d := &SegmentationDescriptor{
+		SegmentationEventID:       eventID,
+		ProgramSegmentationFlag:   true,
+		DeliveryNotRestrictedFlag: true,
+		SegmentationTypeID:        segmentationTypeID,
+	}
+	if duration > 0 {
+		d.SegmentationDurationFlag = true
+		d.SegmentationDuration = duration
+	}
+	return d
+}
+
+// Tag - SegmentationDescriptorTag
+func (d *SegmentationDescriptor) Tag() byte {
+	return SegmentationDescriptorTag
+}
+
+func decodeSegmentationDescriptor(body []byte) (*SegmentationDescriptor, error) {
+	br := bits.NewReader(bytes.NewReader(body))
+	d := &SegmentationDescriptor{}
+	d.SegmentationEventID = uint32(br.Read(32))
+	d.SegmentationEventCancelIndicator = br.ReadFlag()
+	d.SegmentationEventIDComplianceIndicator = br.ReadFlag()
+	_ = br.Read(6) // reserved
+	if !d.SegmentationEventCancelIndicator {
+		d.ProgramSegmentationFlag = br.ReadFlag()
+		d.SegmentationDurationFlag = br.ReadFlag()
+		d.DeliveryNotRestrictedFlag = br.ReadFlag()
+		if !d.DeliveryNotRestrictedFlag {
+			d.WebDeliveryAllowedFlag = br.ReadFlag()
+			d.NoRegionalBlackoutFlag = br.ReadFlag()
+			d.ArchiveAllowedFlag = br.ReadFlag()
+			d.DeviceRestrictions = byte(br.Read(2))
+		} else {
+			_ = br.Read(5) // reserved
+		}
+		if !d.ProgramSegmentationFlag {
+			componentCount := int(br.Read(8))
+			for i := 0; i < componentCount; i++ {
+				comp := SegmentationComponent{ComponentTag: byte(br.Read(8))}
+				_ = br.Read(7) // reserved
+				comp.PTSOffset = read33(br)
+				d.Components = append(d.Components, comp)
+			}
+		}
+		if d.SegmentationDurationFlag {
+			hi := uint64(br.Read(8))
+			d.SegmentationDuration = hi<<32 | uint64(br.Read(32))
+		}
+		d.SegmentationUPIDType = byte(br.Read(8))
+		upidLength := int(br.Read(8))
+		d.SegmentationUPID = readBytes(br, upidLength)
+		d.SegmentationTypeID = byte(br.Read(8))
+		d.SegmentNum = byte(br.Read(8))
+		d.SegmentsExpected = byte(br.Read(8))
+		if err := br.AccError(); err != nil {
+			return nil, fmt.Errorf("segmentation_descriptor: %w", err)
+		}
+		// sub_segment_num and sub_segments_expected are optional for some segmentation types
+		if br.NrBytesRead()+2 <= len(body) && hasSubSegments(d.SegmentationTypeID) {
+			d.SubSegmentsPresent = true
+			d.SubSegmentNum = byte(br.Read(8))
+			d.SubSegmentsExpected = byte(br.Read(8))
+		}
+	}
+	if err := br.AccError(); err != nil {
+		return nil, fmt.Errorf("segmentation_descriptor: %w", err)
+	}
+	return d, nil
+}
+
+// hasSubSegments - segmentation types that may carry sub_segment_num and sub_segments_expected
+func hasSubSegments(segmentationTypeID byte) bool {
+	switch segmentationTypeID {
+	case SegTypeProviderPlacementOpportunityStart, SegTypeDistributorPlacementOpportunityStart,
+		SegTypeProviderOverlayPlacementOpportunity, SegTypeDistributorOverlayPlacementOpportunity,
+		SegTypeProviderAdBlockStart, SegTypeDistributorAdBlockStart:
+		return true
+	}
+	return false
+}
+
+func (d *SegmentationDescriptor) encodeBody(bw *bits.Writer) {
+	bw.Write(uint(d.SegmentationEventID), 32)
+	bw.Write(boolBit(d.SegmentationEventCancelIndicator), 1)
+	bw.Write(boolBit(d.SegmentationEventIDComplianceIndicator), 1)
+	bw.Write(0x3f, 6)
+	if d.SegmentationEventCancelIndicator {
+		return
+	}
+	bw.Write(boolBit(d.ProgramSegmentationFlag), 1)
+	bw.Write(boolBit(d.SegmentationDurationFlag), 1)
+	bw.Write(boolBit(d.DeliveryNotRestrictedFlag), 1)
+	if !d.DeliveryNotRestrictedFlag {
+		bw.Write(boolBit(d.WebDeliveryAllowedFlag), 1)
+		bw.Write(boolBit(d.NoRegionalBlackoutFlag), 1)
+		bw.Write(boolBit(d.ArchiveAllowedFlag), 1)
+		bw.Write(uint(d.DeviceRestrictions), 2)
+	} else {
+		bw.Write(0x1f, 5)
+	}
+	if !d.ProgramSegmentationFlag {
+		bw.Write(uint(len(d.Components)), 8)
+		for _, comp := range d.Components {
+			bw.Write(uint(comp.ComponentTag), 8)
+			bw.Write(0x7f, 7)
+			write33(bw, comp.PTSOffset)
+		}
+	}
+	if d.SegmentationDurationFlag {
+		bw.Write(uint(d.SegmentationDuration>>32)&0xff, 8)
+		bw.Write(uint(d.SegmentationDuration&0xffffffff), 32)
+	}
+	bw.Write(uint(d.SegmentationUPIDType), 8)
+	bw.Write(uint(len(d.SegmentationUPID)), 8)
+	for _, b := range d.SegmentationUPID {
+		bw.Write(uint(b), 8)
+	}
+	bw.Write(uint(d.SegmentationTypeID), 8)
+	bw.Write(uint(d.SegmentNum), 8)
+	bw.Write(uint(d.SegmentsExpected), 8)
+	if d.SubSegmentsPresent {
+		bw.Write(uint(d.SubSegmentNum), 8)
+		bw.Write(uint(d.SubSegmentsExpected), 8)
+	}
+}
+
+// String - descriptor name and main fields
+func (d *SegmentationDescriptor) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "segmentation_descriptor eventID=%d", d.SegmentationEventID)
+	if d.SegmentationEventCancelIndicator {
+		sb.WriteString(" cancel")
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, " typeID=0x%02x segment=%d/%d", d.SegmentationTypeID, d.SegmentNum, d.SegmentsExpected)
+	if d.SubSegmentsPresent {
+		fmt.Fprintf(&sb, " subSegment=%d/%d", d.SubSegmentNum, d.SubSegmentsExpected)
+	}
+	if d.SegmentationDurationFlag {
+		fmt.Fprintf(&sb, " duration=%d", d.SegmentationDuration)
+	}
+	if len(d.SegmentationUPID) > 0 {
+		fmt.Fprintf(&sb, " upidType=0x%02x upid=%x", d.SegmentationUPIDType, d.SegmentationUPID)
+	}
+	return sb.String()
+}
+
+// GenericDescriptor - splice descriptor that is not decoded
+type GenericDescriptor struct {
+	DescriptorTag byte
+	Identifier    uint32
+	Data          []byte
+}
+
+// Tag - descriptor tag
+func (d *GenericDescriptor) Tag() byte {
+	return d.DescriptorTag
+}
+
+func (d *GenericDescriptor) encodeBody(bw *bits.Writer) {
+	for _, b := range d.Data {
+		bw.Write(uint(b), 8)
+	}
+}
+
+// String - descriptor tag, identifier, and data size
+func (d *GenericDescriptor) String() string {
+	return fmt.Sprintf("splice_descriptor tag=0x%02x identifier=0x%08x data=%d bytes", d.DescriptorTag, d.Identifier, len(d.Data))
+}
@@ -0,0 +1,10 @@
+/*
+Package scte35 decodes (parses) and encodes (writes) SCTE-35 splice_info_section messages
+as carried in emsg boxes with scheme urn:scte:scte35:2013:bin.
+
+The splice commands splice_null, splice_insert, time_signal, and bandwidth_reservation,
+as well as the segmentation_descriptor are decoded into typed structures.
+Other commands and descriptors are kept as raw bytes. Encrypted sections are not supported.
+The CRC_32 is validated when decoding and calculated when encoding.
+*/
+package scte35
@@ -0,0 +1,228 @@
+package scte35
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Eyevinn/mp4ff/bits"
+)
+
+// TableID - table_id of splice_info_section
+const TableID = 0xfc
+
+// minSectionLength - section_length without splice command and descriptors
+const minSectionLength = 17
+
+// ErrBadCRC is returned when decoding a section with a CRC_32 mismatch
+var ErrBadCRC = errors.New("splice_info_section CRC_32 mismatch")
+
+// SpliceInfoSection - SCTE-35 splice_info_section (unencrypted)
+type SpliceInfoSection struct {
+	SAPType         byte
+	ProtocolVersion byte
+	PTSAdjustment   uint64 // 33 bits in 90kHz
+	CWIndex         byte
+	Tier            uint16 // 12 bits
+	SpliceCommand   SpliceCommand
+	Descriptors     []SpliceDescriptor
+}
+
+// NewSpliceInfoSection - section with cmd, SAP type 3 (not specified), cw_index 0xff, and tier 0xfff
+func NewSpliceInfoSection(cmd SpliceCommand) *SpliceInfoSection {
+	return &SpliceInfoSection{SAPType: 3, CWIndex: 0xff, Tier: 0xfff, SpliceCommand: cmd}
+}
+
+// AddDescriptor - add a splice descriptor
+func (s *SpliceInfoSection) AddDescriptor(d SpliceDescriptor) {
+	s.Descriptors = append(s.Descriptors, d)
+}
+
+// DecodeSpliceInfoSection - decode splice_info_section at the start of data and validate its CRC_32
+func DecodeSpliceInfoSection(data []byte) (*SpliceInfoSection, error) {
+	if len(data) < 3 {
+		return nil, fmt.Errorf("splice_info_section too short: %d bytes", len(data))
+	}
+	if data[0] != TableID {
+		return nil, fmt.Errorf("table_id 0x%02x is not 0x%02x", data[0], TableID)
+	}
+	sectionLength := int(data[1]&0x0f)<<8 | int(data[2])
+	total := 3 + sectionLength
+	if sectionLength < minSectionLength || total > len(data) {
+		return nil, fmt.Errorf("bad section_length %d for %d bytes", sectionLength, len(data))
+	}
+	section := data[:total]
+	if CRC32(section) != 0 {
+		return nil, ErrBadCRC
+	}
+	br := bits.NewReader(bytes.NewReader(section))
+	s := &SpliceInfoSection{}
+	_ = br.Read(8) // table_id
+	_ = br.Read(2) // section_syntax_indicator and private_indicator
+	s.SAPType = byte(br.Read(2))
+	_ = br.Read(12) // section_length
+	s.ProtocolVersion = byte(br.Read(8))
+	if br.ReadFlag() {
+		return nil, fmt.Errorf("encrypted splice_info_section not supported")
+	}
+	_ = br.Read(6) // encryption_algorithm
+	s.PTSAdjustment = read33(br)
+	s.CWIndex = byte(br.Read(8))
+	s.Tier = uint16(br.Read(12))
+	cmdLength := int(br.Read(12))
+	cmdType := byte(br.Read(8))
+	if err := br.AccError(); err != nil {
+		return nil, err
+	}
+	pos := br.NrBytesRead()
+	end := total - 4 // CRC_32
+	cmdData := section[pos:end]
+	if cmdLength != 0xfff { // 0xfff signals unknown length in legacy sections
+		if pos+cmdLength > end {
+			return nil, fmt.Errorf("splice_command_length %d too large", cmdLength)
+		}
+		cmdData = section[pos : pos+cmdLength]
+	}
+	cmd, n, err := decodeSpliceCommand(cmdType, cmdData, cmdLength != 0xfff)
+	if err != nil {
+		return nil, err
+	}
+	s.SpliceCommand = cmd
+	pos += n
+	if pos+2 > end {
+		return nil, fmt.Errorf("no room for descriptor_loop_length")
+	}
+	loopLength := int(binary.BigEndian.Uint16(section[pos:]))
+	pos += 2
+	if pos+loopLength > end {
+		return nil, fmt.Errorf("descriptor_loop_length %d too large", loopLength)
+	}
+	s.Descriptors, err = decodeSpliceDescriptors(section[pos : pos+loopLength])
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Encode - encode section including CRC_32
+func (s *SpliceInfoSection) Encode() ([]byte, error) {
+	if s.SpliceCommand == nil {
+		return nil, fmt.Errorf("no splice command")
+	}
+	cmd, err := encodeSpliceCommand(s.SpliceCommand)
+	if err != nil {
+		return nil, err
+	}
+	if len(cmd) >= 0xfff {
+		return nil, fmt.Errorf("splice command size %d too large", len(cmd))
+	}
+	var descs []byte
+	for _, d := range s.Descriptors {
+		dd, err := encodeSpliceDescriptor(d)
+		if err != nil {
+			return nil, err
+		}
+		descs = append(descs, dd...)
+	}
+	sectionLength := minSectionLength + len(cmd) + len(descs)
+	if sectionLength > 0xfff || len(descs) > 0xffff {
+		return nil, fmt.Errorf("section_length %d too large", sectionLength)
+	}
+	buf := bytes.Buffer{}
+	bw := bits.NewWriter(&buf)
+	bw.Write(TableID, 8)
+	bw.Write(0, 2) // section_syntax_indicator and private_indicator
+	bw.Write(uint(s.SAPType), 2)
+	bw.Write(uint(sectionLength), 12)
+	bw.Write(uint(s.ProtocolVersion), 8)
+	bw.Write(0, 1) // encrypted_packet
+	bw.Write(0, 6) // encryption_algorithm
+	write33(bw, s.PTSAdjustment)
+	bw.Write(uint(s.CWIndex), 8)
+	bw.Write(uint(s.Tier), 12)
+	bw.Write(uint(len(cmd)), 12)
+	bw.Write(uint(s.SpliceCommand.Type()), 8)
+	if err := bw.AccError(); err != nil {
+		return nil, err
+	}
+	buf.Write(cmd)
+	buf.Write([]byte{byte(len(descs) >> 8), byte(len(descs))})
+	buf.Write(descs)
+	crc := CRC32(buf.Bytes())
+	buf.Write([]byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)})
+	return buf.Bytes(), nil
+}
+
+// SplicePTS - PTS of the splice including pts_adjustment from a time_signal or
+// program-level splice_insert. ok is false for immediate splices and other commands.
+func (s *SpliceInfoSection) SplicePTS() (pts uint64, ok bool) {
+	var st SpliceTime
+	switch c := s.SpliceCommand.(type) {
+	case *TimeSignal:
+		st = c.SpliceTime
+	case *SpliceInsert:
+		if c.SpliceEventCancelIndicator || !c.ProgramSpliceFlag || c.SpliceImmediateFlag {
+			return 0, false
+		}
+		st = c.SpliceTime
+	default:
+		return 0, false
+	}
+	if !st.TimeSpecifiedFlag {
+		return 0, false
+	}
+	return (st.PTSTime + s.PTSAdjustment) & ptsMask, true
+}
+
+// Duration - break_duration of a splice_insert or the segmentation_duration of the first
+// segmentation_descriptor with a duration, in 90kHz ticks. ok is false if no duration is present.
+func (s *SpliceInfoSection) Duration() (duration uint64, ok bool) {
+	if c, isInsert := s.SpliceCommand.(*SpliceInsert); isInsert && c.DurationFlag {
+		return c.BreakDuration.Duration, true
+	}
+	for _, d := range s.Descriptors {
+		if sd, isSeg := d.(*SegmentationDescriptor); isSeg && sd.SegmentationDurationFlag {
+			return sd.SegmentationDuration, true
+		}
+	}
+	return 0, false
+}
+
+// String - multi-line description of the section
+func (s *SpliceInfoSection) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "splice_info_section sapType=%d ptsAdjustment=%d tier=%d", s.SAPType, s.PTSAdjustment, s.Tier)
+	if s.SpliceCommand != nil {
+		fmt.Fprintf(&sb, "\n  %s", s.SpliceCommand)
+	}
+	for _, d := range s.Descriptors {
+		fmt.Fprintf(&sb, "\n  %s", d)
+	}
+	return sb.String()
+}
+
+// read33 reads a 33-bit value
+func read33(br *bits.Reader) uint64 {
+	hi := uint64(br.Read(1))
+	return hi<<32 | uint64(br.Read(32))
+}
+
+// write33 writes a 33-bit value
+func write33(bw *bits.Writer, v uint64) {
+	bw.Write(uint(v>>32)&1, 1)
+	bw.Write(uint(v&0xffffffff), 32)
+}
+
+// readBytes reads n bytes from a byte-aligned reader. Returns nil if n is 0.
+func readBytes(br *bits.Reader, n int) []byte {
+	if n == 0 {
+		return nil
+	}
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(br.Read(8))
+	}
+	return data
+}
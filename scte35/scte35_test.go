@@ -0,0 +1,185 @@
+package scte35
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+// Examples from SCTE 35 2019 section 14
+const (
+	timeSignalPlacementOpportunityStart = "/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg=="
+	spliceInsertOutOfNetwork            = "/DAvAAAAAAAA///wFAVIAACPf+/+c2nALv4AUsz1AAAAAAAKAAhDVUVJAAABNWLbowo="
+)
+
+func TestDecodeEncodeExamples(t *testing.T) {
+	testCases := []struct {
+		name    string
+		b64     string
+		wanted  *SpliceInfoSection
+		pts     uint64
+		dur     uint64
+		hasTime bool
+	}{
+		{
+			name: "time_signal",
+			b64:  timeSignalPlacementOpportunityStart,
+			wanted: &SpliceInfoSection{
+				SAPType:       3,
+				CWIndex:       0xff,
+				Tier:          0xfff,
+				SpliceCommand: &TimeSignal{SpliceTime: SpliceTime{TimeSpecifiedFlag: true, PTSTime: 0x072bd0050}},
+				Descriptors: []SpliceDescriptor{
+					&SegmentationDescriptor{
+						SegmentationEventID:                    0x4800008e,
+						SegmentationEventIDComplianceIndicator: true,
+						ProgramSegmentationFlag:                true,
+						SegmentationDurationFlag:               true,
+						DeliveryNotRestrictedFlag:              false,
+						NoRegionalBlackoutFlag:                 true,
+						ArchiveAllowedFlag:                     true,
+						DeviceRestrictions:                     3,
+						SegmentationDuration:                   0x0001a599b0,
+						SegmentationUPIDType:                   8,
+						SegmentationUPID:                       []byte{0, 0, 0, 0, 0x2c, 0xa0, 0xa1, 0x8a},
+						SegmentationTypeID:                     SegTypeProviderPlacementOpportunityStart,
+						SegmentNum:                             2,
+					},
+				},
+			},
+			pts:     0x072bd0050,
+			dur:     0x0001a599b0,
+			hasTime: true,
+		},
+		{
+			name: "splice_insert",
+			b64:  spliceInsertOutOfNetwork,
+			wanted: &SpliceInfoSection{
+				SAPType: 3,
+				CWIndex: 0xff,
+				Tier:    0xfff,
+				SpliceCommand: &SpliceInsert{
+					SpliceEventID:         0x4800008f,
+					OutOfNetworkIndicator: true,
+					ProgramSpliceFlag:     true,
+					DurationFlag:          true,
+					EventIDComplianceFlag: true,
+					SpliceTime:            SpliceTime{TimeSpecifiedFlag: true, PTSTime: 0x07369c02e},
+					BreakDuration:         BreakDuration{AutoReturn: true, Duration: 0x00052ccf5},
+				},
+				Descriptors: []SpliceDescriptor{
+					&GenericDescriptor{DescriptorTag: AvailDescriptorTag, Identifier: CUEIdentifier,
+						Data: []byte{0, 0, 1, 0x35}},
+				},
+			},
+			pts:     0x07369c02e,
+			dur:     0x00052ccf5,
+			hasTime: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := base64.StdEncoding.DecodeString(tc.b64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sis, err := DecodeSpliceInfoSection(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(sis, tc.wanted); diff != nil {
+				t.Error(diff)
+			}
+			pts, ok := sis.SplicePTS()
+			if ok != tc.hasTime || pts != tc.pts {
+				t.Errorf("got splice PTS %d %t instead of %d", pts, ok, tc.pts)
+			}
+			dur, _ := sis.Duration()
+			if dur != tc.dur {
+				t.Errorf("got duration %d instead of %d", dur, tc.dur)
+			}
+			out, err := sis.Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out, data) {
+				t.Errorf("encoded %x instead of %x", out, data)
+			}
+		})
+	}
+}
+
+func TestCreateAndCRC(t *testing.T) {
+	sis := NewSpliceInfoSection(NewTimeSignal(1<<33 + 100))
+	sis.PTSAdjustment = 1<<33 - 50
+	seg := NewSegmentationDescriptor(17, SegTypeDistributorPlacementOpportunityStart, 30*PTSTimescale)
+	seg.SubSegmentsPresent = true
+	seg.SubSegmentNum = 1
+	seg.SubSegmentsExpected = 2
+	sis.AddDescriptor(seg)
+	sis.AddDescriptor(&SegmentationDescriptor{SegmentationEventID: 18, SegmentationEventCancelIndicator: true})
+	data, err := sis.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if CRC32(data) != 0 {
+		t.Error("CRC of encoded section is not zero")
+	}
+	got, err := DecodeSpliceInfoSection(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(got, sis); diff != nil {
+		t.Error(diff)
+	}
+	if pts, ok := got.SplicePTS(); !ok || pts != 50 {
+		t.Errorf("got splice PTS %d %t instead of 50 after wrap-around", pts, ok)
+	}
+	data[len(data)-5] ^= 0x01
+	if _, err := DecodeSpliceInfoSection(data); !errors.Is(err, ErrBadCRC) {
+		t.Errorf("got error %v instead of ErrBadCRC", err)
+	}
+}
+
+func TestSpliceInsertVariants(t *testing.T) {
+	cmds := []SpliceCommand{
+		&SpliceNull{},
+		&BandwidthReservation{},
+		&SpliceInsert{SpliceEventID: 1, SpliceEventCancelIndicator: true},
+		&SpliceInsert{SpliceEventID: 2, ProgramSpliceFlag: true, SpliceImmediateFlag: true, AvailNum: 1, AvailsExpected: 2},
+		&SpliceInsert{SpliceEventID: 3, Components: []SpliceInsertComponent{
+			{ComponentTag: 1, SpliceTime: NewSpliceTime(1000)}, {ComponentTag: 2}}},
+		&GenericCommand{CommandType: PrivateCommandType, Data: []byte{'a', 'b', 'c', 'd', 1}},
+	}
+	for _, cmd := range cmds {
+		sis := NewSpliceInfoSection(cmd)
+		data, err := sis.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DecodeSpliceInfoSection(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := deep.Equal(got, sis); diff != nil {
+			t.Errorf("%s: %v", cmd, diff)
+		}
+		if _, ok := got.SplicePTS(); ok {
+			t.Errorf("%s: unexpected splice PTS", cmd)
+		}
+	}
+}
+
+func TestMediaTimeConversion(t *testing.T) {
+	const ptsOffset = 1<<33 - 90000
+	pts := PTSFromMediaTime(96000, 48000, ptsOffset)
+	if pts != 90000 {
+		t.Errorf("got PTS %d instead of 90000", pts)
+	}
+	if mt := MediaTimeFromPTS(pts, 48000, ptsOffset); mt != 96000 {
+		t.Errorf("got media time %d instead of 96000", mt)
+	}
+}
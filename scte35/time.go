@@ -0,0 +1,23 @@
+package scte35
+
+// PTSTimescale - timescale of PTS values in splice_info_section
+const PTSTimescale = 90000
+
+// PTSFromMediaTime - 33-bit PTS for mediaTime in timescale, given ptsOffset as the PTS at media time 0
+func PTSFromMediaTime(mediaTime uint64, timescale uint32, ptsOffset uint64) uint64 {
+	return (scale(mediaTime, PTSTimescale, uint64(timescale)) + ptsOffset) & ptsMask
+}
+
+// MediaTimeFromPTS - media time in timescale for a 33-bit PTS, given ptsOffset as the PTS at media time 0.
+// A PTS before ptsOffset is interpreted as a wrap-around of the 33-bit counter.
+func MediaTimeFromPTS(pts uint64, timescale uint32, ptsOffset uint64) uint64 {
+	return scale((pts-ptsOffset)&ptsMask, uint64(timescale), PTSTimescale)
+}
+
+// scale returns t * to / from rounded to the nearest integer
+func scale(t, to, from uint64) uint64 {
+	if from == 0 || to == from {
+		return t
+	}
+	return (t/from)*to + ((t%from)*to+from/2)/from
+}